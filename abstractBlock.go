@@ -1,10 +1,9 @@
 package asciidocgo
 
 import (
-	"strconv"
-
 	"github.com/VonC/asciidocgo/consts/contentModel"
 	"github.com/VonC/asciidocgo/consts/context"
+	"github.com/VonC/asciidocgo/consts/severity"
 )
 
 /* An abstract class that provides state and methods for managing
@@ -23,6 +22,8 @@ type abstractBlock struct {
 	nextSectionNumber int
 	subbedTitle       string
 	_section          sectionAble
+	sourceLocation    *Cursor
+	_node             interface{}
 }
 
 var testab = ""
@@ -40,7 +41,7 @@ func newAbstractBlock(parent *abstractBlock, c context.Context) *abstractBlock {
 		parentAn = parent.abstractNode
 	}
 	an := newAbstractNode(parentAn, c)
-	ab := &abstractBlock{an, contentmodel.Compound, []string{}, templateName, []*abstractBlock{}, level, "", "", "", 0, 1, "", nil}
+	ab := &abstractBlock{an, contentmodel.Compound, []string{}, templateName, []*abstractBlock{}, level, "", "", "", 0, 1, "", nil, nil, nil}
 	return ab
 }
 
@@ -57,6 +58,17 @@ func (ab *abstractBlock) Subs() []string {
 	return ab.subs
 }
 
+/* The substitutions of this block, as they are applied */
+func (ab *abstractBlock) subArray() subArray {
+	res := subArray{}
+	for _, name := range ab.subs {
+		if aSub := aToSEValues(name); aSub != nil {
+			res = append(res, aSub)
+		}
+	}
+	return res
+}
+
 /* Get/Set the String name of the render template */
 func (ab *abstractBlock) TemplateName() string {
 	return ab.templateName
@@ -65,6 +77,23 @@ func (ab *abstractBlock) SetTemplateName(tn string) {
 	ab.templateName = tn
 }
 
+/* The position in the source document of the first line of this block
+(nil if the block was not built from parsed source) */
+func (ab *abstractBlock) SourceLocation() *Cursor {
+	return ab.sourceLocation
+}
+func (ab *abstractBlock) setSourceLocation(cursor *Cursor) {
+	ab.sourceLocation = cursor
+}
+
+/* Report a message about this block to the logger of its document,
+located at the source location of the block */
+func (ab *abstractBlock) log(s severity.Severity, text string) {
+	if ab.Document() != nil {
+		logMessage(ab.Document().Logger(), s, text, ab.sourceLocation)
+	}
+}
+
 /* Array of Asciidoctor::AbstractBlock sub-blocks for this block */
 func (ab *abstractBlock) Blocks() []*abstractBlock {
 	return ab.blocks
@@ -82,6 +111,17 @@ func (ab *abstractBlock) SetLevel(l int) {
 /* Set the String block title. */
 func (ab *abstractBlock) setTitle(t string) {
 	ab.title = t
+	ab.subbedTitle = ""
+}
+
+/* Forget the interpreted title of this block and of its sub-blocks:
+each title is interpreted again (registering its footnotes and index
+terms anew) the next time it is used */
+func (ab *abstractBlock) resetSubbedTitles() {
+	ab.subbedTitle = ""
+	for _, block := range ab.blocks {
+		block.resetSubbedTitles()
+	}
 }
 
 /* Get/Set the String style (block type qualifier) for this block. */
@@ -110,13 +150,20 @@ func (ab *abstractBlock) SetContext(c context.Context) {
 /* Get the rendered String content for this Block.
 If the block has child blocks, the content method should cause them
 to be rendered and returned as content that can be included
-in the parent block's template. */
+in the parent block's template.
+Once a callout list is rendered, the callout ids of the document
+are read from the next list. */
 func (ab *abstractBlock) Render() string {
 	if ab.Document() != nil {
 		ab.Document().PlaybackAttributes(ab.Attributes())
 	}
-	return ab.Renderer().Render(ab.TemplateName(), ab, []interface{}{})
+	res := ab.Renderer().Render(ab.TemplateName(), ab, []interface{}{})
 	// TODO make sure document playback_attributes and renderer hare implemented
+	if doc, ok := ab.Document().(*Document); ok && ab.Context() == context.Colist {
+		// the next callouts refer to the next callout list
+		doc.callouts.NextList()
+	}
+	return res
 }
 
 /* Get an rendered version of the block content, rendering the
@@ -158,14 +205,10 @@ Examples
    block.title
    => "Foo 3^ # :: Bar(1)" */
 func (ab *abstractBlock) Title() string {
-	//if ab.subbedTitle != "" {
-	//	return ab.subbedTitle
-	//}
-	// TODO add substitutor as mixin in Section and Block
-	//if ab.Title() != "" {
-	// return applyTitleSubs(ab.Title())
-	//}
-	return ab.title
+	if ab.subbedTitle == "" && ab.title != "" {
+		ab.subbedTitle = ab.ApplyTitleSubs(ab.title)
+	}
+	return ab.subbedTitle
 }
 
 /* Convenience method that returns the interpreted title of the Block
@@ -177,7 +220,7 @@ If the Block does not have a caption, the interpreted title is returned.
 Returns the String title prefixed with the caption, or just the title if no
 caption is set */
 func (ab *abstractBlock) CaptionedTitle() string {
-	return ab.caption + ab.title
+	return ab.caption + ab.Title()
 }
 
 /* Determine whether this Block contains block content
@@ -196,11 +239,17 @@ func (ab *abstractBlock) HasBlocks() bool {
      # => true
      block.blocks.size
      # => 2
+ A section appended to a document or a section is assigned its index
+ (and number) within its parent.
  Returns nothing. */
 func (ab *abstractBlock) AppendBlock(block *abstractBlock) {
 	// parent assignment pending refactor
 	// block.parent = self
 	ab.blocks = append(ab.Blocks(), block)
+	if block.Context() == context.Section && block._section != nil &&
+		(ab.Context() == context.Section || ab.Context() == context.Document) {
+		ab.assignIndex(block._section)
+	}
 }
 
 /* Get the Array of child Section objects
@@ -303,6 +352,21 @@ func (ab *abstractBlock) MainSectionAble(sa sectionAble) {
 	}
 }
 
+/* Register the concrete node (Document, Section, Block, ...)
+which embeds this abstract block */
+func (ab *abstractBlock) MainNode(node interface{}) {
+	ab._node = node
+}
+
+/* The concrete node (Document, Section, Block, ...) embedding this
+abstract block, or the abstract block itself if none was registered */
+func (ab *abstractBlock) Node() interface{} {
+	if ab._node == nil {
+		return ab
+	}
+	return ab._node
+}
+
 /* Assign the next index (0-based) to this section
 Assign the next index of this section within the parent Block
 (in document order)
@@ -324,9 +388,9 @@ func (ab *abstractBlock) assignIndex(section sectionAble) {
 			caption = appendixCaptionAttr.(string)
 		}
 		if caption != "" {
-			section.SetCaption(caption + " " + strconv.Itoa(appendixNumber) + ": ")
+			section.SetCaption(caption + " " + appendixNumberString(appendixNumber) + ": ")
 		} else {
-			section.SetCaption(strconv.Itoa(appendixNumber) + ". ")
+			section.SetCaption(appendixNumberString(appendixNumber) + ". ")
 		}
 	} else if section.IsNumbered() {
 		// chapters in a book doctype should be sequential even when
@@ -1,6 +1,8 @@
 package asciidocgo
 
 import (
+	"io/fs"
+	"net/http"
 	"testing"
 
 	"github.com/VonC/asciidocgo/consts/contentModel"
@@ -337,6 +339,18 @@ func (tbd *testBlockDocumentAble) BaseDir() string {
 	return ""
 }
 
+func (tbd *testBlockDocumentAble) FS() fs.FS {
+	return nil
+}
+
+func (tbd *testBlockDocumentAble) HTTPClient() *http.Client {
+	return nil
+}
+
+func (tbd *testBlockDocumentAble) IsAttributeLocked(name string) bool {
+	return false
+}
+
 func (tbd *testBlockDocumentAble) PlaybackAttributes(map[string]interface{}) {
 	//
 }
@@ -352,3 +366,15 @@ func (tbd *testBlockDocumentAble) Counter(name, seed string) int {
 func (tbd *testBlockDocumentAble) DocType() string {
 	return ""
 }
+
+func (tbd *testBlockDocumentAble) References() Referencable {
+	return nil
+}
+
+func (tbd *testBlockDocumentAble) Register(typeDoc string, value []string) {
+	//
+}
+
+func (tbd *testBlockDocumentAble) Logger() Logger {
+	return nil
+}
@@ -1,17 +1,22 @@
 package asciidocgo
 
 import (
-	"bufio"
+	"bytes"
+	"encoding/base64"
 	"fmt"
-	"io/ioutil"
-	"os"
+	"io"
+	"io/fs"
+	"net/http"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/VonC/asciidocgo/consts/context"
 	"github.com/VonC/asciidocgo/consts/regexps"
 	"github.com/VonC/asciidocgo/consts/safemode"
+	"github.com/VonC/asciidocgo/consts/severity"
 )
 
 var testan = ""
@@ -22,16 +27,22 @@ type Documentable interface {
 	Attr(name string, defaultValue interface{}, inherit bool) interface{}
 	HasAttr(name string, expect interface{}, inherit bool) bool
 	setAttr(name string, val interface{}, override bool) bool
+	IsAttributeLocked(name string) bool
 	HasReftext() bool
 
 	Safe() safemode.SafeMode
 	BaseDir() string
+	FS() fs.FS
+	HTTPClient() *http.Client
 
 	PlaybackAttributes(map[string]interface{})
 	Renderer() *Renderer
 	CounterIncrement(counterName string, block *abstractNode) string
 	Counter(name, seed string) int
 	DocType() string
+	References() Referencable
+	Register(typeDoc string, value []string)
+	Logger() Logger
 }
 
 /* An abstract base class that provides state and methods for managing
@@ -58,9 +69,21 @@ func newAbstractNode(parent *abstractNode, c context.Context) *abstractNode {
 	} else if parent != nil {
 		abstractNode.document = parent.Document()
 	}
+	// the substitutions of a node create inline nodes under that node,
+	// with the document and parsers of its parent
+	abstractNode.substitutors.abstractNodable = abstractNode
+	abstractNode.substitutors.inlineMaker = &inlineMaker{}
+	if parent != nil && parent.substitutors != nil {
+		abstractNode.substitutors.document = parent.substitutors.document
+		abstractNode.substitutors.parser = parent.substitutors.parser
+		abstractNode.substitutors.attributeListMaker = parent.substitutors.attributeListMaker
+	}
 	return abstractNode
 }
 
+// An abstractNode is the parent of the inline nodes created by its substitutions
+func (an *abstractNode) IsAbstractNodable() {}
+
 func (an *abstractNode) MainDocumentable(d Documentable) {
 	if an.Context() == context.Document {
 		an._doc = d
@@ -419,6 +442,9 @@ If the 'data-uri' attribute is set on the document, and the safe mode level
 is less than SafeMode::SECURE, the image will be safely converted to
 a data URI by reading it from the same directory. If neither of these conditions
 are satisfied, a relative path (i.e., URL) will be returned.
+A remote image (a URI target, or a target in a URI directory) is only
+converted to a data URI if the 'allow-uri-read' attribute is set too,
+from the API (a document cannot make its converter read a URI).
 
 The return value of this method can be safely used in an image tag.
 
@@ -431,10 +457,22 @@ func (an *abstractNode) ImageUri(targetImage, assetDirKey string) string {
 	if assetDirKey == "" {
 		assetDirKey = "imagesdir"
 	}
-	if strings.Contains(targetImage, ":") && regexps.UriSniffRx.MatchString(targetImage) {
+	doc := an.Document()
+	dataUri := doc != nil && doc.Safe() < safemode.SECURE && doc.HasAttr("data-uri", nil, true)
+	if isUri(targetImage) {
+		if dataUri && allowUriRead(doc) {
+			return an.generateDataUriFromUri(targetImage)
+		}
 		return targetImage
 	}
-	if an.Document() != nil && an.Document().Safe() < safemode.SECURE && an.Document().HasAttr("data-uri", nil, true) {
+	if dataUri {
+		if imagesBase, ok := an.Attr(assetDirKey, nil, true).(string); ok && isUri(imagesBase) {
+			imageUri := normalizeWebPath(targetImage, imagesBase)
+			if allowUriRead(doc) {
+				return an.generateDataUriFromUri(imageUri)
+			}
+			return imageUri
+		}
 		return an.generateDataUri(targetImage, assetDirKey)
 	}
 	if assetDirKey != "" && an.HasAttr(assetDirKey, nil, true) {
@@ -444,6 +482,38 @@ func (an *abstractNode) ImageUri(targetImage, assetDirKey string) string {
 	}
 }
 
+/* Check whether the 'allow-uri-read' attribute is set and locked: only
+the API (or the command line) can allow a document to read URIs */
+func allowUriRead(doc Documentable) bool {
+	return doc.IsAttributeLocked("allow-uri-read") && doc.HasAttr("allow-uri-read", nil, true)
+}
+
+/* Report a message about this node to the logger of its document
+(the message is dropped if the node has no document, or no logger) */
+func (an *abstractNode) log(s severity.Severity, text string) {
+	if an.Document() != nil {
+		logMessage(an.Document().Logger(), s, text, nil)
+	}
+}
+
+// Check if target is a URI ('http://...'), and not a path
+func isUri(target string) bool {
+	return strings.Contains(target, ":") && regexps.UriSniffRx.MatchString(target)
+}
+
+/* The default maximum size, in bytes, of an image embedded as a data URI
+(overridden by the 'data-uri-max-size' attribute, 0 meaning no limit) */
+const defaultDataUriMaxSize = 5 * 1024 * 1024
+
+/* A white image of one pixel, embedded instead of an image which cannot
+be, if the 'data-uri-fallback' attribute is set */
+const dataUriFallback = "data:image/gif;base64,R0lGODlhAQABAAAAACH5BAEKAAEALAAAAAABAAEAAAICTAEAOw=="
+
+/* The HTTP client fetching the remote images of a document without
+HTTP client of its own: unlike http.DefaultClient, it gives up on a
+server which does not answer */
+var defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
 /* Generate a data URI that can be used to embed an image in the output document
 
 First, and foremost, the target image path is cleaned if the document
@@ -452,6 +522,12 @@ safe mode level is set to at least SafeMode::SAFE
 in the filesystem.
 The image data is then read and converted to Base64.
 Finally, a data URI is built which can be used in an image tag.
+Its MIME type is sniffed from the image data (PNG, JPEG, GIF, WebP or SVG),
+or derived from the extension of the target image otherwise.
+An image which cannot be read, or which is larger than the
+'data-uri-max-size' attribute (5MB by default), is reported, and
+replaced by an empty data URI (or by a white image of one pixel, if the
+'data-uri-fallback' attribute is set).
 
 target_image - A String path to the target image
 asset_dir_key - The String attribute key used to lookup the directory where
@@ -459,60 +535,201 @@ asset_dir_key - The String attribute key used to lookup the directory where
 
 Returns A String data URI containing the content of the target image*/
 func (an *abstractNode) generateDataUri(targetImage, assetDirKey string) string {
-	ext := filepath.Ext(targetImage)
-	if len(ext) > 1 {
-		ext = ext[1:]
-	}
-	mimetype := "image/" + ext
-	if ext == "svg" {
-		mimetype = mimetype + "+xml"
-	}
-	//return fmt.Sprintf("ext='%v' for mimetype='%v'", ext, mimetype)
+	mimetype := extensionMimeType(targetImage)
 	imagePath := ""
+	var err error
 	if assetDirKey != "" && an.Document() != nil && an.Document().Attr(assetDirKey, nil, true) != nil {
 		// image_path = normalize_system_path(target_image, @document.attr(asset_dir_key), nil, :target_name => 'image')
-		imagePath = an.normalizeSystemPath(targetImage, an.Document().Attr(assetDirKey, nil, true).(string), "", false, "image")
+		imagePath, err = an.normalizeSystemPath(targetImage, an.Document().Attr(assetDirKey, nil, true).(string), "", false, "image")
 	} else {
-		imagePath = an.normalizeSystemPath(targetImage, "", "", false, "")
+		imagePath, err = an.normalizeSystemPath(targetImage, "", "", false, "")
+	}
+	if err != nil {
+		an.log(severity.ERROR, err.Error())
+		return an.emptyDataUri(mimetype)
 	}
 	if testan == "test_generateDataUri_imagePath" {
 		return fmt.Sprintf("imagePath='%v'", imagePath)
 	}
-	if file, err := os.Open(imagePath); err == nil {
-		defer file.Close()
-		reader := bufio.NewReader(file)
-		if content, err := ioutil.ReadAll(reader); err == nil {
-			return string(content)
+	content, err := readFile(an.fileSystem(), imagePath)
+	if err != nil {
+		an.log(severity.WARN, fmt.Sprintf("image to embed not found or not readable: '%v'", imagePath))
+		return an.emptyDataUri(mimetype)
+	}
+	if maxSize := an.dataUriMaxSize(); maxSize > 0 && len(content) > maxSize {
+		an.log(severity.WARN, fmt.Sprintf("image to embed larger than %v bytes: '%v'", maxSize, imagePath))
+		return an.emptyDataUri(mimetype)
+	}
+	if sniffed := sniffImageMimeType(content); sniffed != "" {
+		mimetype = sniffed
+	}
+	return "data:" + mimetype + ";base64," + base64.StdEncoding.EncodeToString(content)
+}
+
+/* Generate a data URI from the content of a remote image, fetched with
+the HTTP client of the document (defaultHTTPClient by default).
+Its MIME type is sniffed from the image data; if it cannot be, it is
+the content type of the response if that is an image type, or else
+the one of the extension of the URI.
+An image which cannot be fetched, or which is larger than the
+'data-uri-max-size' attribute, is reported, and referenced by its URI.
+
+image_uri - The String URI of the image
+
+Returns A String data URI containing the content of the image, or the URI */
+func (an *abstractNode) generateDataUriFromUri(imageUri string) string {
+	var client *http.Client
+	if an.Document() != nil {
+		client = an.Document().HTTPClient()
+	}
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	resp, err := client.Get(imageUri)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		if err == nil {
+			resp.Body.Close()
+		}
+		an.log(severity.WARN, fmt.Sprintf("could not retrieve image data from URI: %v", imageUri))
+		return imageUri
+	}
+	defer resp.Body.Close()
+	body := io.Reader(resp.Body)
+	maxSize := an.dataUriMaxSize()
+	if maxSize > 0 {
+		body = io.LimitReader(body, int64(maxSize)+1)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		an.log(severity.WARN, fmt.Sprintf("could not retrieve image data from URI: %v", imageUri))
+		return imageUri
+	}
+	if maxSize > 0 && len(content) > maxSize {
+		an.log(severity.WARN, fmt.Sprintf("image to embed larger than %v bytes: '%v'", maxSize, imageUri))
+		return imageUri
+	}
+	mimetype := sniffImageMimeType(content)
+	if mimetype == "" {
+		mimetype = strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0])
+		if !strings.HasPrefix(mimetype, "image/") {
+			mimetype = extensionMimeType(imageUri)
+		}
+	}
+	return "data:" + mimetype + ";base64," + base64.StdEncoding.EncodeToString(content)
+}
+
+/* The data URI of an image which cannot be embedded: without data,
+or the white image of one pixel if 'data-uri-fallback' is set */
+func (an *abstractNode) emptyDataUri(mimetype string) string {
+	if an.Document() != nil && an.Document().HasAttr("data-uri-fallback", nil, true) {
+		return dataUriFallback
+	}
+	return "data:" + mimetype + ";base64,"
+}
+
+/* The maximum size, in bytes, of an image embedded as a data URI:
+the 'data-uri-max-size' attribute, if it is a number (0 for no limit) */
+func (an *abstractNode) dataUriMaxSize() int {
+	if an.Document() != nil {
+		if value, ok := an.Document().Attr("data-uri-max-size", nil, true).(string); ok {
+			if size, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && size >= 0 {
+				return size
+			}
 		}
 	}
-	fmt.Errorf("asciidocgo: WARNING: image to embed not found or not readable: '%v'", imagePath)
-	return "data:" + mimetype + ":base64,"
-	// uncomment to return 1 pixel white dot instead
-	// return 'data:image/gif;base64,R0lGODlhAQABAAAAACH5BAEKAAEALAAAAAABAAEAAAICTAEAOw=='
+	return defaultDataUriMaxSize
+}
+
+/* The MIME type of an image, derived from the extension of its path:
+'image/<ext>' ('image/svg+xml' for svg), 'application/octet-stream'
+without extension */
+func extensionMimeType(target string) string {
+	ext := strings.ToLower(filepath.Ext(target))
+	switch {
+	case len(ext) <= 1:
+		return "application/octet-stream"
+	case ext == ".svg":
+		return "image/svg+xml"
+	case ext == ".jpg":
+		return "image/jpeg"
+	}
+	return "image/" + ext[1:]
+}
+
+/* The MIME type of an image, sniffed from its first bytes:
+PNG, JPEG, GIF, WebP or SVG ("" for any other content) */
+func sniffImageMimeType(content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case bytes.HasPrefix(content, []byte{0xff, 0xd8, 0xff}):
+		return "image/jpeg"
+	case bytes.HasPrefix(content, []byte("GIF87a")), bytes.HasPrefix(content, []byte("GIF89a")):
+		return "image/gif"
+	case len(content) >= 12 && bytes.HasPrefix(content, []byte("RIFF")) && string(content[8:12]) == "WEBP":
+		return "image/webp"
+	}
+	// an SVG image is an XML document, whose root element is 'svg'
+	head := content
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	text := strings.TrimSpace(string(head))
+	if strings.HasPrefix(text, "<") && regexps.SvgRootRx.MatchString(text) {
+		return "image/svg+xml"
+	}
+	return ""
+}
+
+/* The file system of the files of the document of this node
+(nil for the file system of the system) */
+func (an *abstractNode) fileSystem() fs.FS {
+	if an.Document() != nil {
+		return an.Document().FS()
+	}
+	return nil
+}
+
+/* A PathResolver for the paths of the document of this node, in its
+file system, reporting its warnings to the logger of the document */
+func (an *abstractNode) pathResolver() *PathResolver {
+	var pr *PathResolver
+	if fsys := an.fileSystem(); fsys != nil {
+		pr = NewFSPathResolver(fsys)
+	} else {
+		pr = NewPathResolver(0, "")
+	}
+	if an.Document() != nil {
+		pr.SetLogger(an.Document().Logger())
+	}
+	return pr
 }
 
 /* Read the contents of the file at the specified path.
 This method assumes that the path is safe to read. It checks
 that the file is readable before attempting to read it.
-path            - the String path from which to read the contents
-warn_on_failure - a Boolean that controls whether a warning is issued if
-                  the file cannot be read
+path   - the String path from which to read the contents
+logger - the Logger warned if the file cannot be read
+         (nil means no warning)
 returns the contents of the file at the specified path, or nil
 if the file does not exist. */
-func ReadAsset(path string, warnOnFailure bool) string {
-	if file, err := os.Open(path); err == nil {
-		defer file.Close()
-		reader := bufio.NewReader(file)
-		if content, err := ioutil.ReadAll(reader); err == nil {
-			res := string(content)
-			// QUESTION should we use strip or rstrip instead of chomp here?
-			// Here: uses a more advanced chomp function
-			res = strings.TrimRight(res, " \r\n")
-			return res
-		}
+func ReadAsset(path string, logger Logger) string {
+	return ReadFSAsset(nil, path, logger)
+}
+
+/* Read the contents of the file at the specified path of fsys, like
+ReadAsset (a nil fsys being the file system of the system).
+The path is rooted at '/', the root of fsys (see Document.UseFS()) */
+func ReadFSAsset(fsys fs.FS, path string, logger Logger) string {
+	if content, err := readFile(fsys, path); err == nil {
+		res := string(content)
+		// QUESTION should we use strip or rstrip instead of chomp here?
+		// Here: uses a more advanced chomp function
+		res = strings.TrimRight(res, " \r\n")
+		return res
 	}
-	if warnOnFailure {
-		fmt.Errorf("asciidocgo: WARNING: file does not exist or cannot be read: '%v'", path)
+	if logger != nil {
+		logger.Log(&LogMessage{Severity: severity.WARN, Text: fmt.Sprintf("file does not exist or cannot be read: '%v'", path)})
 	}
 	return ""
 }
@@ -545,28 +762,28 @@ opts   - an optional Hash of options to control processing (default: {}):
               when an illegal path is encountered
           * :target_name is used in messages to refer to the path being resolved
 
-raises a SecurityError if a jail is specified and the resolved path is
+returns a JailError if a jail is specified and the resolved path is
 outside the jail.
 
 returns a String path resolved from the start and target paths, with any
 parent references resolved and self references removed. If a jail is provided,
 this path will be guaranteed to be contained within the jail. */
 //def normalize_system_path(target, start = nil, jail = nil, opts = {})
-func (an *abstractNode) normalizeSystemPath(target, start, jail string, canrecover bool, targetName string) string {
+func (an *abstractNode) normalizeSystemPath(target, start, jail string, canrecover bool, targetName string) (string, error) {
 	if start == "" && an.Document() != nil {
 		start = an.Document().BaseDir()
 	}
 	if jail == "" && an.Document() != nil && (an.Document().Safe() >= safemode.SAFE || testan == "test_normalizeSystemPath_safeDocument") {
 		jail = an.Document().BaseDir()
 	}
-	return NewPathResolver(0, "").SystemPath(target, start, jail, canrecover, targetName)
+	return an.pathResolver().SystemPath(target, start, jail, canrecover, targetName)
 }
 
 /*Normalize the asset file or directory to a concrete and rinsed path
 
 Delegates to normalize_system_path, with the start path set to the value of
 the base_dir instance variable on the Document object. */
-func (an *abstractNode) normalizeAssetPath(assetRef, assetName string, autocorrect bool) string {
+func (an *abstractNode) normalizeAssetPath(assetRef, assetName string, autocorrect bool) (string, error) {
 	if assetName == "" {
 		assetName = "path"
 	}
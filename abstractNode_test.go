@@ -1,9 +1,13 @@
 package asciidocgo
 
 import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/VonC/asciidocgo/consts/context"
 	"github.com/VonC/asciidocgo/consts/safemode"
@@ -364,15 +368,15 @@ func TestAbstractNode(t *testing.T) {
 		pr := NewPathResolver(0, "")
 		wd := Posixfy(pr.WorkingDir())
 		Convey("Empty target means working dir", func() {
-			So(an.normalizeSystemPath("", "", "", false, ""), ShouldEqual, Posixfy(pr.WorkingDir()))
+			So(pathOf(an.normalizeSystemPath("", "", "", false, "")), ShouldEqual, Posixfy(pr.WorkingDir()))
 		})
 		Convey("Empty start and jail means working dir", func() {
-			So(an.normalizeSystemPath("a/b", "", "", false, ""), ShouldEqual, wd+"/a/b")
+			So(pathOf(an.normalizeSystemPath("a/b", "", "", false, "")), ShouldEqual, wd+"/a/b")
 		})
 		Convey("Empty start and jail and safe document means working dir", func() {
 			testan = "test_normalizeSystemPath_safeDocument"
-			So(an.normalizeSystemPath("a/b", "", "", false, ""), ShouldEqual, wd+"/a/b")
-			testan = ""
+			defer func() { testan = "" }()
+			So(pathOf(an.normalizeSystemPath("a/b", "", "", false, "")), ShouldEqual, wd+"/a/b")
 		})
 	})
 
@@ -383,21 +387,130 @@ func TestAbstractNode(t *testing.T) {
 		wd := Posixfy(pr.WorkingDir())
 
 		Convey("Empty target and assetDir means working dir, meaning defaut data uri content", func() {
-			So(an.generateDataUri("", ""), ShouldEqual, "data:image/:base64,")
-			So(an.generateDataUri("a/b.exe", ""), ShouldEqual, "data:image/exe:base64,")
+			So(an.generateDataUri("", ""), ShouldEqual, "data:application/octet-stream;base64,")
+			So(an.generateDataUri("a/b.exe", ""), ShouldEqual, "data:image/exe;base64,")
 		})
 		Convey("Svg non-existing target and empty assetDir means data: with svg+xml mimetype", func() {
-			So(an.generateDataUri("a/b.svg", ""), ShouldEqual, "data:image/svg+xml:base64,")
+			So(an.generateDataUri("a/b.svg", ""), ShouldEqual, "data:image/svg+xml;base64,")
 		})
 		Convey("Svg target and non-empty assetDir imagePath", func() {
 			testan = "test_generateDataUri_imagePath"
+			defer func() { testan = "" }()
 			So(an.generateDataUri("a/b.svg", "akey"), ShouldEqual, "imagePath='"+wd+"/a/b.svg'")
 			parent.setAttr("akey", "c:/x", true)
 			So(an.generateDataUri("a/b.svg", "akey"), ShouldEqual, "imagePath='c:/x/a/b.svg'")
-			testan = ""
 		})
 		Convey("Existing target and empty assetDir means data content", func() {
-			So(an.generateDataUri("test/t.txt", ""), ShouldEqual, "test data")
+			So(an.generateDataUri("test/t.txt", ""), ShouldEqual, "data:image/txt;base64,dGVzdCBkYXRh")
+		})
+		Convey("The mimetype of the data is sniffed from its content", func() {
+			So(sniffImageMimeType([]byte("\x89PNG\r\n\x1a\n...")), ShouldEqual, "image/png")
+			So(sniffImageMimeType([]byte{0xff, 0xd8, 0xff, 0xe0}), ShouldEqual, "image/jpeg")
+			So(sniffImageMimeType([]byte("GIF87a...")), ShouldEqual, "image/gif")
+			So(sniffImageMimeType([]byte("RIFF\x00\x00\x00\x00WEBPVP8 ")), ShouldEqual, "image/webp")
+			So(sniffImageMimeType([]byte("\n<?xml version=\"1.0\"?>\n<svg xmlns=\"http://www.w3.org/2000/svg\"/>")), ShouldEqual, "image/svg+xml")
+			So(sniffImageMimeType([]byte("test data")), ShouldEqual, "")
+			So(extensionMimeType("a/b.JPG"), ShouldEqual, "image/jpeg")
+		})
+	})
+
+	Convey("An abstractNode embeds the images of its document as data uri", t, func() {
+		fsys := fstest.MapFS{
+			"images/dot.png":  &fstest.MapFile{Data: []byte("\x89PNG\r\n\x1a\ndot")},
+			"images/logo.img": &fstest.MapFile{Data: []byte("<svg></svg>")},
+		}
+		doc := NewDocument([]string{}, map[string]string{"safe": "safe"}).UseFS(fsys)
+		doc.setAttr("data-uri", "", true)
+		doc.setAttr("imagesdir", "images", true)
+		block := newBlock(doc.abstractBlock, context.Paragraph, nil)
+		logger := doc.Logger().(*MemoryLogger)
+
+		Convey("With the mimetype of their content", func() {
+			So(block.ImageUri("dot.png", ""), ShouldEqual, "data:image/png;base64,iVBORw0KGgpkb3Q=")
+			So(block.ImageUri("logo.img", ""), ShouldEqual, "data:image/svg+xml;base64,PHN2Zz48L3N2Zz4=")
+			So(len(logger.Messages()), ShouldEqual, 0)
+		})
+		Convey("Up to a maximum size", func() {
+			doc.setAttr("data-uri-max-size", "10", true)
+			So(block.ImageUri("dot.png", ""), ShouldEqual, "data:image/png;base64,")
+			So(logger.Messages()[0].Text, ShouldEqual, "image to embed larger than 10 bytes: '/images/dot.png'")
+			doc.setAttr("data-uri-max-size", "0", true)
+			So(block.ImageUri("dot.png", ""), ShouldEqual, "data:image/png;base64,iVBORw0KGgpkb3Q=")
+		})
+		Convey("Or as a white pixel if they cannot be embedded, with data-uri-fallback", func() {
+			doc.setAttr("data-uri-fallback", "", true)
+			So(block.ImageUri("missing.png", ""), ShouldEqual, dataUriFallback)
+			So(logger.Messages()[0].Text, ShouldEqual, "image to embed not found or not readable: '/images/missing.png'")
+		})
+	})
+
+	Convey("An abstractNode embeds remote images as data uri, if allow-uri-read is set", t, func() {
+		fetches := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fetches++
+			switch r.URL.Path {
+			case "/dot.gif":
+				w.Write([]byte("GIF89a"))
+			case "/logo":
+				w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+				w.Write([]byte("<svg/>"))
+			case "/icon":
+				w.Header().Set("Content-Type", "image/x-icon")
+				w.Write([]byte("\x00\x00\x01\x00"))
+			case "/dot.png", "/page.png":
+				w.Header().Set("Content-Type", "text/html")
+				if r.URL.Path == "/dot.png" {
+					w.Write([]byte("\x89PNG\r\n\x1a\n"))
+				} else {
+					w.Write([]byte("<html>"))
+				}
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		defer server.Close()
+		doc := NewDocument([]string{}, map[string]string{"safe": "safe"}).UseHTTPClient(server.Client())
+		doc.setAttr("data-uri", "", true)
+		block := newBlock(doc.abstractBlock, context.Paragraph, nil)
+		logger := doc.Logger().(*MemoryLogger)
+		So(doc.HTTPClient(), ShouldEqual, server.Client())
+		So(block.ImageUri(server.URL+"/dot.gif", ""), ShouldEqual, server.URL+"/dot.gif")
+		doc.overrideAttribute("allow-uri-read", "")
+
+		Convey("With the mimetype of the response, or of their content", func() {
+			So(block.ImageUri(server.URL+"/dot.gif", ""), ShouldEqual, "data:image/gif;base64,R0lGODlh")
+			So(block.ImageUri(server.URL+"/logo", ""), ShouldEqual, "data:image/svg+xml;base64,PHN2Zy8+")
+			So(block.ImageUri(server.URL+"/icon", ""), ShouldEqual, "data:image/x-icon;base64,AAABAA==")
+			doc.setAttr("imagesdir", server.URL, true)
+			So(block.ImageUri("dot.gif", ""), ShouldEqual, "data:image/gif;base64,R0lGODlh")
+		})
+		Convey("Whose content type is not trusted if it is not an image type", func() {
+			So(block.ImageUri(server.URL+"/dot.png", ""), ShouldEqual, "data:image/png;base64,iVBORw0KGgo=")
+			So(block.ImageUri(server.URL+"/page.png", ""), ShouldEqual, "data:image/png;base64,PGh0bWw+")
+		})
+		Convey("Fetched by default with a client which times out", func() {
+			So(defaultHTTPClient.Timeout, ShouldBeGreaterThan, 0)
+			doc.UseHTTPClient(nil)
+			So(block.ImageUri(server.URL+"/dot.gif", ""), ShouldEqual, "data:image/gif;base64,R0lGODlh")
+		})
+		Convey("Or as a reference, if they cannot be fetched, or are too large", func() {
+			So(block.ImageUri(server.URL+"/missing.png", ""), ShouldEqual, server.URL+"/missing.png")
+			So(logger.Messages()[0].Text, ShouldEqual, "could not retrieve image data from URI: "+server.URL+"/missing.png")
+			doc.setAttr("data-uri-max-size", "3", true)
+			So(block.ImageUri(server.URL+"/dot.gif", ""), ShouldEqual, server.URL+"/dot.gif")
+			So(logger.Messages()[1].Text, ShouldEqual, "image to embed larger than 3 bytes: '"+server.URL+"/dot.gif'")
+		})
+		Convey("But not in SECURE mode", func() {
+			doc.safe = safemode.SECURE
+			So(block.ImageUri(server.URL+"/dot.gif", ""), ShouldEqual, server.URL+"/dot.gif")
+		})
+		Convey("Nor if allow-uri-read is only set by the document", func() {
+			lines := []string{":data-uri:", ":allow-uri-read:", "", "image::" + server.URL + "/dot.gif[]"}
+			doc, _ := NewDocument(lines, map[string]string{"safe": "server"}).UseHTTPClient(server.Client()).Parse()
+			So(doc.HasAttr("allow-uri-read", nil, false), ShouldBeTrue)
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, `<img src="`+server.URL+`/dot.gif"`)
+			So(fetches, ShouldEqual, 0)
 		})
 	})
 
@@ -425,15 +538,35 @@ func TestAbstractNode(t *testing.T) {
 		Convey("If the data-uri attribute is on the Document, generate data uri", func() {
 			an.Document().setAttr("data-uri", "anything", true)
 			an.ImageUri("c/d", "")
-			So(an.ImageUri("c/d.anext", ""), ShouldEqual, "data:image/anext:base64,")
+			So(an.ImageUri("c/d.anext", ""), ShouldEqual, "data:image/anext;base64,")
 		})
 	})
 	Convey("An abstractNode can read asset", t, func() {
 		Convey("It can warn on an non-existing asset", func() {
-			So(ReadAsset("a/b.txt", true), ShouldEqual, "")
+			logger := NewMemoryLogger()
+			So(ReadAsset("a/b.txt", logger), ShouldEqual, "")
+			So(logger.Messages()[0].String(), ShouldEqual, "asciidocgo: WARNING: file does not exist or cannot be read: 'a/b.txt'")
+			So(ReadAsset("a/b.txt", nil), ShouldEqual, "")
 		})
 		Convey("It read an existing asset", func() {
-			So(ReadAsset("test/t.txt", true), ShouldEqual, "test data")
+			So(ReadAsset("test/t.txt", nil), ShouldEqual, "test data")
+		})
+		Convey("It can read an asset of a file system", func() {
+			fsys := fstest.MapFS{"test/t.txt": &fstest.MapFile{Data: []byte("fs data\n")}}
+			So(ReadFSAsset(fsys, "/test/t.txt", nil), ShouldEqual, "fs data")
+			So(ReadFSAsset(fsys, "test/t.txt", nil), ShouldEqual, "fs data")
+			So(ReadFSAsset(nil, "test/t.txt", nil), ShouldEqual, "test data")
+			logger := NewMemoryLogger()
+			So(ReadFSAsset(fsys, "/test/../../t.txt", logger), ShouldEqual, "")
+			So(len(logger.Messages()), ShouldEqual, 1)
+		})
+		Convey("It embeds an image of the file system of its document", func() {
+			fsys := fstest.MapFS{"docs/images/dot.gif": &fstest.MapFile{Data: []byte("GIF89a")}}
+			doc := NewDocument([]string{}, map[string]string{"base_dir": "docs", "safe": "safe"}).UseFS(fsys)
+			doc.setAttr("imagesdir", "images", true)
+			block := newBlock(doc.abstractBlock, context.Paragraph, nil)
+			So(block.generateDataUri("dot.gif", "imagesdir"), ShouldEqual, "data:image/gif;base64,R0lGODlh")
+			So(block.generateDataUri("../../dot.gif", "imagesdir"), ShouldEqual, "data:image/gif;base64,")
 		})
 	})
 	Convey("An abstractNode can normalize asset path", t, func() {
@@ -442,10 +575,10 @@ func TestAbstractNode(t *testing.T) {
 		pr := NewPathResolver(0, "")
 		wd := Posixfy(pr.WorkingDir())
 		Convey("Empty parameters means working directory", func() {
-			So(an.normalizeAssetPath("", "", false), ShouldEqual, wd)
+			So(pathOf(an.normalizeAssetPath("", "", false)), ShouldEqual, wd)
 		})
 		Convey("target means working directory + target", func() {
-			So(an.normalizeAssetPath("a/b", "", false), ShouldEqual, wd+"/a/b")
+			So(pathOf(an.normalizeAssetPath("a/b", "", false)), ShouldEqual, wd+"/a/b")
 		})
 	})
 	Convey("An abstractNode can compute relative path", t, func() {
@@ -487,6 +620,18 @@ func (td *testDocumentAble) BaseDir() string {
 	return ""
 }
 
+func (td *testDocumentAble) FS() fs.FS {
+	return nil
+}
+
+func (td *testDocumentAble) HTTPClient() *http.Client {
+	return nil
+}
+
+func (td *testDocumentAble) IsAttributeLocked(name string) bool {
+	return false
+}
+
 func (td *testDocumentAble) PlaybackAttributes(map[string]interface{}) {
 	//
 }
@@ -502,3 +647,15 @@ func (td *testDocumentAble) Counter(name, seed string) int {
 func (td *testDocumentAble) DocType() string {
 	return ""
 }
+
+func (td *testDocumentAble) References() Referencable {
+	return nil
+}
+
+func (td *testDocumentAble) Register(typeDoc string, value []string) {
+	//
+}
+
+func (td *testDocumentAble) Logger() Logger {
+	return nil
+}
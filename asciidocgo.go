@@ -43,21 +43,99 @@ Use custom (Tilt-supported) templates:
 */
 package asciidocgo
 
-import "io"
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
 
 // Accepts input as a string
-func LoadString(input string) *Document {
-	return nil
+func LoadString(input string) (*Document, error) {
+	return LoadStrings(splitLines(input)...)
 }
 
 // Accepts input as an array of strings
-func LoadStrings(inputs ...string) *Document {
-	return nil
+func LoadStrings(inputs ...string) (*Document, error) {
+	lines := []string{}
+	for _, input := range inputs {
+		lines = append(lines, strings.TrimRight(input, "\r\n"))
+	}
+	return NewDocument(lines, nil).Parse()
 }
 
 // Accepts input as an IO.
-// If the input is a File, information about the file is stored in attributes on
+// If the input is a regular File, information about the file is stored in attributes on
 // the Document object.
-func Load(input io.Reader) *Document {
-	return nil
+func Load(input io.Reader) (*Document, error) {
+	return LoadWithOptions(input, nil, nil)
+}
+
+// Accepts input as an IO, with the options of NewDocument (backend, doctype,
+// safe, header_footer, template_dir, ...) and attribute overrides.
+// An attribute override locks the attribute: the document cannot change it.
+// A name ending with '!' undefines the attribute instead.
+func LoadWithOptions(input io.Reader, options map[string]string, attributes map[string]string) (*Document, error) {
+	if input == nil {
+		return nil, errors.New("asciidocgo: no input to load")
+	}
+	content, err := ioutil.ReadAll(bufio.NewReader(input))
+	if err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = make(map[string]string)
+	}
+	attrs := make(map[string]string)
+	// the other inputs (like stdin or a pipe) are relative to the working directory
+	if file, isFile := input.(*os.File); isFile && isRegularFile(file) {
+		docfile, err := filepath.Abs(file.Name())
+		if err != nil {
+			return nil, err
+		}
+		docdir := filepath.Dir(docfile)
+		docname := filepath.Base(docfile)
+		docname = docname[:len(docname)-len(filepath.Ext(docname))]
+		attrs["docfile"] = docfile
+		attrs["docdir"] = docdir
+		attrs["docname"] = docname
+		if options["base_dir"] == "" {
+			options["base_dir"] = docdir
+		}
+	}
+	doc := NewDocument(splitLines(string(content)), options)
+	for name, value := range attrs {
+		doc.overrideAttribute(name, value)
+	}
+	for name, value := range attributes {
+		if strings.HasSuffix(name, "!") {
+			doc.overrideAttribute(strings.TrimSuffix(name, "!"), nil)
+		} else {
+			doc.overrideAttribute(name, value)
+		}
+	}
+	return doc.Parse()
+}
+
+// Check whether a File is a regular file (not a pipe nor a device)
+func isRegularFile(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode().IsRegular()
+}
+
+/* Split a String into lines, removing the trailing end of line
+characters (and the UTF-8 BOM, if any) */
+func splitLines(input string) []string {
+	input = strings.TrimPrefix(input, "\ufeff")
+	if input == "" {
+		return []string{}
+	}
+	lines := strings.Split(strings.TrimRight(input, "\r\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, "\r")
+	}
+	return lines
 }
@@ -1,9 +1,21 @@
 package main
 
-import "fmt"
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/VonC/asciidocgo"
+	"github.com/VonC/asciidocgo/consts/severity"
+)
 
 func main() {
-	showVersion()
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
 }
 
 var (
@@ -11,6 +23,189 @@ var (
 	VERSION   string
 )
 
-func showVersion() {
-	fmt.Printf("asciidoc version %s, build %s\n", VERSION, GITCOMMIT)
+func showVersion(out io.Writer) {
+	fmt.Fprintf(out, "asciidoc version %s, build %s\n", VERSION, GITCOMMIT)
+}
+
+/* The attributes set with -a: 'name=value', 'name' (empty value)
+or 'name!' (undefined) */
+type attributesFlag map[string]string
+
+func (a attributesFlag) String() string {
+	return fmt.Sprint(map[string]string(a))
+}
+
+func (a attributesFlag) Set(value string) error {
+	nameValue := strings.SplitN(value, "=", 2)
+	name := strings.TrimSpace(nameValue[0])
+	if name == "" {
+		return errors.New("missing attribute name")
+	}
+	a[name] = ""
+	if len(nameValue) == 2 {
+		a[name] = nameValue[1]
+	}
+	return nil
+}
+
+/* The options of the command line */
+type cli struct {
+	flags      *flag.FlagSet
+	backend    string
+	doctype    string
+	attributes attributesFlag
+	outFile    string
+	destDir    string
+	safe       string
+	noHeader   bool
+	templates  string
+	version    bool
+}
+
+func newCli(stderr io.Writer) *cli {
+	c := &cli{flags: flag.NewFlagSet("asciidocgo", flag.ContinueOnError), attributes: make(attributesFlag)}
+	c.flags.SetOutput(stderr)
+	c.flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: asciidocgo [options] FILE...\nConverts the AsciiDoc FILEs (- for stdin).\nOptions:")
+		c.flags.PrintDefaults()
+	}
+	c.flags.StringVar(&c.backend, "b", "html5", "set the output `backend` (html5, docbook5)")
+	c.flags.StringVar(&c.doctype, "d", "", "set the document `doctype` (article, book, manpage)")
+	c.flags.Var(c.attributes, "a", "set a document `attribute` (name=value, name, or name! to unset); may be repeated")
+	c.flags.StringVar(&c.outFile, "o", "", "write the output to `outfile` (- for stdout)")
+	c.flags.StringVar(&c.destDir, "D", "", "write the output files to `destdir`")
+	c.flags.StringVar(&c.safe, "S", "unsafe", "set the `safe-mode` level (unsafe, safe, server, secure)")
+	c.flags.BoolVar(&c.noHeader, "s", false, "suppress the document header and footer")
+	c.flags.StringVar(&c.templates, "T", "", "load the templates of `dir`, overriding the built-in converter")
+	c.flags.BoolVar(&c.version, "V", false, "display the version and exit")
+	return c
+}
+
+/* Reported by convert when the document logged errors:
+those are already printed on stderr */
+var errLogged = errors.New("errors logged")
+
+/* Run the command line with its arguments (without the program name).
+Returns the exit status: 0 on success, 1 on error (including an error
+logged while converting a document) */
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := newCli(stderr)
+	if err := c.flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if c.version {
+		showVersion(stdout)
+		return 0
+	}
+	files := c.flags.Args()
+	if len(files) == 0 {
+		c.flags.Usage()
+		return 1
+	}
+	if len(files) > 1 && c.outFile != "" && c.outFile != "-" {
+		fmt.Fprintln(stderr, "asciidocgo: error: -o cannot write several input files to the same output file")
+		return 1
+	}
+	status := 0
+	for _, file := range files {
+		if err := c.convert(file, stdin, stdout, stderr); err != nil {
+			if err != errLogged {
+				fmt.Fprintf(stderr, "asciidocgo: error: %v\n", err)
+			}
+			status = 1
+		}
+	}
+	return status
+}
+
+/* Convert one input file (- for stdin).
+The diagnostic messages of the document are printed on stderr:
+the output is still written if some of them are errors,
+but errLogged is returned */
+func (c *cli) convert(file string, stdin io.Reader, stdout, stderr io.Writer) error {
+	input := stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+	doc, err := asciidocgo.LoadWithOptions(input, c.options(), c.attributes)
+	if err != nil {
+		return fmt.Errorf("%s: %v", file, err)
+	}
+	res, err := doc.Render()
+	logged := false
+	if logger, ok := doc.Logger().(*asciidocgo.MemoryLogger); ok {
+		for _, message := range logger.Messages() {
+			fmt.Fprintln(stderr, message.String())
+		}
+		logged = len(logger.MessagesAtLeast(severity.ERROR)) > 0
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", file, err)
+	}
+	if err := c.write(file, doc, res, stdout); err != nil {
+		return err
+	}
+	if logged {
+		return errLogged
+	}
+	return nil
+}
+
+// Write the converted document as is, in its output file or on stdout
+func (c *cli) write(file string, doc *asciidocgo.Document, res string, stdout io.Writer) error {
+	outFile := c.outputPath(file, doc)
+	if outFile == "" {
+		_, err := io.WriteString(stdout, res)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(outFile), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(outFile, []byte(res), 0644)
+}
+
+/* The options of the documents to convert */
+func (c *cli) options() map[string]string {
+	options := map[string]string{"backend": c.backend, "safe": c.safe, "header_footer": "true"}
+	if c.noHeader {
+		options["header_footer"] = "false"
+	}
+	if c.doctype != "" {
+		options["doctype"] = c.doctype
+	}
+	if c.templates != "" {
+		options["template_dir"] = c.templates
+	}
+	return options
+}
+
+/* The path of the output file of file ("" for stdout):
+the -o outfile (relative to the -D destdir), or the name of the input file
+with the suffix of the backend, in destdir or next to the input file.
+The output of stdin goes to stdout, unless -o is set */
+func (c *cli) outputPath(file string, doc *asciidocgo.Document) string {
+	if c.outFile == "-" || (c.outFile == "" && file == "-") {
+		return ""
+	}
+	if c.outFile != "" {
+		if c.destDir != "" && !filepath.IsAbs(c.outFile) {
+			return filepath.Join(c.destDir, c.outFile)
+		}
+		return c.outFile
+	}
+	dir := filepath.Dir(file)
+	if c.destDir != "" {
+		dir = c.destDir
+	}
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	suffix, _ := doc.Attr("outfilesuffix", ".html", false).(string)
+	return filepath.Join(dir, name+suffix)
 }
@@ -1,7 +1,120 @@
 package main
 
-import "testing"
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
 
-func TestMain(t *testing.T) {
-	main()
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRun(t *testing.T) {
+
+	Convey("The command line needs at least one file", t, func() {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		So(run([]string{}, nil, stdout, stderr), ShouldEqual, 1)
+		So(stderr.String(), ShouldStartWith, "Usage: asciidocgo [options] FILE...")
+		So(run([]string{"-unknown", "a.adoc"}, nil, stdout, stderr), ShouldEqual, 1)
+		So(run([]string{"-a", "=value", "a.adoc"}, nil, stdout, stderr), ShouldEqual, 1)
+		So(run([]string{"-h"}, nil, stdout, stderr), ShouldEqual, 0)
+	})
+
+	Convey("The command line can display its version", t, func() {
+		stdout := &bytes.Buffer{}
+		So(run([]string{"-V"}, nil, stdout, &bytes.Buffer{}), ShouldEqual, 0)
+		So(stdout.String(), ShouldStartWith, "asciidoc version ")
+	})
+
+	Convey("The command line converts stdin to stdout", t, func() {
+		stdout := &bytes.Buffer{}
+		stdin := strings.NewReader("= Title\n\n{greeting} *world*\n")
+		So(run([]string{"-s", "-a", "greeting=Hello", "-"}, stdin, stdout, &bytes.Buffer{}), ShouldEqual, 0)
+		So(stdout.String(), ShouldEqual, "<h1>Title</h1>\n<div class=\"paragraph\">\n<p>Hello <strong>world</strong></p>\n</div>\n")
+		Convey("With a backend and a doctype", func() {
+			stdout := &bytes.Buffer{}
+			stdin := strings.NewReader("= Title\n\ntext\n")
+			So(run([]string{"-b", "docbook", "-d", "book", "-o", "-", "-"}, stdin, stdout, &bytes.Buffer{}), ShouldEqual, 0)
+			So(stdout.String(), ShouldContainSubstring, "<book xmlns=")
+		})
+	})
+
+	Convey("The command line resolves the includes of stdin from the working directory", t, func() {
+		dir, err := ioutil.TempDir("", "asciidocgo-cli")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		os.Mkdir(filepath.Join(dir, "sub"), 0755)
+		ioutil.WriteFile(filepath.Join(dir, "sub", "a.adoc"), []byte("Included *text*.\n"), 0644)
+		wd, _ := os.Getwd()
+		So(os.Chdir(dir), ShouldBeNil)
+		defer os.Chdir(wd)
+		stdin, w, err := os.Pipe()
+		So(err, ShouldBeNil)
+		defer stdin.Close()
+		w.WriteString("include::sub/a.adoc[]\n\nifndef::docfile[No docfile.]\n")
+		w.Close()
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		So(run([]string{"-s", "-"}, stdin, stdout, stderr), ShouldEqual, 0)
+		So(stderr.String(), ShouldEqual, "")
+		So(stdout.String(), ShouldEqual, "<div class=\"paragraph\">\n<p>Included <strong>text</strong>.</p>\n</div>\n"+
+			"<div class=\"paragraph\">\n<p>No docfile.</p>\n</div>\n")
+	})
+
+	Convey("The command line writes output files", t, func() {
+		dir, err := ioutil.TempDir("", "asciidocgo-cli")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		input := filepath.Join(dir, "doc.adoc")
+		ioutil.WriteFile(input, []byte("= Title\n\ntext\n"), 0644)
+		stderr := &bytes.Buffer{}
+		Convey("Next to the input file, with the suffix of the backend", func() {
+			So(run([]string{input}, nil, &bytes.Buffer{}, stderr), ShouldEqual, 0)
+			res, err := ioutil.ReadFile(filepath.Join(dir, "doc.html"))
+			So(err, ShouldBeNil)
+			So(string(res), ShouldStartWith, "<!DOCTYPE html>")
+			So(string(res), ShouldEndWith, "</html>")
+			So(run([]string{"-b", "docbook5", input}, nil, &bytes.Buffer{}, stderr), ShouldEqual, 0)
+			_, err = os.Stat(filepath.Join(dir, "doc.xml"))
+			So(err, ShouldBeNil)
+		})
+		Convey("In the destination directory", func() {
+			So(run([]string{"-D", filepath.Join(dir, "out"), input}, nil, &bytes.Buffer{}, stderr), ShouldEqual, 0)
+			_, err := os.Stat(filepath.Join(dir, "out", "doc.html"))
+			So(err, ShouldBeNil)
+			So(run([]string{"-D", filepath.Join(dir, "out"), "-o", "other.html", input}, nil, &bytes.Buffer{}, stderr), ShouldEqual, 0)
+			_, err = os.Stat(filepath.Join(dir, "out", "other.html"))
+			So(err, ShouldBeNil)
+		})
+		Convey("But a single output file cannot hold several input files", func() {
+			So(run([]string{"-o", filepath.Join(dir, "all.html"), input, input}, nil, &bytes.Buffer{}, stderr), ShouldEqual, 1)
+			So(stderr.String(), ShouldContainSubstring, "asciidocgo: error:")
+		})
+	})
+
+	Convey("The command line prints the diagnostic messages on stderr", t, func() {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		stdin := strings.NewReader("a {counter:num:bad} b\n")
+		So(run([]string{"-s", "-"}, stdin, stdout, stderr), ShouldEqual, 1)
+		So(stderr.String(), ShouldStartWith, "asciidocgo: ERROR: ")
+		So(stderr.String(), ShouldContainSubstring, "counter 'num'")
+		So(stderr.String(), ShouldNotContainSubstring, "asciidocgo: error:")
+		So(stdout.String(), ShouldStartWith, "<div class=\"paragraph\">")
+		Convey("But exits zero on warnings", func() {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			stdin := strings.NewReader("<<missing>>\n")
+			So(run([]string{"-s", "-"}, stdin, stdout, stderr), ShouldEqual, 0)
+			So(stderr.String(), ShouldStartWith, "asciidocgo: WARNING: ")
+		})
+	})
+
+	Convey("The command line exits non-zero on errors", t, func() {
+		stderr := &bytes.Buffer{}
+		So(run([]string{"no-such-file.adoc"}, nil, &bytes.Buffer{}, stderr), ShouldEqual, 1)
+		So(stderr.String(), ShouldStartWith, "asciidocgo: error: ")
+		So(run([]string{"-b", "pdf", "-"}, strings.NewReader("text"), &bytes.Buffer{}, stderr), ShouldEqual, 1)
+		So(stderr.String(), ShouldContainSubstring, "missing converter for backend 'pdf'")
+		So(run([]string{"-T", "no-such-dir", "-"}, strings.NewReader("text"), &bytes.Buffer{}, stderr), ShouldEqual, 1)
+	})
 }
@@ -1,25 +1,96 @@
 package asciidocgo
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
 	. "github.com/smartystreets/goconvey/convey"
 )
 
 func TestAsciidocgo(t *testing.T) {
-	Load(nil)
 	Convey("Asciidocgo load() takes a string and return a Document", t, func() {
-		Convey("A empty string must returns a nil Document", func() {
-			So(LoadString(""), ShouldBeNil)
+		Convey("A empty string must returns an empty Document", func() {
+			doc, err := LoadString("")
+			So(err, ShouldBeNil)
+			So(doc, ShouldNotBeNil)
+			So(doc.HasBlocks(), ShouldBeFalse)
+			So(doc.HasHeader(), ShouldBeFalse)
+		})
+		Convey("A string is parsed into a header, sections and blocks", func() {
+			doc, err := LoadString("= Title\n\nA paragraph.\n\n== Section\n\nIn the section.\r\n")
+			So(err, ShouldBeNil)
+			So(doc.Title(), ShouldEqual, "Title")
+			So(len(doc.Blocks()), ShouldEqual, 2)
+			So(len(doc.Sections()), ShouldEqual, 1)
+			So(doc.Sections()[0].Title(), ShouldEqual, "Section")
 		})
 	})
 	Convey("Asciidocgo load() takes a array and return a Document", t, func() {
-		Convey("A empty array of strings must returns a nil Document", func() {
-			So(LoadStrings(), ShouldBeNil)
+		Convey("A empty array of strings must returns an empty Document", func() {
+			doc, err := LoadStrings()
+			So(err, ShouldBeNil)
+			So(doc, ShouldNotBeNil)
+			So(doc.HasBlocks(), ShouldBeFalse)
+		})
+		Convey("An array of lines is parsed into a Document", func() {
+			doc, _ := LoadStrings("== Section\n", "", "paragraph")
+			So(len(doc.Sections()), ShouldEqual, 1)
+			So(len(doc.Sections()[0].Blocks()), ShouldEqual, 1)
 		})
 	})
 	Convey("Asciidocgo load() takes a Reader and return a Document", t, func() {
-		Convey("A nil Reader must returns a nil Document", func() {
-			So(Load(nil), ShouldBeNil)
+		Convey("A nil Reader must returns a nil Document and an error", func() {
+			doc, err := Load(nil)
+			So(doc, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("A Reader is read and parsed", func() {
+			doc, err := Load(strings.NewReader("= Title\n\nparagraph\n"))
+			So(err, ShouldBeNil)
+			So(doc.Title(), ShouldEqual, "Title")
+			So(doc.HasAttr("docfile", nil, false), ShouldBeFalse)
+		})
+		Convey("A File sets docfile, docdir and docname attributes", func() {
+			file, err := os.Open("test/sample.adoc")
+			So(err, ShouldBeNil)
+			defer file.Close()
+			doc, err := Load(file)
+			So(err, ShouldBeNil)
+			docfile, _ := filepath.Abs("test/sample.adoc")
+			So(doc.Attr("docfile", nil, false), ShouldEqual, docfile)
+			So(doc.Attr("docdir", nil, false), ShouldEqual, filepath.Dir(docfile))
+			So(doc.Attr("docname", nil, false), ShouldEqual, "sample")
+			So(doc.BaseDir(), ShouldEqual, filepath.Dir(docfile))
+			So(doc.Title(), ShouldEqual, "Document Title")
+			So(doc.Attr("author", nil, false), ShouldEqual, "Doc Writer")
+			So(doc.Attr("email", nil, false), ShouldEqual, "doc.writer@asciidoc.org")
+			So(len(doc.Sections()), ShouldEqual, 2)
+			So(doc.Sections()[1].SourceLocation().String(), ShouldEqual, "sample.adoc:14")
+			So(doc.Reader().Cursor().File(), ShouldEqual, docfile)
+		})
+		Convey("A File which is not a regular file (like stdin) is relative to the working directory", func() {
+			r, w, err := os.Pipe()
+			So(err, ShouldBeNil)
+			defer r.Close()
+			w.WriteString("text\n")
+			w.Close()
+			doc, err := Load(r)
+			So(err, ShouldBeNil)
+			So(doc.HasAttr("docfile", nil, false), ShouldBeFalse)
+			So(doc.HasAttr("docdir", nil, false), ShouldBeFalse)
+			wd, _ := os.Getwd()
+			So(doc.BaseDir(), ShouldEqual, wd)
+		})
+		Convey("Options and attribute overrides can be given", func() {
+			attrs := map[string]string{"backend": "docbook", "lang": "fr", "sectids!": ""}
+			doc, err := LoadWithOptions(strings.NewReader(":lang: en\n\ntext\n"), map[string]string{"doctype": "book"}, attrs)
+			So(err, ShouldBeNil)
+			So(doc.DocType(), ShouldEqual, "book")
+			So(doc.Attr("basebackend", nil, false), ShouldEqual, "docbook")
+			So(doc.Attr("lang", nil, false), ShouldEqual, "fr")
+			So(doc.HasAttr("sectids", nil, false), ShouldBeFalse)
 		})
 	})
 }
@@ -0,0 +1,256 @@
+package asciidocgo
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/* Handles parsing AsciiDoc attribute lists into a Hash of key/value
+pairs. By default, attributes must each be separated by a comma and
+quotes may be used around the value. If a key is not detected, the
+value is assigned to a 1-based positional key. Positional attributes
+can be "rekeyed" when given a posattrs array either during parsing or
+after the fact.
+
+Examples
+
+   attrlist = Asciidoctor::AttributeList.new('astyle')
+
+   attrlist.parse
+   => {1 => 'astyle'}
+
+   attrlist.rekey(['style'])
+   => {'style' => 'astyle'}
+
+   attrlist = Asciidoctor::AttributeList.new('quote, Famous Person, Famous Book (2001)')
+
+   attrlist.parse(['style', 'attribution', 'citetitle'])
+   => {'style' => 'quote', 'attribution' => 'Famous Person', 'citetitle' => 'Famous Book (2001)'} */
+type AttributeList struct {
+	source     string
+	pos        int
+	block      ApplyNormalSubsable
+	delimiter  byte
+	attributes map[string]interface{}
+}
+
+// Regular expressions for detecting the boundary of a value
+var attributeListNameRx, _ = regexp.Compile(`^[\p{L}\p{N}_][\p{L}\p{N}_\-.]*`)
+var attributeListBlankRx, _ = regexp.Compile(`^[ \t]+`)
+
+/* Initialize an AttributeList.
+source    - the String attribute list to parse
+block     - the block applying normal substitutions to single-quoted values
+(may be nil)
+delimiter - the String delimiter between attributes (default: ",") */
+func NewAttributeList(source string, block ApplyNormalSubsable, delimiter string) *AttributeList {
+	d := byte(',')
+	if delimiter != "" {
+		d = delimiter[0]
+	}
+	return &AttributeList{source: source, block: block, delimiter: d}
+}
+
+/* Parse the attributes and merge them into the attributes map */
+func (al *AttributeList) ParseInto(into map[string]interface{}, posAttrs []string) map[string]interface{} {
+	for key, value := range al.Parse(posAttrs) {
+		into[key] = value
+	}
+	return into
+}
+
+/* Parse the attributes, positional attributes being also stored
+under the name at the same (0-based) index in posAttrs.
+The attributes are parsed only once. */
+func (al *AttributeList) Parse(posAttrs []string) map[string]interface{} {
+	if al.attributes != nil {
+		return al.attributes
+	}
+	al.attributes = make(map[string]interface{})
+	for index := 0; al.parseAttribute(index, posAttrs); index++ {
+		if al.eos() {
+			break
+		}
+		al.skipDelimiter()
+	}
+	return al.attributes
+}
+
+/* Store positional attributes under the name at the same (0-based)
+index in posAttrs */
+func (al *AttributeList) Rekey(posAttrs []string) map[string]interface{} {
+	return rekeyAttributes(al.attributes, posAttrs)
+}
+
+func rekeyAttributes(attributes map[string]interface{}, posAttrs []string) map[string]interface{} {
+	for index, key := range posAttrs {
+		if key == "" {
+			continue
+		}
+		if val, ok := attributes[strconv.Itoa(index+1)]; ok && val != nil {
+			attributes[key] = val
+		}
+	}
+	return attributes
+}
+
+func (al *AttributeList) parseAttribute(index int, posAttrs []string) bool {
+	singleQuotedValue := false
+	al.skipBlank()
+	var name, value string
+	hasValue := false
+	switch al.peek() {
+	case '"':
+		name = al.parseAttributeValue(al.getByte())
+	case '\'':
+		name = al.parseAttributeValue(al.getByte())
+		singleQuotedValue = true
+	default:
+		name = al.scanName()
+		skipped := 0
+		var c byte
+		if al.eos() {
+			if name == "" {
+				return false
+			}
+		} else {
+			skipped = al.skipBlank()
+			c = al.getByte()
+		}
+		switch {
+		case c == 0 || c == al.delimiter:
+			// example: "quote" || ''
+		case c != '=' || name == "":
+			// example: Sherlock Holmes || =foo=
+			name = name + strings.Repeat(" ", skipped) + string(c) + al.scanToDelimiter()
+		default:
+			al.skipBlank()
+			if !al.eos() {
+				c = al.getByte()
+				switch {
+				case c == '"':
+					// example: foo="bar" || foo="ba\"zaar"
+					value, hasValue = al.parseAttributeValue(c), true
+				case c == '\'':
+					// example: foo='bar' || foo='ba\'zaar' || foo='ba"zaar'
+					value, hasValue = al.parseAttributeValue(c), true
+					singleQuotedValue = true
+				case c == al.delimiter:
+					// example: foo=,
+				default:
+					// example: foo=bar (all spaces ignored)
+					value, hasValue = string(c)+al.scanToDelimiter(), true
+					if value == "None" {
+						return true
+					}
+				}
+			}
+		}
+	}
+	if hasValue {
+		switch name {
+		case "options", "opts":
+			// opts is an alias for options
+			name = "options"
+			for _, opt := range strings.Split(strings.Replace(value, " ", "", -1), ",") {
+				al.attributes[opt+"-option"] = ""
+			}
+			al.attributes[name] = value
+		default:
+			if singleQuotedValue && al.block != nil {
+				value = al.block.ApplyNormalSubs(value)
+			}
+			al.attributes[name] = value
+		}
+		return true
+	}
+	resolvedName := name
+	if singleQuotedValue && al.block != nil {
+		resolvedName = al.block.ApplyNormalSubs(name)
+	}
+	if index < len(posAttrs) && posAttrs[index] != "" {
+		al.attributes[posAttrs[index]] = resolvedName
+	}
+	al.attributes[strconv.Itoa(index+1)] = resolvedName
+	return true
+}
+
+/* Parse a value enclosed in quote, unescaping the escaped quotes.
+If the closing quote is missing, the value runs until the delimiter
+and keeps its opening quote. */
+func (al *AttributeList) parseAttributeValue(quote byte) string {
+	// empty quoted value
+	if al.peek() == quote {
+		al.getByte()
+		return ""
+	}
+	rest := al.source[al.pos:]
+	for i := 1; i < len(rest); i++ {
+		if rest[i] == quote && rest[i-1] != '\\' {
+			al.pos += i + 1
+			return strings.Replace(rest[:i], "\\"+string(quote), string(quote), -1)
+		}
+	}
+	return string(quote) + al.scanToDelimiter()
+}
+
+func (al *AttributeList) eos() bool {
+	return al.pos >= len(al.source)
+}
+
+func (al *AttributeList) peek() byte {
+	if al.eos() {
+		return 0
+	}
+	return al.source[al.pos]
+}
+
+func (al *AttributeList) getByte() byte {
+	c := al.peek()
+	if !al.eos() {
+		al.pos++
+	}
+	return c
+}
+
+// Skip blanks, and return the number of blanks skipped
+func (al *AttributeList) skipBlank() int {
+	blank := attributeListBlankRx.FindString(al.source[al.pos:])
+	al.pos += len(blank)
+	return len(blank)
+}
+
+// Skip the blanks and the delimiter following a value
+func (al *AttributeList) skipDelimiter() {
+	al.skipBlank()
+	if al.peek() == al.delimiter {
+		al.pos++
+	}
+}
+
+func (al *AttributeList) scanName() string {
+	name := attributeListNameRx.FindString(al.source[al.pos:])
+	al.pos += len(name)
+	return name
+}
+
+// Scan up to the next delimiter, excluding the blanks before it
+func (al *AttributeList) scanToDelimiter() string {
+	rest := al.source[al.pos:]
+	end := strings.IndexByte(rest, al.delimiter)
+	if end < 0 {
+		end = len(rest)
+	}
+	value := strings.TrimRight(rest[:end], " \t")
+	al.pos += len(value)
+	return value
+}
+
+/* Makes AttributeList, used by substitutors to parse
+the attributes of inline macros */
+type attributeListMaker struct{}
+
+func (alm *attributeListMaker) NewAttributeList(attrline string, block ApplyNormalSubsable, delimiter string) AttributeListable {
+	return NewAttributeList(attrline, block, delimiter)
+}
@@ -0,0 +1,73 @@
+package asciidocgo
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testNormalSubs struct{}
+
+func (tns *testNormalSubs) ApplyNormalSubs(lines string) string {
+	return "<" + lines + ">"
+}
+
+func TestAttributeList(t *testing.T) {
+
+	Convey("An AttributeList can parse positional attributes", t, func() {
+		attrs := NewAttributeList("astyle", nil, "").Parse([]string{})
+		So(attrs, ShouldResemble, map[string]interface{}{"1": "astyle"})
+		al := NewAttributeList("quote, Famous Person, Famous Book (2001)", nil, "")
+		attrs = al.Parse([]string{"style", "attribution", "citetitle"})
+		So(attrs["style"], ShouldEqual, "quote")
+		So(attrs["attribution"], ShouldEqual, "Famous Person")
+		So(attrs["citetitle"], ShouldEqual, "Famous Book (2001)")
+		So(attrs["3"], ShouldEqual, "Famous Book (2001)")
+		So(al.Parse(nil), ShouldEqual, attrs)
+	})
+
+	Convey("An AttributeList can parse named attributes", t, func() {
+		attrs := NewAttributeList(`NOTE, caption="Good \"to\" know", id='x', width = 20, a=, opts="step, header"`, nil, "").Parse([]string{})
+		So(attrs["1"], ShouldEqual, "NOTE")
+		So(attrs["caption"], ShouldEqual, `Good "to" know`)
+		So(attrs["id"], ShouldEqual, "x")
+		So(attrs["width"], ShouldEqual, "20")
+		_, hasA := attrs["a"]
+		So(hasA, ShouldBeFalse)
+		So(attrs["options"], ShouldEqual, "step, header")
+		So(attrs["step-option"], ShouldEqual, "")
+		So(attrs["header-option"], ShouldEqual, "")
+	})
+
+	Convey("An AttributeList keeps unusual values as positional attributes", t, func() {
+		attrs := NewAttributeList(`Sherlock Holmes, =foo=, "", "unclosed, b=None`, nil, "").Parse([]string{})
+		So(attrs["1"], ShouldEqual, "Sherlock Holmes")
+		So(attrs["2"], ShouldEqual, "=foo=")
+		So(attrs["3"], ShouldEqual, "")
+		So(attrs["4"], ShouldEqual, `"unclosed`)
+		_, hasB := attrs["b"]
+		So(hasB, ShouldBeFalse)
+		So(len(NewAttributeList("", nil, "").Parse(nil)), ShouldEqual, 0)
+	})
+
+	Convey("An AttributeList applies normal subs to single-quoted values", t, func() {
+		attrs := NewAttributeList(`'a', b='c', "d"`, &testNormalSubs{}, "").Parse([]string{})
+		So(attrs["1"], ShouldEqual, "<a>")
+		So(attrs["b"], ShouldEqual, "<c>")
+		So(attrs["3"], ShouldEqual, "d")
+	})
+
+	Convey("An AttributeList can use another delimiter, and rekey its attributes", t, func() {
+		al := NewAttributeList("a;b", nil, ";")
+		al.Parse(nil)
+		attrs := al.Rekey([]string{"first", "", "third"})
+		So(attrs["first"], ShouldEqual, "a")
+		So(attrs["2"], ShouldEqual, "b")
+		_, hasThird := attrs["third"]
+		So(hasThird, ShouldBeFalse)
+		into := map[string]interface{}{"x": "y"}
+		var maker AttributeListMaker = &attributeListMaker{}
+		maker.NewAttributeList("z", nil, "").ParseInto(into, []string{"style"})
+		So(into, ShouldResemble, map[string]interface{}{"x": "y", "1": "z", "style": "z"})
+	})
+}
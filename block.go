@@ -0,0 +1,86 @@
+package asciidocgo
+
+import (
+	"strings"
+
+	"github.com/VonC/asciidocgo/consts/contentModel"
+	"github.com/VonC/asciidocgo/consts/context"
+)
+
+/* Methods for managing blocks of Asciidoc content in a section.
+
+Examples
+
+  block = Asciidoctor::Block.new(parent, :paragraph, :source => '_This_ is a <test>')
+  block.content
+  => "<em>This</em> is a &lt;test&gt;" */
+type Block struct {
+	*abstractBlock
+	lines []string
+}
+
+/* The content model of each kind of block:
+a block not listed here is compound (it holds other blocks) */
+var blockContentModels = map[context.Context]contentmodel.ContentModel{
+	context.Paragraph: contentmodel.Simple,
+	context.Listing:   contentmodel.Verbatim,
+	context.Literal:   contentmodel.Verbatim,
+	context.Verse:     contentmodel.Verbatim,
+	context.Pass:      contentmodel.Raw,
+	context.Comment:   contentmodel.Empty,
+	context.Image:     contentmodel.Empty,
+	context.Toc:       contentmodel.Empty,
+}
+
+/* The default substitutions applied to the content of each kind
+of block: a block not listed here has no substitutions */
+var blockDefaultSubs = map[context.Context]subArray{
+	context.Paragraph: subs[sub.normal],
+	context.Listing:   subs[sub.verbatim],
+	context.Literal:   subs[sub.verbatim],
+	context.Verse:     subs[sub.normal],
+	context.Pass:      subs[sub.pass],
+}
+
+/* Initialize an Asciidoctor::Block object.
+parent  - The parent Asciidoc Object.
+context - The Symbol context name for the type of content.
+lines   - The source lines of this block */
+func newBlock(parent *abstractBlock, c context.Context, lines []string) *Block {
+	ab := newAbstractBlock(parent, c)
+	if cm, ok := blockContentModels[c]; ok {
+		ab.SetContentModel(cm)
+	}
+	ab.subs = values(blockDefaultSubs[c])
+	if lines == nil {
+		lines = []string{}
+	}
+	block := &Block{ab, lines}
+	ab.MainNode(block)
+	return block
+}
+
+/* The source lines of this block */
+func (b *Block) Lines() []string {
+	return b.lines
+}
+
+/* Get the source of this block.
+Returns the String source lines of this block joined by the EOL character */
+func (b *Block) Source() string {
+	return strings.Join(b.lines, "\n")
+}
+
+/* Get the converted content for this block.
+If the block has child blocks, they are rendered and returned
+as the content. Otherwise, the substitutions of the block are applied
+to its source lines. */
+func (b *Block) Content() string {
+	switch b.ContentModel() {
+	case contentmodel.Compound:
+		return b.abstractBlock.Content()
+	case contentmodel.Empty:
+		return ""
+	}
+	return b.ApplySubs(b.Source(), b.subArray(), false)
+}
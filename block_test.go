@@ -0,0 +1,52 @@
+package asciidocgo
+
+import (
+	"testing"
+
+	"github.com/VonC/asciidocgo/consts/contentModel"
+	"github.com/VonC/asciidocgo/consts/context"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBlock(t *testing.T) {
+
+	Convey("A Block can be initialized", t, func() {
+		Convey("A Block has a context and no lines by default", func() {
+			b := newBlock(nil, context.Paragraph, nil)
+			So(b.Context(), ShouldEqual, context.Paragraph)
+			So(len(b.Lines()), ShouldEqual, 0)
+			So(b.Source(), ShouldEqual, "")
+		})
+		Convey("A Block source is made of its lines", func() {
+			b := newBlock(nil, context.Paragraph, []string{"a", "b"})
+			So(b.Source(), ShouldEqual, "a\nb")
+		})
+	})
+
+	Convey("A Block has a content model and default subs depending on its context", t, func() {
+		b := newBlock(nil, context.Paragraph, nil)
+		So(b.ContentModel(), ShouldEqual, contentmodel.Simple)
+		So(b.Subs(), ShouldResemble, []string{"specialcharacters", "quotes", "attributes", "replacements", "macros", "post_replacements"})
+		b = newBlock(nil, context.Listing, nil)
+		So(b.ContentModel(), ShouldEqual, contentmodel.Verbatim)
+		So(b.Subs(), ShouldResemble, []string{"specialcharacters", "callouts"})
+		b = newBlock(nil, context.Literal, nil)
+		So(b.ContentModel(), ShouldEqual, contentmodel.Verbatim)
+		b = newBlock(nil, context.Pass, nil)
+		So(b.ContentModel(), ShouldEqual, contentmodel.Raw)
+		So(b.Subs(), ShouldResemble, []string{})
+		b = newBlock(nil, context.Example, nil)
+		So(b.ContentModel(), ShouldEqual, contentmodel.Compound)
+		So(b.Subs(), ShouldResemble, []string{})
+	})
+
+	Convey("A Block content depends on its content model", t, func() {
+		So(newBlock(nil, context.Paragraph, []string{"a & b", "c"}).Content(), ShouldEqual, "a &amp; b\nc")
+		So(newBlock(nil, context.Listing, []string{"<tag>"}).Content(), ShouldEqual, "&lt;tag&gt;")
+		So(newBlock(nil, context.Pass, []string{"<tag>"}).Content(), ShouldEqual, "<tag>")
+		So(newBlock(nil, context.Comment, []string{"comment"}).Content(), ShouldEqual, "")
+		example := newBlock(nil, context.Example, nil)
+		example.AppendBlock(newBlock(example.abstractBlock, context.Paragraph, []string{"a"}).abstractBlock)
+		So(example.Content(), ShouldEqual, "\n")
+	})
+}
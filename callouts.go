@@ -0,0 +1,95 @@
+package asciidocgo
+
+import "fmt"
+
+/* Maintains a catalog of callouts and their associations.
+The callouts of a verbatim block are registered while parsing,
+each one with the number of the callout list item it refers to,
+and read back, in the same order, when the block is converted.
+Each callout list starts a new list of callouts. */
+type callouts struct {
+	lists     [][]*callout
+	listIndex int
+	coIndex   int
+}
+
+// A registered callout: the list item ordinal it refers to, and its id
+type callout struct {
+	ordinal int
+	id      string
+}
+
+/* Initialize the catalog, with an empty current list of callouts */
+func newCallouts() *callouts {
+	c := &callouts{[][]*callout{}, 0, 0}
+	c.NextList()
+	return c
+}
+
+/* Register a new callout for the given list item ordinal.
+Generates a unique id for this callout based on the index of the
+next callout list in the document and the index of this callout
+since the end of the last callout list.
+ordinal - the String ordinal of the list item to which this callout refers
+Returns the unique String id of this callout
+ Examples
+   callouts := newCallouts()
+   callouts.Register("1")
+   => "CO1-1"
+   callouts.NextList()
+   callouts.Register("2")
+   => "CO2-1" */
+func (c *callouts) Register(ordinal string) string {
+	id := fmt.Sprintf("CO%d-%d", c.listIndex, c.coIndex)
+	num := 0
+	fmt.Sscanf(ordinal, "%d", &num)
+	c.lists[c.listIndex-1] = append(c.lists[c.listIndex-1], &callout{num, id})
+	c.coIndex++
+	return id
+}
+
+/* Get the next callout id in sequence.
+Used during rendering to retrieve the id of the next callout in the
+current list; the index is advanced even if there is no such callout.
+Returns the unique String id of the next callout ("" if none) */
+func (c *callouts) ReadNextId() string {
+	id := ""
+	list := c.lists[c.listIndex-1]
+	if c.coIndex <= len(list) {
+		id = list[c.coIndex-1].id
+	}
+	c.coIndex++
+	return id
+}
+
+/* Get the ids of the callouts of the current list referring to the
+given list item ordinal, separated by spaces ("" if none) */
+func (c *callouts) CalloutIds(ordinal int) string {
+	ids := ""
+	for _, co := range c.lists[c.listIndex-1] {
+		if co.ordinal == ordinal {
+			if ids != "" {
+				ids = ids + " "
+			}
+			ids = ids + co.id
+		}
+	}
+	return ids
+}
+
+/* Advance to the next callout list in the document,
+once a callout list has been parsed */
+func (c *callouts) NextList() {
+	c.listIndex++
+	if len(c.lists) < c.listIndex {
+		c.lists = append(c.lists, []*callout{})
+	}
+	c.coIndex = 1
+}
+
+/* Rewind the list index pointer, reset the callout index pointer,
+so that the ids can be read back when the document is converted */
+func (c *callouts) Rewind() {
+	c.listIndex = 1
+	c.coIndex = 1
+}
@@ -0,0 +1,39 @@
+package asciidocgo
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCallouts(t *testing.T) {
+
+	Convey("Callouts can be registered for a list item ordinal", t, func() {
+		c := newCallouts()
+		So(c.Register("1"), ShouldEqual, "CO1-1")
+		So(c.Register("2"), ShouldEqual, "CO1-2")
+		So(c.Register("1"), ShouldEqual, "CO1-3")
+		So(c.CalloutIds(1), ShouldEqual, "CO1-1 CO1-3")
+		So(c.CalloutIds(3), ShouldEqual, "")
+
+		Convey("Each callout list starts a new list of callouts", func() {
+			c.NextList()
+			So(c.Register("1"), ShouldEqual, "CO2-1")
+			So(c.CalloutIds(1), ShouldEqual, "CO2-1")
+		})
+	})
+
+	Convey("Callout ids can be read back in sequence once rewound", t, func() {
+		c := newCallouts()
+		c.Register("1")
+		c.Register("2")
+		c.NextList()
+		c.Register("1")
+		c.Rewind()
+		So(c.ReadNextId(), ShouldEqual, "CO1-1")
+		So(c.ReadNextId(), ShouldEqual, "CO1-2")
+		So(c.ReadNextId(), ShouldEqual, "")
+		c.NextList()
+		So(c.ReadNextId(), ShouldEqual, "CO2-1")
+	})
+}
@@ -9,6 +9,7 @@ type compliance struct {
 	attribute_missing              string
 	attribute_undefined            string
 	markdown_syntax                bool
+	unique_id_start_index          int
 }
 
 var cpl = &compliance{
@@ -20,6 +21,7 @@ var cpl = &compliance{
 	attribute_missing:              "skip",
 	attribute_undefined:            "drop-line",
 	markdown_syntax:                true,
+	unique_id_start_index:          2,
 }
 
 /* AsciiDoc terminates paragraphs adjacent to block content
@@ -55,6 +57,11 @@ func UnderlineStyleSectionTitles() bool {
 	return cpl.underline_style_section_titles
 }
 
+/* Enable or disable the underlined variant of section titles */
+func SetUnderlineStyleSectionTitles(underline bool) {
+	cpl.underline_style_section_titles = underline
+}
+
 /* Asciidoctor will unwrap the content in a preamble
 if the document has a title and no sections.
 Compliance value: false */
@@ -82,3 +89,11 @@ Compliance value: false */
 func MarkdownSyntax() bool {
 	return cpl.markdown_syntax
 }
+
+/* The start index of the numerical suffix appended to a generated id
+which is already used in the document ('_section_2' for the second
+'_section' id).
+Compliance value: 2 */
+func UniqueIdStartIndex() int {
+	return cpl.unique_id_start_index
+}
@@ -15,5 +15,13 @@ func TestRegexps(t *testing.T) {
 		So(AttributeMissing(), ShouldEqual, "skip")
 		So(AttributeUndefined(), ShouldEqual, "drop-line")
 		So(MarkdownSyntax(), ShouldBeTrue)
+		So(UniqueIdStartIndex(), ShouldEqual, 2)
+	})
+
+	Convey("Underline style section titles can be disabled", t, func() {
+		SetUnderlineStyleSectionTitles(false)
+		So(UnderlineStyleSectionTitles(), ShouldBeFalse)
+		SetUnderlineStyleSectionTitles(true)
+		So(UnderlineStyleSectionTitles(), ShouldBeTrue)
 	})
 }
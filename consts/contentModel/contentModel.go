@@ -8,6 +8,8 @@ const (
 	Verse
 	Verbatim
 	Simple
+	Raw
+	Empty
 	UnknownCM
 )
 
@@ -21,6 +23,10 @@ func (cm ContentModel) String() string {
 		return "verbatim"
 	case Simple:
 		return "simple"
+	case Raw:
+		return "raw"
+	case Empty:
+		return "empty"
 	}
 	return "unknowncm"
 }
@@ -12,6 +12,8 @@ func TestContentModel(t *testing.T) {
 		So(Verse.String(), ShouldEqual, "verse")
 		So(Verbatim.String(), ShouldEqual, "verbatim")
 		So(Simple.String(), ShouldEqual, "simple")
+		So(Raw.String(), ShouldEqual, "raw")
+		So(Empty.String(), ShouldEqual, "empty")
 		So(UnknownCM.String(), ShouldEqual, "unknowncm")
 	})
 
@@ -7,6 +7,29 @@ const (
 	Document Context = iota
 	Section
 	Paragraph
+	// Delimited blocks
+	Listing
+	Literal
+	Example
+	Sidebar
+	Quote
+	Verse
+	Pass
+	Open
+	Comment
+	Admonition
+	// Lists
+	Ulist
+	Olist
+	Dlist
+	Colist
+	ListItem
+	// Tables
+	Table
+	TableColumn
+	TableCell
+	// Block macros
+	Toc
 	// Used by substitutors in SubMacros()
 	Kbd
 	Button
@@ -16,6 +39,8 @@ const (
 	Anchor
 	Footnote
 	Quoted
+	Callout
+	Break
 	Unknown
 )
 
@@ -27,6 +52,44 @@ func (c Context) String() string {
 		return "section"
 	case Paragraph:
 		return "paragraph"
+	case Listing:
+		return "listing"
+	case Literal:
+		return "literal"
+	case Example:
+		return "example"
+	case Sidebar:
+		return "sidebar"
+	case Quote:
+		return "quote"
+	case Verse:
+		return "verse"
+	case Pass:
+		return "pass"
+	case Open:
+		return "open"
+	case Comment:
+		return "comment"
+	case Admonition:
+		return "admonition"
+	case Ulist:
+		return "ulist"
+	case Olist:
+		return "olist"
+	case Dlist:
+		return "dlist"
+	case Colist:
+		return "colist"
+	case ListItem:
+		return "list_item"
+	case Table:
+		return "table"
+	case TableColumn:
+		return "column"
+	case TableCell:
+		return "cell"
+	case Toc:
+		return "toc"
 	case Kbd:
 		return "kbd"
 	case Button:
@@ -43,6 +106,10 @@ func (c Context) String() string {
 		return "footnote"
 	case Quoted:
 		return "quoted"
+	case Callout:
+		return "callout"
+	case Break:
+		return "break"
 	}
 	return "unknown"
 }
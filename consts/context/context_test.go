@@ -11,6 +11,25 @@ func TestContext(t *testing.T) {
 		So(Document.String(), ShouldEqual, "document")
 		So(Section.String(), ShouldEqual, "section")
 		So(Paragraph.String(), ShouldEqual, "paragraph")
+		So(Listing.String(), ShouldEqual, "listing")
+		So(Literal.String(), ShouldEqual, "literal")
+		So(Example.String(), ShouldEqual, "example")
+		So(Sidebar.String(), ShouldEqual, "sidebar")
+		So(Quote.String(), ShouldEqual, "quote")
+		So(Verse.String(), ShouldEqual, "verse")
+		So(Pass.String(), ShouldEqual, "pass")
+		So(Open.String(), ShouldEqual, "open")
+		So(Comment.String(), ShouldEqual, "comment")
+		So(Admonition.String(), ShouldEqual, "admonition")
+		So(Ulist.String(), ShouldEqual, "ulist")
+		So(Olist.String(), ShouldEqual, "olist")
+		So(Dlist.String(), ShouldEqual, "dlist")
+		So(Colist.String(), ShouldEqual, "colist")
+		So(ListItem.String(), ShouldEqual, "list_item")
+		So(Table.String(), ShouldEqual, "table")
+		So(TableColumn.String(), ShouldEqual, "column")
+		So(TableCell.String(), ShouldEqual, "cell")
+		So(Toc.String(), ShouldEqual, "toc")
 		So(Kbd.String(), ShouldEqual, "kbd")
 		So(Button.String(), ShouldEqual, "button")
 		So(Menu.String(), ShouldEqual, "menu")
@@ -19,6 +38,8 @@ func TestContext(t *testing.T) {
 		So(Anchor.String(), ShouldEqual, "anchor")
 		So(Footnote.String(), ShouldEqual, "footnote")
 		So(Quoted.String(), ShouldEqual, "quoted")
+		So(Callout.String(), ShouldEqual, "callout")
+		So(Break.String(), ShouldEqual, "break")
 		So(Unknown.String(), ShouldEqual, "unknown")
 	})
 
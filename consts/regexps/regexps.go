@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/VonC/asciidocgo/utils"
 )
@@ -341,7 +342,7 @@ Examples
 
    FootnoteInlineMacroRx = /\\?(footnote(?:ref)?):\[(.*?[^\\])\]/m */
 
-var FootnoteInlineMacroRx, _ = regexp.Compile(`\\?(footnote(?:ref)?):\[(.*?[^\\])\]`)
+var FootnoteInlineMacroRx, _ = regexp.Compile(`(?s)\\?(footnote(?:ref)?):\[(.*?[^\\])\]`)
 
 type FootnoteInlineMacroRxres struct {
 	*Reres
@@ -734,8 +735,8 @@ func NewXrefInlineMacroRxres(s string) *XrefInlineMacroRxres {
 /* Return id of '<<id,reftext>>' or xref:id[reftext]' */
 func (ximr *XrefInlineMacroRxres) XId() string {
 	if ximr.Group(1) != "" {
-		t := strings.Split(ximr.Group(1), ",")
-		return t[0]
+		t := strings.SplitN(ximr.Group(1), ",", 2)
+		return strings.TrimSpace(t[0])
 	} else {
 		return ximr.Group(2)
 	}
@@ -744,8 +745,11 @@ func (ximr *XrefInlineMacroRxres) XId() string {
 /* Return reftext of '<<id,reftext>>' or xref:id[reftext]' */
 func (ximr *XrefInlineMacroRxres) XrefText() string {
 	if ximr.Group(1) != "" {
-		t := strings.Split(ximr.Group(1), ",")
-		return t[1]
+		t := strings.SplitN(ximr.Group(1), ",", 2)
+		if len(t) < 2 {
+			return ""
+		}
+		return strings.TrimSpace(t[1])
 	} else {
 		return ximr.Group(3)
 	}
@@ -833,6 +837,14 @@ func (dqr *DoubleQuotedMultiRxres) DQMText() string {
      data:info */
 var UriSniffRx, _ = regexp.Compile(fmt.Sprintf("^([%v][%v.+-]*:/{0,2}).*", CC_ALPHA, CC_ALNUM))
 
+/* Detects the content of an SVG image: an XML document whose root
+element is svg, after an optional XML declaration, comments and doctype.
+
+   Examples
+     <svg xmlns="http://www.w3.org/2000/svg">
+     <?xml version="1.0"?><!-- logo --><svg> */
+var SvgRootRx, _ = regexp.Compile(`^(?:<\?xml[^>]*\?>\s*)?(?:(?s:<!--.*?-->)\s*|<!DOCTYPE[^>]*>\s*)*<svg[\s>]`)
+
 /* Detects the end of an implicit URI in the text
  Examples
    (http://google.com)
@@ -917,3 +929,643 @@ func iniReplacements() []*Replacement {
 	res = append(res, &Replacement{rx, false, true, "", false})
 	return res
 }
+
+/* Section titles */
+
+/* Matches a single-line (Atx-style) section title.
+ Examples
+   == Foo
+   # ^ a level 1 (h2) section title
+   == Foo ==
+   # ^ also a level 1 (h2) section title
+   match[1] is the delimiter, whose length determines the level
+   match[2] is the title itself
+   match[3] is an inline anchor, which becomes the section id
+AtxSectionRx = /^((?:=|#){1,6})\s+(\S.+?)(?:\s+\1)?$/
+(the trailing delimiter is removed by AtxSectionTitle,
+since Go regexps don't support backreferences) */
+var AtxSectionRx, _ = regexp.Compile(`^((?:=|#){1,6})[ \t]+(\S.*?)[ \t]*$`)
+
+type AtxSectionRxres struct {
+	*Reres
+}
+
+/* Results for AtxSectionRx */
+func NewAtxSectionRxres(s string) *AtxSectionRxres {
+	return &AtxSectionRxres{NewReres(s, AtxSectionRx)}
+}
+
+/* Return the level of the section: 0 for '=', 1 for '==', ... */
+func (asr *AtxSectionRxres) AtxSectionLevel() int {
+	return len(asr.Group(1)) - 1
+}
+
+/* Return the title of the section, without any trailing delimiter
+('Foo' for '== Foo ==') */
+func (asr *AtxSectionRxres) AtxSectionTitle() string {
+	title := asr.Group(2)
+	delimiter := asr.Group(1)
+	if strings.HasSuffix(title, " "+delimiter) || strings.HasSuffix(title, "\t"+delimiter) {
+		title = strings.TrimRight(title[:len(title)-len(delimiter)], " \t")
+	}
+	return title
+}
+
+/* Matches the title line of a two-line (Setext-style) section title.
+The title must contain at least one word character, and may not start
+with a dot (a block title) nor with a blank (a literal paragraph).
+ Examples
+   Foo
+   ~~~
+SetextSectionTitleRx = /^((?=.*\w+.*)[^.].*?)$/ */
+var SetextSectionTitleRx, _ = regexp.Compile(`^((?:\w|[^.\w \t].*?\w).*?)$`)
+
+/* Matches the underline of a two-line (Setext-style) section title.
+ Examples
+   ~~~
+   # ^ a level 2 (h3) section title underline
+SetextSectionLineRx = /^(?:=|-|~|\^|\+)+$/ */
+var SetextSectionLineRx, _ = regexp.Compile(`^(?:=|-|~|\^|\+)+$`)
+
+/* The level of a two-line section title, by underline character */
+var setextSectionLevels = map[byte]int{'=': 0, '-': 1, '~': 2, '^': 3, '+': 4}
+
+/* Check if title and underline form a two-line (Setext-style) section title:
+the underline must be made of a single section level character,
+and its length must be within one character of the title length.
+Returns the level of the section, and true if it is a section title */
+func SetextSectionLevel(title, underline string) (int, bool) {
+	if !SetextSectionTitleRx.MatchString(title) || !SetextSectionLineRx.MatchString(underline) {
+		return -1, false
+	}
+	if strings.Trim(underline, underline[:1]) != "" {
+		return -1, false
+	}
+	diff := utf8.RuneCountInString(title) - len(underline)
+	if diff < -1 || diff > 1 {
+		return -1, false
+	}
+	return setextSectionLevels[underline[0]], true
+}
+
+/* Matches a single-line comment
+(but not the delimiter of a comment block).
+ Examples
+   // and then whatever
+CommentLineRx = %r{^//(?:[^/]|$)} */
+var CommentLineRx, _ = regexp.Compile(`^//(?:[^/]|$)`)
+
+/* Block attributes */
+
+/* Matches an anchor (i.e., id + optional reference text) on a line
+above a block.
+ Examples
+   [[idname]]
+   [[idname,Reference Text]]
+BlockAnchorRx = /^\[\[(?:|([#{CC_ALPHA}:_][#{CC_WORD}:.-]*)(?:,#{CC_BLANK}*(\S.*))?)\]\]$/ */
+var BlockAnchorRx, _ = regexp.Compile(`^\[\[(?:|([a-zA-Z:_][\w:.-]*)(?:,[ \t]*(\S.*))?)\]\]$`)
+
+type BlockAnchorRxres struct {
+	*Reres
+}
+
+/* Results for BlockAnchorRx */
+func NewBlockAnchorRxres(s string) *BlockAnchorRxres {
+	return &BlockAnchorRxres{NewReres(s, BlockAnchorRx)}
+}
+
+/* Return the id of the block anchor */
+func (bar *BlockAnchorRxres) BlockAnchorId() string {
+	return bar.Group(1)
+}
+
+/* Return the reference text of the block anchor ("" if none) */
+func (bar *BlockAnchorRxres) BlockAnchorRefText() string {
+	return bar.Group(2)
+}
+
+/* Matches an attribute list above a block element.
+ Examples
+   # strictly positional
+   [quote, Adam Smith, Wealth of Nations]
+   # name/value pairs
+   [NOTE, caption="Good to know"]
+   # as attribute reference
+   [{lead}]
+BlockAttributeListRx = /^\[(|[#{CC_WORD}\{,.#"'%].*)\]$/ */
+var BlockAttributeListRx, _ = regexp.Compile(`^\[(|[\w\{,.#"'%].*)\]$`)
+
+type BlockAttributeListRxres struct {
+	*Reres
+}
+
+/* Results for BlockAttributeListRx */
+func NewBlockAttributeListRxres(s string) *BlockAttributeListRxres {
+	return &BlockAttributeListRxres{NewReres(s, BlockAttributeListRx)}
+}
+
+/* Return the attribute list, without its brackets */
+func (balr *BlockAttributeListRxres) BlockAttributeList() string {
+	return balr.Group(1)
+}
+
+/* Matches a title above a block.
+ Examples
+   .Title goes here
+BlockTitleRx = /^\.([^\s.].*)$/ */
+var BlockTitleRx, _ = regexp.Compile(`^\.([^\s.].*)$`)
+
+/* Document header */
+
+/* Matches the author info line immediately following the document title.
+ Examples
+   Doc Writer <doc@example.com>
+   Mary_Sue Brontë
+AuthorInfoLineRx = /^(\w[\w\-'.]*)(?: +(\w[\w\-'.]*))?(?: +(\w[\w\-'.]*))?(?: +<([^>]+)>)?$/ */
+var AuthorInfoLineRx, _ = regexp.Compile(`^([\p{L}\p{N}_][\p{L}\p{N}_\-'.]*)(?: +([\p{L}\p{N}_][\p{L}\p{N}_\-'.]*))?(?: +([\p{L}\p{N}_][\p{L}\p{N}_\-'.]*))?(?: +<([^>]+)>)?$`)
+
+/* Matches the revision info line, which appears immediately following
+the author info line beneath the document title.
+ Examples
+   v1.0, 2013-01-01: Ring in the new year release
+   1.0, Jan 01, 2013
+RevisionInfoLineRx = /^(?:\D*(.*?),)?(?:\s*(?!:)(.*?))(?:\s*(?!^):\s*(.*))?$/ */
+var RevisionInfoLineRx, _ = regexp.Compile(`^(?:\D*(.*?),)?[ \t]*((?:[^:\s].*?)?)(?:[ \t]*:[ \t]*(.*))?$`)
+
+type RevisionInfoLineRxres struct {
+	*Reres
+}
+
+/* Results for RevisionInfoLineRx */
+func NewRevisionInfoLineRxres(s string) *RevisionInfoLineRxres {
+	return &RevisionInfoLineRxres{NewReres(s, RevisionInfoLineRx)}
+}
+
+/* Return the revision number ('1.0' for 'v1.0') */
+func (rilr *RevisionInfoLineRxres) RevNumber() string {
+	return strings.TrimRight(rilr.Group(1), " \t")
+}
+
+/* Return the revision date */
+func (rilr *RevisionInfoLineRxres) RevDate() string {
+	return strings.TrimSpace(rilr.Group(2))
+}
+
+/* Return the revision remark */
+func (rilr *RevisionInfoLineRxres) RevRemark() string {
+	return strings.TrimRight(rilr.Group(3), " \t")
+}
+
+/* Matches an attribute entry, which can be an assignment (':name: value'),
+or an unassignment (':name!:' or ':!name:').
+ Examples
+   :foo: bar
+   :First Name: Dan
+   :sectnums!:
+   :!toc:
+   :long-entry: Attribute value lines ending in ' +'
+                are joined together as a single value,
+                collapsing the line breaks and indentation to
+                a single space.
+AttributeEntryRx = /^:(!?\w.*?):(?:[ \t]+(.*))?$/ */
+var AttributeEntryRx, _ = regexp.Compile(`^:(!?\w.*?):(?:[ \t]+(.*))?$`)
+
+type AttributeEntryRxres struct {
+	*Reres
+}
+
+/* Results for AttributeEntryRx */
+func NewAttributeEntryRxres(s string) *AttributeEntryRxres {
+	return &AttributeEntryRxres{NewReres(s, AttributeEntryRx)}
+}
+
+/* Return the name of the attribute, including any '!' */
+func (aer *AttributeEntryRxres) AttributeName() string {
+	return aer.Group(1)
+}
+
+/* Return the value of the attribute ("" if none) */
+func (aer *AttributeEntryRxres) AttributeValue() string {
+	return aer.Group(2)
+}
+
+/* Matches invalid characters in an attribute name.
+InvalidAttributeNameCharsRx = /[^\w\-]/ */
+var InvalidAttributeNameCharsRx, _ = regexp.Compile(`[^\w\-]`)
+
+/* Matches an image block macro.
+ Examples
+   image::filename.png[Caption]
+BlockImageRx = /^image::(\S+?)\[(.*?)\]$/ */
+var BlockImageRx, _ = regexp.Compile(`^image::(\S+?)\[(.*?)\]$`)
+
+type BlockImageRxres struct {
+	*Reres
+}
+
+/* Results for BlockImageRx */
+func NewBlockImageRxres(s string) *BlockImageRxres {
+	return &BlockImageRxres{NewReres(s, BlockImageRx)}
+}
+
+/* Return the target of the image */
+func (bir *BlockImageRxres) BlockImageTarget() string {
+	return bir.Group(1)
+}
+
+/* Return the attribute list of the image ("" if none) */
+func (bir *BlockImageRxres) BlockImageAttributes() string {
+	return bir.Group(2)
+}
+
+/* Matches a table of contents block macro.
+ Examples
+   toc::[]
+   toc::[levels=3]
+BlockTocRx = /^toc::\[(.*?)\]$/ */
+var BlockTocRx, _ = regexp.Compile(`^toc::\[(.*?)\]$`)
+
+type BlockTocRxres struct {
+	*Reres
+}
+
+/* Results for BlockTocRx */
+func NewBlockTocRxres(s string) *BlockTocRxres {
+	return &BlockTocRxres{NewReres(s, BlockTocRx)}
+}
+
+/* Return the attribute list of the toc macro ("" if none) */
+func (btr *BlockTocRxres) BlockTocAttributes() string {
+	return btr.Group(1)
+}
+
+/* Matches the anchors of a converted title, dropped from the entries
+of a table of contents (which are links themselves).
+ Examples
+   <a id="install"></a>Installation
+   <a href="#faq">FAQ</a>
+DropAnchorRx = /<(?:a\b[^>]*|\/a)>/ */
+var DropAnchorRx, _ = regexp.Compile(`<(?:a\b[^>]*|/a)>`)
+
+/* Matches an include preprocessor directive.
+ Examples
+   include::chapter1.ad[]
+   include::example.txt[lines=1;2;5..10]
+IncludeDirectiveRx = /^(\\)?include::([^\[]+)\[(.*?)\]$/ */
+var IncludeDirectiveRx, _ = regexp.Compile(`^(\\)?include::([^\[]+)\[(.*?)\]$`)
+
+type IncludeDirectiveRxres struct {
+	*Reres
+}
+
+/* Results for IncludeDirectiveRx */
+func NewIncludeDirectiveRxres(s string) *IncludeDirectiveRxres {
+	return &IncludeDirectiveRxres{NewReres(s, IncludeDirectiveRx)}
+}
+
+/* Return true if the directive is escaped ('\include::') */
+func (idr *IncludeDirectiveRxres) IsEscaped() bool {
+	return idr.Group(1) != ""
+}
+
+/* Return the target of the include directive */
+func (idr *IncludeDirectiveRxres) IncludeTarget() string {
+	return idr.Group(2)
+}
+
+/* Return the attribute list of the include directive ("" if none) */
+func (idr *IncludeDirectiveRxres) IncludeAttributes() string {
+	return idr.Group(3)
+}
+
+/* Matches a tag directive marking the start or the end of a region
+of an include file, usually in a comment (which may close after it).
+ Examples
+   // tag::try-catch[]
+   # end::try-catch[]
+   <!-- tag::try-catch[] -->
+TagDirectiveRx = /\b(?:tag|e(nd))::(\S+?)\[\](?=$|[ \r])/m */
+var TagDirectiveRx, _ = regexp.Compile(`\b(tag|end)::(\S+?)\[\](?:$|[ \r])`)
+
+/* Matches a conditional preprocessor directive (e.g., ifdef, ifndef, ifeval
+and endif), with the names of the attributes it depends on separated by
+',' (any of them) or '+' (all of them), and its single-line content
+(or expression, for ifeval).
+ Examples
+   ifdef::basebackend-html[]
+   ifndef::theme[]
+   ifdef::env-github,env-browser[]
+   ifdef::backend-html5[Only in HTML.]
+   ifeval::["{backend}" == "html5"]
+   endif::basebackend-html[]
+ConditionalDirectiveRx = /^(\\)?(ifdef|ifndef|ifeval|endif)::(\S*?(?:([,+])\S*?)?)\[(.+)?\]$/ */
+var ConditionalDirectiveRx, _ = regexp.Compile(`^(\\)?(ifdef|ifndef|ifeval|endif)::(\S*?(?:([,+])\S*?)?)\[(.+)?\]$`)
+
+type ConditionalDirectiveRxres struct {
+	*Reres
+}
+
+/* Results for ConditionalDirectiveRx */
+func NewConditionalDirectiveRxres(s string) *ConditionalDirectiveRxres {
+	return &ConditionalDirectiveRxres{NewReres(s, ConditionalDirectiveRx)}
+}
+
+/* Return true if the directive is escaped ('\ifdef::') */
+func (cdr *ConditionalDirectiveRxres) IsEscaped() bool {
+	return cdr.Group(1) != ""
+}
+
+/* Return the directive: 'ifdef', 'ifndef', 'ifeval' or 'endif' */
+func (cdr *ConditionalDirectiveRxres) Directive() string {
+	return cdr.Group(2)
+}
+
+/* Return the target of the directive (the attribute names) */
+func (cdr *ConditionalDirectiveRxres) ConditionalTarget() string {
+	return cdr.Group(3)
+}
+
+/* Return the delimiter of the attribute names: ',', '+' or "" */
+func (cdr *ConditionalDirectiveRxres) Delimiter() string {
+	return cdr.Group(4)
+}
+
+/* Return the text between the brackets ("" if none) */
+func (cdr *ConditionalDirectiveRxres) Text() string {
+	return cdr.Group(5)
+}
+
+/* Matches the expression of an ifeval directive.
+ Examples
+   {sectnumlevels} > 2
+   "{backend}" == "html5"
+EvalExpressionRx = /^(.+?)[ \t]*(==|!=|<=|>=|<|>)[ \t]*(.+)$/ */
+var EvalExpressionRx, _ = regexp.Compile(`^(.+?)[ \t]*(==|!=|<=|>=|<|>)[ \t]*(.+)$`)
+
+/* The numbering styles of an ordered list, by nesting level
+(restarting at the first one beyond the fifth level) */
+var ORDERED_LIST_STYLES utils.Arr = []string{"arabic", "loweralpha", "lowerroman", "upperalpha", "upperroman"}
+
+/* Matches an unordered list item (one level for each hyphen or
+asterisk, up to five).
+ Examples
+   * Foo
+   - Foo
+   ** Nested foo
+UnorderedListRx = /^[ \t]*(-|\*{1,5}|•{1,5})[ \t]+(.*)$/ */
+var UnorderedListRx, _ = regexp.Compile(`^[ \t]*(-|\*{1,5}|\x{2022}{1,5})[ \t]+(.*)$`)
+
+/* Matches an ordered list item (explicit numbering or up to five
+implicit dot levels).
+ Examples
+   . Foo
+   .. Nested foo
+   1. Foo
+   b. Foo
+   iii) Foo
+OrderedListRx = /^[ \t]*(\.{1,5}|\d+\.|[a-zA-Z]\.|[IVXivx]+\))[ \t]+(.*)$/ */
+var OrderedListRx, _ = regexp.Compile(`^[ \t]*(\.{1,5}|\d+\.|[a-zA-Z]\.|[IVXivx]+\))[ \t]+(.*)$`)
+
+/* Matches a callout list item.
+ Examples
+   <1> Explanation
+   <.> Explanation, numbered automatically
+CalloutListRx = /^<(\d+|\.)>[ \t]+(.*)$/ */
+var CalloutListRx, _ = regexp.Compile(`^<(\d+|\.)>[ \t]+(.*)$`)
+
+type ListItemRxres struct {
+	*Reres
+}
+
+/* Results for UnorderedListRx, OrderedListRx or CalloutListRx */
+func NewListItemRxres(s string, rx *regexp.Regexp) *ListItemRxres {
+	return &ListItemRxres{NewReres(s, rx)}
+}
+
+/* Return the marker of the list item ('*', '..', '1.', '1' for '<1>') */
+func (lir *ListItemRxres) ListItemMarker() string {
+	return lir.Group(1)
+}
+
+/* Return the text of the list item */
+func (lir *ListItemRxres) ListItemText() string {
+	return lir.Group(2)
+}
+
+/* Matches a description list item (one level for each extra colon,
+or ';;' for the second level).
+ Examples
+   foo::
+   foo:: The metasyntactic variable
+   bar::: Nested
+   baz;; Alternative nested marker
+DescriptionListRx = /^(?!\/\/)[ \t]*([^ \t]|[^ \t].*?[^ \t])(:{2,4}|;;)(?:[ \t]+(.*))?$/ */
+var DescriptionListRx, _ = regexp.Compile(`^[ \t]*([^ \t]|[^ \t].*?[^ \t])(:{2,4}|;;)(?:[ \t]+(.*))?$`)
+
+type DescriptionListRxres struct {
+	*Reres
+}
+
+/* Results for DescriptionListRx.
+A comment line ('// foo::') is not a description list item */
+func NewDescriptionListRxres(s string) *DescriptionListRxres {
+	if CommentLineRx.MatchString(s) {
+		return &DescriptionListRxres{NewReres("", DescriptionListRx)}
+	}
+	return &DescriptionListRxres{NewReres(s, DescriptionListRx)}
+}
+
+/* Return the term of the description list item */
+func (dlr *DescriptionListRxres) Term() string {
+	return dlr.Group(1)
+}
+
+/* Return the marker of the description list item ('::', ';;', ...) */
+func (dlr *DescriptionListRxres) DescriptionMarker() string {
+	return dlr.Group(2)
+}
+
+/* Return the description following the term on the same line
+("" if none) */
+func (dlr *DescriptionListRxres) Description() string {
+	return dlr.Group(3)
+}
+
+/* Matches the checkbox at the start of the text of a checklist item.
+ Examples
+   [ ] Not done
+   [x] Done
+   [*] Done
+ChecklistItemRx = /^\[([ xX\*])\][ \t]+(.*)$/ */
+var ChecklistItemRx, _ = regexp.Compile(`^\[([ xX*])\][ \t]+(.*)$`)
+
+
+/* The horizontal alignment of a table cell, by cell spec operator */
+var TableCellHorzAlignments = map[string]string{"<": "left", ">": "right", "^": "center"}
+
+/* The vertical alignment of a table cell, by cell spec operator */
+var TableCellVertAlignments = map[string]string{"<": "top", ">": "bottom", "^": "middle"}
+
+/* The style of a table cell, by cell spec letter */
+var TableCellStyles = map[string]string{"d": "none", "s": "strong", "e": "emphasis", "m": "monospaced",
+	"h": "header", "l": "literal", "v": "verse", "a": "asciidoc"}
+
+/* Matches a column spec of the cols attribute of a table: an optional
+multiplier, an optional alignment, an optional width and an optional style.
+ Examples
+   1
+   2*
+   3*^.>2m
+   25%
+ColumnSpecRx = /^(?:(\d+)\*)?([<^>](?:\.[<^>]?)?|(?:[<^>]?\.)?[<^>])?(\d+%?)?([a-z])?$/ */
+var ColumnSpecRx, _ = regexp.Compile(`^(?:(\d+)\*)?([<^>](?:\.[<^>]?)?|(?:[<^>]?\.)?[<^>])?(\d+%?)?([a-z])?$`)
+
+/* Matches a cell spec at the start of a line (before the first separator):
+an optional span or duplication factor, an optional alignment and
+an optional style.
+ Examples
+   2+
+   .3+^.>m
+   3*a
+CellSpecStartRx = /^[ \t]*(?:(\d+(?:\.\d*)?|(?:\d*\.)?\d+)([*+]))?([<^>](?:\.[<^>]?)?|(?:[<^>]?\.)?[<^>])?([a-z])?$/ */
+var CellSpecStartRx, _ = regexp.Compile(`^[ \t]*(?:(\d+(?:\.\d*)?|(?:\d*\.)?\d+)([*+]))?([<^>](?:\.[<^>]?)?|(?:[<^>]?\.)?[<^>])?([a-z])?$`)
+
+/* Matches a cell spec at the end of the text of the previous cell
+(just before the separator of the cell it applies to).
+CellSpecEndRx = /[ \t]+(?:(\d+(?:\.\d*)?|(?:\d*\.)?\d+)([*+]))?([<^>](?:\.[<^>]?)?|(?:[<^>]?\.)?[<^>])?([a-z])?$/ */
+var CellSpecEndRx, _ = regexp.Compile(`[ \t]+(?:(\d+(?:\.\d*)?|(?:\d*\.)?\d+)([*+]))?([<^>](?:\.[<^>]?)?|(?:[<^>]?\.)?[<^>])?([a-z])?$`)
+
+/* Matches a callout reference inside a verbatim block, once its special
+characters are substituted, optionally preceded by a line comment,
+and only followed by other callouts up to the end of the line
+(the last group is the lookahead).
+ Examples
+   // &lt;1&gt;
+   # &lt;2&gt; &lt;3&gt;
+   \&lt;4&gt;
+   &lt;!--5--&gt;
+CalloutConvertRx = /(?:(?:\/\/|#|;;) ?)?(\\)?&lt;!?(--|)(\d+)\2&gt;(?=(?: ?\\?&lt;!?\2\d+\2&gt;)*$)/ */
+var CalloutConvertRx, _ = regexp.Compile(`(?m)(?:(?://|#|;;) ?)?(\\)?&lt;(?:!--(\d+)--|(\d+))&gt;((?: ?\\?&lt;(?:!--\d+--|\d+)&gt;)*$)`)
+
+type CalloutConvertRxres struct {
+	*Reres
+}
+
+/* Results for CalloutConvertRx */
+func NewCalloutConvertRxres(s string) *CalloutConvertRxres {
+	return &CalloutConvertRxres{NewReresLAGroup(s, CalloutConvertRx)}
+}
+
+/* Check if the callout is escaped ('\<1>') */
+func (ccr *CalloutConvertRxres) IsEscaped() bool {
+	return ccr.Group(1) == "\\"
+}
+
+/* Return the number of the callout ('1' for '<1>' or '<!--1-->') */
+func (ccr *CalloutConvertRxres) CalloutNumber() string {
+	if ccr.HasGroup(2) {
+		return ccr.Group(2)
+	}
+	return ccr.Group(3)
+}
+
+/* Matches a callout reference in the source of a verbatim block,
+only followed by other callouts up to the end of the line
+(the last group is the lookahead).
+ Examples
+   <1>
+   \<2>
+   <!--3-->
+CalloutScanRx = /\\?<!?(--|)(\d+)\1>(?=(?: ?\\?<!?\1\d+\1>)*$)/ */
+var CalloutScanRx, _ = regexp.Compile(`(?m)\\?<(?:!--(\d+)--|(\d+))>((?: ?\\?<(?:!--\d+--|\d+)>)*$)`)
+
+type CalloutScanRxres struct {
+	*Reres
+}
+
+/* Results for CalloutScanRx */
+func NewCalloutScanRxres(s string) *CalloutScanRxres {
+	return &CalloutScanRxres{NewReresLAGroup(s, CalloutScanRx)}
+}
+
+/* Return the number of the callout ('1' for '<1>' or '<!--1-->') */
+func (csr *CalloutScanRxres) CalloutNumber() string {
+	if csr.HasGroup(1) {
+		return csr.Group(1)
+	}
+	return csr.Group(2)
+}
+
+/* Matches a line ending with a hard line break ('+' after a space).
+ Examples
+   Rubies are red +
+LineBreakRx = /^(.*)[[:blank:]]\+$/ */
+var LineBreakRx, _ = regexp.Compile(`(?m)^(.*)[ \t]\+$`)
+
+/* The marker of a hard line break at the end of a line */
+const LINE_BREAK = " +"
+
+/* Matches a callout reference in the source of a source block, before
+it is highlighted, optionally preceded by a line comment, and only
+followed by other callouts up to the end of the line
+(the last group is the lookahead).
+ Examples
+   // <1>
+   # <2> <3>
+   \<4>
+   <!--5-->
+CalloutExtractRx = /(?:(?:\/\/|#|;;) ?)?(\\)?<!?(--|)(\d+)\2>(?=(?: ?\\?<!?\2\d+\2>)*$)/ */
+var CalloutExtractRx, _ = regexp.Compile(`(?m)(?:(?://|#|;;) ?)?(\\)?<(?:!--(\d+)--|(\d+))>((?: ?\\?<(?:!--\d+--|\d+)>)*$)`)
+
+type CalloutExtractRxres struct {
+	*Reres
+}
+
+/* Results for CalloutExtractRx */
+func NewCalloutExtractRxres(s string) *CalloutExtractRxres {
+	return &CalloutExtractRxres{NewReresLAGroup(s, CalloutExtractRx)}
+}
+
+/* Check if the callout is escaped ('\<1>') */
+func (cer *CalloutExtractRxres) IsEscaped() bool {
+	return cer.Group(1) == "\\"
+}
+
+/* Return the number of the callout ('1' for '<1>' or '<!--1-->') */
+func (cer *CalloutExtractRxres) CalloutNumber() string {
+	if cer.HasGroup(2) {
+		return cer.Group(2)
+	}
+	return cer.Group(3)
+}
+
+/* Matches the block macro of an extension.
+ Examples
+   gist::123456[]
+   shout::[Hello]
+GenericBlockMacroRx = /^(#{CG_WORD}[#{CC_WORD}-]*)::(\S*?)\[((?:\\\]|[^\]])*?)\]$/ */
+var GenericBlockMacroRx, _ = regexp.Compile(`^(\w[\w-]*)::(\S*?)\[((?:\\\]|[^\]])*?)\]$`)
+
+type GenericBlockMacroRxres struct {
+	*Reres
+}
+
+/* Results for GenericBlockMacroRx */
+func NewGenericBlockMacroRxres(s string) *GenericBlockMacroRxres {
+	return &GenericBlockMacroRxres{NewReres(s, GenericBlockMacroRx)}
+}
+
+/* Return the name of the block macro ('gist' for 'gist::123[]') */
+func (gbmr *GenericBlockMacroRxres) MacroName() string {
+	return gbmr.Group(1)
+}
+
+/* Return the target of the block macro ("" if none) */
+func (gbmr *GenericBlockMacroRxres) MacroTarget() string {
+	return gbmr.Group(2)
+}
+
+/* Return the attribute list of the block macro ("" if none) */
+func (gbmr *GenericBlockMacroRxres) MacroAttributes() string {
+	return gbmr.Group(3)
+}
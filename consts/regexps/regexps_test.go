@@ -36,6 +36,13 @@ func TestRegexps(t *testing.T) {
 		So(UriSniffRx.MatchString("data:info"), ShouldBeTrue)
 	})
 
+	Convey("Regexps can detect the content of an SVG image", t, func() {
+		So(SvgRootRx.MatchString(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), ShouldBeTrue)
+		So(SvgRootRx.MatchString("<?xml version=\"1.0\"?>\n<!-- a\nlogo -->\n<!DOCTYPE svg>\n<svg>"), ShouldBeTrue)
+		So(SvgRootRx.MatchString(`<svgx>`), ShouldBeFalse)
+		So(SvgRootRx.MatchString(`<html><svg>`), ShouldBeFalse)
+	})
+
 	Convey("Regexps can detect escaped brackets", t, func() {
 		So(EscapedBracketRx.MatchString(`\]`), ShouldBeTrue)
 		So(EscapedBracketRx.MatchString(`a\\]a`), ShouldBeTrue)
@@ -646,6 +653,11 @@ func TestRegexps(t *testing.T) {
 			So(r.FootnoteText(), ShouldEqual, "id")
 
 		})
+		Convey("FootnoteInlineMacroRx should detect a text spanning multiple lines", func() {
+			r := NewFootnoteInlineMacroRxres("footnote:[a text\non two lines]")
+			So(r.HasAnyMatch(), ShouldBeTrue)
+			So(r.FootnoteText(), ShouldEqual, "a text\non two lines")
+		})
 	})
 
 	Convey("Regexps can encapsulate InlineBiblioAnchorRx results in a struct InlineBiblioAnchorRxres", t, func() {
@@ -769,6 +781,12 @@ notext"`)
 			So(r.XId(), ShouldEqual, `id4`)
 			So(r.XrefText(), ShouldEqual, `reftext4`)
 
+			r = NewXrefInlineMacroRxres(`&lt;&lt;id5&gt;&gt; &lt;&lt;id6, text, with comma&gt;&gt;`)
+			So(r.XId(), ShouldEqual, `id5`)
+			So(r.XrefText(), ShouldEqual, ``)
+			r.Next()
+			So(r.XId(), ShouldEqual, `id6`)
+			So(r.XrefText(), ShouldEqual, `text, with comma`)
 		})
 	})
 
@@ -795,4 +813,267 @@ notext"`)
 			So(r.HasAnyMatch(), ShouldBeFalse)
 		})
 	})
+
+	Convey("Regexps can encapsulate Atx section title results in a struct AtxSectionRxres", t, func() {
+		Convey("AtxSectionRxres should detect section titles and their level", func() {
+			r := NewAtxSectionRxres("== Section Title")
+			So(r.HasAnyMatch(), ShouldBeTrue)
+			So(r.AtxSectionLevel(), ShouldEqual, 1)
+			So(r.AtxSectionTitle(), ShouldEqual, "Section Title")
+
+			r = NewAtxSectionRxres("= Document Title")
+			So(r.AtxSectionLevel(), ShouldEqual, 0)
+			So(r.AtxSectionTitle(), ShouldEqual, "Document Title")
+
+			r = NewAtxSectionRxres("### Markdown Title ###")
+			So(r.AtxSectionLevel(), ShouldEqual, 2)
+			So(r.AtxSectionTitle(), ShouldEqual, "Markdown Title")
+		})
+		Convey("AtxSectionRxres should not detect lines without a title", func() {
+			So(NewAtxSectionRxres("==").HasAnyMatch(), ShouldBeFalse)
+			So(NewAtxSectionRxres("==Title").HasAnyMatch(), ShouldBeFalse)
+			So(NewAtxSectionRxres("======= Too deep").HasAnyMatch(), ShouldBeFalse)
+		})
+	})
+
+	Convey("Regexps can detect two-line section titles", t, func() {
+		level, ok := SetextSectionLevel("Section Title", "-------------")
+		So(ok, ShouldBeTrue)
+		So(level, ShouldEqual, 1)
+		level, ok = SetextSectionLevel("A", "~~")
+		So(ok, ShouldBeTrue)
+		So(level, ShouldEqual, 2)
+		level, ok = SetextSectionLevel("Document Title", "=============")
+		So(level, ShouldEqual, 0)
+		_, ok = SetextSectionLevel("Title", "--")
+		So(ok, ShouldBeFalse)
+		_, ok = SetextSectionLevel("Title", "--~--")
+		So(ok, ShouldBeFalse)
+		_, ok = SetextSectionLevel(".Block title", "++++++++++++")
+		So(ok, ShouldBeFalse)
+		_, ok = SetextSectionLevel("  literal", "+++++++++")
+		So(ok, ShouldBeFalse)
+		_, ok = SetextSectionLevel("----", "----")
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("Regexps can detect single-line comments", t, func() {
+		So(CommentLineRx.MatchString("// comment"), ShouldBeTrue)
+		So(CommentLineRx.MatchString("//"), ShouldBeTrue)
+		So(CommentLineRx.MatchString("////"), ShouldBeFalse)
+		So(CommentLineRx.MatchString("/ not a comment"), ShouldBeFalse)
+	})
+
+	Convey("Regexps can encapsulate block anchor results in a struct BlockAnchorRxres", t, func() {
+		r := NewBlockAnchorRxres("[[idname,Reference Text]]")
+		So(r.HasAnyMatch(), ShouldBeTrue)
+		So(r.BlockAnchorId(), ShouldEqual, "idname")
+		So(r.BlockAnchorRefText(), ShouldEqual, "Reference Text")
+		r = NewBlockAnchorRxres("[[idname]]")
+		So(r.BlockAnchorId(), ShouldEqual, "idname")
+		So(r.BlockAnchorRefText(), ShouldEqual, "")
+		So(NewBlockAnchorRxres("[[1id]]").HasAnyMatch(), ShouldBeFalse)
+	})
+
+	Convey("Regexps can encapsulate block attribute lists in a struct BlockAttributeListRxres", t, func() {
+		r := NewBlockAttributeListRxres("[quote, Adam Smith, Wealth of Nations]")
+		So(r.HasAnyMatch(), ShouldBeTrue)
+		So(r.BlockAttributeList(), ShouldEqual, "quote, Adam Smith, Wealth of Nations")
+		So(NewBlockAttributeListRxres("[]").HasAnyMatch(), ShouldBeTrue)
+		So(NewBlockAttributeListRxres("[ not a list]").HasAnyMatch(), ShouldBeFalse)
+		So(BlockTitleRx.MatchString(".Title"), ShouldBeTrue)
+		So(BlockTitleRx.MatchString("..."), ShouldBeFalse)
+		So(BlockTitleRx.MatchString(". Title"), ShouldBeFalse)
+	})
+
+	Convey("Regexps can detect author info lines", t, func() {
+		m := AuthorInfoLineRx.FindStringSubmatch("Doc Writer <doc@example.com>")
+		So(m, ShouldNotBeNil)
+		So(m[1], ShouldEqual, "Doc")
+		So(m[2], ShouldEqual, "Writer")
+		So(m[4], ShouldEqual, "doc@example.com")
+		So(AuthorInfoLineRx.MatchString("Mary_Sue Brontë"), ShouldBeTrue)
+		So(AuthorInfoLineRx.MatchString("<doc@example.com>"), ShouldBeFalse)
+	})
+
+	Convey("Regexps can encapsulate revision info lines in a struct RevisionInfoLineRxres", t, func() {
+		r := NewRevisionInfoLineRxres("v1.0, 2013-01-01: Ring in the new year release")
+		So(r.HasAnyMatch(), ShouldBeTrue)
+		So(r.RevNumber(), ShouldEqual, "1.0")
+		So(r.RevDate(), ShouldEqual, "2013-01-01")
+		So(r.RevRemark(), ShouldEqual, "Ring in the new year release")
+		r = NewRevisionInfoLineRxres("2013-01-01")
+		So(r.HasGroup(1), ShouldBeFalse)
+		So(r.RevDate(), ShouldEqual, "2013-01-01")
+		So(r.HasGroup(3), ShouldBeFalse)
+	})
+
+	Convey("Regexps can encapsulate attribute entries in a struct AttributeEntryRxres", t, func() {
+		r := NewAttributeEntryRxres(":First Name: Dan")
+		So(r.HasAnyMatch(), ShouldBeTrue)
+		So(r.AttributeName(), ShouldEqual, "First Name")
+		So(r.AttributeValue(), ShouldEqual, "Dan")
+		r = NewAttributeEntryRxres(":sectnums!:")
+		So(r.AttributeName(), ShouldEqual, "sectnums!")
+		So(r.AttributeValue(), ShouldEqual, "")
+		So(NewAttributeEntryRxres(":!toc:").AttributeName(), ShouldEqual, "!toc")
+		So(NewAttributeEntryRxres(": not an entry:").HasAnyMatch(), ShouldBeFalse)
+		So(InvalidAttributeNameCharsRx.ReplaceAllString("First Name", ""), ShouldEqual, "FirstName")
+	})
+
+	Convey("Regexps can encapsulate image block macros in a struct BlockImageRxres", t, func() {
+		r := NewBlockImageRxres("image::images/tiger.png[Tiger, 200, 100]")
+		So(r.HasAnyMatch(), ShouldBeTrue)
+		So(r.BlockImageTarget(), ShouldEqual, "images/tiger.png")
+		So(r.BlockImageAttributes(), ShouldEqual, "Tiger, 200, 100")
+		So(NewBlockImageRxres("image::tiger.png[]").BlockImageAttributes(), ShouldEqual, "")
+		So(NewBlockImageRxres("image:tiger.png[]").HasAnyMatch(), ShouldBeFalse)
+		So(NewBlockImageRxres("image::tiger.png[] trailing").HasAnyMatch(), ShouldBeFalse)
+	})
+
+	Convey("Regexps can encapsulate toc block macros in a struct BlockTocRxres", t, func() {
+		r := NewBlockTocRxres("toc::[levels=3]")
+		So(r.HasAnyMatch(), ShouldBeTrue)
+		So(r.BlockTocAttributes(), ShouldEqual, "levels=3")
+		So(NewBlockTocRxres("toc::[]").HasAnyMatch(), ShouldBeTrue)
+		So(NewBlockTocRxres("toc::main[]").HasAnyMatch(), ShouldBeFalse)
+		So(DropAnchorRx.ReplaceAllString(`<a id="a"></a>A <a href="#b">B</a> <abbr>C</abbr>`, ""), ShouldEqual, "A B <abbr>C</abbr>")
+	})
+
+	Convey("Regexps can encapsulate include directives in a struct IncludeDirectiveRxres", t, func() {
+		r := NewIncludeDirectiveRxres("include::chapter1.adoc[leveloffset=+1]")
+		So(r.HasAnyMatch(), ShouldBeTrue)
+		So(r.IsEscaped(), ShouldBeFalse)
+		So(r.IncludeTarget(), ShouldEqual, "chapter1.adoc")
+		So(r.IncludeAttributes(), ShouldEqual, "leveloffset=+1")
+		So(NewIncludeDirectiveRxres("\\include::chapter1.adoc[]").IsEscaped(), ShouldBeTrue)
+		So(NewIncludeDirectiveRxres("include::[]").HasAnyMatch(), ShouldBeFalse)
+		So(NewIncludeDirectiveRxres(" include::a.adoc[]").HasAnyMatch(), ShouldBeFalse)
+		So(TagDirectiveRx.FindStringSubmatch("// tag::try-catch[]"), ShouldResemble, []string{"tag::try-catch[]", "tag", "try-catch"})
+		So(TagDirectiveRx.FindStringSubmatch("# end::try-catch[]")[1], ShouldEqual, "end")
+		So(TagDirectiveRx.MatchString("retag::x[]"), ShouldBeFalse)
+		So(TagDirectiveRx.FindStringSubmatch("<!-- end::try-catch[] -->")[2], ShouldEqual, "try-catch")
+		So(TagDirectiveRx.MatchString("tag::x[]y"), ShouldBeFalse)
+	})
+
+	Convey("Regexps can encapsulate conditional directives in a struct ConditionalDirectiveRxres", t, func() {
+		r := NewConditionalDirectiveRxres("ifdef::env-github,env-browser[]")
+		So(r.HasAnyMatch(), ShouldBeTrue)
+		So(r.IsEscaped(), ShouldBeFalse)
+		So(r.Directive(), ShouldEqual, "ifdef")
+		So(r.ConditionalTarget(), ShouldEqual, "env-github,env-browser")
+		So(r.Delimiter(), ShouldEqual, ",")
+		So(r.Text(), ShouldEqual, "")
+		r = NewConditionalDirectiveRxres("\\ifndef::a+b[Some text]")
+		So(r.IsEscaped(), ShouldBeTrue)
+		So(r.Directive(), ShouldEqual, "ifndef")
+		So(r.Delimiter(), ShouldEqual, "+")
+		So(r.Text(), ShouldEqual, "Some text")
+		r = NewConditionalDirectiveRxres("ifeval::[{sectnumlevels} > 2]")
+		So(r.ConditionalTarget(), ShouldEqual, "")
+		So(r.Text(), ShouldEqual, "{sectnumlevels} > 2")
+		So(NewConditionalDirectiveRxres("endif::[]").Directive(), ShouldEqual, "endif")
+		So(NewConditionalDirectiveRxres("ifdef::a b[]").HasAnyMatch(), ShouldBeFalse)
+		So(EvalExpressionRx.FindStringSubmatch(`"{backend}" == "html5"`)[1:], ShouldResemble, []string{`"{backend}"`, "==", `"html5"`})
+		So(EvalExpressionRx.FindStringSubmatch("{sectnumlevels}>=2")[1:], ShouldResemble, []string{"{sectnumlevels}", ">=", "2"})
+	})
+
+	Convey("Regexps can encapsulate list items in structs ListItemRxres and DescriptionListRxres", t, func() {
+		r := NewListItemRxres("** nested *item*", UnorderedListRx)
+		So(r.HasAnyMatch(), ShouldBeTrue)
+		So(r.ListItemMarker(), ShouldEqual, "**")
+		So(r.ListItemText(), ShouldEqual, "nested *item*")
+		So(NewListItemRxres("  - item", UnorderedListRx).ListItemMarker(), ShouldEqual, "-")
+		So(UnorderedListRx.MatchString("****"), ShouldBeFalse)
+		So(UnorderedListRx.MatchString("*strong* text"), ShouldBeFalse)
+		So(NewListItemRxres("... third", OrderedListRx).ListItemMarker(), ShouldEqual, "...")
+		So(NewListItemRxres("10. ten", OrderedListRx).ListItemMarker(), ShouldEqual, "10.")
+		So(NewListItemRxres("b. bee", OrderedListRx).ListItemMarker(), ShouldEqual, "b.")
+		So(NewListItemRxres("iv) four", OrderedListRx).ListItemMarker(), ShouldEqual, "iv)")
+		So(OrderedListRx.MatchString(".Title"), ShouldBeFalse)
+		So(NewListItemRxres("<1> callout", CalloutListRx).ListItemMarker(), ShouldEqual, "1")
+		So(NewListItemRxres("<.> callout", CalloutListRx).ListItemText(), ShouldEqual, "callout")
+		d := NewDescriptionListRxres("CPU:: The brain")
+		So(d.HasAnyMatch(), ShouldBeTrue)
+		So(d.Term(), ShouldEqual, "CPU")
+		So(d.DescriptionMarker(), ShouldEqual, "::")
+		So(d.Description(), ShouldEqual, "The brain")
+		d = NewDescriptionListRxres("Disk:::")
+		So(d.DescriptionMarker(), ShouldEqual, ":::")
+		So(d.Description(), ShouldEqual, "")
+		So(NewDescriptionListRxres("a;; b").DescriptionMarker(), ShouldEqual, ";;")
+		So(NewDescriptionListRxres("// CPU:: comment").HasAnyMatch(), ShouldBeFalse)
+		So(NewDescriptionListRxres("image::tiger.png[]").HasAnyMatch(), ShouldBeFalse)
+		So(ChecklistItemRx.FindStringSubmatch("[x] done"), ShouldResemble, []string{"[x] done", "x", "done"})
+		So(ChecklistItemRx.MatchString("[y] no"), ShouldBeFalse)
+	})
+
+	Convey("Regexps can match table column specs and cell specs", t, func() {
+		So(ColumnSpecRx.FindStringSubmatch("3*^.>2m"), ShouldResemble, []string{"3*^.>2m", "3", "^.>", "2", "m"})
+		So(ColumnSpecRx.FindStringSubmatch("25%"), ShouldResemble, []string{"25%", "", "", "25%", ""})
+		So(ColumnSpecRx.MatchString("a b"), ShouldBeFalse)
+		So(CellSpecStartRx.FindStringSubmatch("2.3+<s"), ShouldResemble, []string{"2.3+<s", "2.3", "+", "<", "s"})
+		So(CellSpecStartRx.FindStringSubmatch(""), ShouldResemble, []string{"", "", "", "", ""})
+		So(CellSpecStartRx.MatchString("text"), ShouldBeFalse)
+		So(CellSpecEndRx.FindStringSubmatch("text 3*a"), ShouldResemble, []string{" 3*a", "3", "*", "", "a"})
+		So(CellSpecEndRx.MatchString("text"), ShouldBeFalse)
+		So(TableCellStyles["a"], ShouldEqual, "asciidoc")
+		So(TableCellHorzAlignments["^"], ShouldEqual, "center")
+		So(TableCellVertAlignments[">"], ShouldEqual, "bottom")
+	})
+
+	Convey("Regexps can encapsulate callouts in structs CalloutConvertRxres and CalloutScanRxres", t, func() {
+		r := NewCalloutConvertRxres("a // &lt;1&gt; &lt;!--2--&gt;\nb \\&lt;3&gt;\nc &lt;4&gt; d")
+		So(r.HasAnyMatch(), ShouldBeTrue)
+		So(r.FullMatch(), ShouldEqual, "// &lt;1&gt;")
+		So(r.CalloutNumber(), ShouldEqual, "1")
+		So(r.IsEscaped(), ShouldBeFalse)
+		r.Next()
+		So(r.FullMatch(), ShouldEqual, "&lt;!--2--&gt;")
+		So(r.CalloutNumber(), ShouldEqual, "2")
+		r.Next()
+		So(r.IsEscaped(), ShouldBeTrue)
+		So(r.CalloutNumber(), ShouldEqual, "3")
+		r.Next()
+		So(r.HasNext(), ShouldBeFalse)
+		s := NewCalloutScanRxres("x <1> <!--2-->\n<3> y\n\\<4>")
+		So(s.CalloutNumber(), ShouldEqual, "1")
+		s.Next()
+		So(s.CalloutNumber(), ShouldEqual, "2")
+		s.Next()
+		So(s.FullMatch(), ShouldEqual, "\\<4>")
+		So(s.IsEscaped(), ShouldBeTrue)
+		So(LineBreakRx.FindAllStringSubmatch("a +\nb+\nc +", -1), ShouldResemble, [][]string{{"a +", "a"}, {"c +", "c"}})
+	})
+
+	Convey("Regexps can encapsulate callouts to extract in struct CalloutExtractRxres", t, func() {
+		r := NewCalloutExtractRxres("fmt.Println() // <1>\nx := \\<2>\ny # <!--3--> <4>\nz <5> z")
+		So(r.HasAnyMatch(), ShouldBeTrue)
+		So(r.FullMatch(), ShouldEqual, "// <1>")
+		So(r.CalloutNumber(), ShouldEqual, "1")
+		So(r.IsEscaped(), ShouldBeFalse)
+		r.Next()
+		So(r.IsEscaped(), ShouldBeTrue)
+		So(r.CalloutNumber(), ShouldEqual, "2")
+		r.Next()
+		So(r.FullMatch(), ShouldEqual, "# <!--3-->")
+		So(r.CalloutNumber(), ShouldEqual, "3")
+		r.Next()
+		So(r.CalloutNumber(), ShouldEqual, "4")
+		r.Next()
+		So(r.HasNext(), ShouldBeFalse)
+	})
+
+	Convey("Regexps can encapsulate block macros in struct GenericBlockMacroRxres", t, func() {
+		r := NewGenericBlockMacroRxres("gist::123[a, b=\\]c]")
+		So(r.HasAnyMatch(), ShouldBeTrue)
+		So(r.MacroName(), ShouldEqual, "gist")
+		So(r.MacroTarget(), ShouldEqual, "123")
+		So(r.MacroAttributes(), ShouldEqual, "a, b=\\]c")
+		r = NewGenericBlockMacroRxres("my-toc::[]")
+		So(r.MacroName(), ShouldEqual, "my-toc")
+		So(r.MacroTarget(), ShouldEqual, "")
+		So(NewGenericBlockMacroRxres("gist::123[] more").HasAnyMatch(), ShouldBeFalse)
+		So(NewGenericBlockMacroRxres("-a::b[]").HasAnyMatch(), ShouldBeFalse)
+	})
 }
@@ -0,0 +1,29 @@
+package severity
+
+// Severity of a diagnostic message reported while processing a document.
+type Severity int
+
+const (
+	/* Details useful to trace the processing of a document */
+	DEBUG Severity = iota
+	/* An information about the processing of a document */
+	INFO
+	/* A problem in the document, which has been recovered from
+	(a missing image, an illegal path auto-recovered, ...) */
+	WARN
+	/* A problem which prevents part of the document from being processed
+	(a path outside of the jail, a bad counter seed, ...) */
+	ERROR
+	/* A problem which prevents the document from being processed */
+	FATAL
+)
+
+var severityNames = [...]string{"DEBUG", "INFO", "WARNING", "ERROR", "FATAL"}
+
+/* The name of the severity, as printed in the messages ('WARNING', ...) */
+func (s Severity) String() string {
+	if s < DEBUG || s > FATAL {
+		return "UNKNOWN"
+	}
+	return severityNames[s]
+}
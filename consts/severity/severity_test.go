@@ -0,0 +1,24 @@
+package severity
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSeverity(t *testing.T) {
+
+	Convey("A severity is gradual", t, func() {
+		So(DEBUG < INFO, ShouldBeTrue)
+		So(INFO < WARN, ShouldBeTrue)
+		So(WARN < ERROR, ShouldBeTrue)
+		So(ERROR < FATAL, ShouldBeTrue)
+	})
+
+	Convey("A severity has a name", t, func() {
+		So(WARN.String(), ShouldEqual, "WARNING")
+		So(FATAL.String(), ShouldEqual, "FATAL")
+		So(Severity(-1).String(), ShouldEqual, "UNKNOWN")
+		So(Severity(10).String(), ShouldEqual, "UNKNOWN")
+	})
+}
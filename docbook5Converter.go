@@ -0,0 +1,688 @@
+package asciidocgo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VonC/asciidocgo/consts/severity"
+)
+
+/* The built-in converter for the DocBook 5 backend, producing the same
+DocBook 5 XML as Asciidoctor: an <article>, or a <book> for the book doctype.
+Selected with the backend attribute 'docbook5' (or 'docbook'),
+which sets the basebackend attribute to 'docbook'. */
+type docbook5Converter struct{}
+
+/* Convert a Document, a Section, a Block or an Inline node to DocBook 5 */
+func (c *docbook5Converter) Convert(node interface{}, transform string) (string, bool) {
+	switch n := node.(type) {
+	case *Document:
+		switch transform {
+		case "document":
+			return c.document(n), true
+		case "embedded":
+			return n.Content(), true
+		}
+	case *Section:
+		if transform == "section" {
+			return c.section(n), true
+		}
+	case *Block:
+		switch transform {
+		case "block_paragraph":
+			return c.paragraph(n), true
+		case "block_admonition":
+			return c.admonition(n), true
+		case "block_listing":
+			return c.listing(n), true
+		case "block_literal":
+			return c.literal(n), true
+		case "block_example":
+			return c.example(n), true
+		case "block_sidebar":
+			return c.sidebar(n), true
+		case "block_quote":
+			return c.quote(n), true
+		case "block_verse":
+			return c.verse(n), true
+		case "block_pass":
+			return n.Content(), true
+		case "block_toc":
+			// DocBook toolchains generate their own table of contents
+			return "", true
+		case "block_open":
+			return c.open(n), true
+		case "block_image":
+			return c.image(n), true
+		}
+	case *List:
+		switch transform {
+		case "block_ulist":
+			return c.ulist(n), true
+		case "block_olist":
+			return c.olist(n), true
+		case "block_dlist":
+			return c.dlist(n), true
+		case "block_colist":
+			return c.colist(n), true
+		}
+	case *Table:
+		if transform == "block_table" {
+			return c.table(n), true
+		}
+	case *Inline:
+		switch transform {
+		case "inline_anchor":
+			return c.inlineAnchor(n), true
+		case "inline_break":
+			return fmt.Sprintf("%s<?asciidoc-br?>", n.Text()), true
+		case "inline_button":
+			return fmt.Sprintf("<guibutton>%s</guibutton>", n.Text()), true
+		case "inline_callout":
+			return fmt.Sprintf("<co%s/>", commonAttributes(n.abstractNode)), true
+		case "inline_footnote":
+			return c.inlineFootnote(n), true
+		case "inline_image":
+			return c.inlineImage(n), true
+		case "inline_indexterm":
+			return c.inlineIndexterm(n), true
+		case "inline_kbd":
+			return c.inlineKbd(n), true
+		case "inline_menu":
+			return c.inlineMenu(n), true
+		case "inline_quoted":
+			return c.inlineQuoted(n), true
+		}
+	}
+	return "", false
+}
+
+// The xml:id, role and xreflabel attributes of an element
+func commonAttributes(an *abstractNode) string {
+	res := ""
+	if an.Id() != "" {
+		res = res + fmt.Sprintf(` xml:id="%s"`, escapeAttribute(an.Id()))
+	}
+	if role, _ := an.Role().(string); role != "" {
+		res = res + fmt.Sprintf(` role="%s"`, escapeAttribute(role))
+	}
+	if reftext := attrString(an, "reftext"); reftext != "" {
+		res = res + fmt.Sprintf(` xreflabel="%s"`, escapeAttribute(reftext))
+	}
+	return res
+}
+
+// The title of a block as a title element, followed by a new line ("" if no title)
+func titleTag(ab *abstractBlock) string {
+	if !ab.HasTitle() {
+		return ""
+	}
+	return fmt.Sprintf("<title>%s</title>\n", ab.Title())
+}
+
+// The content of a block, wrapped in a simpara if the block is simple
+func resolveContent(b *Block) string {
+	if b.HasBlocks() {
+		return strings.TrimSuffix(b.Content(), "\n")
+	}
+	return fmt.Sprintf("<simpara>%s</simpara>", b.Content())
+}
+
+func (c *docbook5Converter) document(d *Document) string {
+	rootTag := "article"
+	if d.DocType() == "book" {
+		rootTag = "book"
+	}
+	lang := ""
+	if !d.HasAttr("nolang", nil, false) {
+		lang = fmt.Sprintf(` xml:lang="%s"`, escapeAttribute(d.Attr("lang", "en", false)))
+	}
+	res := []string{fmt.Sprintf(`<?xml version="1.0" encoding="%s"?>`, escapeAttribute(d.Attr("encoding", "UTF-8", false))),
+		fmt.Sprintf(`<%s xmlns="http://docbook.org/ns/docbook" xmlns:xl="http://www.w3.org/1999/xlink" version="5.0"%s%s>`, rootTag, lang, commonAttributes(d.abstractNode))}
+	if info := c.info(d); info != "" {
+		res = append(res, info)
+	}
+	res = append(res, strings.TrimSuffix(d.Content(), "\n"))
+	if docinfo := d.Docinfo("footer", ""); docinfo != "" {
+		res = append(res, docinfo)
+	}
+	res = append(res, fmt.Sprintf("</%s>", rootTag))
+	return strings.Join(res, "\n")
+}
+
+/* The info element of the document: title, date, authors, revision
+and head docinfo */
+func (c *docbook5Converter) info(d *Document) string {
+	res := []string{}
+	if d.HasHeader() && !d.HasAttr("notitle", nil, false) {
+		res = append(res, fmt.Sprintf("<title>%s</title>", d.Header().Title()))
+	}
+	date := attrString(d.abstractNode, "revdate")
+	if date == "" {
+		date = attrString(d.abstractNode, "docdate")
+	}
+	if date != "" {
+		res = append(res, fmt.Sprintf("<date>%s</date>", date))
+	}
+	if d.HasAttr("author", nil, false) {
+		authorCount := 1
+		fmt.Sscan(attrString(d.abstractNode, "authorcount"), &authorCount)
+		authors := []string{}
+		for i := 1; i <= authorCount; i++ {
+			suffix := ""
+			if authorCount > 1 {
+				suffix = fmt.Sprintf("_%d", i)
+			}
+			authors = append(authors, c.author(d, suffix))
+		}
+		if authorCount > 1 {
+			res = append(res, "<authorgroup>", strings.Join(authors, "\n"), "</authorgroup>")
+		} else {
+			res = append(res, authors...)
+			if initials := attrString(d.abstractNode, "authorinitials"); initials != "" {
+				res = append(res, fmt.Sprintf("<authorinitials>%s</authorinitials>", initials))
+			}
+		}
+	}
+	if revnumber, revremark := attrString(d.abstractNode, "revnumber"), attrString(d.abstractNode, "revremark"); revnumber != "" || revremark != "" {
+		res = append(res, "<revhistory>", "<revision>")
+		if revnumber != "" {
+			res = append(res, fmt.Sprintf("<revnumber>%s</revnumber>", revnumber))
+		}
+		res = append(res, fmt.Sprintf("<date>%s</date>", date))
+		if initials := attrString(d.abstractNode, "authorinitials"); initials != "" {
+			res = append(res, fmt.Sprintf("<authorinitials>%s</authorinitials>", initials))
+		}
+		if revremark != "" {
+			res = append(res, fmt.Sprintf("<revremark>%s</revremark>", revremark))
+		}
+		res = append(res, "</revision>", "</revhistory>")
+	}
+	if docinfo := d.Docinfo("head", ""); docinfo != "" {
+		res = append(res, docinfo)
+	}
+	if len(res) == 0 {
+		return ""
+	}
+	return "<info>\n" + strings.Join(res, "\n") + "\n</info>"
+}
+
+// An author element, from the author attributes ending with suffix ('', '_2', ...)
+func (c *docbook5Converter) author(d *Document, suffix string) string {
+	res := []string{"<author>", "<personname>"}
+	for _, name := range [][2]string{{"firstname", "firstname"}, {"middlename", "othername"}, {"lastname", "surname"}} {
+		if value := attrString(d.abstractNode, name[0]+suffix); value != "" {
+			res = append(res, fmt.Sprintf("<%s>%s</%s>", name[1], value, name[1]))
+		}
+	}
+	res = append(res, "</personname>")
+	if email := attrString(d.abstractNode, "email"+suffix); email != "" {
+		res = append(res, fmt.Sprintf("<email>%s</email>", email))
+	}
+	return strings.Join(append(res, "</author>"), "\n")
+}
+
+func (c *docbook5Converter) section(s *Section) string {
+	tag := "section"
+	if s.IsSpecial() && s.SectName() != "" {
+		tag = s.SectName()
+	} else if s.Document() != nil && s.Document().DocType() == "book" && s.Level() <= 1 {
+		tag = "chapter"
+		if s.Level() == 0 {
+			tag = "part"
+		}
+	}
+	return fmt.Sprintf("<%s%s>\n<title>%s</title>\n%s</%s>", tag, commonAttributes(s.abstractNode), s.Title(), s.Content(), tag)
+}
+
+func (c *docbook5Converter) paragraph(b *Block) string {
+	if b.HasTitle() {
+		return fmt.Sprintf("<formalpara%s>\n<title>%s</title>\n<para>%s</para>\n</formalpara>", commonAttributes(b.abstractNode), b.Title(), b.Content())
+	}
+	return fmt.Sprintf("<simpara%s>%s</simpara>", commonAttributes(b.abstractNode), b.Content())
+}
+
+func (c *docbook5Converter) admonition(b *Block) string {
+	name := attrString(b.abstractNode, "name")
+	return fmt.Sprintf("<%s%s>\n%s%s\n</%s>", name, commonAttributes(b.abstractNode), titleTag(b.abstractBlock), resolveContent(b), name)
+}
+
+// A verbatim element, wrapped in a formalpara if the block has a title
+func formalVerbatim(b *Block, verbatim string) string {
+	if !b.HasTitle() {
+		return verbatim
+	}
+	return fmt.Sprintf("<formalpara%s>\n<title>%s</title>\n<para>\n%s\n</para>\n</formalpara>", commonAttributes(b.abstractNode), b.Title(), verbatim)
+}
+
+func (c *docbook5Converter) listing(b *Block) string {
+	attributes := ""
+	if !b.HasTitle() {
+		attributes = commonAttributes(b.abstractNode)
+	}
+	if b.Style() != "source" {
+		return formalVerbatim(b, fmt.Sprintf("<screen%s>%s</screen>", attributes, b.Content()))
+	}
+	if language := attrString(b.abstractNode, "language"); language != "" {
+		attributes = attributes + fmt.Sprintf(` language="%s"`, escapeAttribute(language))
+	}
+	numbering := "unnumbered"
+	if b.HasAttr("linenums", nil, false) || b.HasOption("linenums") {
+		numbering = "numbered"
+	}
+	return formalVerbatim(b, fmt.Sprintf(`<programlisting%s linenumbering="%s">%s</programlisting>`, attributes, numbering, b.Content()))
+}
+
+func (c *docbook5Converter) literal(b *Block) string {
+	attributes := ""
+	if !b.HasTitle() {
+		attributes = commonAttributes(b.abstractNode)
+	}
+	return formalVerbatim(b, fmt.Sprintf(`<literallayout%s class="monospaced">%s</literallayout>`, attributes, b.Content()))
+}
+
+func (c *docbook5Converter) example(b *Block) string {
+	tag := "informalexample"
+	if b.HasTitle() {
+		tag = "example"
+	}
+	return fmt.Sprintf("<%s%s>\n%s%s\n</%s>", tag, commonAttributes(b.abstractNode), titleTag(b.abstractBlock), resolveContent(b), tag)
+}
+
+func (c *docbook5Converter) sidebar(b *Block) string {
+	return fmt.Sprintf("<sidebar%s>\n%s%s\n</sidebar>", commonAttributes(b.abstractNode), titleTag(b.abstractBlock), resolveContent(b))
+}
+
+func (c *docbook5Converter) quote(b *Block) string {
+	return fmt.Sprintf("<blockquote%s>\n%s%s%s\n</blockquote>", commonAttributes(b.abstractNode), titleTag(b.abstractBlock), c.attribution(b), resolveContent(b))
+}
+
+func (c *docbook5Converter) verse(b *Block) string {
+	return fmt.Sprintf("<blockquote%s>\n%s%s<literallayout>%s</literallayout>\n</blockquote>", commonAttributes(b.abstractNode), titleTag(b.abstractBlock), c.attribution(b), b.Content())
+}
+
+// The attribution (author and citation title) of a quote or of a verse
+func (c *docbook5Converter) attribution(b *Block) string {
+	author, citetitle := attrString(b.abstractNode, "attribution"), attrString(b.abstractNode, "citetitle")
+	if author == "" && citetitle == "" {
+		return ""
+	}
+	res := "<attribution>\n"
+	if author != "" {
+		res = res + escapeAttribute(author) + "\n"
+	}
+	if citetitle != "" {
+		res = res + "<citetitle>" + escapeAttribute(citetitle) + "</citetitle>\n"
+	}
+	return res + "</attribution>\n"
+}
+
+func (c *docbook5Converter) open(b *Block) string {
+	if b.Style() == "abstract" {
+		return fmt.Sprintf("<abstract>\n%s%s\n</abstract>", titleTag(b.abstractBlock), resolveContent(b))
+	}
+	if b.Style() == "partintro" {
+		return fmt.Sprintf("<partintro%s>\n%s%s\n</partintro>", commonAttributes(b.abstractNode), titleTag(b.abstractBlock), resolveContent(b))
+	}
+	if b.HasTitle() {
+		return fmt.Sprintf("<formalpara%s>\n<title>%s</title>\n<para>%s</para>\n</formalpara>", commonAttributes(b.abstractNode), b.Title(), resolveContent(b))
+	}
+	return resolveContent(b)
+}
+
+// The imagedata and textobject elements of an image
+func (c *docbook5Converter) imageObject(an *abstractNode, src string) string {
+	attrs := ""
+	for _, name := range [][2]string{{"width", "contentwidth"}, {"height", "contentdepth"}, {"scale", "scale"}, {"align", "align"}} {
+		if value := attrString(an, name[0]); value != "" {
+			attrs = attrs + fmt.Sprintf(` %s="%s"`, name[1], escapeAttribute(value))
+		}
+	}
+	return fmt.Sprintf("<imageobject>\n<imagedata fileref=\"%s\"%s/>\n</imageobject>\n<textobject><phrase>%s</phrase></textobject>", escapeAttribute(src), attrs, escapeAttribute(attrString(an, "alt")))
+}
+
+func (c *docbook5Converter) image(b *Block) string {
+	tag := "informalfigure"
+	if b.HasTitle() {
+		tag = "figure"
+	}
+	return fmt.Sprintf("<%s%s>\n%s<mediaobject>\n%s\n</mediaobject>\n</%s>", tag, commonAttributes(b.abstractNode), titleTag(b.abstractBlock),
+		c.imageObject(b.abstractNode, b.ImageUri(attrString(b.abstractNode, "target"), "")), tag)
+}
+
+func (c *docbook5Converter) inlineAnchor(i *Inline) string {
+	switch i.Type() {
+	case "xref":
+		refid := attrString(i.abstractNode, "refid")
+		if path := attrString(i.abstractNode, "path"); path != "" {
+			text := i.Text()
+			if text == "" {
+				text = path
+			}
+			return fmt.Sprintf(`<link xl:href="%s">%s</link>`, escapeAttribute(i.Target()), text)
+		}
+		if i.Text() == "" {
+			return fmt.Sprintf(`<xref linkend="%s"/>`, escapeAttribute(refid))
+		}
+		return fmt.Sprintf(`<link linkend="%s">%s</link>`, escapeAttribute(refid), i.Text())
+	case "ref":
+		return fmt.Sprintf(`<anchor xml:id="%s" xreflabel="%s"/>`, escapeAttribute(i.Target()), escapeAttribute(i.Text()))
+	case "bibref":
+		return fmt.Sprintf(`<anchor xml:id="%s" xreflabel="[%s]"/>[%s]`, escapeAttribute(i.Target()), escapeAttribute(i.Target()), i.Target())
+	case "link":
+		return fmt.Sprintf(`<link xl:href="%s">%s</link>`, escapeAttribute(i.Target()), i.Text())
+	}
+	return ""
+}
+
+func (c *docbook5Converter) inlineFootnote(i *Inline) string {
+	if i.Type() == "xref" {
+		return fmt.Sprintf(`<footnoteref linkend="_footnote_%s"/>`, i.Target())
+	}
+	id := ""
+	if i.Id() != "" {
+		id = fmt.Sprintf(` xml:id="_footnote_%s"`, i.Id())
+	}
+	return fmt.Sprintf("<footnote%s><simpara>%s</simpara></footnote>", id, i.Text())
+}
+
+func (c *docbook5Converter) inlineImage(i *Inline) string {
+	src := ""
+	if i.Type() == "icon" {
+		src = i.IconUri(i.Target())
+	} else {
+		src = i.ImageUri(i.Target(), "")
+	}
+	return fmt.Sprintf("<inlinemediaobject>\n%s\n</inlinemediaobject>", c.imageObject(i.abstractNode, src))
+}
+
+func (c *docbook5Converter) inlineIndexterm(i *Inline) string {
+	if i.Type() == "visible" {
+		return fmt.Sprintf("<indexterm><primary>%s</primary></indexterm>%s", i.Text(), i.Text())
+	}
+	terms, _ := i.Attr("terms", nil, false).([]string)
+	res := ""
+	for index, tag := range []string{"primary", "secondary", "tertiary"} {
+		if index < len(terms) {
+			res = res + fmt.Sprintf("<%s>%s</%s>", tag, terms[index], tag)
+		}
+	}
+	return "<indexterm>" + res + "</indexterm>"
+}
+
+func (c *docbook5Converter) inlineKbd(i *Inline) string {
+	keys, _ := i.Attr("keys", nil, false).([]string)
+	if len(keys) == 1 {
+		return "<keycap>" + keys[0] + "</keycap>"
+	}
+	res := ""
+	for _, key := range keys {
+		res = res + "<keycap>" + key + "</keycap>"
+	}
+	return "<keycombo>" + res + "</keycombo>"
+}
+
+func (c *docbook5Converter) inlineMenu(i *Inline) string {
+	menu := attrString(i.abstractNode, "menu")
+	submenus, _ := i.Attr("submenu", nil, false).([]string)
+	menuitem := attrString(i.abstractNode, "menuitem")
+	if len(submenus) == 0 && menuitem == "" {
+		return "<guimenu>" + menu + "</guimenu>"
+	}
+	res := "<menuchoice><guimenu>" + menu + "</guimenu> "
+	for _, submenu := range submenus {
+		res = res + "<guisubmenu>" + submenu + "</guisubmenu> "
+	}
+	return res + "<guimenuitem>" + menuitem + "</guimenuitem></menuchoice>"
+}
+
+/* The opening and closing marks of each type of quoted text */
+var docbook5QuoteTags = map[string][2]string{
+	"emphasis":    {"<emphasis>", "</emphasis>"},
+	"strong":      {`<emphasis role="strong">`, "</emphasis>"},
+	"monospaced":  {"<literal>", "</literal>"},
+	"superscript": {"<superscript>", "</superscript>"},
+	"subscript":   {"<subscript>", "</subscript>"},
+	"mark":        {`<emphasis role="marked">`, "</emphasis>"},
+	"double":      {"<quote>", "</quote>"},
+	"single":      {"<quote>", "</quote>"},
+	"asciimath":   {"<inlineequation><mathphrase><![CDATA[", "]]></mathphrase></inlineequation>"},
+	"latexmath":   {"<inlineequation><mathphrase><![CDATA[", "]]></mathphrase></inlineequation>"},
+}
+
+func (c *docbook5Converter) inlineQuoted(i *Inline) string {
+	tags := docbook5QuoteTags[strings.ToLower(i.Type())]
+	res := tags[0] + i.Text() + tags[1]
+	if role, _ := i.Role().(string); role != "" {
+		res = fmt.Sprintf(`<phrase role="%s">%s</phrase>`, escapeAttribute(role), res)
+	}
+	if i.Id() != "" {
+		res = fmt.Sprintf(`<anchor xml:id="%s" xreflabel="%s"/>%s`, escapeAttribute(i.Id()), escapeAttribute(i.Text()), res)
+	}
+	return res
+}
+
+// The text of a list item as a simpara, followed by its blocks
+func listItemBody(item *ListItem, textMarker string, res []string) []string {
+	if item.HasText() || textMarker != "" {
+		res = append(res, fmt.Sprintf("<simpara>%s%s</simpara>", textMarker, item.Text()))
+	}
+	if item.HasBlocks() {
+		res = append(res, itemContent(item))
+	}
+	return res
+}
+
+func (c *docbook5Converter) ulist(l *List) string {
+	checklist := l.HasOption("checklist")
+	mark := l.Style()
+	if checklist {
+		mark = "none"
+	}
+	markAttribute := ""
+	if mark != "" {
+		markAttribute = fmt.Sprintf(` mark="%s"`, escapeAttribute(mark))
+	}
+	res := []string{fmt.Sprintf("<itemizedlist%s%s>", commonAttributes(l.abstractNode), markAttribute)}
+	res = append(res, strings.TrimSuffix(titleTag(l.abstractBlock), "\n"))
+	for _, item := range l.Items() {
+		textMarker := ""
+		if checklist && item.HasAttr("checkbox", nil, false) {
+			textMarker = "&#10063; "
+			if item.HasAttr("checked", nil, false) {
+				textMarker = "&#10003; "
+			}
+		}
+		res = append(res, fmt.Sprintf("<listitem%s>", commonAttributes(item.abstractNode)))
+		res = append(listItemBody(item, textMarker, res), "</listitem>")
+	}
+	res = append(res, "</itemizedlist>")
+	return joinLines(res)
+}
+
+func (c *docbook5Converter) olist(l *List) string {
+	attributes := ""
+	if l.Style() != "" {
+		attributes = fmt.Sprintf(` numeration="%s"`, escapeAttribute(l.Style()))
+	}
+	if start := attrString(l.abstractNode, "start"); start != "" {
+		attributes = attributes + fmt.Sprintf(` startingnumber="%s"`, escapeAttribute(start))
+	}
+	res := []string{fmt.Sprintf("<orderedlist%s%s>", commonAttributes(l.abstractNode), attributes)}
+	res = append(res, strings.TrimSuffix(titleTag(l.abstractBlock), "\n"))
+	for _, item := range l.Items() {
+		res = append(res, fmt.Sprintf("<listitem%s>", commonAttributes(item.abstractNode)))
+		res = append(listItemBody(item, "", res), "</listitem>")
+	}
+	res = append(res, "</orderedlist>")
+	return joinLines(res)
+}
+
+/* The DocBook elements of a description list, by style:
+the list, an entry, the label of the terms, a term and a description */
+var dlistTags = map[string][]string{
+	"labeled":  {"variablelist", "varlistentry", "", "term", "listitem"},
+	"qanda":    {"qandaset", "qandaentry", "question", "simpara", "answer"},
+	"glossary": {"", "glossentry", "", "glossterm", "glossdef"},
+}
+
+func (c *docbook5Converter) dlist(l *List) string {
+	res := []string{}
+	if l.Style() == "horizontal" {
+		tag := "informaltable"
+		if l.HasTitle() {
+			tag = "table"
+		}
+		res = append(res, fmt.Sprintf(`<%s%s tabstyle="horizontal" frame="none" colsep="0" rowsep="0">`, tag, commonAttributes(l.abstractNode)),
+			strings.TrimSuffix(titleTag(l.abstractBlock), "\n"), `<tgroup cols="2">`,
+			fmt.Sprintf(`<colspec colwidth="%s*"/>`, escapeAttribute(l.Attr("labelwidth", "15", false))),
+			fmt.Sprintf(`<colspec colwidth="%s*"/>`, escapeAttribute(l.Attr("itemwidth", "85", false))),
+			`<tbody valign="top">`)
+		for _, item := range l.Items() {
+			res = append(res, "<row>", "<entry>")
+			for _, term := range item.Terms() {
+				res = append(res, fmt.Sprintf("<simpara>%s</simpara>", term.Text()))
+			}
+			res = append(res, "</entry>", "<entry>")
+			res = append(listItemBody(item, "", res), "</entry>", "</row>")
+		}
+		res = append(res, "</tbody>", "</tgroup>", fmt.Sprintf("</%s>", tag))
+		return joinLines(res)
+	}
+	tags, ok := dlistTags[l.Style()]
+	if !ok {
+		tags = dlistTags["labeled"]
+	}
+	listTag, entryTag, labelTag, termTag, itemTag := tags[0], tags[1], tags[2], tags[3], tags[4]
+	if listTag != "" {
+		res = append(res, fmt.Sprintf("<%s%s>", listTag, commonAttributes(l.abstractNode)), strings.TrimSuffix(titleTag(l.abstractBlock), "\n"))
+	}
+	for _, item := range l.Items() {
+		res = append(res, fmt.Sprintf("<%s>", entryTag))
+		if labelTag != "" {
+			res = append(res, fmt.Sprintf("<%s>", labelTag))
+		}
+		for _, term := range item.Terms() {
+			res = append(res, fmt.Sprintf("<%s>%s</%s>", termTag, term.Text(), termTag))
+		}
+		if labelTag != "" {
+			res = append(res, fmt.Sprintf("</%s>", labelTag))
+		}
+		res = append(res, fmt.Sprintf("<%s>", itemTag))
+		res = append(listItemBody(item, "", res), fmt.Sprintf("</%s>", itemTag), fmt.Sprintf("</%s>", entryTag))
+	}
+	if listTag != "" {
+		res = append(res, fmt.Sprintf("</%s>", listTag))
+	}
+	return joinLines(res)
+}
+
+func (c *docbook5Converter) colist(l *List) string {
+	res := []string{fmt.Sprintf("<calloutlist%s>", commonAttributes(l.abstractNode))}
+	res = append(res, strings.TrimSuffix(titleTag(l.abstractBlock), "\n"))
+	for _, item := range l.Items() {
+		res = append(res, fmt.Sprintf(`<callout arearefs="%s">`, escapeAttribute(attrString(item.abstractNode, "coids"))), fmt.Sprintf("<para>%s</para>", item.Text()))
+		if item.HasBlocks() {
+			res = append(res, itemContent(item))
+		}
+		res = append(res, "</callout>")
+	}
+	res = append(res, "</calloutlist>")
+	return joinLines(res)
+}
+
+func (c *docbook5Converter) table(t *Table) string {
+	tag := "informaltable"
+	if t.HasTitle() {
+		tag = "table"
+	}
+	pgwide := ""
+	if t.HasOption("pgwide") {
+		pgwide = ` pgwide="1"`
+	}
+	grid := attrString(t.abstractNode, "grid")
+	rowsep, colsep := 1, 1
+	if grid == "none" || grid == "cols" {
+		rowsep = 0
+	}
+	if grid == "none" || grid == "rows" {
+		colsep = 0
+	}
+	res := []string{fmt.Sprintf(`<%s%s%s frame="%s" rowsep="%d" colsep="%d">`, tag, commonAttributes(t.abstractNode), pgwide, escapeAttribute(t.Attr("frame", "all", false)), rowsep, colsep)}
+	if t.HasOption("unbreakable") {
+		res = append(res, `<?dbfo keep-together="always"?>`)
+	} else if t.HasOption("breakable") {
+		res = append(res, `<?dbfo keep-together="auto"?>`)
+	}
+	if tag == "table" {
+		res = append(res, fmt.Sprintf("<title>%s</title>", t.Title()))
+	}
+	res = append(res, fmt.Sprintf(`<tgroup cols="%v">`, t.Attr("colcount", nil, false)))
+	for _, column := range t.Columns() {
+		res = append(res, fmt.Sprintf(`<colspec colname="col_%v" colwidth="%s*"/>`, column.Attr("colnumber", nil, false), attrString(column.abstractNode, "colpcwidth")))
+	}
+	bgcolor := ""
+	if t.Document() != nil && t.Document().HasAttr("cellbgcolor", nil, false) {
+		bgcolor = fmt.Sprintf(`<?dbfo bgcolor="%v"?>`, escapeAttribute(t.Document().Attr("cellbgcolor", nil, false)))
+	}
+	for _, section := range tableSections {
+		rows := tableSectionRows(t, section)
+		if len(rows) == 0 {
+			continue
+		}
+		res = append(res, fmt.Sprintf("<t%s>", section))
+		for _, row := range rows {
+			res = append(res, "<row>")
+			for _, cell := range row {
+				res = append(res, c.tableEntry(cell, section == "head", bgcolor))
+			}
+			res = append(res, "</row>")
+		}
+		res = append(res, fmt.Sprintf("</t%s>", section))
+	}
+	res = append(res, "</tgroup>", fmt.Sprintf("</%s>", tag))
+	if len(t.Rows().Body()) == 0 {
+		t.log(severity.WARN, "tables must have at least one body row")
+	}
+	return strings.Join(res, "\n")
+}
+
+// A cell of a table, as an entry element (without whitespace as direct descendant)
+func (c *docbook5Converter) tableEntry(cell *TableCell, head bool, bgcolor string) string {
+	attrs := ""
+	if halign := attrString(cell.abstractNode, "halign"); halign != "" {
+		attrs = fmt.Sprintf(` align="%s"`, escapeAttribute(halign))
+	}
+	if valign := attrString(cell.abstractNode, "valign"); valign != "" {
+		attrs = attrs + fmt.Sprintf(` valign="%s"`, escapeAttribute(valign))
+	}
+	if cell.Colspan() > 0 {
+		colnumber := cell.Column().Attr("colnumber", 1, false).(int)
+		attrs = attrs + fmt.Sprintf(` namest="col_%d" nameend="col_%d"`, colnumber, colnumber+cell.Colspan()-1)
+	}
+	if cell.Rowspan() > 0 {
+		attrs = attrs + fmt.Sprintf(` morerows="%d"`, cell.Rowspan()-1)
+	}
+	content := ""
+	if head {
+		content = cell.Text()
+	} else {
+		switch cell.Style() {
+		case "asciidoc":
+			content = strings.Join(cell.Content(), "")
+		case "verse":
+			content = fmt.Sprintf("<literallayout>%s</literallayout>", cell.Text())
+		case "literal":
+			content = fmt.Sprintf("<literallayout class=\"monospaced\">%s</literallayout>", cell.Text())
+		case "header":
+			for _, paragraph := range cell.Content() {
+				content = content + fmt.Sprintf("<simpara><emphasis role=\"strong\">%s</emphasis></simpara>", paragraph)
+			}
+		default:
+			for _, paragraph := range cell.Content() {
+				content = content + fmt.Sprintf("<simpara>%s</simpara>", paragraph)
+			}
+		}
+	}
+	return fmt.Sprintf("<entry%s>%s%s</entry>", attrs, content, bgcolor)
+}
@@ -0,0 +1,207 @@
+package asciidocgo
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/VonC/asciidocgo/consts/context"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDocbook5Converter(t *testing.T) {
+
+	Convey("A docbook5Converter converts only known nodes and transforms", t, func() {
+		c := &docbook5Converter{}
+		_, ok := c.Convert("not a node", "section")
+		So(ok, ShouldBeFalse)
+		doc := NewDocument([]string{}, map[string]string{"backend": "docbook5"})
+		_, ok = c.Convert(doc, "block_paragraph")
+		So(ok, ShouldBeFalse)
+		res, ok := c.Convert(newBlock(doc.abstractBlock, context.Paragraph, []string{"a < b"}), "block_paragraph")
+		So(ok, ShouldBeTrue)
+		So(res, ShouldEqual, "<simpara>a &lt; b</simpara>")
+		res, ok = c.Convert(newBlock(doc.abstractBlock, context.Toc, nil), "block_toc")
+		So(ok, ShouldBeTrue)
+		So(res, ShouldEqual, "")
+	})
+
+	Convey("A docbook5Converter converts a full document", t, func() {
+		lines := []string{"= Doc Title", "Doc Writer <doc@example.com>", "v1.0, 2013-01-01: Remark", "",
+			"[[top]]", "Some *strong* and _emphasis_, <<top>>, <<_first,First>>.", "", "== First", "", "content"}
+		doc, _ := NewDocument(lines, map[string]string{"header_footer": "true", "backend": "docbook"}).Parse()
+		res, err := doc.Render()
+		So(err, ShouldBeNil)
+		So(res, ShouldStartWith, `<?xml version="1.0" encoding="UTF-8"?>
+<article xmlns="http://docbook.org/ns/docbook" xmlns:xl="http://www.w3.org/1999/xlink" version="5.0" xml:lang="en">
+<info>
+<title>Doc Title</title>
+<date>2013-01-01</date>
+<author>
+<personname>
+<firstname>Doc</firstname>
+<surname>Writer</surname>
+</personname>
+<email>doc@example.com</email>
+</author>
+<authorinitials>DW</authorinitials>
+<revhistory>
+<revision>
+<revnumber>1.0</revnumber>
+<date>2013-01-01</date>
+<authorinitials>DW</authorinitials>
+<revremark>Remark</revremark>
+</revision>
+</revhistory>
+</info>`)
+		So(res, ShouldContainSubstring, `<simpara xml:id="top">Some <emphasis role="strong">strong</emphasis> and <emphasis>emphasis</emphasis>, <xref linkend="top"/>, <link linkend="_first">First</link>.</simpara>`)
+		So(res, ShouldEndWith, "<section xml:id=\"_first\">\n<title>First</title>\n<simpara>content</simpara>\n</section>\n</article>")
+		Convey("A document includes its docinfo files", func() {
+			fsys := fstest.MapFS{
+				"docs/docinfo.xml":        &fstest.MapFile{Data: []byte("<subtitle>Sub</subtitle>")},
+				"docs/docinfo-footer.xml": &fstest.MapFile{Data: []byte("<colophon><simpara>End</simpara></colophon>")},
+			}
+			lines := []string{"= Doc", ":docinfo: shared", "", "text"}
+			doc, _ := NewDocument(lines, map[string]string{"header_footer": "true", "backend": "docbook5", "safe": "safe", "base_dir": "docs"}).UseFS(fsys).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, "<info>\n<title>Doc</title>\n<subtitle>Sub</subtitle>\n</info>")
+			So(res, ShouldEndWith, "<simpara>text</simpara>\n<colophon><simpara>End</simpara></colophon>\n</article>")
+		})
+		Convey("An embedded document has only its content", func() {
+			doc.options["header_footer"] = "false"
+			res, _ := doc.Render()
+			So(res, ShouldStartWith, `<simpara xml:id="top">`)
+		})
+	})
+
+	Convey("A docbook5Converter converts a book into parts and chapters", t, func() {
+		lines := []string{"= Book", ":doctype: book", "", "= Part", "", "== Chapter", "", "text", "", "[appendix]", "== Extra", "", "more"}
+		doc, _ := NewDocument(lines, map[string]string{"header_footer": "true", "backend": "docbook5"}).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, "<book xmlns=")
+		So(res, ShouldContainSubstring, "<part xml:id=\"_part\">\n<title>Part</title>\n<chapter xml:id=\"_chapter\">")
+		So(res, ShouldContainSubstring, "<appendix xml:id=\"_extra\">\n<title>Extra</title>")
+		So(res, ShouldEndWith, "</book>")
+	})
+
+	Convey("A docbook5Converter converts blocks", t, func() {
+		lines := []string{"NOTE: a note", "", ".Fig", "image::tiger.png[Tiger, 200]", "",
+			"[source, go]", "----", "x < y", "----", "", ".Out", "----", "out", "----", "",
+			"[quote, Someone, Book]", "____", "quoted", "____", "", "====", "example", "====", "",
+			"****", "side", "****", "", "[abstract]", "--", "summary", "--", "", "  literal"}
+		doc, _ := NewDocument(lines, map[string]string{"backend": "docbook5"}).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, "<note>\n<simpara>a note</simpara>\n</note>")
+		So(res, ShouldContainSubstring, "<figure>\n<title>Fig</title>\n<mediaobject>\n<imageobject>\n<imagedata fileref=\"tiger.png\" contentwidth=\"200\"/>\n</imageobject>\n<textobject><phrase>Tiger</phrase></textobject>\n</mediaobject>\n</figure>")
+		So(res, ShouldContainSubstring, `<programlisting language="go" linenumbering="unnumbered">x &lt; y</programlisting>`)
+		So(res, ShouldContainSubstring, "<formalpara>\n<title>Out</title>\n<para>\n<screen>out</screen>\n</para>\n</formalpara>")
+		So(res, ShouldContainSubstring, "<blockquote>\n<attribution>\nSomeone\n<citetitle>Book</citetitle>\n</attribution>\n<simpara>quoted</simpara>\n</blockquote>")
+		Convey("Including verses, and quote or verse paragraphs", func() {
+			lines := []string{"[verse, Poet, Poem]", "____", "The fog comes", "  on little cat feet.", "____", "",
+				"[quote, Someone]", "A quoted paragraph.", "", "[verse]", "A verse", "  paragraph."}
+			doc, _ := NewDocument(lines, map[string]string{"backend": "docbook5"}).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldEqual, "<blockquote>\n<attribution>\nPoet\n<citetitle>Poem</citetitle>\n</attribution>\n<literallayout>The fog comes\n  on little cat feet.</literallayout>\n</blockquote>\n"+
+				"<blockquote>\n<attribution>\nSomeone\n</attribution>\n<simpara>A quoted paragraph.</simpara>\n</blockquote>\n"+
+				"<blockquote>\n<literallayout>A verse\n  paragraph.</literallayout>\n</blockquote>\n")
+		})
+		So(res, ShouldContainSubstring, "<informalexample>\n<simpara>example</simpara>\n</informalexample>")
+		So(res, ShouldContainSubstring, "<sidebar>\n<simpara>side</simpara>\n</sidebar>")
+		So(res, ShouldContainSubstring, "<abstract>\n<simpara>summary</simpara>\n</abstract>")
+		So(res, ShouldContainSubstring, `<literallayout class="monospaced">literal</literallayout>`)
+	})
+
+	Convey("A docbook5Converter converts inline elements", t, func() {
+		lines := []string{":experimental:", "", "kbd:[Ctrl+T] kbd:[F1] btn:[OK] menu:File[Save] image:tiger.png[Tiger] ((visible)) indexterm:[Tigers,Big cats] ^sup^ ~sub~ http://example.com[site]", "", "menu:Help[]"}
+		doc, _ := NewDocument(lines, map[string]string{"backend": "docbook5"}).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, "<keycombo><keycap>Ctrl</keycap><keycap>T</keycap></keycombo> <keycap>F1</keycap>")
+		So(res, ShouldContainSubstring, "<guibutton>OK</guibutton>")
+		So(res, ShouldContainSubstring, "<menuchoice><guimenu>File</guimenu> <guimenuitem>Save</guimenuitem></menuchoice>")
+		So(res, ShouldContainSubstring, "<guimenu>Help</guimenu>")
+		So(res, ShouldContainSubstring, "<inlinemediaobject>\n<imageobject>\n<imagedata fileref=\"tiger.png\"/>\n</imageobject>\n<textobject><phrase>Tiger</phrase></textobject>\n</inlinemediaobject>")
+		So(res, ShouldContainSubstring, "<indexterm><primary>visible</primary></indexterm>visible <indexterm><primary>Tigers</primary><secondary>Big cats</secondary></indexterm>")
+		So(res, ShouldContainSubstring, "<superscript>sup</superscript> <subscript>sub</subscript>")
+		So(res, ShouldContainSubstring, `<link xl:href="http://example.com">site</link>`)
+	})
+
+	Convey("A docbook5Converter escapes the values of the attributes", t, func() {
+		lines := []string{"= Doc Title", "", `[[top,a "b" <c>]]`, `[role="r\" onclick=\"evil()"]`, "para", "",
+			`image::a.png[alt="x\" onerror=\"alert(2)"]`, "", `link:http://a.org?q="><script>[x] [.r"o]#phrase#`, "",
+			"[quote, AT&T, <Book>]", "____", "quoted", "____"}
+		doc, _ := NewDocument(lines, map[string]string{"header_footer": "true", "backend": "docbook5"}).Parse()
+		res, err := doc.Render()
+		So(err, ShouldBeNil)
+		So(res, ShouldContainSubstring, `<simpara xml:id="top" role="r&quot; onclick=&quot;evil()" xreflabel="a &quot;b&quot; &lt;c&gt;">para</simpara>`)
+		So(res, ShouldContainSubstring, `<textobject><phrase>x&quot; onerror=&quot;alert(2)</phrase></textobject>`)
+		So(res, ShouldContainSubstring, "<attribution>\nAT&amp;T\n<citetitle>&lt;Book&gt;</citetitle>\n</attribution>")
+		Convey("Its output is well-formed XML", func() {
+			decoder := xml.NewDecoder(strings.NewReader(res))
+			var err error
+			for err == nil {
+				_, err = decoder.Token()
+			}
+			So(err, ShouldEqual, io.EOF)
+		})
+	})
+
+	Convey("A docbook5Converter converts footnotes in place", t, func() {
+		lines := []string{"A footnote:[First *note*.] and footnoteref:[disc,Disclaimer.] again footnoteref:[disc]."}
+		doc, _ := NewDocument(lines, map[string]string{"backend": "docbook5"}).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldEqual, `<simpara>A <footnote><simpara>First <emphasis role="strong">note</emphasis>.</simpara></footnote>`+
+			` and <footnote xml:id="_footnote_disc"><simpara>Disclaimer.</simpara></footnote> again <footnoteref linkend="_footnote_disc"/>.</simpara>`+"\n")
+		So(len(doc.Footnotes()), ShouldEqual, 2)
+	})
+
+	Convey("A docbook5Converter converts lists", t, func() {
+		lines := []string{"* [x] a", "** nested", "", "//", "[start=2]", ". one", "", "//", "CPU:: brain", "", "//", "[qanda]", "Q?:: A", "",
+			"//", "[horizontal]", "T:: d", "", "<1> co"}
+		doc, _ := NewDocument(lines, map[string]string{"backend": "docbook5"}).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, "<itemizedlist mark=\"none\">\n<listitem>\n<simpara>&#10003; a</simpara>\n<itemizedlist>\n<listitem>\n<simpara>nested</simpara>\n</listitem>\n</itemizedlist>\n</listitem>\n</itemizedlist>")
+		So(res, ShouldContainSubstring, "<orderedlist numeration=\"arabic\" startingnumber=\"2\">\n<listitem>\n<simpara>one</simpara>\n</listitem>\n</orderedlist>")
+		So(res, ShouldContainSubstring, "<variablelist>\n<varlistentry>\n<term>CPU</term>\n<listitem>\n<simpara>brain</simpara>\n</listitem>\n</varlistentry>\n</variablelist>")
+		So(res, ShouldContainSubstring, "<qandaset>\n<qandaentry>\n<question>\n<simpara>Q?</simpara>\n</question>\n<answer>\n<simpara>A</simpara>\n</answer>\n</qandaentry>\n</qandaset>")
+		So(res, ShouldContainSubstring, "<informaltable tabstyle=\"horizontal\" frame=\"none\" colsep=\"0\" rowsep=\"0\">\n<tgroup cols=\"2\">\n<colspec colwidth=\"15*\"/>")
+		So(res, ShouldContainSubstring, "<calloutlist>\n<callout arearefs=\"\">\n<para>co</para>\n</callout>\n</calloutlist>")
+	})
+
+	Convey("A docbook5Converter converts callouts and hard line breaks", t, func() {
+		lines := []string{"----", "a <1>", "b <!--2-->", "----", "<1> one", "<2> two", "", "line +", "break"}
+		doc, _ := NewDocument(lines, map[string]string{"backend": "docbook5"}).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, "<screen>a <co xml:id=\"CO1-1\"/>\nb <co xml:id=\"CO1-2\"/></screen>")
+		So(res, ShouldContainSubstring, "<callout arearefs=\"CO1-2\">\n<para>two</para>")
+		So(res, ShouldContainSubstring, "<simpara>line<?asciidoc-br?>\nbreak</simpara>")
+		res, _ = doc.Render()
+		So(res, ShouldContainSubstring, "<screen>a <co xml:id=\"CO1-1\"/>")
+	})
+
+	Convey("A docbook5Converter converts tables", t, func() {
+		lines := []string{".Sizes", `[cols="1,1a",options="header,footer",grid=cols]`, "|===", "|Name |Doc", "2+|span",
+			".2+^.>h|rowspan |* item", "l|  lit", "v|verse| f", "|===", "", "[%unbreakable]", "|===", "|a", "|==="}
+		doc, _ := NewDocument(lines, map[string]string{"backend": "docbook5"}).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, "<table frame=\"all\" rowsep=\"0\" colsep=\"1\">\n<title>Sizes</title>\n<tgroup cols=\"2\">\n"+
+			"<colspec colname=\"col_1\" colwidth=\"50*\"/>\n<colspec colname=\"col_2\" colwidth=\"50*\"/>\n"+
+			"<thead>\n<row>\n<entry align=\"left\" valign=\"top\">Name</entry>\n<entry align=\"left\" valign=\"top\">Doc</entry>\n</row>\n</thead>\n"+
+			"<tfoot>\n<row>\n<entry align=\"left\" valign=\"top\"><literallayout>verse</literallayout></entry>\n"+
+			"<entry align=\"left\" valign=\"top\"><simpara>f</simpara></entry>\n</row>\n</tfoot>\n<tbody>\n")
+		So(res, ShouldContainSubstring, "<entry align=\"left\" valign=\"top\" namest=\"col_1\" nameend=\"col_2\"><simpara>span</simpara></entry>")
+		So(res, ShouldContainSubstring, "<entry align=\"center\" valign=\"bottom\" morerows=\"1\"><simpara><emphasis role=\"strong\">rowspan</emphasis></simpara></entry>\n"+
+			"<entry align=\"left\" valign=\"top\"><itemizedlist>\n<listitem>\n<simpara>item</simpara>\n</listitem>\n</itemizedlist></entry>")
+		So(res, ShouldContainSubstring, "<entry align=\"left\" valign=\"top\"><literallayout class=\"monospaced\">  lit</literallayout></entry>")
+		So(res, ShouldContainSubstring, "<informaltable frame=\"all\" rowsep=\"1\" colsep=\"1\">\n<?dbfo keep-together=\"always\"?>\n<tgroup cols=\"1\">")
+
+		Convey("A table without body rows is reported", func() {
+			doc, _ := NewDocument([]string{"[%header]", "|===", "|a", "|==="}, map[string]string{"backend": "docbook5"}).Parse()
+			doc.Render()
+			messages := doc.Logger().(*MemoryLogger).Messages()
+			So(len(messages), ShouldEqual, 1)
+			So(messages[0].Text, ShouldEqual, "tables must have at least one body row")
+		})
+	})
+}
@@ -1,10 +1,44 @@
 package asciidocgo
 
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/VonC/asciidocgo/consts/context"
+	"github.com/VonC/asciidocgo/consts/safemode"
+)
+
 // Asciidoc Document, onced loaded from an IO, string or array
 type Document struct {
+	*abstractBlock
 	monitorData *monitorData
 	data        []string
 	options     map[string]string
+	header      *Section
+	safe        safemode.SafeMode
+	baseDir     string
+	counters    map[string]string
+	renderer    *Renderer
+	rendererErr error
+	references  *references
+	overrides   map[string]interface{}
+	parsed      bool
+	logger      Logger
+	parentDoc   *Document
+	cursor      *Cursor
+	callouts    *callouts
+	extensions  *Registry
+	active      *Registry
+	fsys        fs.FS
+	httpClient  *http.Client
+	footnotes   *footnotes
+	index       *indexCatalog
 }
 
 type monitorData struct {
@@ -42,6 +76,10 @@ Initialize an Asciidoc object.
 - data: The Array of Strings holding the Asciidoc source document. (default: [])
 - options - A Hash of options to control processing, such as setting the safe mode (:safe), suppressing the header/footer (:header_footer) and attribute overrides (:attributes)
 (default: {})
+The template_dir option names a directory of templates overriding
+the rendering of the nodes, and the template_set option set to 'builtin'
+renders the document, sections and blocks with the built-in templates
+(see NewTemplateRenderer) rather than with the built-in converter.
 
 Examples
 
@@ -50,10 +88,739 @@ Examples
     puts doc.render
 */
 func NewDocument(data []string, options map[string]string) *Document {
-	document := &Document{nil, data, options}
+	if options == nil {
+		options = make(map[string]string)
+	}
+	document := &Document{newAbstractBlock(nil, context.Document), nil, data, options, nil, safemode.SECURE, "", make(map[string]string), nil, nil, newReferences(), make(map[string]interface{}), false, NewMemoryLogger(), nil, nil, newCallouts(), nil, nil, nil, nil, newFootnotes(), newIndexCatalog()}
+	document.MainDocumentable(document)
+	document.MainNode(document)
+	// the document of a Document is the document itself
+	document.abstractNode.document = document
+	document.substitutors.document = &substDocument{document}
+	document.substitutors.parser = newParser()
+	document.substitutors.attributeListMaker = &attributeListMaker{}
+	document.loadRenderer()
+	document.safe = safeModeOption(options["safe"])
+	document.baseDir = document.resolveBaseDir(options["base_dir"])
+	document.setAttr("doctype", "article", false)
+	document.setAttr("encoding", "UTF-8", false)
+	document.setAttr("sectids", "", false)
+	for name, value := range defaultLabels {
+		document.setAttr(name, value, false)
+	}
+	if doctype := options["doctype"]; doctype != "" {
+		document.overrideAttribute("doctype", doctype)
+	}
+	if backend := options["backend"]; backend != "" {
+		document.overrideAttribute("backend", backend)
+	}
+	document.setAttr("backend", "html5", false)
+	document.updateBackendAttributes()
 	return document
 }
 
+/* Initialize the renderer of the document: with the built-in templates
+if the template_set option is 'builtin', and with the templates of the
+template_dir option, read from the file system of the document */
+func (d *Document) loadRenderer() {
+	d.renderer, d.rendererErr = newRenderer(d.fsys, d.options["template_dir"], d.options["template_set"] == "builtin")
+}
+
+/* The backend aliases, and the default output file suffix of each base backend */
+var (
+	backendAliases  = map[string]string{"html": "html5", "docbook": "docbook5"}
+	outfileSuffixes = map[string]string{"html": ".html", "docbook": ".xml"}
+)
+
+/* Update the attributes derived from the backend attribute:
+basebackend (the backend without its version: 'html', 'docbook'),
+backend-<backend> and basebackend-<basebackend>, outfilesuffix and filetype.
+The renderer converts nodes with the built-in converter of that backend.
+The 'html' and 'docbook' aliases stand for 'html5' and 'docbook5';
+the 'xhtml' prefix selects the xml html syntax. */
+func (d *Document) updateBackendAttributes() {
+	backend := strings.ToLower(strings.TrimSpace(fmt.Sprint(d.Attr("backend", "html5", false))))
+	if strings.HasPrefix(backend, "xhtml") {
+		d.setAttr("htmlsyntax", "xml", true)
+		backend = backend[1:]
+	}
+	if alias, ok := backendAliases[backend]; ok {
+		backend = alias
+	}
+	for name := range d.Attributes() {
+		if strings.HasPrefix(name, "backend-") || strings.HasPrefix(name, "basebackend-") {
+			delete(d.Attributes(), name)
+		}
+	}
+	basebackend := strings.TrimRight(backend, "0123456789")
+	d.setAttr("backend", backend, true)
+	d.setAttr("basebackend", basebackend, true)
+	d.setAttr("backend-"+backend, "", true)
+	d.setAttr("basebackend-"+basebackend, "", true)
+	if suffix, ok := outfileSuffixes[basebackend]; ok && !d.IsAttributeLocked("outfilesuffix") {
+		d.setAttr("outfilesuffix", suffix, true)
+		d.setAttr("filetype", suffix[1:], true)
+	}
+	d.renderer.setBackend(backend)
+}
+
+/* Resolve the placement of the table of contents, once the header is
+parsed, from the toc attribute (':toc: left'), or from the toc-placement
+and toc-position attributes:
+ - toc-placement is 'auto' (the toc is in the header), 'preamble'
+   (the toc follows the preamble) or 'macro' (the toc replaces the
+   toc::[] block macro);
+ - toc-position, for an auto placement, is left, right, top or bottom,
+   and toc-class is then 'toc2' (a toc beside or around the content).
+The deprecated toc2 attribute is an alias of ':toc: left'.
+An empty toc places a toc with the 'toc' class in the header. */
+func (d *Document) updateTocAttributes() {
+	toc, ok := d.Attr("toc", nil, false).(string)
+	if d.HasAttr("toc2", nil, false) {
+		delete(d.Attributes(), "toc2")
+		toc, ok = "left", true
+	}
+	if !ok {
+		return
+	}
+	position := ""
+	if placement := stringAttr(d, "toc-placement", ""); placement != "" && placement != "auto" {
+		position = placement
+	} else {
+		position = stringAttr(d, "toc-position", "")
+	}
+	if position == "" {
+		position = toc
+	}
+	d.setAttr("toc", "", true)
+	d.setAttr("toc-placement", "auto", true)
+	if position == "" {
+		return
+	}
+	tocClass := "toc2"
+	switch position {
+	case "left", "<", "&lt;":
+		d.setAttr("toc-position", "left", true)
+	case "right", ">", "&gt;":
+		d.setAttr("toc-position", "right", true)
+	case "top", "^":
+		d.setAttr("toc-position", "top", true)
+	case "bottom", "v":
+		d.setAttr("toc-position", "bottom", true)
+	case "preamble", "macro":
+		d.setAttr("toc-position", "content", true)
+		d.setAttr("toc-placement", position, true)
+		tocClass = ""
+	default:
+		delete(d.Attributes(), "toc-position")
+		tocClass = ""
+	}
+	if tocClass != "" && !d.HasAttr("toc-class", nil, false) {
+		d.setAttr("toc-class", tocClass, true)
+	}
+}
+
+/* The default captions and labels of a document */
+var defaultLabels = map[string]string{
+	"appendix-caption":  "Appendix",
+	"caution-caption":   "Caution",
+	"example-caption":   "Example",
+	"figure-caption":    "Figure",
+	"important-caption": "Important",
+	"note-caption":      "Note",
+	"table-caption":     "Table",
+	"tip-caption":       "Tip",
+	"version-label":     "Version",
+	"warning-caption":   "Warning",
+}
+
+/* Convert the :safe option to a SafeMode level.
+Accepts either a level name ("unsafe", "safe", "server", "secure")
+or its numeric value; defaults to SafeMode::SECURE, like the API. */
+func safeModeOption(safe string) safemode.SafeMode {
+	switch strings.ToLower(strings.TrimSpace(safe)) {
+	case "unsafe":
+		return safemode.UNSAFE
+	case "safe":
+		return safemode.SAFE
+	case "server":
+		return safemode.SERVER
+	case "secure", "":
+		return safemode.SECURE
+	case "paranoid":
+		return safemode.PARANOID
+	}
+	if level, err := strconv.Atoi(safe); err == nil && level >= int(safemode.UNSAFE) && level <= int(safemode.PARANOID) {
+		return safemode.SafeMode(level)
+	}
+	return safemode.SECURE
+}
+
+/* Resolve the base directory of the document:
+the :base_dir option if present, the current working directory otherwise
+(the root '/' of the file system of the document, if it has one) */
+func (d *Document) resolveBaseDir(baseDir string) string {
+	if d.fsys != nil {
+		return path.Join("/", Posixfy(baseDir))
+	}
+	if baseDir == "" {
+		return NewPathResolver(0, "").WorkingDir()
+	}
+	if abs, err := filepath.Abs(baseDir); err == nil {
+		return abs
+	}
+	return baseDir
+}
+
+/* Initialize a document nested in parent, like the content of an AsciiDoc
+table cell: it is converted with the same options and backend as its
+parent, inherits the attributes of its parent (except the doctitle),
+registers its references, footnotes and index terms in the catalogs of
+its parent
+(its counters continuing the ones of its parent) and reports its
+messages to the logger of its parent.
+cursor - the position of the first line of data in the parent document
+(may be nil) */
+func newInnerDocument(data []string, parent *Document, cursor *Cursor) *Document {
+	options := make(map[string]string)
+	for name, value := range parent.options {
+		options[name] = value
+	}
+	delete(options, "header_footer")
+	options["backend"] = fmt.Sprint(parent.Attr("backend", "html5", false))
+	doc := NewDocument(data, options)
+	for name, value := range parent.Attributes() {
+		if name != "doctitle" {
+			doc.setAttr(name, value, true)
+		}
+	}
+	doc.parentDoc, doc.cursor = parent, cursor
+	doc.logger = parent.Logger()
+	doc.references = parent.references
+	doc.footnotes = parent.footnotes
+	doc.index = parent.index
+	doc.counters = parent.counters
+	if parent.fsys != nil {
+		doc.UseFS(parent.fsys)
+	}
+	doc.httpClient = parent.httpClient
+	return doc
+}
+
+/* The parent document of a nested document (nil for a top-level document) */
+func (d *Document) ParentDocument() *Document {
+	return d.parentDoc
+}
+
+/* Check whether this document is nested in another one
+(like the content of an AsciiDoc table cell) */
+func (d *Document) IsNested() bool {
+	return d.parentDoc != nil
+}
+
+/* Parse the AsciiDoc source stored in this document into a tree of
+sections and blocks.
+Parsing happens only once: subsequent calls return the document as is.
+Returns self, for easy composition, and the first error encountered */
+func (d *Document) Parse() (*Document, error) {
+	if d.parsed {
+		return d, nil
+	}
+	d.parsed = true
+	// the global extensions registered until now apply to this document
+	d.active = nil
+	reader := d.Reader()
+	if !d.IsNested() {
+		for _, preprocessor := range d.Extensions().preprocessors {
+			if res := preprocessor.Process(d, reader); res != nil {
+				reader = res
+			}
+		}
+	}
+	if err := newParser().parseDocument(reader, d); err != nil {
+		return d, err
+	}
+	// the callout ids are read back in the same order when converting
+	d.callouts.Rewind()
+	doc := d
+	if !d.IsNested() {
+		for _, treeProcessor := range d.Extensions().treeProcessors {
+			if res := treeProcessor.Process(doc); res != nil {
+				doc = res
+			}
+		}
+	}
+	return doc, nil
+}
+
+/* A Reader over the source lines of this document,
+processing their preprocessor directives (include::).
+Lines are reported relative to the docfile attribute, if set,
+or to the position of a nested document in its parent */
+func (d *Document) Reader() *Reader {
+	docfile, _ := d.Attr("docfile", "", false).(string)
+	reader := newPreprocessorReader(d, d.data, docfile)
+	if d.cursor != nil {
+		reader.file, reader.dir, reader.path, reader.lineno = d.cursor.file, d.cursor.dir, d.cursor.path, d.cursor.lineno
+	}
+	return reader
+}
+
+/* The header of the document: a level-0 Section holding the document title
+(nil if the document has no header) */
+func (d *Document) Header() *Section {
+	return d.header
+}
+
+// Check if the document has a header (that is to say a document title)
+func (d *Document) HasHeader() bool {
+	return d.header != nil
+}
+
+/* The document title, as found in the header,
+or the value of the 'doctitle' attribute */
+func (d *Document) Title() string {
+	if d.header != nil {
+		return d.header.Title()
+	}
+	if title, ok := d.Attributes()["doctitle"].(string); ok {
+		return title
+	}
+	return ""
+}
+
+// Alias for Title()
+func (d *Document) Doctitle() string {
+	return d.Title()
+}
+
+// Get the safe mode level for this document
+func (d *Document) Safe() safemode.SafeMode {
+	return d.safe
+}
+
+// Get the base directory from which relative paths are resolved
+func (d *Document) BaseDir() string {
+	return d.baseDir
+}
+
+// Get the doctype of this document (article, book or manpage)
+func (d *Document) DocType() string {
+	return d.Attr("doctype", "article", false).(string)
+}
+
+/* Read the docinfo files of this document, to be inserted at location
+('head' or 'footer') of the converted document, with their attribute
+references substituted.
+The docinfo attribute selects them (a comma-separated list of 'shared',
+'private', 'shared-<location>' or 'private-<location>'; docinfo1 and
+docinfo2 being the legacy forms of 'shared' and 'shared,private'):
+the shared one is docinfo[-<location>]<suffix>, the private one is
+<docname>-docinfo[-<location>]<suffix>, both in the docinfodir attribute
+(the base directory by default), read from the file system of the
+document.
+suffix - the String suffix of the files ("" for the outfilesuffix attribute)
+Returns the String content of the files ("" in SECURE mode) */
+func (d *Document) Docinfo(location, suffix string) string {
+	if d.Safe() >= safemode.SECURE {
+		return ""
+	}
+	var docinfo []string
+	if value := attrString(d.abstractNode, "docinfo"); value != "" {
+		for _, name := range strings.Split(value, ",") {
+			docinfo = append(docinfo, strings.TrimSpace(name))
+		}
+	} else if d.HasAttr("docinfo2", nil, false) {
+		docinfo = []string{"private", "shared"}
+	} else if d.HasAttr("docinfo1", nil, false) {
+		docinfo = []string{"shared"}
+	} else if d.HasAttr("docinfo", nil, false) {
+		docinfo = []string{"private"}
+	}
+	has := func(kind string) bool {
+		for _, name := range docinfo {
+			if name == kind || name == kind+"-"+location {
+				return true
+			}
+		}
+		return false
+	}
+	qualifier := ""
+	if location != "head" {
+		qualifier = "-" + location
+	}
+	if suffix == "" {
+		suffix = attrString(d.abstractNode, "outfilesuffix")
+	}
+	file, dir := "docinfo"+qualifier+suffix, attrString(d.abstractNode, "docinfodir")
+	content := []string{}
+	if has("shared") {
+		content = append(content, d.readDocinfo(file, dir)...)
+	}
+	if docname := attrString(d.abstractNode, "docname"); docname != "" && has("private") {
+		content = append(content, d.readDocinfo(docname+"-"+file, dir)...)
+	}
+	return strings.Join(content, "\n")
+}
+
+/* Read a docinfo file in dir, with its attribute references substituted
+(nothing if it cannot be read) */
+func (d *Document) readDocinfo(file, dir string) []string {
+	path, err := d.normalizeSystemPath(file, dir, "", true, "")
+	if err != nil {
+		return nil
+	}
+	if content := ReadFSAsset(d.FS(), path, nil); content != "" {
+		return []string{d.SubAttributes(content, nil)}
+	}
+	return nil
+}
+
+/* Restore the attributes to the previously saved state
+(a no-op for now: attribute entries are applied during parsing) */
+func (d *Document) PlaybackAttributes(blockAttributes map[string]interface{}) {
+}
+
+/* Render the parsed document, using the 'document' template
+(with a header and a footer) if the header_footer option is "true",
+or the 'embedded' template otherwise.
+Returns an error if the templates of the template_dir option could not
+be loaded, if the backend has neither a converter nor templates,
+or if a template fails to render */
+func (d *Document) Render() (string, error) {
+	if d.rendererErr != nil {
+		return "", d.rendererErr
+	}
+	if d.renderer.converter == nil && d.renderer.templates == nil {
+		return "", fmt.Errorf("asciidocgo: missing converter for backend '%v'", d.Attr("backend", nil, false))
+	}
+	view := "embedded"
+	if d.options["header_footer"] == "true" {
+		view = "document"
+	}
+	d.SetTemplateName(view)
+	d.callouts.Rewind()
+	if !d.IsNested() {
+		// the footnotes and index terms are registered anew by each conversion,
+		// including the ones of the titles already interpreted while parsing
+		d.footnotes.Reset()
+		d.index.Reset()
+		delete(d.counters, "footnote-number")
+		d.abstractBlock.resetSubbedTitles()
+		if d.header != nil {
+			d.header.resetSubbedTitles()
+		}
+	}
+	res := d.abstractBlock.Render()
+	if !d.IsNested() {
+		for _, postprocessor := range d.Extensions().postprocessors {
+			res = postprocessor.Process(d, res)
+		}
+	}
+	return res, d.renderer.Err()
+}
+
+// Get the Renderer used to render the nodes of this document
+func (d *Document) Renderer() *Renderer {
+	return d.renderer
+}
+
+/* The Logger collecting the diagnostic messages of this document
+(a MemoryLogger by default) */
+func (d *Document) Logger() Logger {
+	return d.logger
+}
+
+/* Report the diagnostic messages of this document to logger
+(nil drops them) */
+func (d *Document) SetLogger(logger Logger) {
+	d.logger = logger
+}
+
+/* Set the value of a document attribute, after applying the header
+substitutions to it (special characters and attribute references).
+An attribute overridden from the API (or the command line)
+cannot be changed by the document.
+name  - the String attribute name
+value - the String attribute value
+Returns true if the attribute was set, false if it is locked */
+func (d *Document) SetAttribute(name, value string) bool {
+	if d.IsAttributeLocked(name) {
+		return false
+	}
+	d.setAttr(name, d.ApplyHeaderSubs(value), true)
+	if name == "backend" {
+		d.updateBackendAttributes()
+	}
+	return true
+}
+
+/* Delete (undefine) a document attribute.
+Returns true if the attribute was deleted, false if it is locked */
+func (d *Document) DeleteAttribute(name string) bool {
+	if d.IsAttributeLocked(name) {
+		return false
+	}
+	delete(d.Attributes(), name)
+	return true
+}
+
+/* Check whether the attribute is locked: it has been overridden
+from the API (or the command line) and cannot be changed by the document */
+func (d *Document) IsAttributeLocked(name string) bool {
+	_, ok := d.overrides[name]
+	return ok
+}
+
+/* Override (and lock) a document attribute: a nil value undefines it */
+func (d *Document) overrideAttribute(name string, value interface{}) {
+	d.overrides[name] = value
+	if value == nil {
+		delete(d.Attributes(), name)
+	} else {
+		d.setAttr(name, value, true)
+	}
+	if name == "backend" {
+		d.updateBackendAttributes()
+	}
+}
+
+/* The references of the document: the ids of its sections, blocks,
+inline anchors and bibliography entries, with their reference text,
+and the root names of the AsciiDoc files included in the document */
+type references struct {
+	ids      map[string]string
+	includes map[string]bool
+}
+
+func newReferences() *references {
+	return &references{make(map[string]string), make(map[string]bool)}
+}
+
+// Check if the id has been registered
+func (r *references) HasId(id string) bool {
+	_, ok := r.ids[id]
+	return ok
+}
+
+// Get the reference text of a registered id ("" if unknown)
+func (r *references) Get(id string) string {
+	return r.ids[id]
+}
+
+/* Check if the contents of the AsciiDoc file of this root name
+(its path without extension, like "chapters/intro") has been included
+in the document */
+func (r *references) HasInclude(path string) bool {
+	return r.includes[path]
+}
+
+/* Use the extensions of registry for this document, in addition to
+the global ones.
+Returns self, for easy composition */
+func (d *Document) UseExtensions(registry *Registry) *Document {
+	d.extensions = registry
+	d.active = nil
+	return d
+}
+
+/* Read the files of this document (include files, images embedded as
+data URIs, docinfo files, embedded stylesheets, templates of the
+template_dir option, ...) from fsys instead
+of the file system of the system.
+The base directory of the document (the :base_dir option) becomes a
+directory of fsys, '/' being its root; and no path of the document can
+resolve outside of that root, whatever the safe mode.
+  Examples
+    //go:embed docs
+    var docs embed.FS
+    doc := NewDocument(lines, map[string]string{"base_dir": "docs"}).UseFS(docs)
+Returns self, for easy composition */
+func (d *Document) UseFS(fsys fs.FS) *Document {
+	d.fsys = fsys
+	d.baseDir = d.resolveBaseDir(d.options["base_dir"])
+	if d.options["template_dir"] != "" {
+		d.loadRenderer()
+		d.renderer.setBackend(fmt.Sprint(d.Attr("backend", "html5", false)))
+	}
+	return d
+}
+
+/* The file system of the files of this document
+(nil for the file system of the system) */
+func (d *Document) FS() fs.FS {
+	return d.fsys
+}
+
+/* Fetch the remote resources of this document (the images embedded as
+data URIs, if the allow-uri-read attribute is set) with client.
+Returns self, for easy composition */
+func (d *Document) UseHTTPClient(client *http.Client) *Document {
+	d.httpClient = client
+	return d
+}
+
+/* The HTTP client fetching the remote resources of this document
+(nil for a default client, with a timeout) */
+func (d *Document) HTTPClient() *http.Client {
+	return d.httpClient
+}
+
+/* The extensions which apply to this document: the global ones, and
+the ones of its own registry (or of its parent document, if nested) */
+func (d *Document) Extensions() *Registry {
+	if d.active == nil {
+		registry := d.extensions
+		if registry == nil && d.parentDoc != nil {
+			registry = d.parentDoc.Extensions()
+		}
+		d.active = globalRegistry.merge(registry)
+	}
+	return d.active
+}
+
+/* The catalog of the callouts of this document, registered from
+the verbatim blocks and associated to the callout list items */
+func (d *Document) Callouts() Calloutable {
+	return d.callouts
+}
+
+// Get the references of this document
+func (d *Document) References() Referencable {
+	return d.references
+}
+
+/* The footnotes of this document, in their order of conversion
+(empty until the document has been converted) */
+func (d *Document) Footnotes() []*Footnote {
+	return d.footnotes.Footnotes()
+}
+
+// Check whether this document has footnotes (once converted)
+func (d *Document) HasFootnotes() bool {
+	return len(d.Footnotes()) > 0
+}
+
+/* Register a reference in the document.
+typeDoc - the String type of reference ("ids" or "includes")
+value   - for "ids", the String id followed by an optional reference text
+(default: "[id]"); for "includes", the String path of the included file,
+registered without its extension */
+func (d *Document) Register(typeDoc string, value []string) {
+	switch typeDoc {
+	case "ids":
+		if len(value) == 0 {
+			return
+		}
+		reftext := "[" + value[0] + "]"
+		if len(value) > 1 && value[1] != "" {
+			reftext = value[1]
+		}
+		d.references.ids[value[0]] = reftext
+	case "includes":
+		if len(value) == 0 {
+			return
+		}
+		d.references.includes[rootname(value[0])] = true
+	}
+}
+
+/* The path without its extension
+  Examples
+    rootname("chapters/intro.adoc")
+    => "chapters/intro" */
+func rootname(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path))
+}
+
+/* Get the named counter and take the next number in the sequence.
+name  - the String name of the counter
+seed  - the initial value as a String: a number or a letter (default: 1)
+returns the next number in the sequence for the specified counter, letters
+being numbered from 1 ('A' or 'a' is 1) */
+func (d *Document) Counter(name, seed string) int {
+	return counterNumber(d.counterValue(name, seed))
+}
+
+// Error returned when the seed of a counter is neither a number nor a letter
+type CounterSeedError struct {
+	Name string // name of the counter
+	Seed string // the invalid seed
+}
+
+// Print description of a counter seed error
+func (e *CounterSeedError) Error() string {
+	return fmt.Sprintf("counter reference seed is neither a number nor a letter: '%v' for counter '%v'", e.Seed, e.Name)
+}
+
+/* Check that the seed of the counter name is a number, a letter, or empty */
+func checkCounterSeed(name, seed string) error {
+	if seed == "" || counterNumber(seed) > 0 && len(seed) == 1 {
+		return nil
+	}
+	if _, err := strconv.Atoi(seed); err == nil {
+		return nil
+	}
+	return &CounterSeedError{name, seed}
+}
+
+/* Increment the specified counter and store it in the block's attributes
+counter_name - the String name of the counter attribute
+block        - the Block on which to save the counter
+returns the next number in the sequence for the specified counter,
+as a String */
+func (d *Document) CounterIncrement(counterName string, block *abstractNode) string {
+	val := d.counterValue(counterName, "")
+	if block != nil {
+		block.setAttr(counterName, val, true)
+	}
+	return val
+}
+
+/* Get the next value of a counter, as a string.
+The value is also stored as a document attribute of the same name */
+func (d *Document) counterValue(name, seed string) string {
+	val, ok := d.counters[name]
+	if !ok {
+		if seed == "" {
+			val = "1"
+		} else {
+			val = seed
+		}
+	} else {
+		val = nextCounterValue(val)
+	}
+	d.counters[name] = val
+	d.setAttr(name, val, true)
+	return val
+}
+
+/* Compute the successor of a counter value:
+the next integer for a number, the next character for a letter */
+func nextCounterValue(current string) string {
+	if i, err := strconv.Atoi(current); err == nil {
+		return strconv.Itoa(i + 1)
+	}
+	r, _ := utf8.DecodeRuneInString(current)
+	return string(r + 1)
+}
+
+/* Convert a counter value to a number,
+letters being numbered from 1 ('A' or 'a' is 1) */
+func counterNumber(val string) int {
+	if i, err := strconv.Atoi(val); err == nil {
+		return i
+	}
+	r, _ := utf8.DecodeRuneInString(val)
+	switch {
+	case r >= 'a' && r <= 'z':
+		return int(r-'a') + 1
+	case r >= 'A' && r <= 'Z':
+		return int(r-'A') + 1
+	}
+	return 0
+}
+
 // Time to read the document from IO source
 // Error if document didn't activated the monitoring
 func (d *Document) ReadTime() (readTime int, err error) {
@@ -122,3 +889,52 @@ func (d *Document) TotalTime() (totalTime int, err error) {
 	writeTime, _ := d.WriteTime()
 	return loadRenderTime + writeTime, nil
 }
+
+/* Adapts a Document to the SubstDocumentable interface used by the
+substitutors (whose Counter returns the counter value as a String) */
+type substDocument struct {
+	*Document
+}
+
+/* Get the named counter and take the next value in the sequence,
+as a String (an empty seed starting the sequence at 1) */
+func (sd *substDocument) Counter(name string, seed string) string {
+	return sd.counterValue(name, seed)
+}
+
+/* Check whether the base backend of the document is base */
+func (sd *substDocument) Basebackend(base interface{}) bool {
+	return sd.Attr("basebackend", nil, false) == base
+}
+
+// The extensions of the document, with its inline macros
+func (sd *substDocument) Extensions() Extensionables {
+	return sd.Document.Extensions()
+}
+
+// Initialize a footnote of the document
+func (sd *substDocument) NewFootnote(index int, id string, text string) Footnotable {
+	return NewFootnote(index, id, text)
+}
+
+// Register a footnote in the footnotes of the document
+func (sd *substDocument) RegisterFootnote(f Footnotable) {
+	if footnote, ok := f.(*Footnote); ok {
+		sd.footnotes.Register(footnote)
+	}
+}
+
+/* Register an occurrence of index terms in node, in the index of the
+document.
+Returns the id of the anchor of the occurrence */
+func (sd *substDocument) RegisterIndexTerm(terms []string, node AbstractNodable) string {
+	return sd.registerIndexTerm(terms, node)
+}
+
+// Find a footnote of the document by its id (nil if unknown)
+func (sd *substDocument) FindFootnote(id string) Footnotable {
+	if footnote := sd.footnotes.Find(id); footnote != nil {
+		return footnote
+	}
+	return nil
+}
@@ -3,6 +3,11 @@ package asciidocgo
 import (
 	"reflect"
 	"testing"
+	"testing/fstest"
+
+	"github.com/VonC/asciidocgo/consts/context"
+	"github.com/VonC/asciidocgo/consts/safemode"
+	"github.com/VonC/asciidocgo/consts/severity"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -84,5 +89,289 @@ func TestDocumentInitialization(t *testing.T) {
 		Convey("A Document take an array of strings as data, and a map as options", func() {
 			So(NewDocument([]string{}, map[string]string{}), ShouldNotBeNil)
 		})
+		Convey("A Document can read its files from a file system", func() {
+			fsys := fstest.MapFS{"docs/a.adoc": &fstest.MapFile{Data: []byte("a")}}
+			doc := NewDocument([]string{}, map[string]string{"base_dir": "docs"})
+			So(doc.FS(), ShouldBeNil)
+			So(doc.UseFS(fsys), ShouldEqual, doc)
+			So(doc.FS(), ShouldEqual, fsys)
+			So(doc.BaseDir(), ShouldEqual, "/docs")
+			So(NewDocument([]string{}, nil).UseFS(fsys).BaseDir(), ShouldEqual, "/")
+			So(newInnerDocument([]string{}, doc, nil).BaseDir(), ShouldEqual, "/docs")
+			Convey("Including the templates of its template dir", func() {
+				fsys := fstest.MapFS{"templates/block_paragraph.html": &fstest.MapFile{Data: []byte(`<p class="fs">{{raw .Content}}</p>`)}}
+				doc, _ := NewDocument([]string{"text"}, map[string]string{"template_dir": "templates", "backend": "docbook5"}).UseFS(fsys).Parse()
+				So(doc.Renderer().HasTemplate("block_paragraph"), ShouldBeTrue)
+				res, err := doc.Render()
+				So(err, ShouldBeNil)
+				So(res, ShouldEqual, "<p class=\"fs\">text</p>\n")
+				doc = NewDocument([]string{"text"}, map[string]string{"template_dir": "missing"}).UseFS(fsys)
+				_, err = doc.Render()
+				So(err, ShouldNotBeNil)
+			})
+			Convey("Including its docinfo files", func() {
+				fsys := fstest.MapFS{
+					"docs/docinfo.html":        &fstest.MapFile{Data: []byte(`<meta name="project" content="{project}">` + "\n")},
+					"docs/guide-docinfo.html":  &fstest.MapFile{Data: []byte(`<meta name="guide">`)},
+					"docs/docinfo-footer.html": &fstest.MapFile{Data: []byte("<script>footer</script>")},
+					"docs/info/docinfo.xml":    &fstest.MapFile{Data: []byte("<subtitle>Info</subtitle>")},
+					"docinfo.html":             &fstest.MapFile{Data: []byte("<script>secret</script>")},
+				}
+				lines := []string{":project: p", ":docname: guide", ":docinfo: shared,private-head"}
+				doc, _ := NewDocument(lines, map[string]string{"safe": "safe", "base_dir": "docs"}).UseFS(fsys).Parse()
+				So(doc.Docinfo("head", ""), ShouldEqual, "<meta name=\"project\" content=\"p\">\n<meta name=\"guide\">")
+				So(doc.Docinfo("footer", ""), ShouldEqual, "<script>footer</script>")
+				So(doc.Docinfo("head", ".xml"), ShouldEqual, "")
+				doc.setAttr("docinfodir", "info", true)
+				So(doc.Docinfo("head", ".xml"), ShouldEqual, "<subtitle>Info</subtitle>")
+				doc.setAttr("docinfodir", "..", true)
+				// recovered in the jail of the base dir
+				So(doc.Docinfo("head", ""), ShouldEqual, "<meta name=\"project\" content=\"p\">\n<meta name=\"guide\">")
+				doc, _ = NewDocument(lines, map[string]string{"base_dir": "docs"}).UseFS(fsys).Parse()
+				So(doc.Docinfo("head", ""), ShouldEqual, "")
+			})
+		})
+	})
+}
+
+func TestDocumentAttributes(t *testing.T) {
+
+	Convey("A Document has default attributes and settings", t, func() {
+		doc := NewDocument([]string{}, nil)
+		So(doc.Document(), ShouldEqual, doc)
+		So(doc.DocType(), ShouldEqual, "article")
+		So(doc.Safe(), ShouldEqual, safemode.SECURE)
+		So(doc.BaseDir(), ShouldNotEqual, "")
+		So(doc.Renderer(), ShouldNotBeNil)
+		So(doc.Title(), ShouldEqual, "")
+	})
+
+	Convey("A Document safe mode can be set through options", t, func() {
+		So(NewDocument(nil, map[string]string{"safe": "unsafe"}).Safe(), ShouldEqual, safemode.UNSAFE)
+		So(NewDocument(nil, map[string]string{"safe": "SAFE"}).Safe(), ShouldEqual, safemode.SAFE)
+		So(NewDocument(nil, map[string]string{"safe": "server"}).Safe(), ShouldEqual, safemode.SERVER)
+		So(NewDocument(nil, map[string]string{"safe": "paranoid"}).Safe(), ShouldEqual, safemode.PARANOID)
+		So(NewDocument(nil, map[string]string{"safe": "1"}).Safe(), ShouldEqual, safemode.SAFE)
+		So(NewDocument(nil, map[string]string{"safe": "42"}).Safe(), ShouldEqual, safemode.SECURE)
+	})
+
+	Convey("A Document title comes from its header or its doctitle attribute", t, func() {
+		doc := NewDocument([]string{}, nil)
+		doc.setAttr("doctitle", "attr title", true)
+		So(doc.Doctitle(), ShouldEqual, "attr title")
+		doc, _ = NewDocument([]string{"= Header Title"}, nil).Parse()
+		So(doc.Title(), ShouldEqual, "Header Title")
+		doc, err := doc.Parse()
+		So(err, ShouldBeNil)
+		So(len(doc.Blocks()), ShouldEqual, 0)
+	})
+
+	Convey("A Document manages counters", t, func() {
+		doc := NewDocument([]string{}, nil)
+		So(doc.Counter("num", ""), ShouldEqual, 1)
+		So(doc.Counter("num", ""), ShouldEqual, 2)
+		So(doc.Attr("num", nil, false), ShouldEqual, "2")
+		So(doc.Counter("seeded", "10"), ShouldEqual, 10)
+		So(doc.Counter("appendix-number", "A"), ShouldEqual, 1)
+		So(doc.Counter("appendix-number", "A"), ShouldEqual, 2)
+		So(doc.Attr("appendix-number", nil, false), ShouldEqual, "B")
+		So(doc.Counter("lower", "a"), ShouldEqual, 1)
+		So(counterNumber("?"), ShouldEqual, 0)
+		block := newAbstractNode(doc.abstractNode, context.Paragraph)
+		So(doc.CounterIncrement("table-number", block), ShouldEqual, "1")
+		So(block.Attr("table-number", nil, false), ShouldEqual, "1")
+		So(doc.CounterIncrement("table-number", nil), ShouldEqual, "2")
+		sd := &substDocument{doc}
+		So(sd.Counter("subst", ""), ShouldEqual, "1")
+		So(sd.Counter("seeded-subst", "5"), ShouldEqual, "5")
+	})
+
+	Convey("A Document sets attributes, unless they are overridden", t, func() {
+		doc := NewDocument([]string{}, nil)
+		So(doc.SetAttribute("copy", "a & b"), ShouldBeTrue)
+		So(doc.Attr("copy", nil, false), ShouldEqual, "a &amp; b")
+		So(doc.IsAttributeLocked("copy"), ShouldBeFalse)
+		So(doc.DeleteAttribute("copy"), ShouldBeTrue)
+		So(doc.HasAttr("copy", nil, false), ShouldBeFalse)
+		doc.overrideAttribute("backend", "html5")
+		So(doc.IsAttributeLocked("backend"), ShouldBeTrue)
+		So(doc.SetAttribute("backend", "docbook"), ShouldBeFalse)
+		So(doc.DeleteAttribute("backend"), ShouldBeFalse)
+		So(doc.Attr("backend", nil, false), ShouldEqual, "html5")
+		doc.overrideAttribute("sectids", nil)
+		So(doc.HasAttr("sectids", nil, false), ShouldBeFalse)
+		So(doc.SetAttribute("sectids", ""), ShouldBeFalse)
+		doc.setAttr("basebackend", "html", true)
+		So((&substDocument{doc}).Basebackend("html"), ShouldBeTrue)
+	})
+
+	Convey("A Document collects its diagnostic messages", t, func() {
+		doc := NewDocument([]string{}, map[string]string{"safe": "safe"})
+		logger, _ := doc.Logger().(*MemoryLogger)
+		So(logger, ShouldNotBeNil)
+		Convey("An image outside of the jail is reported as an error", func() {
+			doc.setAttr("data-uri", "", true)
+			So(doc.ImageUri("../../../../../../../etc/tiger.png", ""), ShouldEqual, "data:image/png;base64,")
+			So(logger.Messages()[0].Severity, ShouldEqual, severity.ERROR)
+			So(logger.Messages()[0].Text, ShouldContainSubstring, "refers to location outside jail")
+		})
+		Convey("A missing image to embed is reported as a warning", func() {
+			doc.setAttr("data-uri", "", true)
+			doc.ImageUri("missing.png", "")
+			So(logger.Messages()[0].Severity, ShouldEqual, severity.WARN)
+			So(logger.Messages()[0].Text, ShouldStartWith, "image to embed not found or not readable: ")
+		})
+		Convey("A bad counter seed is reported, with the location of its block", func() {
+			doc, _ := NewDocument([]string{"a {counter:num:10} b", "", "a {counter:num:bad} b"}, nil).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, "<p>a 10 b</p>")
+			So(res, ShouldContainSubstring, "<p>a {counter:num:bad} b</p>")
+			messages := doc.Logger().(*MemoryLogger).Messages()
+			So(len(messages), ShouldEqual, 1)
+			So(messages[0].String(), ShouldEqual, "asciidocgo: ERROR: counter reference seed is neither a number nor a letter: 'bad' for counter 'num'")
+			So(checkCounterSeed("num", "A"), ShouldBeNil)
+			So(checkCounterSeed("num", "-2"), ShouldBeNil)
+			So(checkCounterSeed("num", "?"), ShouldHaveSameTypeAs, &CounterSeedError{})
+		})
+		Convey("The messages can be sent to another logger", func() {
+			other := NewMemoryLogger()
+			doc.SetLogger(other)
+			doc.setAttr("data-uri", "", true)
+			doc.ImageUri("missing.png", "")
+			So(len(other.Messages()), ShouldEqual, 1)
+			So(len(logger.Messages()), ShouldEqual, 0)
+		})
+	})
+
+	Convey("A Document can be nested in another document", t, func() {
+		parent := NewDocument([]string{}, map[string]string{"backend": "docbook5", "header_footer": "true"})
+		parent.setAttr("doctitle", "Parent", true)
+		parent.setAttr("custom", "value", true)
+		So(parent.IsNested(), ShouldBeFalse)
+		So(parent.ParentDocument(), ShouldBeNil)
+		inner := newInnerDocument([]string{"", "{custom}"}, parent, &Cursor{file: "a.adoc", lineno: 10})
+		So(inner.IsNested(), ShouldBeTrue)
+		So(inner.ParentDocument(), ShouldEqual, parent)
+		So(inner.Attr("custom", nil, false), ShouldEqual, "value")
+		So(inner.HasAttr("doctitle", nil, false), ShouldBeFalse)
+		So(inner.Logger(), ShouldEqual, parent.Logger())
+		So(inner.References(), ShouldEqual, parent.References())
+		So(inner.footnotes, ShouldEqual, parent.footnotes)
+		reader := inner.Reader()
+		reader.SkipBlankLines()
+		So(reader.Cursor().LineNo(), ShouldEqual, 11)
+		inner.Parse()
+		res, _ := inner.Render()
+		So(res, ShouldEqual, "<simpara>value</simpara>\n")
+	})
+
+	Convey("A Document derives its backend attributes from the backend", t, func() {
+		doc := NewDocument([]string{}, nil)
+		So(doc.Attr("backend", nil, false), ShouldEqual, "html5")
+		So(doc.Attr("basebackend", nil, false), ShouldEqual, "html")
+		So(doc.HasAttr("backend-html5", nil, false), ShouldBeTrue)
+		So(doc.HasAttr("basebackend-html", nil, false), ShouldBeTrue)
+		So(doc.Attr("outfilesuffix", nil, false), ShouldEqual, ".html")
+		So(doc.Attr("filetype", nil, false), ShouldEqual, "html")
+		Convey("The docbook backend is an alias of docbook5", func() {
+			doc := NewDocument([]string{}, map[string]string{"backend": "docbook"})
+			So(doc.Attr("backend", nil, false), ShouldEqual, "docbook5")
+			So(doc.Attr("basebackend", nil, false), ShouldEqual, "docbook")
+			So((&substDocument{doc}).Basebackend("docbook"), ShouldBeTrue)
+			So(doc.Attr("outfilesuffix", nil, false), ShouldEqual, ".xml")
+			So(doc.IsAttributeLocked("backend"), ShouldBeTrue)
+			So(doc.Renderer().converter, ShouldEqual, converters["docbook5"])
+		})
+		Convey("The backend can be set by the document", func() {
+			doc, _ := NewDocument([]string{":backend: docbook5", "", "text"}, nil).Parse()
+			So(doc.Attr("basebackend", nil, false), ShouldEqual, "docbook")
+			So(doc.HasAttr("backend-html5", nil, false), ShouldBeFalse)
+			So(doc.HasAttr("backend-docbook5", nil, false), ShouldBeTrue)
+			res, _ := doc.Render()
+			So(res, ShouldEqual, "<simpara>text</simpara>\n")
+		})
+		Convey("The xhtml backend selects the xml html syntax", func() {
+			doc := NewDocument([]string{}, map[string]string{"backend": "xhtml5"})
+			So(doc.Attr("backend", nil, false), ShouldEqual, "html5")
+			So(doc.Attr("htmlsyntax", nil, false), ShouldEqual, "xml")
+		})
+		Convey("An unknown backend has no built-in converter", func() {
+			doc := NewDocument([]string{}, map[string]string{"backend": "pdf"})
+			So(doc.Attr("basebackend", nil, false), ShouldEqual, "pdf")
+			So(doc.Renderer().converter, ShouldBeNil)
+			_, err := doc.Render()
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("A Document can be rendered with templates", t, func() {
+		lines := []string{"= Title", "", "Preamble.", "", "== Section", "", "----", "code", "----"}
+		doc, _ := NewDocument(lines, nil).Parse()
+		res, err := doc.Render()
+		So(err, ShouldBeNil)
+		So(res, ShouldEqual, `<h1>Title</h1>
+<div class="paragraph">
+<p>Preamble.</p>
+</div>
+<div class="sect1">
+<h2 id="_section">Section</h2>
+<div class="sectionbody">
+<div class="listingblock">
+<div class="content">
+<pre>code</pre>
+</div>
+</div>
+</div>
+</div>
+`)
+		Convey("With a header and a footer, if the header_footer option is set", func() {
+			doc, _ := NewDocument(lines, map[string]string{"header_footer": "true"}).Parse()
+			res, err := doc.Render()
+			So(err, ShouldBeNil)
+			So(res, ShouldStartWith, "<!DOCTYPE html>")
+			So(res, ShouldContainSubstring, "<title>Title</title>")
+			So(res, ShouldContainSubstring, "<body class=\"article\">\n<div id=\"header\">\n<h1>Title</h1>")
+		})
+		Convey("Or with the built-in templates, if the template_set option is 'builtin'", func() {
+			doc, _ := NewDocument(append(lines, "", "* item"), map[string]string{"template_set": "builtin"}).Parse()
+			So(doc.Renderer().HasTemplate("section"), ShouldBeTrue)
+			res, err := doc.Render()
+			So(err, ShouldBeNil)
+			So(res, ShouldContainSubstring, "<h2 id=\"_section\">Section</h2>")
+			So(res, ShouldContainSubstring, "<pre>code</pre>")
+			So(res, ShouldContainSubstring, "<div class=\"ulist\">")
+		})
+		Convey("Unless the template dir can not be loaded", func() {
+			doc := NewDocument(lines, map[string]string{"template_dir": "test/no-such-dir"})
+			So(doc.Renderer(), ShouldNotBeNil)
+			_, err := doc.Render()
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("A Document resolves the placement of its table of contents", t, func() {
+		tocDocument := func(entries ...string) *Document {
+			doc, _ := NewDocument(append(append([]string{"= Title"}, entries...), "", "== Section"), nil).Parse()
+			return doc
+		}
+		doc := tocDocument(":toc:")
+		So(doc.Attr("toc-placement", nil, false), ShouldEqual, "auto")
+		So(doc.HasAttr("toc-position", nil, false), ShouldBeFalse)
+		So(doc.HasAttr("toc-class", nil, false), ShouldBeFalse)
+		doc = tocDocument(":toc: right")
+		So(doc.Attr("toc", nil, false), ShouldEqual, "")
+		So(doc.Attr("toc-placement", nil, false), ShouldEqual, "auto")
+		So(doc.Attr("toc-position", nil, false), ShouldEqual, "right")
+		So(doc.Attr("toc-class", nil, false), ShouldEqual, "toc2")
+		doc = tocDocument(":toc2:", ":toc-class: side")
+		So(doc.HasAttr("toc2", nil, false), ShouldBeFalse)
+		So(doc.Attr("toc-position", nil, false), ShouldEqual, "left")
+		So(doc.Attr("toc-class", nil, false), ShouldEqual, "side")
+		doc = tocDocument(":toc:", ":toc-placement: preamble")
+		So(doc.Attr("toc-placement", nil, false), ShouldEqual, "preamble")
+		So(doc.Attr("toc-position", nil, false), ShouldEqual, "content")
+		So(doc.HasAttr("toc-class", nil, false), ShouldBeFalse)
+		So(tocDocument(":toc: macro").Attr("toc-placement", nil, false), ShouldEqual, "macro")
+		So(tocDocument().HasAttr("toc", nil, false), ShouldBeFalse)
 	})
 }
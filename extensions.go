@@ -0,0 +1,412 @@
+package asciidocgo
+
+import (
+	"regexp"
+
+	"github.com/VonC/asciidocgo/consts/context"
+)
+
+/* A node of a document, as handed to the extensions: a Document,
+Section, Block, List, Table, ... or the node whose text an inline
+macro is found in */
+type Node interface {
+	Context() context.Context
+	Document() Documentable
+	Attr(name string, defaultValue interface{}, inherit bool) interface{}
+	HasAttr(name string, expect interface{}, inherit bool) bool
+	nodeOf() *abstractNode
+}
+
+/* A block node (Document, Section, Block, List, Table, ...), which
+can be the parent of the blocks created by an extension, or be
+returned by an extension to be added to its parent */
+type BlockNode interface {
+	Node
+	blockOf() *abstractBlock
+}
+
+func (an *abstractNode) nodeOf() *abstractNode {
+	return an
+}
+
+func (ab *abstractBlock) blockOf() *abstractBlock {
+	return ab
+}
+
+// The concrete node (Document, Section, Block, ...) of an abstract block
+func blockNodeOf(ab *abstractBlock) BlockNode {
+	if node, ok := ab.Node().(BlockNode); ok {
+		return node
+	}
+	return ab
+}
+
+/* A block processor converts the content of a paragraph or of a
+delimited block whose style is the name of the processor ('[shout]').
+Process returns the block to add to parent in place of the block
+read (or nil to drop it).
+parent     - the parent BlockNode of the block
+reader     - the Reader of the lines of the block (without delimiters)
+attributes - the attributes of the block (the style is '1') */
+type BlockProcessor interface {
+	Process(parent BlockNode, reader *Reader, attributes map[string]interface{}) BlockNode
+}
+
+/* A block macro processor converts a block macro line ('gist::123[]')
+whose name is the name of the processor.
+Process returns the block to add to parent (or nil to add nothing).
+parent     - the parent BlockNode of the macro
+target     - the String target of the macro ('123'), attributes
+             substituted
+attributes - the parsed attributes of the macro */
+type BlockMacroProcessor interface {
+	Process(parent BlockNode, target string, attributes map[string]interface{}) BlockNode
+}
+
+/* An inline macro processor converts an inline macro ('issue:42[]')
+whose name is the name of the processor.
+Process returns the converted text which replaces the macro.
+parent     - the Node whose text the macro is found in
+target     - the String target of the macro ('42'), empty for a
+             short format macro ('issue:[42]')
+attributes - the parsed attributes of the macro, or its text (as
+             'text' attribute) if it has no positional attributes */
+type InlineMacroProcessor interface {
+	Process(parent Node, target string, attributes map[string]interface{}) string
+}
+
+/* A preprocessor rewrites the lines of the source of a document,
+before it is parsed, through the Reader of these lines.
+Process returns the Reader the document is parsed from: reader itself,
+once modified (with ReadLines and UnshiftLines), or a new Reader
+(nil keeps reader).
+document - the Document being parsed, with the attributes passed in
+           its options (the header is not parsed yet)
+reader   - the Reader of the source lines of document */
+type Preprocessor interface {
+	Process(document *Document, reader *Reader) *Reader
+}
+
+/* A tree processor walks and mutates the tree of blocks of a document,
+once it is parsed, with its header attributes.
+Process returns the Document to convert in place of document
+(nil keeps document). */
+type TreeProcessor interface {
+	Process(document *Document) *Document
+}
+
+/* A postprocessor transforms the output of the conversion of a document.
+Process returns the transformed output. */
+type Postprocessor interface {
+	Process(document *Document, output string) string
+}
+
+// A function used as Preprocessor
+type PreprocessorFunc func(document *Document, reader *Reader) *Reader
+
+func (f PreprocessorFunc) Process(document *Document, reader *Reader) *Reader {
+	return f(document, reader)
+}
+
+// A function used as TreeProcessor
+type TreeProcessorFunc func(document *Document) *Document
+
+func (f TreeProcessorFunc) Process(document *Document) *Document {
+	return f(document)
+}
+
+// A function used as Postprocessor
+type PostprocessorFunc func(document *Document, output string) string
+
+func (f PostprocessorFunc) Process(document *Document, output string) string {
+	return f(document, output)
+}
+
+// A function used as BlockProcessor
+type BlockProcessorFunc func(parent BlockNode, reader *Reader, attributes map[string]interface{}) BlockNode
+
+func (f BlockProcessorFunc) Process(parent BlockNode, reader *Reader, attributes map[string]interface{}) BlockNode {
+	return f(parent, reader, attributes)
+}
+
+// A function used as BlockMacroProcessor
+type BlockMacroProcessorFunc func(parent BlockNode, target string, attributes map[string]interface{}) BlockNode
+
+func (f BlockMacroProcessorFunc) Process(parent BlockNode, target string, attributes map[string]interface{}) BlockNode {
+	return f(parent, target, attributes)
+}
+
+// A function used as InlineMacroProcessor
+type InlineMacroProcessorFunc func(parent Node, target string, attributes map[string]interface{}) string
+
+func (f InlineMacroProcessorFunc) Process(parent Node, target string, attributes map[string]interface{}) string {
+	return f(parent, target, attributes)
+}
+
+/* The options of a processor (nil for the defaults):
+ - Contexts: the contexts of the blocks a block processor applies to
+   (paragraph and open blocks by default);
+ - PosAttrs: the names of the positional attributes of a block or
+   a macro (after the style, for a block);
+ - ParseAttributes: parse the attributes of an inline macro, even
+   without PosAttrs (instead of keeping its text as 'text' attribute);
+ - ShortFormat: an inline macro without target ('name:[text]'). */
+type ProcessorOptions struct {
+	Contexts        []context.Context
+	PosAttrs        []string
+	ParseAttributes bool
+	ShortFormat     bool
+}
+
+type blockExtension struct {
+	processor BlockProcessor
+	options   *ProcessorOptions
+}
+
+// Check if the block processor applies to blocks of context c
+func (be *blockExtension) appliesTo(c context.Context) bool {
+	contexts := be.options.Contexts
+	if len(contexts) == 0 {
+		contexts = []context.Context{context.Paragraph, context.Open}
+	}
+	for _, ac := range contexts {
+		if ac == c {
+			return true
+		}
+	}
+	return false
+}
+
+type blockMacroExtension struct {
+	processor BlockMacroProcessor
+	options   *ProcessorOptions
+}
+
+/* An inline macro extension, matched and processed by the substitutors
+(see InlineMacroable) */
+type inlineMacroExtension struct {
+	name      string
+	processor InlineMacroProcessor
+	options   *ProcessorOptions
+	rx        *regexp.Regexp
+}
+
+func newInlineMacroExtension(name string, processor InlineMacroProcessor, options *ProcessorOptions) *inlineMacroExtension {
+	rx := `\\?` + regexp.QuoteMeta(name) + `:(\S+?)\[((?:\\\]|[^\]])*?)\]`
+	if options.ShortFormat {
+		rx = `\\?` + regexp.QuoteMeta(name) + `:\[((?:\\\]|[^\]])*?)\]`
+	}
+	return &inlineMacroExtension{name, processor, options, regexp.MustCompile(rx)}
+}
+
+func (ime *inlineMacroExtension) IsShortFormat() bool {
+	return ime.options.ShortFormat
+}
+func (ime *inlineMacroExtension) IsContentModelAttributes() bool {
+	return ime.options.ParseAttributes || len(ime.options.PosAttrs) > 0
+}
+func (ime *inlineMacroExtension) Regexp() *regexp.Regexp {
+	return ime.rx
+}
+func (ime *inlineMacroExtension) PosAttrs() []string {
+	return ime.options.PosAttrs
+}
+
+/* Process the macro, self being the node whose text the macro is in */
+func (ime *inlineMacroExtension) ProcessMethod(self interface{}, target string, attributes map[string]interface{}) string {
+	parent, _ := self.(Node)
+	return ime.processor.Process(parent, target, attributes)
+}
+
+/* A registry of extensions: block processors, block macros and
+inline macros, by name, and the preprocessors, tree processors and
+postprocessors, run in registration order, and the include processors.
+The global registry (GlobalExtensions()) applies to all documents;
+a document can use its own registry as well (Document.UseExtensions()),
+whose extensions take precedence over the global ones of the same name,
+and whose processors run after the global ones.
+ Examples
+   asciidocgo.GlobalExtensions().InlineMacro("issue",
+       asciidocgo.InlineMacroProcessorFunc(func(parent asciidocgo.Node, target string, attributes map[string]interface{}) string {
+           return `<a href="https://example.org/issues/` + target + `">#` + target + `</a>`
+       }), nil)
+*/
+type Registry struct {
+	blocks            map[string]*blockExtension
+	blockMacros       map[string]*blockMacroExtension
+	inlineMacros      []*inlineMacroExtension
+	preprocessors     []Preprocessor
+	treeProcessors    []TreeProcessor
+	postprocessors    []Postprocessor
+	includeProcessors []IncludeProcessor
+}
+
+// Initialize an empty registry of extensions
+func NewRegistry() *Registry {
+	return &Registry{map[string]*blockExtension{}, map[string]*blockMacroExtension{}, []*inlineMacroExtension{}, nil, nil, nil, nil}
+}
+
+var globalRegistry = NewRegistry()
+
+/* The registry of the extensions applying to all documents */
+func GlobalExtensions() *Registry {
+	return globalRegistry
+}
+
+/* Unregister all the global extensions */
+func UnregisterAllExtensions() {
+	globalRegistry = NewRegistry()
+}
+
+func processorOptions(options *ProcessorOptions) *ProcessorOptions {
+	if options == nil {
+		return &ProcessorOptions{}
+	}
+	return options
+}
+
+/* Register a block processor for the blocks whose style is name.
+Returns the registry, for easy composition */
+func (r *Registry) Block(name string, processor BlockProcessor, options *ProcessorOptions) *Registry {
+	r.blocks[name] = &blockExtension{processor, processorOptions(options)}
+	return r
+}
+
+/* Register a block macro processor for the 'name::target[]' macros.
+Returns the registry, for easy composition */
+func (r *Registry) BlockMacro(name string, processor BlockMacroProcessor, options *ProcessorOptions) *Registry {
+	r.blockMacros[name] = &blockMacroExtension{processor, processorOptions(options)}
+	return r
+}
+
+/* Register an inline macro processor for the 'name:target[]' macros
+(or 'name:[]' with the ShortFormat option), replacing any inline
+macro of the same name.
+Returns the registry, for easy composition */
+func (r *Registry) InlineMacro(name string, processor InlineMacroProcessor, options *ProcessorOptions) *Registry {
+	extension := newInlineMacroExtension(name, processor, processorOptions(options))
+	for i, ime := range r.inlineMacros {
+		if ime.name == name {
+			r.inlineMacros[i] = extension
+			return r
+		}
+	}
+	r.inlineMacros = append(r.inlineMacros, extension)
+	return r
+}
+
+/* Register a preprocessor, run before the document is parsed.
+Returns the registry, for easy composition */
+func (r *Registry) Preprocessor(processor Preprocessor) *Registry {
+	r.preprocessors = append(r.preprocessors, processor)
+	return r
+}
+
+/* Register a tree processor, run once the document is parsed.
+Returns the registry, for easy composition */
+func (r *Registry) TreeProcessor(processor TreeProcessor) *Registry {
+	r.treeProcessors = append(r.treeProcessors, processor)
+	return r
+}
+
+/* Register a postprocessor, run on the output of the conversion.
+Returns the registry, for easy composition */
+func (r *Registry) Postprocessor(processor Postprocessor) *Registry {
+	r.postprocessors = append(r.postprocessors, processor)
+	return r
+}
+
+/* Register an include processor, the first one which handles the
+target of an include directive providing its content.
+Returns the registry, for easy composition */
+func (r *Registry) IncludeProcessor(processor IncludeProcessor) *Registry {
+	r.includeProcessors = append(r.includeProcessors, processor)
+	return r
+}
+
+/* The block processor registered for style name, if it applies to
+blocks of context c (nil otherwise) */
+func (r *Registry) blockFor(name string, c context.Context) *blockExtension {
+	if extension, ok := r.blocks[name]; ok && extension.appliesTo(c) {
+		return extension
+	}
+	return nil
+}
+
+/* The block macro processor registered for name (nil if none) */
+func (r *Registry) blockMacroFor(name string) *blockMacroExtension {
+	return r.blockMacros[name]
+}
+
+/* Check if any inline macro is registered */
+func (r *Registry) HasInlineMacros() bool {
+	return len(r.inlineMacros) > 0
+}
+
+/* The inline macros, in registration order */
+func (r *Registry) InlineMacros() []InlineMacroable {
+	res := []InlineMacroable{}
+	for _, ime := range r.inlineMacros {
+		res = append(res, ime)
+	}
+	return res
+}
+
+/* A new registry with the extensions of r, and those of other
+(which replace the ones of r with the same name) */
+func (r *Registry) merge(other *Registry) *Registry {
+	res := NewRegistry()
+	for _, registry := range []*Registry{r, other} {
+		if registry == nil {
+			continue
+		}
+		for name, extension := range registry.blocks {
+			res.blocks[name] = extension
+		}
+		for name, extension := range registry.blockMacros {
+			res.blockMacros[name] = extension
+		}
+		for _, extension := range registry.inlineMacros {
+			res.InlineMacro(extension.name, extension.processor, extension.options)
+		}
+		res.preprocessors = append(res.preprocessors, registry.preprocessors...)
+		res.treeProcessors = append(res.treeProcessors, registry.treeProcessors...)
+		res.postprocessors = append(res.postprocessors, registry.postprocessors...)
+		res.includeProcessors = append(res.includeProcessors, registry.includeProcessors...)
+	}
+	return res
+}
+
+/* Create a block of context c with the source lines and attributes
+given (title, id, style and others), as child of parent.
+The block is not added to parent: a block processor returns it. */
+func CreateBlock(parent BlockNode, c context.Context, source []string, attributes map[string]interface{}) *Block {
+	block := newBlock(parent.blockOf(), c, source)
+	if attributes != nil {
+		applyBlockAttributes(block.abstractBlock, attributes)
+	}
+	return block
+}
+
+/* Append child (a block created with CreateBlock, ...) to the blocks
+of parent (used by a tree processor to add blocks to the tree) */
+func AppendChild(parent BlockNode, child BlockNode) {
+	parent.blockOf().AppendBlock(child.blockOf())
+}
+
+/* Parse lines as AsciiDoc blocks appended to parent (the content
+of a compound block, like an open or example block).
+Returns parent, for easy composition */
+func ParseContent(parent BlockNode, lines []string) BlockNode {
+	newParser().parseBlocks(NewReader(lines, ""), parent.blockOf())
+	return parent
+}
+
+/* Create an inline node of context c ('quoted', 'anchor', ...) in
+parent, and convert it with the renderer of its document.
+typeInline - the String type of the inline ('strong', 'link', ...)
+target     - the String target of the inline (a link, an id, ...) */
+func ConvertInline(parent Node, c context.Context, text string, typeInline string, target string, attributes map[string]interface{}) string {
+	opts := &OptionsInline{typeInline: typeInline, target: target, attributes: attributes}
+	return newInline(parent.nodeOf(), c, text, opts).Convert()
+}
@@ -0,0 +1,174 @@
+package asciidocgo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/VonC/asciidocgo/consts/context"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var shoutProcessor = BlockProcessorFunc(func(parent BlockNode, reader *Reader, attributes map[string]interface{}) BlockNode {
+	lines := reader.ReadLines()
+	for i, line := range lines {
+		lines[i] = strings.ToUpper(line)
+	}
+	if volume, ok := attributes["volume"].(string); ok {
+		lines[len(lines)-1] = lines[len(lines)-1] + strings.Repeat("!", len(volume))
+	}
+	return CreateBlock(parent, context.Paragraph, lines, attributes)
+})
+
+var gistProcessor = BlockMacroProcessorFunc(func(parent BlockNode, target string, attributes map[string]interface{}) BlockNode {
+	line := fmt.Sprintf(`<script src="https://gist.github.com/%s.js"></script>`, target)
+	if title, ok := attributes["title"].(string); ok {
+		line = line + "<!-- " + title + " -->"
+	}
+	return CreateBlock(parent, context.Pass, []string{line}, nil)
+})
+
+var issueProcessor = InlineMacroProcessorFunc(func(parent Node, target string, attributes map[string]interface{}) string {
+	return ConvertInline(parent, context.Anchor, "#"+target, "link", "https://example.org/issues/"+target, nil)
+})
+
+func TestExtensions(t *testing.T) {
+
+	Convey("A registry registers extensions by name", t, func() {
+		r := NewRegistry()
+		So(r.Block("shout", shoutProcessor, nil), ShouldEqual, r)
+		So(r.blockFor("shout", context.Paragraph), ShouldNotBeNil)
+		So(r.blockFor("shout", context.Open), ShouldNotBeNil)
+		So(r.blockFor("shout", context.Listing), ShouldBeNil)
+		So(r.blockFor("whisper", context.Paragraph), ShouldBeNil)
+		r.Block("listing", shoutProcessor, &ProcessorOptions{Contexts: []context.Context{context.Listing}})
+		So(r.blockFor("listing", context.Paragraph), ShouldBeNil)
+		So(r.blockFor("listing", context.Listing), ShouldNotBeNil)
+		So(r.BlockMacro("gist", gistProcessor, nil).blockMacroFor("gist"), ShouldNotBeNil)
+		So(r.HasInlineMacros(), ShouldBeFalse)
+		r.InlineMacro("issue", issueProcessor, nil).InlineMacro("issue", issueProcessor, &ProcessorOptions{ShortFormat: true})
+		So(r.HasInlineMacros(), ShouldBeTrue)
+		So(len(r.InlineMacros()), ShouldEqual, 1)
+		So(r.InlineMacros()[0].IsShortFormat(), ShouldBeTrue)
+		So(r.InlineMacros()[0].Regexp().String(), ShouldEqual, `\\?issue:\[((?:\\\]|[^\]])*?)\]`)
+
+		Convey("A registry merged with another one gets its extensions", func() {
+			other := NewRegistry().BlockMacro("gist", nil, nil).InlineMacro("pr", issueProcessor, nil)
+			merged := r.merge(other)
+			So(merged.blockFor("shout", context.Paragraph), ShouldNotBeNil)
+			So(merged.blockMacroFor("gist").processor, ShouldBeNil)
+			So(len(merged.InlineMacros()), ShouldEqual, 2)
+			So(len(r.InlineMacros()), ShouldEqual, 1)
+		})
+	})
+
+	Convey("A block processor converts the blocks with its style", t, func() {
+		GlobalExtensions().Block("shout", shoutProcessor, &ProcessorOptions{PosAttrs: []string{"volume"}})
+		defer UnregisterAllExtensions()
+		lines := []string{"[shout]", "hello", "world", "", ".Title", "[shout,high]", "--", "open", "--", "", "[shout]", "----", "code", "----"}
+		doc, _ := NewDocument(lines, nil).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, "<div class=\"paragraph\">\n<p>HELLO\nWORLD</p>\n</div>")
+		So(res, ShouldContainSubstring, "<div class=\"paragraph\">\n<div class=\"title\">Title</div>\n<p>OPEN!!!!</p>\n</div>")
+		So(res, ShouldContainSubstring, "<pre>code</pre>")
+		So(doc.Blocks()[1].SourceLocation().LineNo(), ShouldEqual, 7)
+	})
+
+	Convey("A block macro processor converts its block macros", t, func() {
+		GlobalExtensions().BlockMacro("gist", gistProcessor, &ProcessorOptions{PosAttrs: []string{"title"}})
+		defer UnregisterAllExtensions()
+		lines := []string{":id: 42", "", "gist::{id}[Snippet]", "", "other::1[]"}
+		doc, _ := NewDocument(lines, nil).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldStartWith, "<script src=\"https://gist.github.com/42.js\"></script><!-- Snippet -->\n")
+		So(res, ShouldContainSubstring, "<p>other::1[]</p>")
+	})
+
+	Convey("An inline macro processor converts its inline macros", t, func() {
+		GlobalExtensions().InlineMacro("issue", issueProcessor, nil)
+		GlobalExtensions().InlineMacro("shout", InlineMacroProcessorFunc(func(parent Node, target string, attributes map[string]interface{}) string {
+			return fmt.Sprintf("%s-%v-%v", strings.ToUpper(attributes["text"].(string)), attributes["size"], parent.Context())
+		}), &ProcessorOptions{ShortFormat: true, PosAttrs: []string{"text", "size"}})
+		defer UnregisterAllExtensions()
+		lines := []string{"See issue:42[] and \\issue:43[], shout:[hi, 3]."}
+		doc, _ := NewDocument(lines, nil).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, "<p>See <a href=\"https://example.org/issues/42\">#42</a> and issue:43[], HI-3-paragraph.</p>")
+	})
+
+	Convey("A document can use its own extensions, in addition to the global ones", t, func() {
+		GlobalExtensions().InlineMacro("issue", issueProcessor, nil)
+		defer UnregisterAllExtensions()
+		registry := NewRegistry().Block("shout", shoutProcessor, nil)
+		lines := []string{"[shout]", "hi", "", "issue:1[]"}
+		doc, _ := NewDocument(lines, nil).UseExtensions(registry).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, "<p>HI</p>")
+		So(res, ShouldContainSubstring, "<p><a href=\"https://example.org/issues/1\">#1</a></p>")
+		doc, _ = NewDocument(lines, nil).Parse()
+		res, _ = doc.Render()
+		So(res, ShouldContainSubstring, "<p>hi</p>")
+
+		Convey("A block processor can parse the content of the block it creates", func() {
+			registry.Block("boxed", BlockProcessorFunc(func(parent BlockNode, reader *Reader, attributes map[string]interface{}) BlockNode {
+				return ParseContent(CreateBlock(parent, context.Sidebar, nil, nil), reader.ReadLines())
+			}), &ProcessorOptions{Contexts: []context.Context{context.Open}})
+			lines := []string{"[boxed]", "--", "* a", "--"}
+			doc, _ := NewDocument(lines, nil).UseExtensions(registry).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, "<div class=\"sidebarblock\">\n<div class=\"content\">\n<div class=\"ulist\">")
+		})
+	})
+
+	Convey("Preprocessors, tree processors and postprocessors run in registration order", t, func() {
+		calls := []string{}
+		GlobalExtensions().Preprocessor(PreprocessorFunc(func(document *Document, reader *Reader) *Reader {
+			calls = append(calls, "pre1")
+			lines := reader.ReadLines()
+			return NewReader(append([]string{":product: Go"}, lines...), "")
+		}))
+		defer UnregisterAllExtensions()
+		registry := NewRegistry().Preprocessor(PreprocessorFunc(func(document *Document, reader *Reader) *Reader {
+			calls = append(calls, "pre2:"+fmt.Sprint(document.Attr("mode", nil, false)))
+			lines := reader.ReadLines()
+			for i, line := range lines {
+				lines[i] = strings.Replace(line, "Hi", "Hello", 1)
+			}
+			reader.UnshiftLines(lines)
+			return nil
+		}))
+		registry.TreeProcessor(TreeProcessorFunc(func(document *Document) *Document {
+			calls = append(calls, "tree:"+fmt.Sprint(document.Attr("product", nil, false)))
+			for _, block := range document.Blocks() {
+				block.SetStyle("lead")
+				block.setAttr("role", "lead", true)
+			}
+			AppendChild(document, CreateBlock(document, context.Paragraph, []string{"Added"}, nil))
+			return nil
+		}))
+		registry.Postprocessor(PostprocessorFunc(func(document *Document, output string) string {
+			calls = append(calls, "post1")
+			return strings.ToUpper(output)
+		}))
+		registry.Postprocessor(PostprocessorFunc(func(document *Document, output string) string {
+			calls = append(calls, "post2")
+			return output + "<!-- " + fmt.Sprint(document.Attr("product", nil, false)) + " -->"
+		}))
+		doc := NewDocument([]string{"Hi {product}"}, nil).UseExtensions(registry)
+		doc.overrideAttribute("mode", "test")
+		doc.Parse()
+		res, _ := doc.Render()
+		So(res, ShouldEqual, "<DIV CLASS=\"PARAGRAPH LEAD\">\n<P>HELLO GO</P>\n</DIV>\n<DIV CLASS=\"PARAGRAPH\">\n<P>ADDED</P>\n</DIV>\n<!-- Go -->")
+		So(calls, ShouldResemble, []string{"pre1", "pre2:test", "tree:Go", "post1", "post2"})
+
+		Convey("A tree processor can replace the document", func() {
+			other := NewDocument([]string{"Other"}, nil)
+			other.Parse()
+			registry.TreeProcessor(TreeProcessorFunc(func(document *Document) *Document {
+				return other
+			}))
+			doc, _ := NewDocument([]string{"Hi"}, nil).UseExtensions(registry).Parse()
+			So(doc, ShouldEqual, other)
+		})
+	})
+}
@@ -0,0 +1,85 @@
+package asciidocgo
+
+import "fmt"
+
+/* A footnote of a document: its number in the sequence of the footnotes
+of the document, its optional id (with which footnoteref:[id] references
+it again) and its text, once substituted.
+ Examples
+   footnote:[An example footnote.]
+   footnoteref:[disclaimer,Opinions are my own.]
+   footnoteref:[disclaimer] */
+type Footnote struct {
+	index int
+	id    string
+	text  string
+}
+
+/* Initialize a Footnote.
+index - the Integer number of the footnote in the document
+id    - the String id of the footnote ("" for an anonymous one)
+text  - the String text of the footnote */
+func NewFootnote(index int, id, text string) *Footnote {
+	return &Footnote{index, id, text}
+}
+
+// The number of the footnote in the document
+func (f *Footnote) Index() int {
+	return f.index
+}
+
+// The id of the footnote ("" for an anonymous footnote)
+func (f *Footnote) Id() string {
+	return f.id
+}
+
+// The text of the footnote
+func (f *Footnote) Text() string {
+	return f.text
+}
+
+func (f *Footnote) String() string {
+	return fmt.Sprintf("footnote(%v,%v): '%v'", f.id, f.index, f.text)
+}
+
+/* The catalog of the footnotes of a document, in their order of
+registration, which is the order in which the document is converted.
+The documents nested in a document (like the content of an AsciiDoc
+table cell) register their footnotes in the catalog of their parent */
+type footnotes struct {
+	list []*Footnote
+}
+
+func newFootnotes() *footnotes {
+	return &footnotes{[]*Footnote{}}
+}
+
+// Register a footnote, once its inline macro has been substituted
+func (f *footnotes) Register(footnote *Footnote) {
+	f.list = append(f.list, footnote)
+}
+
+/* Find the first footnote registered with this id
+(nil if there is none, or if the id is empty) */
+func (f *footnotes) Find(id string) *Footnote {
+	if id == "" {
+		return nil
+	}
+	for _, footnote := range f.list {
+		if footnote.id == id {
+			return footnote
+		}
+	}
+	return nil
+}
+
+/* Forget the registered footnotes, before the document is converted
+again */
+func (f *footnotes) Reset() {
+	f.list = []*Footnote{}
+}
+
+// The registered footnotes, in their order of registration
+func (f *footnotes) Footnotes() []*Footnote {
+	return f.list
+}
@@ -0,0 +1,34 @@
+package asciidocgo
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFootnote(t *testing.T) {
+
+	Convey("A Footnote has a number, an optional id and a text", t, func() {
+		footnote := NewFootnote(2, "disclaimer", "Opinions are my own.")
+		So(footnote.Index(), ShouldEqual, 2)
+		So(footnote.Id(), ShouldEqual, "disclaimer")
+		So(footnote.Text(), ShouldEqual, "Opinions are my own.")
+		So(footnote.String(), ShouldEqual, "footnote(disclaimer,2): 'Opinions are my own.'")
+	})
+
+	Convey("Footnotes can be registered and found by their id", t, func() {
+		f := newFootnotes()
+		f.Register(NewFootnote(1, "", "First."))
+		f.Register(NewFootnote(2, "second", "Second."))
+		So(len(f.Footnotes()), ShouldEqual, 2)
+		So(f.Find("second").Text(), ShouldEqual, "Second.")
+		So(f.Find("third"), ShouldBeNil)
+		So(f.Find(""), ShouldBeNil)
+
+		Convey("And forgotten before a new conversion", func() {
+			f.Reset()
+			So(len(f.Footnotes()), ShouldEqual, 0)
+			So(f.Find("second"), ShouldBeNil)
+		})
+	})
+}
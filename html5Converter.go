@@ -0,0 +1,1060 @@
+package asciidocgo
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/VonC/asciidocgo/consts/contentModel"
+	"github.com/VonC/asciidocgo/consts/context"
+	"github.com/VonC/asciidocgo/consts/safemode"
+	"github.com/VonC/asciidocgo/consts/severity"
+)
+
+/* A Converter converts the nodes of a document to the output format
+of a backend.
+transform is the name of the template which would render the node
+('document', 'section', 'block_paragraph', 'inline_quoted', ...).
+Returns the converted node, and false if transform is not handled */
+type Converter interface {
+	Convert(node interface{}, transform string) (string, bool)
+}
+
+/* The built-in converter for the HTML5 backend, producing the same
+semantic HTML5 as Asciidoctor.
+Void elements are closed ('<br/>') when the document
+attribute htmlsyntax is 'xml' (see ShortTagSlash()). */
+type html5Converter struct{}
+
+/* Convert a Document, a Section, a Block or an Inline node to HTML5 */
+func (c *html5Converter) Convert(node interface{}, transform string) (string, bool) {
+	switch n := node.(type) {
+	case *Document:
+		switch transform {
+		case "document":
+			return c.document(n), true
+		case "embedded":
+			return c.embedded(n), true
+		}
+	case *Section:
+		if transform == "section" {
+			return c.section(n), true
+		}
+	case *Block:
+		switch transform {
+		case "block_paragraph":
+			return c.paragraph(n), true
+		case "block_admonition":
+			return c.admonition(n), true
+		case "block_listing":
+			return c.listing(n), true
+		case "block_literal":
+			return c.literal(n), true
+		case "block_example":
+			return c.example(n), true
+		case "block_sidebar":
+			return c.sidebar(n), true
+		case "block_quote":
+			return c.quote(n), true
+		case "block_verse":
+			return c.verse(n), true
+		case "block_pass":
+			return n.Content(), true
+		case "block_open":
+			return c.open(n), true
+		case "block_image":
+			return c.image(n), true
+		case "block_toc":
+			return c.toc(n), true
+		}
+	case *List:
+		switch transform {
+		case "block_ulist":
+			return c.ulist(n), true
+		case "block_olist":
+			return c.olist(n), true
+		case "block_dlist":
+			return c.dlist(n), true
+		case "block_colist":
+			return c.colist(n), true
+		}
+	case *Table:
+		if transform == "block_table" {
+			return c.table(n), true
+		}
+	case *Inline:
+		switch transform {
+		case "inline_anchor":
+			return c.inlineAnchor(n), true
+		case "inline_break":
+			return fmt.Sprintf("%s<br%s>", n.Text(), voidElementSlash(n.abstractNode)), true
+		case "inline_button":
+			return fmt.Sprintf(`<b class="button">%s</b>`, n.Text()), true
+		case "inline_callout":
+			return c.inlineCallout(n), true
+		case "inline_footnote":
+			return c.inlineFootnote(n), true
+		case "inline_image":
+			return c.inlineImage(n), true
+		case "inline_indexterm":
+			return c.inlineIndexterm(n), true
+		case "inline_kbd":
+			return c.inlineKbd(n), true
+		case "inline_menu":
+			return c.inlineMenu(n), true
+		case "inline_quoted":
+			return c.inlineQuoted(n), true
+		}
+	}
+	return "", false
+}
+
+// Matches the XML tags, removed from the title of the HTML page
+var sanitizeXmlRx, _ = regexp.Compile(`<[^>]+>`)
+
+// "/" if the void elements must be closed, "" otherwise
+func voidElementSlash(an *abstractNode) string {
+	if slash := an.ShortTagSlash(); slash != nil {
+		return string(*slash)
+	}
+	return ""
+}
+
+// The String value of an attribute ("" if not a String)
+func attrString(an *abstractNode, name string) string {
+	res, _ := an.Attr(name, nil, false).(string)
+	return res
+}
+
+/* Matches the characters to escape in the value of an attribute, and the
+character references (already escaped by a substitution) to keep as is */
+var attributeCharsRx, _ = regexp.Compile(`&(?:[a-zA-Z][a-zA-Z0-9]*|#\d+|#x[a-fA-F0-9]+);|[&<>"]`)
+
+var attributeChars = map[string]string{"&": "&amp;", "<": "&lt;", ">": "&gt;", `"`: "&quot;"}
+
+/* Escape the value of an attribute: '&', '<', '>' and '"' are replaced
+by their character reference (a character reference is kept as is) */
+func escapeAttribute(value interface{}) string {
+	return attributeCharsRx.ReplaceAllStringFunc(fmt.Sprint(value), func(s string) string {
+		if res, ok := attributeChars[s]; ok {
+			return res
+		}
+		return s
+	})
+}
+
+// The id attribute of an element (" id=\"id\"", or "")
+func idAttribute(an *abstractNode) string {
+	if an.Id() == "" {
+		return ""
+	}
+	return fmt.Sprintf(` id="%s"`, escapeAttribute(an.Id()))
+}
+
+// The (escaped) classes of an element, followed by the role of the node
+func classes(an *abstractNode, class ...string) string {
+	if role, _ := an.Role().(string); role != "" {
+		class = append(class, role)
+	}
+	return escapeAttribute(strings.Join(class, " "))
+}
+
+// The title of a block as a div, followed by a new line ("" if no title)
+func titleElement(ab *abstractBlock, captioned bool) string {
+	if !ab.HasTitle() {
+		return ""
+	}
+	title := ab.Title()
+	if captioned {
+		title = ab.CaptionedTitle()
+	}
+	return fmt.Sprintf("<div class=\"title\">%s</div>\n", title)
+}
+
+func (c *html5Converter) document(d *Document) string {
+	slash := voidElementSlash(d.abstractNode)
+	res := []string{"<!DOCTYPE html>",
+		fmt.Sprintf(`<html lang="%s">`, escapeAttribute(d.Attr("lang", "en", false))),
+		"<head>",
+		fmt.Sprintf(`<meta charset="%s"%s>`, escapeAttribute(d.Attr("encoding", "UTF-8", false)), slash),
+		fmt.Sprintf(`<meta name="generator" content="asciidocgo"%s>`, slash)}
+	for _, name := range []string{"description", "keywords", "author"} {
+		if value := attrString(d.abstractNode, name); value != "" {
+			res = append(res, fmt.Sprintf(`<meta name="%s" content="%s"%s>`, name, escapeAttribute(value), slash))
+		}
+	}
+	res = append(res, fmt.Sprintf("<title>%s</title>", sanitizeXmlRx.ReplaceAllString(d.Doctitle(), "")))
+	if stylesheet := c.stylesheet(d, slash); stylesheet != "" {
+		res = append(res, stylesheet)
+	}
+	if docinfo := d.Docinfo("head", ""); docinfo != "" {
+		res = append(res, docinfo)
+	}
+	res = append(res, "</head>")
+	bodyClasses := []string{d.DocType()}
+	if hasToc(d, "auto") && d.HasAttr("toc-class", nil, false) {
+		bodyClasses = append(bodyClasses, attrString(d.abstractNode, "toc-class"), "toc-"+fmt.Sprint(d.Attr("toc-position", "header", false)))
+	}
+	res = append(res, fmt.Sprintf(`<body%s class="%s">`, idAttribute(d.abstractNode), classes(d.abstractNode, bodyClasses...)))
+	if !d.HasAttr("noheader", nil, false) {
+		res = append(res, `<div id="header">`)
+		if d.HasHeader() && !d.HasAttr("notitle", nil, false) {
+			res = append(res, fmt.Sprintf("<h1>%s</h1>", d.Header().Title()))
+		}
+		if details := c.details(d, slash); details != "" {
+			res = append(res, details)
+		}
+		if hasToc(d, "auto") {
+			res = append(res, c.tocElement(d, fmt.Sprint(d.Attr("toc-class", "toc", false))))
+		}
+		res = append(res, "</div>")
+	}
+	res = append(res, `<div id="content">`, c.content(d)+"</div>")
+	if footnotes := c.footnotes(d, slash); footnotes != "" {
+		res = append(res, footnotes)
+	}
+	if !d.HasAttr("nofooter", nil, false) {
+		res = append(res, `<div id="footer">`, `<div id="footer-text">`)
+		if revnumber := attrString(d.abstractNode, "revnumber"); revnumber != "" {
+			res = append(res, fmt.Sprintf("%s %s<br%s>", d.Attr("version-label", "Version", false), revnumber, slash))
+		}
+		res = append(res, "</div>", "</div>")
+	}
+	if docinfo := d.Docinfo("footer", ""); docinfo != "" {
+		res = append(res, docinfo)
+	}
+	res = append(res, "</body>", "</html>")
+	return strings.Join(res, "\n")
+}
+
+/* The stylesheet of the document, if its stylesheet attribute is set:
+linked (in the stylesdir attribute) if the linkcss attribute is set, or
+in SECURE mode; embedded otherwise, read from the file system of the
+document */
+func (c *html5Converter) stylesheet(d *Document, slash string) string {
+	stylesheet := attrString(d.abstractNode, "stylesheet")
+	if stylesheet == "" {
+		return ""
+	}
+	stylesdir := attrString(d.abstractNode, "stylesdir")
+	if d.Safe() >= safemode.SECURE || d.HasAttr("linkcss", nil, false) {
+		return fmt.Sprintf(`<link rel="stylesheet" href="%s"%s>`, escapeAttribute(normalizeWebPath(stylesheet, stylesdir)), slash)
+	}
+	path, err := d.normalizeSystemPath(stylesheet, stylesdir, "", true, "stylesheet")
+	if err != nil {
+		d.log(severity.ERROR, err.Error())
+		return ""
+	}
+	return "<style>\n" + ReadFSAsset(d.FS(), path, d.Logger()) + "\n</style>"
+}
+
+// The authors and the revision of the document header
+func (c *html5Converter) details(d *Document, slash string) string {
+	res := []string{}
+	if d.HasAttr("author", nil, false) {
+		authorCount := 1
+		fmt.Sscan(attrString(d.abstractNode, "authorcount"), &authorCount)
+		for i := 1; i <= authorCount; i++ {
+			suffix, idSuffix := "", ""
+			if i > 1 {
+				suffix, idSuffix = fmt.Sprintf("_%d", i), fmt.Sprintf("%d", i)
+			}
+			if author := attrString(d.abstractNode, "author"+suffix); author != "" {
+				res = append(res, fmt.Sprintf(`<span id="author%s" class="author">%s</span><br%s>`, idSuffix, author, slash))
+			}
+			if email := attrString(d.abstractNode, "email"+suffix); email != "" {
+				res = append(res, fmt.Sprintf(`<span id="email%s" class="email"><a href="mailto:%s">%s</a></span><br%s>`, idSuffix, escapeAttribute(email), email, slash))
+			}
+		}
+	}
+	if revnumber := attrString(d.abstractNode, "revnumber"); revnumber != "" {
+		separator := ""
+		if d.HasAttr("revdate", nil, false) {
+			separator = ","
+		}
+		res = append(res, fmt.Sprintf(`<span id="revnumber">%s %s%s</span>`, strings.ToLower(fmt.Sprint(d.Attr("version-label", "Version", false))), revnumber, separator))
+	}
+	if revdate := attrString(d.abstractNode, "revdate"); revdate != "" {
+		res = append(res, fmt.Sprintf(`<span id="revdate">%s</span>`, revdate))
+	}
+	if revremark := attrString(d.abstractNode, "revremark"); revremark != "" {
+		res = append(res, fmt.Sprintf(`<br%s><span id="revremark">%s</span>`, slash, revremark))
+	}
+	if len(res) == 0 {
+		return ""
+	}
+	return "<div class=\"details\">\n" + strings.Join(res, "\n") + "\n</div>"
+}
+
+func (c *html5Converter) embedded(d *Document) string {
+	res := ""
+	if d.HasHeader() && !d.HasAttr("notitle", nil, false) {
+		res = fmt.Sprintf("<h1%s>%s</h1>\n", idAttribute(d.Header().abstractNode), d.Header().Title())
+	}
+	if hasToc(d, "auto") {
+		res = res + c.tocElement(d, "toc") + "\n"
+	}
+	res = res + c.content(d)
+	if footnotes := c.footnotes(d, voidElementSlash(d.abstractNode)); footnotes != "" {
+		res = res + footnotes + "\n"
+	}
+	return res
+}
+
+/* The footnotes of a document, converted after its content (the
+footnotes of a nested document are part of the ones of its parent),
+unless the nofootnotes attribute is set */
+func (c *html5Converter) footnotes(d *Document, slash string) string {
+	if d.IsNested() || !d.HasFootnotes() || d.HasAttr("nofootnotes", nil, false) {
+		return ""
+	}
+	res := []string{`<div id="footnotes">`, fmt.Sprintf("<hr%s>", slash)}
+	for _, footnote := range d.Footnotes() {
+		res = append(res, fmt.Sprintf(`<div class="footnote" id="_footnote_%d">`, footnote.Index()),
+			fmt.Sprintf(`<a href="#_footnoteref_%d">%d</a>. %s`, footnote.Index(), footnote.Index(), footnote.Text()),
+			"</div>")
+	}
+	return strings.Join(append(res, "</div>"), "\n")
+}
+
+/* The content of a document, followed by its table of contents if it
+is placed after the preamble (the blocks before the first section of
+a document with a header).
+The index of the index section is converted once the whole content has
+been, with all the index terms of the document (see indexPlaceholder) */
+func (c *html5Converter) content(d *Document) string {
+	res := ""
+	if !hasToc(d, "preamble") || !d.HasHeader() {
+		res = d.Content()
+	} else {
+		for i, block := range d.Blocks() {
+			if i > 0 && block.Context() == context.Section && d.Blocks()[i-1].Context() != context.Section {
+				res = res + c.tocElement(d, fmt.Sprint(d.Attr("toc-class", "toc", false))) + "\n"
+			}
+			res = res + block.Render() + "\n"
+		}
+	}
+	if d.IsNested() || !strings.Contains(res, indexPlaceholder) {
+		return res
+	}
+	return strings.Replace(res, indexPlaceholder, c.index(d), -1)
+}
+
+/* Check if the table of contents of a document is placed at placement
+('auto', 'preamble' or 'macro'): the document must have sections */
+func hasToc(d *Document, placement string) bool {
+	return d.HasAttr("toc", nil, false) && d.Attr("toc-placement", "auto", false) == placement && len(d.Sections()) > 0
+}
+
+// The table of contents of a document, with the toc-title as title
+func (c *html5Converter) tocElement(d *Document, class string) string {
+	return fmt.Sprintf("<div id=\"toc\" class=\"%s\">\n<div id=\"toctitle\">%s</div>\n%s\n</div>",
+		escapeAttribute(class), d.Attr("toc-title", "Table of Contents", false), c.outline(d.Outline(), intAttr(d, "toclevels", 2)))
+}
+
+/* The nested lists of links to the sections of an outline, down to the
+toclevels level */
+func (c *html5Converter) outline(entries []*OutlineEntry, toclevels int) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	res := []string{fmt.Sprintf(`<ul class="sectlevel%d">`, entries[0].Level)}
+	for _, entry := range entries {
+		link := fmt.Sprintf(`<li><a href="#%s">%s</a>`, escapeAttribute(entry.Id), entry.numberedTitle())
+		if entry.Level < toclevels && len(entry.Entries) > 0 {
+			res = append(res, link, c.outline(entry.Entries, toclevels), "</li>")
+		} else {
+			res = append(res, link+"</li>")
+		}
+	}
+	return strings.Join(append(res, "</ul>"), "\n")
+}
+
+/* The toc::[] block macro: the table of contents of the document,
+if its toc-placement is 'macro' (with the levels attribute of the
+macro overriding toclevels) */
+func (c *html5Converter) toc(b *Block) string {
+	d, ok := b.Document().(*Document)
+	if !ok || !hasToc(d, "macro") {
+		return "<!-- toc disabled -->"
+	}
+	id, titleId := "toc", "toctitle"
+	if b.Id() != "" {
+		id, titleId = b.Id(), b.Id()+"title"
+	}
+	title := b.Title()
+	if !b.HasTitle() {
+		title = fmt.Sprint(d.Attr("toc-title", "Table of Contents", false))
+	}
+	levels := intAttr(d, "toclevels", 2)
+	if value, err := strconv.Atoi(attrString(b.abstractNode, "levels")); err == nil {
+		levels = value
+	}
+	role, _ := b.Role().(string)
+	if role == "" {
+		role = fmt.Sprint(d.Attr("toc-class", "toc", false))
+	}
+	return fmt.Sprintf("<div id=\"%s\" class=\"%s\">\n<div id=\"%s\" class=\"title\">%s</div>\n%s\n</div>",
+		escapeAttribute(id), escapeAttribute(role), escapeAttribute(titleId), title, c.outline(d.Outline(), levels))
+}
+
+func (c *html5Converter) section(s *Section) string {
+	level := s.Level()
+	if level == 0 {
+		return fmt.Sprintf("<h1%s class=\"sect0\">%s</h1>\n%s", idAttribute(s.abstractNode), s.Title(), s.Content())
+	}
+	title := s.CaptionedTitle()
+	if s.IsNumbered() && s.Caption() == "" && (s.Document() == nil || level <= intAttr(s.Document(), "sectnumlevels", 3)) {
+		title = s.SectNum() + " " + title
+	}
+	content := s.Content()
+	if s.IsSpecial() && s.SectName() == "index" {
+		content = content + indexPlaceholder
+	}
+	if level == 1 {
+		content = "<div class=\"sectionbody\">\n" + content + "</div>\n"
+	}
+	return fmt.Sprintf("<div class=\"%s\">\n<h%d%s>%s</h%d>\n%s</div>",
+		classes(s.abstractNode, fmt.Sprintf("sect%d", level)), level+1, idAttribute(s.abstractNode), title, level+1, content)
+}
+
+/* The mark left in its index section, until the whole content of the
+document has been converted: the index terms which follow the index
+section are then registered as well */
+const indexPlaceholder = subPASS_START + "index" + subPASS_END
+
+/* The index of a document, in its index section: its primary terms,
+grouped by category, each term followed by the links to the sections
+in which it occurs, and by its subterms */
+func (c *html5Converter) index(d *Document) string {
+	categories := d.IndexCategories()
+	if len(categories) == 0 {
+		return ""
+	}
+	sectnumlevels := intAttr(d, "sectnumlevels", 3)
+	res := []string{`<div class="index">`}
+	for _, category := range categories {
+		res = append(res, `<div class="indexcategory">`, fmt.Sprintf(`<div class="title">%s</div>`, category.Name),
+			c.indexTerms(category.Terms, sectnumlevels), "</div>")
+	}
+	return strings.Join(append(res, "</div>"), "\n") + "\n"
+}
+
+/* The list of index terms, each one with one link per section in which
+it occurs (numbered links for the locations outside of any section) */
+func (c *html5Converter) indexTerms(terms []*IndexTerm, sectnumlevels int) string {
+	res := []string{"<ul>"}
+	for _, term := range terms {
+		item := "<li>" + term.Name
+		sections := make(map[*Section]bool)
+		for n, location := range term.Locations {
+			text := fmt.Sprintf("[%d]", n+1)
+			if location.Section != nil {
+				if sections[location.Section] {
+					continue
+				}
+				sections[location.Section] = true
+				text = newOutlineEntry(location.Section, sectnumlevels).numberedTitle()
+			}
+			item = item + fmt.Sprintf(`, <a href="#%s">%s</a>`, escapeAttribute(location.Id), text)
+		}
+		if subterms := term.Subterms(); len(subterms) > 0 {
+			item = item + "\n" + c.indexTerms(subterms, sectnumlevels) + "\n"
+		}
+		res = append(res, item+"</li>")
+	}
+	return strings.Join(append(res, "</ul>"), "\n")
+}
+
+func (c *html5Converter) paragraph(b *Block) string {
+	return fmt.Sprintf("<div%s class=\"%s\">\n%s<p>%s</p>\n</div>",
+		idAttribute(b.abstractNode), classes(b.abstractNode, "paragraph"), titleElement(b.abstractBlock, false), b.Content())
+}
+
+func (c *html5Converter) admonition(b *Block) string {
+	name := attrString(b.abstractNode, "name")
+	label := attrString(b.abstractNode, "textlabel")
+	icon := fmt.Sprintf(`<div class="title">%s</div>`, label)
+	if b.Document() != nil && b.Document().HasAttr("icons", nil, false) {
+		if b.Document().Attr("icons", nil, false) == "font" {
+			icon = fmt.Sprintf(`<i class="fa icon-%s" title="%s"></i>`, escapeAttribute(name), escapeAttribute(label))
+		} else {
+			icon = fmt.Sprintf(`<img src="%s" alt="%s"%s>`, escapeAttribute(b.IconUri(name)), escapeAttribute(label), voidElementSlash(b.abstractNode))
+		}
+	}
+	return fmt.Sprintf("<div%s class=\"%s\">\n<table>\n<tr>\n<td class=\"icon\">\n%s\n</td>\n<td class=\"content\">\n%s%s\n</td>\n</tr>\n</table>\n</div>",
+		idAttribute(b.abstractNode), classes(b.abstractNode, "admonitionblock", name), icon, titleElement(b.abstractBlock, false), strings.TrimSuffix(b.Content(), "\n"))
+}
+
+func (c *html5Converter) listing(b *Block) string {
+	preStart, preEnd := "<pre>", "</pre>"
+	if b.Style() == "source" {
+		codeAttrs := ""
+		if language := attrString(b.abstractNode, "language"); language != "" {
+			language = escapeAttribute(language)
+			codeAttrs = fmt.Sprintf(` class="language-%s" data-lang="%s"`, language, language)
+		}
+		preStart, preEnd = fmt.Sprintf(`<pre class="highlight"><code%s>`, codeAttrs), "</code></pre>"
+	} else if b.HasOption("nowrap") {
+		preStart = `<pre class="nowrap">`
+	}
+	return fmt.Sprintf("<div%s class=\"%s\">\n%s<div class=\"content\">\n%s%s%s\n</div>\n</div>",
+		idAttribute(b.abstractNode), classes(b.abstractNode, "listingblock"), titleElement(b.abstractBlock, true), preStart, b.Content(), preEnd)
+}
+
+func (c *html5Converter) literal(b *Block) string {
+	return fmt.Sprintf("<div%s class=\"%s\">\n%s<div class=\"content\">\n<pre>%s</pre>\n</div>\n</div>",
+		idAttribute(b.abstractNode), classes(b.abstractNode, "literalblock"), titleElement(b.abstractBlock, false), b.Content())
+}
+
+func (c *html5Converter) example(b *Block) string {
+	return fmt.Sprintf("<div%s class=\"%s\">\n%s<div class=\"content\">\n%s</div>\n</div>",
+		idAttribute(b.abstractNode), classes(b.abstractNode, "exampleblock"), titleElement(b.abstractBlock, true), b.Content())
+}
+
+func (c *html5Converter) sidebar(b *Block) string {
+	return fmt.Sprintf("<div%s class=\"%s\">\n<div class=\"content\">\n%s%s</div>\n</div>",
+		idAttribute(b.abstractNode), classes(b.abstractNode, "sidebarblock"), titleElement(b.abstractBlock, false), b.Content())
+}
+
+func (c *html5Converter) quote(b *Block) string {
+	content := b.Content()
+	if b.ContentModel() == contentmodel.Simple {
+		// the content of a quote paragraph
+		content = content + "\n"
+	}
+	return fmt.Sprintf("<div%s class=\"%s\">\n%s<blockquote>\n%s</blockquote>%s\n</div>",
+		idAttribute(b.abstractNode), classes(b.abstractNode, "quoteblock"), titleElement(b.abstractBlock, false), content, c.attribution(b))
+}
+
+func (c *html5Converter) verse(b *Block) string {
+	return fmt.Sprintf("<div%s class=\"%s\">\n%s<pre class=\"content\">%s</pre>%s\n</div>",
+		idAttribute(b.abstractNode), classes(b.abstractNode, "verseblock"), titleElement(b.abstractBlock, false), b.Content(), c.attribution(b))
+}
+
+// The attribution (author and citation title) of a quote or of a verse
+func (c *html5Converter) attribution(b *Block) string {
+	res := ""
+	author, citetitle := attrString(b.abstractNode, "attribution"), attrString(b.abstractNode, "citetitle")
+	if author == "" && citetitle == "" {
+		return ""
+	}
+	if author != "" {
+		res = "&#8212; " + escapeAttribute(author)
+		if citetitle != "" {
+			res = res + "<br" + voidElementSlash(b.abstractNode) + ">\n"
+		}
+	}
+	if citetitle != "" {
+		res = res + "<cite>" + escapeAttribute(citetitle) + "</cite>"
+	}
+	return "\n<div class=\"attribution\">\n" + res + "\n</div>"
+}
+
+func (c *html5Converter) open(b *Block) string {
+	if b.Style() == "abstract" {
+		return fmt.Sprintf("<div%s class=\"%s\">\n%s<blockquote>\n%s</blockquote>\n</div>",
+			idAttribute(b.abstractNode), classes(b.abstractNode, "quoteblock", "abstract"), titleElement(b.abstractBlock, false), b.Content())
+	}
+	class := []string{"openblock"}
+	if style := b.Style(); style != "" && style != "open" {
+		class = append(class, style)
+	}
+	return fmt.Sprintf("<div%s class=\"%s\">\n%s<div class=\"content\">\n%s</div>\n</div>",
+		idAttribute(b.abstractNode), classes(b.abstractNode, class...), titleElement(b.abstractBlock, false), b.Content())
+}
+
+// The img element of an image, with its optional link
+func (c *html5Converter) img(an *abstractNode, src string) string {
+	attrs := fmt.Sprintf(` alt="%s"`, escapeAttribute(attrString(an, "alt")))
+	for _, name := range []string{"width", "height", "title"} {
+		if value := attrString(an, name); value != "" {
+			attrs = attrs + fmt.Sprintf(` %s="%s"`, name, escapeAttribute(value))
+		}
+	}
+	img := fmt.Sprintf(`<img src="%s"%s%s>`, escapeAttribute(src), attrs, voidElementSlash(an))
+	if link := attrString(an, "link"); link != "" {
+		img = fmt.Sprintf(`<a class="image" href="%s">%s</a>`, escapeAttribute(link), img)
+	}
+	return img
+}
+
+func (c *html5Converter) image(b *Block) string {
+	return fmt.Sprintf("<div%s class=\"%s\">\n<div class=\"content\">\n%s\n</div>%s\n</div>",
+		idAttribute(b.abstractNode), classes(b.abstractNode, "imageblock"),
+		c.img(b.abstractNode, b.ImageUri(attrString(b.abstractNode, "target"), "")),
+		strings.TrimSuffix("\n"+titleElement(b.abstractBlock, true), "\n"))
+}
+
+func (c *html5Converter) inlineAnchor(i *Inline) string {
+	switch i.Type() {
+	case "xref":
+		refid := attrString(i.abstractNode, "refid")
+		text := i.Text()
+		if text == "" && i.Document() != nil && i.Document().References() != nil {
+			text = i.Document().References().Get(refid)
+		}
+		if text == "" {
+			text = "[" + refid + "]"
+		}
+		return fmt.Sprintf(`<a href="%s">%s</a>`, escapeAttribute(i.Target()), text)
+	case "ref":
+		return fmt.Sprintf(`<a id="%s"></a>`, escapeAttribute(i.Target()))
+	case "bibref":
+		return fmt.Sprintf(`<a id="%s"></a>[%s]`, escapeAttribute(i.Target()), i.Target())
+	case "link":
+		attrs := ""
+		if role, _ := i.Role().(string); role != "" {
+			attrs = fmt.Sprintf(` class="%s"`, escapeAttribute(role))
+		}
+		if window := attrString(i.abstractNode, "window"); window != "" {
+			attrs = attrs + fmt.Sprintf(` target="%s"`, escapeAttribute(window))
+		}
+		return fmt.Sprintf(`<a href="%s"%s>%s</a>`, escapeAttribute(i.Target()), attrs, i.Text())
+	}
+	return ""
+}
+
+func (c *html5Converter) inlineCallout(i *Inline) string {
+	doc := i.Document()
+	switch {
+	case doc != nil && doc.Attr("icons", nil, false) == "font":
+		return fmt.Sprintf(`<i class="conum" data-value="%s"></i><b>(%s)</b>`, escapeAttribute(i.Text()), i.Text())
+	case doc != nil && doc.HasAttr("icons", nil, false):
+		return fmt.Sprintf(`<img src="%s" alt="%s"%s>`, escapeAttribute(i.IconUri("callouts/"+i.Text())), escapeAttribute(i.Text()), voidElementSlash(i.abstractNode))
+	}
+	return fmt.Sprintf(`<b class="conum">(%s)</b>`, i.Text())
+}
+
+func (c *html5Converter) inlineFootnote(i *Inline) string {
+	index := fmt.Sprint(i.Attr("index", "", false))
+	if i.Type() == "xref" && index == "" {
+		return fmt.Sprintf(`<span class="footnoteref red" title="Unresolved footnote reference.">[%s]</span>`, i.Text())
+	}
+	if i.Type() == "xref" {
+		return fmt.Sprintf(`<span class="footnoteref">[<a class="footnote" href="#_footnote_%s" title="View footnote.">%s</a>]</span>`, index, index)
+	}
+	id := ""
+	if i.Id() != "" {
+		id = fmt.Sprintf(` id="_footnote_%s"`, escapeAttribute(i.Id()))
+	}
+	return fmt.Sprintf(`<span class="footnote"%s>[<a id="_footnoteref_%s" class="footnote" href="#_footnote_%s" title="View footnote.">%s</a>]</span>`, id, index, index, index)
+}
+
+/* An index term: its text if it is visible, preceded by an anchor,
+if the document has an index section linking to it */
+func (c *html5Converter) inlineIndexterm(i *Inline) string {
+	res := ""
+	if d, ok := i.Document().(*Document); ok && i.Id() != "" && d.HasIndex() {
+		res = fmt.Sprintf(`<a id="%s"></a>`, escapeAttribute(i.Id()))
+	}
+	if i.Type() == "visible" {
+		res = res + i.Text()
+	}
+	return res
+}
+
+func (c *html5Converter) inlineImage(i *Inline) string {
+	if i.Type() == "icon" && i.Document() != nil && i.Document().Attr("icons", nil, false) == "font" {
+		class := "fa fa-" + i.Target()
+		if size := attrString(i.abstractNode, "size"); size != "" {
+			class = class + " fa-" + size
+		}
+		return fmt.Sprintf(`<span class="icon"><i class="%s"></i></span>`, escapeAttribute(class))
+	}
+	src := ""
+	if i.Type() == "icon" {
+		if i.Document() != nil && i.Document().HasAttr("icons", nil, false) {
+			src = i.IconUri(i.Target())
+		} else {
+			return fmt.Sprintf(`<span class="icon">[%s]</span>`, attrString(i.abstractNode, "alt"))
+		}
+	} else {
+		src = i.ImageUri(i.Target(), "")
+	}
+	return fmt.Sprintf(`<span class="%s">%s</span>`, classes(i.abstractNode, i.Type()), c.img(i.abstractNode, src))
+}
+
+func (c *html5Converter) inlineKbd(i *Inline) string {
+	keys, _ := i.Attr("keys", nil, false).([]string)
+	if len(keys) == 1 {
+		return "<kbd>" + keys[0] + "</kbd>"
+	}
+	kbds := []string{}
+	for _, key := range keys {
+		kbds = append(kbds, "<kbd>"+key+"</kbd>")
+	}
+	return `<span class="keyseq">` + strings.Join(kbds, "+") + "</span>"
+}
+
+func (c *html5Converter) inlineMenu(i *Inline) string {
+	menu := attrString(i.abstractNode, "menu")
+	submenus, _ := i.Attr("submenu", nil, false).([]string)
+	menuitem := attrString(i.abstractNode, "menuitem")
+	if len(submenus) == 0 && menuitem == "" {
+		return `<span class="menu">` + menu + "</span>"
+	}
+	res := `<span class="menuseq"><span class="menu">` + menu + "</span>&#160;&#9656; "
+	for _, submenu := range submenus {
+		res = res + `<span class="submenu">` + submenu + "</span>&#160;&#9656; "
+	}
+	return res + `<span class="menuitem">` + menuitem + "</span></span>"
+}
+
+/* The opening and closing marks of each type of quoted text,
+and whether they are tags (which can hold a class) */
+var html5QuoteTags = map[string][3]string{
+	"emphasis":    {"<em>", "</em>", "tag"},
+	"strong":      {"<strong>", "</strong>", "tag"},
+	"monospaced":  {"<code>", "</code>", "tag"},
+	"superscript": {"<sup>", "</sup>", "tag"},
+	"subscript":   {"<sub>", "</sub>", "tag"},
+	"mark":        {"<mark>", "</mark>", "tag"},
+	"double":      {"&#8220;", "&#8221;", ""},
+	"single":      {"&#8216;", "&#8217;", ""},
+	"asciimath":   {"\\$", "\\$", ""},
+	"latexmath":   {"\\(", "\\)", ""},
+}
+
+func (c *html5Converter) inlineQuoted(i *Inline) string {
+	tags := html5QuoteTags[strings.ToLower(i.Type())]
+	open, close, isTag := tags[0], tags[1], tags[2] != ""
+	res := open + i.Text() + close
+	if role, _ := i.Role().(string); role != "" {
+		if isTag {
+			res = fmt.Sprintf(`%s class="%s">%s%s`, open[:len(open)-1], escapeAttribute(role), i.Text(), close)
+		} else {
+			res = fmt.Sprintf(`<span class="%s">%s</span>`, escapeAttribute(role), res)
+		}
+	}
+	if i.Id() != "" {
+		res = fmt.Sprintf(`<a id="%s"></a>%s`, escapeAttribute(i.Id()), res)
+	}
+	return res
+}
+
+// The converted blocks of a list item, without the trailing new line
+func itemContent(item *ListItem) string {
+	return strings.TrimSuffix(item.Content(), "\n")
+}
+
+// Check if a description list item holds a description
+func hasDescription(item *ListItem) bool {
+	return item.HasText() || item.HasBlocks()
+}
+
+// The text and the blocks of a list item, each one on its own line
+func itemBody(item *ListItem, res []string) []string {
+	if item.HasText() {
+		res = append(res, fmt.Sprintf("<p>%s</p>", item.Text()))
+	}
+	if item.HasBlocks() {
+		res = append(res, itemContent(item))
+	}
+	return res
+}
+
+func (c *html5Converter) ulist(l *List) string {
+	class := []string{"ulist"}
+	ulClass := ""
+	markerChecked, markerUnchecked := "", ""
+	checklist := l.HasOption("checklist")
+	if checklist {
+		class = append(class, "checklist")
+		ulClass = ` class="checklist"`
+		if l.HasOption("interactive") {
+			if voidElementSlash(l.abstractNode) != "" {
+				markerChecked = `<input type="checkbox" data-item-complete="1" checked="checked"/> `
+				markerUnchecked = `<input type="checkbox" data-item-complete="0"/> `
+			} else {
+				markerChecked = `<input type="checkbox" data-item-complete="1" checked> `
+				markerUnchecked = `<input type="checkbox" data-item-complete="0"> `
+			}
+		} else if l.Document() != nil && l.Document().Attr("icons", nil, false) == "font" {
+			markerChecked = `<i class="fa fa-check-square-o"></i> `
+			markerUnchecked = `<i class="fa fa-square-o"></i> `
+		} else {
+			markerChecked = "&#10003; "
+			markerUnchecked = "&#10063; "
+		}
+	} else if l.Style() != "" {
+		ulClass = fmt.Sprintf(` class="%s"`, escapeAttribute(l.Style()))
+	}
+	if l.Style() != "" {
+		class = append(class, l.Style())
+	}
+	res := []string{fmt.Sprintf("<div%s class=\"%s\">", idAttribute(l.abstractNode), classes(l.abstractNode, class...))}
+	res = append(res, strings.TrimSuffix(titleElement(l.abstractBlock, false), "\n"), fmt.Sprintf("<ul%s>", ulClass))
+	for _, item := range l.Items() {
+		res = append(res, "<li>")
+		marker := ""
+		if checklist && item.HasAttr("checkbox", nil, false) {
+			marker = markerUnchecked
+			if item.HasAttr("checked", nil, false) {
+				marker = markerChecked
+			}
+		}
+		res = append(res, fmt.Sprintf("<p>%s%s</p>", marker, item.Text()))
+		if item.HasBlocks() {
+			res = append(res, itemContent(item))
+		}
+		res = append(res, "</li>")
+	}
+	res = append(res, "</ul>", "</div>")
+	return joinLines(res)
+}
+
+func (c *html5Converter) olist(l *List) string {
+	class := []string{"olist"}
+	if l.Style() != "" {
+		class = append(class, l.Style())
+	}
+	res := []string{fmt.Sprintf("<div%s class=\"%s\">", idAttribute(l.abstractNode), classes(l.abstractNode, class...))}
+	res = append(res, strings.TrimSuffix(titleElement(l.abstractBlock, false), "\n"))
+	attrs := ""
+	if keyword := l.listMarkerKeyword(l.Style()); keyword != 0 {
+		attrs = fmt.Sprintf(` type="%c"`, keyword)
+	}
+	if start := attrString(l.abstractNode, "start"); start != "" {
+		attrs = attrs + fmt.Sprintf(` start="%s"`, escapeAttribute(start))
+	}
+	if l.HasOption("reversed") {
+		if voidElementSlash(l.abstractNode) != "" {
+			attrs = attrs + ` reversed="reversed"`
+		} else {
+			attrs = attrs + " reversed"
+		}
+	}
+	res = append(res, fmt.Sprintf("<ol class=\"%s\"%s>", l.Style(), attrs))
+	for _, item := range l.Items() {
+		res = append(res, "<li>", fmt.Sprintf("<p>%s</p>", item.Text()))
+		if item.HasBlocks() {
+			res = append(res, itemContent(item))
+		}
+		res = append(res, "</li>")
+	}
+	res = append(res, "</ol>", "</div>")
+	return joinLines(res)
+}
+
+func (c *html5Converter) dlist(l *List) string {
+	var class []string
+	switch l.Style() {
+	case "qanda":
+		class = []string{"qlist", "qanda"}
+	case "horizontal":
+		class = []string{"hdlist"}
+	default:
+		class = []string{"dlist"}
+		if l.Style() != "" {
+			class = append(class, l.Style())
+		}
+	}
+	slash := voidElementSlash(l.abstractNode)
+	res := []string{fmt.Sprintf("<div%s class=\"%s\">", idAttribute(l.abstractNode), classes(l.abstractNode, class...))}
+	res = append(res, strings.TrimSuffix(titleElement(l.abstractBlock, false), "\n"))
+	switch l.Style() {
+	case "qanda":
+		res = append(res, "<ol>")
+		for _, item := range l.Items() {
+			res = append(res, "<li>")
+			for _, term := range item.Terms() {
+				res = append(res, fmt.Sprintf("<p><em>%s</em></p>", term.Text()))
+			}
+			res = append(itemBody(item, res), "</li>")
+		}
+		res = append(res, "</ol>")
+	case "horizontal":
+		res = append(res, "<table>")
+		labelWidth, itemWidth := attrString(l.abstractNode, "labelwidth"), attrString(l.abstractNode, "itemwidth")
+		if labelWidth != "" || itemWidth != "" {
+			res = append(res, "<colgroup>")
+			for _, width := range []string{labelWidth, itemWidth} {
+				style := ""
+				if width != "" {
+					style = fmt.Sprintf(` style="width: %s%%;"`, strings.TrimSuffix(width, "%"))
+				}
+				res = append(res, fmt.Sprintf("<col%s%s>", style, slash))
+			}
+			res = append(res, "</colgroup>")
+		}
+		strong := ""
+		if l.HasOption("strong") {
+			strong = " strong"
+		}
+		for _, item := range l.Items() {
+			res = append(res, "<tr>", fmt.Sprintf("<td class=\"hdlist1%s\">", strong))
+			for i, term := range item.Terms() {
+				res = append(res, term.Text())
+				if i < len(item.Terms())-1 {
+					res = append(res, fmt.Sprintf("<br%s>", slash))
+				}
+			}
+			res = append(res, "</td>", "<td class=\"hdlist2\">")
+			res = append(itemBody(item, res), "</td>", "</tr>")
+		}
+		res = append(res, "</table>")
+	default:
+		res = append(res, "<dl>")
+		dtClass := ` class="hdlist1"`
+		if l.Style() != "" {
+			dtClass = ""
+		}
+		for _, item := range l.Items() {
+			for _, term := range item.Terms() {
+				res = append(res, fmt.Sprintf("<dt%s>%s</dt>", dtClass, term.Text()))
+			}
+			if hasDescription(item) {
+				res = append(itemBody(item, append(res, "<dd>")), "</dd>")
+			}
+		}
+		res = append(res, "</dl>")
+	}
+	res = append(res, "</div>")
+	return joinLines(res)
+}
+
+func (c *html5Converter) colist(l *List) string {
+	class := []string{"colist"}
+	if l.Style() != "" {
+		class = append(class, l.Style())
+	}
+	res := []string{fmt.Sprintf("<div%s class=\"%s\">", idAttribute(l.abstractNode), classes(l.abstractNode, class...))}
+	res = append(res, strings.TrimSuffix(titleElement(l.abstractBlock, false), "\n"))
+	if l.Document() != nil && l.Document().HasAttr("icons", nil, false) {
+		res = append(res, "<table>")
+		fontIcons := l.Document().Attr("icons", nil, false) == "font"
+		for i, item := range l.Items() {
+			num := fmt.Sprintf("%d", i+1)
+			numElement := fmt.Sprintf(`<img src="%s" alt="%s"%s>`, escapeAttribute(l.IconUri("callouts/"+num)), num, voidElementSlash(l.abstractNode))
+			if fontIcons {
+				numElement = fmt.Sprintf(`<i class="conum" data-value="%s"></i><b>%s</b>`, num, num)
+			}
+			res = append(res, "<tr>", fmt.Sprintf("<td>%s</td>", numElement), fmt.Sprintf("<td>%s</td>", item.Text()), "</tr>")
+		}
+		res = append(res, "</table>")
+	} else {
+		res = append(res, "<ol>")
+		for _, item := range l.Items() {
+			res = append(res, "<li>", fmt.Sprintf("<p>%s</p>", item.Text()), "</li>")
+		}
+		res = append(res, "</ol>")
+	}
+	res = append(res, "</div>")
+	return joinLines(res)
+}
+
+// The sections of a table, in the order they are converted
+var tableSections = []string{"head", "foot", "body"}
+
+// The rows of a section of a table ("head", "foot" or "body")
+func tableSectionRows(t *Table, section string) [][]*TableCell {
+	switch section {
+	case "head":
+		return t.Rows().Head()
+	case "foot":
+		return t.Rows().Foot()
+	}
+	return t.Rows().Body()
+}
+
+func (c *html5Converter) table(t *Table) string {
+	class := []string{"tableblock", "frame-" + t.Attr("frame", "all", false).(string), "grid-" + t.Attr("grid", "all", false).(string)}
+	styles := []string{}
+	if !t.HasOption("autowidth") {
+		if pcwidth := t.Attr("tablepcwidth", 100, false).(int); pcwidth == 100 {
+			class = append(class, "spread")
+		} else {
+			styles = append(styles, fmt.Sprintf("width: %d%%;", pcwidth))
+		}
+	}
+	if float := attrString(t.abstractNode, "float"); float != "" {
+		styles = append(styles, fmt.Sprintf("float: %s;", float))
+	}
+	style := ""
+	if len(styles) > 0 {
+		style = fmt.Sprintf(` style="%s"`, escapeAttribute(strings.Join(styles, " ")))
+	}
+	res := []string{fmt.Sprintf("<table%s class=\"%s\"%s>", idAttribute(t.abstractNode), classes(t.abstractNode, class...), style)}
+	if t.HasTitle() {
+		res = append(res, fmt.Sprintf("<caption class=\"title\">%s</caption>", t.CaptionedTitle()))
+	}
+	if rowcount, _ := t.Attr("rowcount", 0, false).(int); rowcount > 0 {
+		slash := voidElementSlash(t.abstractNode)
+		res = append(res, "<colgroup>")
+		for _, column := range t.Columns() {
+			if t.HasOption("autowidth") {
+				res = append(res, fmt.Sprintf("<col%s>", slash))
+			} else {
+				res = append(res, fmt.Sprintf("<col style=\"width: %s%%;\"%s>", attrString(column.abstractNode, "colpcwidth"), slash))
+			}
+		}
+		res = append(res, "</colgroup>")
+		bgcolor := ""
+		if t.Document() != nil && t.Document().HasAttr("cellbgcolor", nil, false) {
+			bgcolor = fmt.Sprintf(` style="background-color: %v;"`, t.Document().Attr("cellbgcolor", nil, false))
+		}
+		for _, section := range tableSections {
+			rows := tableSectionRows(t, section)
+			if len(rows) == 0 {
+				continue
+			}
+			res = append(res, fmt.Sprintf("<t%s>", section))
+			for _, row := range rows {
+				res = append(res, "<tr>")
+				for _, cell := range row {
+					res = append(res, c.tableCell(cell, section == "head", bgcolor))
+				}
+				res = append(res, "</tr>")
+			}
+			res = append(res, fmt.Sprintf("</t%s>", section))
+		}
+	}
+	res = append(res, "</table>")
+	return joinLines(res)
+}
+
+// A cell of a table, as a th (header cell) or a td element
+func (c *html5Converter) tableCell(cell *TableCell, head bool, bgcolor string) string {
+	content := ""
+	if head {
+		content = cell.Text()
+	} else {
+		switch cell.Style() {
+		case "asciidoc":
+			content = fmt.Sprintf("<div>%s</div>", strings.Join(cell.Content(), ""))
+		case "verse":
+			content = fmt.Sprintf("<div class=\"verse\">%s</div>", cell.Text())
+		case "literal":
+			content = fmt.Sprintf("<div class=\"literal\"><pre>%s</pre></div>", cell.Text())
+		default:
+			for _, paragraph := range cell.Content() {
+				content = content + fmt.Sprintf("<p class=\"tableblock\">%s</p>", paragraph)
+			}
+		}
+	}
+	tag := "td"
+	if head || cell.Style() == "header" {
+		tag = "th"
+	}
+	spans := ""
+	if cell.Colspan() > 0 {
+		spans = fmt.Sprintf(` colspan="%d"`, cell.Colspan())
+	}
+	if cell.Rowspan() > 0 {
+		spans = spans + fmt.Sprintf(` rowspan="%d"`, cell.Rowspan())
+	}
+	return fmt.Sprintf("<%s class=\"tableblock halign-%s valign-%s\"%s%s>%s</%s>", tag, attrString(cell.abstractNode, "halign"), attrString(cell.abstractNode, "valign"), spans, bgcolor, content, tag)
+}
+
+// Join the lines of an element, skipping the empty ones
+func joinLines(lines []string) string {
+	res := []string{}
+	for _, line := range lines {
+		if line != "" {
+			res = append(res, line)
+		}
+	}
+	return strings.Join(res, "\n")
+}
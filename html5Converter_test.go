@@ -0,0 +1,335 @@
+package asciidocgo
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/VonC/asciidocgo/consts/context"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHtml5Converter(t *testing.T) {
+
+	Convey("An html5Converter converts only known nodes and transforms", t, func() {
+		c := &html5Converter{}
+		_, ok := c.Convert("not a node", "section")
+		So(ok, ShouldBeFalse)
+		doc := NewDocument([]string{}, nil)
+		_, ok = c.Convert(doc, "block_paragraph")
+		So(ok, ShouldBeFalse)
+		_, ok = c.Convert(newBlock(doc.abstractBlock, context.Paragraph, nil), "block_paragraph")
+		So(ok, ShouldBeTrue)
+	})
+
+	Convey("An html5Converter converts a full document", t, func() {
+		lines := []string{"= Doc *Title*", "Doc Writer <doc@example.com>", "v1.0, 2013-01-01: Remark", "",
+			"[[top]]", "Some *strong* and _emphasis_, <<top>>.", "", "== First", "", "content"}
+		doc, _ := NewDocument(lines, map[string]string{"header_footer": "true"}).Parse()
+		res, err := doc.Render()
+		So(err, ShouldBeNil)
+		So(res, ShouldStartWith, "<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"UTF-8\">")
+		So(res, ShouldContainSubstring, "<title>Doc Title</title>")
+		So(res, ShouldContainSubstring, "<h1>Doc <strong>Title</strong></h1>")
+		So(res, ShouldContainSubstring, `<span id="author" class="author">Doc Writer</span><br>`)
+		So(res, ShouldContainSubstring, `<span id="email" class="email"><a href="mailto:doc@example.com">doc@example.com</a></span><br>`)
+		So(res, ShouldContainSubstring, `<span id="revnumber">version 1.0,</span>`)
+		So(res, ShouldContainSubstring, `<span id="revdate">2013-01-01</span>`)
+		So(res, ShouldContainSubstring, `<br><span id="revremark">Remark</span>`)
+		So(res, ShouldContainSubstring, "<div id=\"top\" class=\"paragraph\">\n<p>Some <strong>strong</strong> and <em>emphasis</em>, <a href=\"#top\">[top]</a>.</p>")
+		So(res, ShouldContainSubstring, "<div class=\"sect1\">\n<h2 id=\"_first\">First</h2>\n<div class=\"sectionbody\">")
+		So(res, ShouldContainSubstring, "Version 1.0<br>\n</div>")
+		So(res, ShouldEndWith, "</body>\n</html>")
+		Convey("Void elements are closed with the xml html syntax", func() {
+			doc.setAttr("htmlsyntax", "xml", true)
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, "<meta charset=\"UTF-8\"/>")
+			So(res, ShouldContainSubstring, "Version 1.0<br/>")
+		})
+		Convey("A document links or embeds its stylesheet, and includes its docinfo files", func() {
+			fsys := fstest.MapFS{
+				"docs/css/site.css":        &fstest.MapFile{Data: []byte("p { color: red; }\n")},
+				"docs/docinfo.html":        &fstest.MapFile{Data: []byte(`<meta name="shared">`)},
+				"docs/docinfo-footer.html": &fstest.MapFile{Data: []byte("<script>footer</script>")},
+			}
+			lines := []string{":stylesheet: site.css", ":stylesdir: css", ":docinfo: shared", "", "text"}
+			doc, _ := NewDocument(lines, map[string]string{"header_footer": "true", "safe": "safe", "base_dir": "docs"}).UseFS(fsys).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, "</title>\n<style>\np { color: red; }\n</style>\n<meta name=\"shared\">\n</head>")
+			So(res, ShouldEndWith, "</div>\n<script>footer</script>\n</body>\n</html>")
+			doc.setAttr("linkcss", "", true)
+			res, _ = doc.Render()
+			So(res, ShouldContainSubstring, "</title>\n<link rel=\"stylesheet\" href=\"css/site.css\">\n<meta name=\"shared\">\n</head>")
+		})
+		Convey("An embedded document has no header nor footer", func() {
+			doc.options["header_footer"] = "false"
+			res, _ := doc.Render()
+			So(res, ShouldStartWith, "<h1>Doc <strong>Title</strong></h1>\n<div id=\"top\" class=\"paragraph\">")
+			So(res, ShouldNotContainSubstring, "footer")
+		})
+	})
+
+	Convey("An html5Converter converts blocks", t, func() {
+		lines := []string{"NOTE: a note", "", ".Fig", "image::tiger.png[Tiger, 200]", "",
+			"[source, go]", "----", "x < y", "----", "", "[quote, Someone, Book]", "____", "quoted", "____", "",
+			"[appendix]", "== Extra", "", "****", "side", "****"}
+		doc, _ := NewDocument(lines, nil).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, "<div class=\"admonitionblock note\">\n<table>\n<tr>\n<td class=\"icon\">\n<div class=\"title\">Note</div>\n</td>\n<td class=\"content\">\na note\n</td>")
+		So(res, ShouldContainSubstring, "<div class=\"imageblock\">\n<div class=\"content\">\n<img src=\"tiger.png\" alt=\"Tiger\" width=\"200\">\n</div>\n<div class=\"title\">Figure 1. Fig</div>\n</div>")
+		So(res, ShouldContainSubstring, "<pre class=\"highlight\"><code class=\"language-go\" data-lang=\"go\">x &lt; y</code></pre>")
+		So(res, ShouldContainSubstring, "<div class=\"attribution\">\n&#8212; Someone<br>\n<cite>Book</cite>\n</div>")
+		So(res, ShouldContainSubstring, "<h2 id=\"_extra\">Appendix A: Extra</h2>")
+		Convey("Including verses, and quote or verse paragraphs", func() {
+			lines := []string{"[verse, Poet, Poem]", "____", "The fog comes", "  on *little* cat feet.", "____", "",
+				"[quote, Someone]", "A quoted paragraph.", "", "[verse]", "A verse", "  paragraph."}
+			doc, _ := NewDocument(lines, nil).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldEqual, "<div class=\"verseblock\">\n<pre class=\"content\">The fog comes\n  on <strong>little</strong> cat feet.</pre>\n"+
+				"<div class=\"attribution\">\n&#8212; Poet<br>\n<cite>Poem</cite>\n</div>\n</div>\n"+
+				"<div class=\"quoteblock\">\n<blockquote>\nA quoted paragraph.\n</blockquote>\n<div class=\"attribution\">\n&#8212; Someone\n</div>\n</div>\n"+
+				"<div class=\"verseblock\">\n<pre class=\"content\">A verse\n  paragraph.</pre>\n</div>\n")
+		})
+		So(res, ShouldContainSubstring, "<div class=\"sidebarblock\">\n<div class=\"content\">")
+	})
+
+	Convey("An html5Converter converts inline elements", t, func() {
+		lines := []string{":experimental:", "", "kbd:[Ctrl+T] kbd:[F1] btn:[OK] menu:File[Save] image:tiger.png[Tiger] ((visible)) indexterm:[hidden] ^sup^ ~sub~", "", "menu:Help[]"}
+		doc, _ := NewDocument(lines, nil).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, `<span class="keyseq"><kbd>Ctrl</kbd>+<kbd>T</kbd></span>`)
+		So(res, ShouldContainSubstring, `<kbd>F1</kbd>`)
+		So(res, ShouldContainSubstring, `<b class="button">OK</b>`)
+		So(res, ShouldContainSubstring, `<span class="menuseq"><span class="menu">File</span>&#160;&#9656; <span class="menuitem">Save</span></span>`)
+		So(res, ShouldContainSubstring, `<span class="menu">Help</span>`)
+		So(res, ShouldContainSubstring, `<span class="image"><img src="tiger.png" alt="Tiger"></span>`)
+		So(res, ShouldContainSubstring, `visible  <sup>sup</sup> <sub>sub</sub>`)
+	})
+
+	Convey("An html5Converter escapes the values of the attributes", t, func() {
+		lines := []string{`[role="r\" onclick=\"evil()"]`, "para", "", `image::a.png[alt="x\" onerror=\"alert(2)"]`, "",
+			`link:http://a.org?q="><script>[x]`, "", `[quote, "<b>x</b>", AT&T]`, "____", "quoted", "____"}
+		doc, _ := NewDocument(lines, nil).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldNotContainSubstring, `" onclick="`)
+		So(res, ShouldNotContainSubstring, `" onerror="`)
+		So(res, ShouldNotContainSubstring, `"><script>`)
+		So(res, ShouldContainSubstring, `<div class="paragraph r&quot; onclick=&quot;evil()">`)
+		So(res, ShouldContainSubstring, `<img src="a.png" alt="x&quot; onerror=&quot;alert(2)">`)
+		So(res, ShouldContainSubstring, `<a href="http://a.org?q=&quot;&gt;&lt;script&amp;gt">x</a>;`)
+		So(res, ShouldContainSubstring, "<div class=\"attribution\">\n&#8212; &lt;b&gt;x&lt;/b&gt;<br>\n<cite>AT&amp;T</cite>\n</div>")
+		Convey("Keeping the character references of a substituted value", func() {
+			So(escapeAttribute(`a&amp;b & "c" &#8217; <d>`), ShouldEqual, `a&amp;b &amp; &quot;c&quot; &#8217; &lt;d&gt;`)
+		})
+	})
+
+	Convey("An html5Converter converts lists", t, func() {
+		lines := []string{".Title", "* a", "** nested", "* [x] b", "", "[%reversed,start=3]", "c. three", "",
+			"//", "[qanda]", "Q?:: A", "", "//", "[horizontal%strong,labelwidth=20]", "T1::", "T2:: d", "", "//", "CPU:: brain", "RAM::", "", "<1> co"}
+		doc, _ := NewDocument(lines, nil).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, "<div class=\"ulist checklist\">\n<div class=\"title\">Title</div>\n<ul class=\"checklist\">\n<li>\n<p>a</p>\n<div class=\"ulist\">\n<ul>\n<li>\n<p>nested</p>\n</li>\n</ul>\n</div>\n</li>\n<li>\n<p>&#10003; b</p>\n</li>\n</ul>\n</div>")
+		So(res, ShouldContainSubstring, "<div class=\"olist loweralpha\">\n<ol class=\"loweralpha\" type=\"a\" start=\"3\" reversed>\n<li>\n<p>three</p>")
+		So(res, ShouldContainSubstring, "<div class=\"qlist qanda\">\n<ol>\n<li>\n<p><em>Q?</em></p>\n<p>A</p>\n</li>\n</ol>\n</div>")
+		So(res, ShouldContainSubstring, "<div class=\"hdlist\">\n<table>\n<colgroup>\n<col style=\"width: 20%;\">\n<col>\n</colgroup>\n<tr>\n<td class=\"hdlist1 strong\">\nT1\n<br>\nT2\n</td>\n<td class=\"hdlist2\">\n<p>d</p>\n</td>\n</tr>")
+		So(res, ShouldContainSubstring, "<div class=\"dlist\">\n<dl>\n<dt class=\"hdlist1\">CPU</dt>\n<dd>\n<p>brain</p>\n</dd>\n<dt class=\"hdlist1\">RAM</dt>\n</dl>\n</div>")
+		So(res, ShouldContainSubstring, "<div class=\"colist arabic\">\n<ol>\n<li>\n<p>co</p>\n</li>\n</ol>\n</div>")
+
+		Convey("Checklists and callouts use icons, and reversed is an xml attribute with the xml html syntax", func() {
+			lines := []string{":icons: font", ":htmlsyntax: xml", "", "* [ ] todo", "", "//", "[%interactive]", "* [x] done", "", "//", "[%reversed]", ". one", "", "<1> co"}
+			doc, _ := NewDocument(lines, nil).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, `<p><i class="fa fa-square-o"></i> todo</p>`)
+			So(res, ShouldContainSubstring, `<p><input type="checkbox" data-item-complete="1" checked="checked"/> done</p>`)
+			So(res, ShouldContainSubstring, `<ol class="arabic" reversed="reversed">`)
+			So(res, ShouldContainSubstring, "<table>\n<tr>\n<td><i class=\"conum\" data-value=\"1\"></i><b>1</b></td>\n<td>co</td>\n</tr>\n</table>")
+		})
+	})
+
+	Convey("An html5Converter converts callouts and hard line breaks", t, func() {
+		lines := []string{"----", "a // <1>", `b \<2>`, "----", "<1> one", "", "line +", "break", "", "[%hardbreaks]", "a", "b"}
+		doc, _ := NewDocument(lines, nil).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, "<pre>a <b class=\"conum\">(1)</b>\nb &lt;2&gt;</pre>")
+		So(res, ShouldContainSubstring, "<p>line<br>\nbreak</p>")
+		So(res, ShouldContainSubstring, "<p>a<br>\nb</p>")
+
+		Convey("Callouts use icons, and breaks are closed with the xml html syntax", func() {
+			lines := []string{":icons: font", ":htmlsyntax: xml", ":hardbreaks:", "", "----", "a <1>", "----", "", "c", "d"}
+			doc, _ := NewDocument(lines, nil).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, "<pre>a <i class=\"conum\" data-value=\"1\"></i><b>(1)</b></pre>")
+			So(res, ShouldContainSubstring, "<p>c<br/>\nd</p>")
+		})
+	})
+
+	Convey("An html5Converter converts tables", t, func() {
+		lines := []string{".Sizes", `[cols="1,1a",options="footer",frame=topbot,grid=rows]`, "|===", "|Name |Doc", "", "2+|span",
+			".2+^.>s|rowspan |* item", "l|  lit", "v|verse| f", "|===", "", `[width=50%,float=left]`, "|===", "e|a|b", "|==="}
+		doc, _ := NewDocument(lines, nil).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, "<table class=\"tableblock frame-topbot grid-rows spread\">\n<caption class=\"title\">Table 1. Sizes</caption>\n"+
+			"<colgroup>\n<col style=\"width: 50%;\">\n<col style=\"width: 50%;\">\n</colgroup>\n<thead>\n<tr>\n"+
+			"<th class=\"tableblock halign-left valign-top\">Name</th>\n<th class=\"tableblock halign-left valign-top\">Doc</th>\n</tr>\n</thead>\n"+
+			"<tfoot>\n<tr>\n<td class=\"tableblock halign-left valign-top\"><div class=\"verse\">verse</div></td>\n"+
+			"<td class=\"tableblock halign-left valign-top\"><div><div class=\"paragraph\">\n<p>f</p>\n</div></div></td>\n</tr>\n</tfoot>\n<tbody>\n")
+		So(res, ShouldContainSubstring, "<td class=\"tableblock halign-left valign-top\" colspan=\"2\"><p class=\"tableblock\">span</p></td>")
+		So(res, ShouldContainSubstring, "<td class=\"tableblock halign-center valign-bottom\" rowspan=\"2\"><p class=\"tableblock\"><strong>rowspan</strong></p></td>\n"+
+			"<td class=\"tableblock halign-left valign-top\"><div><div class=\"ulist\">\n<ul>\n<li>\n<p>item</p>\n</li>\n</ul>\n</div></div></td>")
+		So(res, ShouldContainSubstring, "<td class=\"tableblock halign-left valign-top\"><div class=\"literal\"><pre>  lit</pre></div></td>")
+		So(res, ShouldContainSubstring, "<table class=\"tableblock frame-all grid-all\" style=\"width: 50%; float: left;\">")
+		So(res, ShouldContainSubstring, "<tr>\n<td class=\"tableblock halign-left valign-top\"><p class=\"tableblock\"><em>a</em></p></td>")
+	})
+
+	Convey("An html5Converter converts the table of contents", t, func() {
+		tocLines := func(entries ...string) []string {
+			return append(append([]string{"= Title"}, entries...), "", "Preamble.", "", "toc::[levels=3]", "", "== One", "", "=== Two", "", "==== Three")
+		}
+		outline := "<ul class=\"sectlevel1\">\n<li><a href=\"#_one\">One</a>\n<ul class=\"sectlevel2\">\n<li><a href=\"#_two\">Two</a></li>\n</ul>\n</li>\n</ul>"
+		Convey("In the header, by default", func() {
+			doc, _ := NewDocument(tocLines(":toc:"), map[string]string{"header_footer": "true"}).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, "<body class=\"article\">\n<div id=\"header\">\n<h1>Title</h1>\n<div id=\"toc\" class=\"toc\">\n<div id=\"toctitle\">Table of Contents</div>\n"+outline+"\n</div>\n</div>")
+			So(res, ShouldContainSubstring, "<!-- toc disabled -->")
+			doc, _ = NewDocument(tocLines(":toc:", ":toc-title: Contents", ":toclevels: 1"), nil).Parse()
+			res, _ = doc.Render()
+			So(res, ShouldStartWith, "<h1>Title</h1>\n<div id=\"toc\" class=\"toc\">\n<div id=\"toctitle\">Contents</div>\n<ul class=\"sectlevel1\">\n<li><a href=\"#_one\">One</a></li>\n</ul>\n</div>\n")
+		})
+		Convey("Beside the content, for a left or right position", func() {
+			doc, _ := NewDocument(tocLines(":toc: left"), map[string]string{"header_footer": "true"}).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, "<body class=\"article toc2 toc-left\">")
+			So(res, ShouldContainSubstring, "<div id=\"toc\" class=\"toc2\">")
+		})
+		Convey("After the preamble", func() {
+			doc, _ := NewDocument(tocLines(":toc: preamble"), nil).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, "<p>Preamble.</p>\n</div>\n<!-- toc disabled -->\n<div id=\"toc\" class=\"toc\">\n<div id=\"toctitle\">Table of Contents</div>\n"+outline+"\n</div>\n<div class=\"sect1\">")
+		})
+		Convey("In place of the toc macro", func() {
+			lines := append(tocLines(":toc: macro", ":sectnums:"), "", "[#nav.menu]", ".Menu", "toc::[]")
+			doc, _ := NewDocument(lines, nil).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, "<p>Preamble.</p>\n</div>\n<div id=\"toc\" class=\"toc\">\n<div id=\"toctitle\" class=\"title\">Table of Contents</div>\n"+
+				"<ul class=\"sectlevel1\">\n<li><a href=\"#_one\">1. One</a>\n<ul class=\"sectlevel2\">\n<li><a href=\"#_two\">1.1. Two</a>\n"+
+				"<ul class=\"sectlevel3\">\n<li><a href=\"#_three\">1.1.1. Three</a></li>\n</ul>\n</li>\n</ul>\n</li>\n</ul>\n</div>\n<div class=\"sect1\">")
+			So(res, ShouldContainSubstring, "<div id=\"nav\" class=\"menu\">\n<div id=\"navtitle\" class=\"title\">Menu</div>\n"+
+				"<ul class=\"sectlevel1\">\n<li><a href=\"#_one\">1. One</a>\n<ul class=\"sectlevel2\">\n<li><a href=\"#_two\">1.1. Two</a></li>")
+			So(res, ShouldNotContainSubstring, "<div id=\"header\">")
+		})
+		Convey("With the section numbers down to sectnumlevels", func() {
+			doc, _ := NewDocument(tocLines(":sectnums:", ":sectnumlevels: 1"), nil).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, "<h2 id=\"_one\">1. One</h2>")
+			So(res, ShouldContainSubstring, "<h3 id=\"_two\">Two</h3>")
+		})
+	})
+
+	Convey("An html5Converter converts cross references", t, func() {
+		xrefLines := func(attributes ...string) []string {
+			return append(attributes, "", "See <<_one>>, <<_one,the first>>, <<table>>, <<anchor>>, <<bib>> and <<missing>>.", "",
+				".Results", "[#table]", "|===", "|a", "|===", "", "== One", "", "Some [[anchor,an anchor]]text.", "",
+				"* [[[bib]]] A book", "", "See xref:other.adoc#frag[] and xref:chapters/two.adoc#[Two].", "", "See <<doc.adoc#anchor>>.")
+		}
+		Convey("To the ids of the document, with the title of their target as default text", func() {
+			doc, _ := NewDocument(xrefLines(), nil).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, `<p>See <a href="#_one">One</a>, <a href="#_one">the first</a>, <a href="#table">Results</a>, <a href="#anchor">an anchor</a>, <a href="#bib">[bib]</a> and <a href="#missing">[missing]</a>.</p>`)
+			messages := doc.Logger().(*MemoryLogger).Messages()
+			So(len(messages), ShouldEqual, 1)
+			So(messages[0].String(), ShouldEqual, "asciidocgo: WARNING: possible invalid reference: missing")
+		})
+		Convey("To other documents, with their output file suffix", func() {
+			doc, _ := NewDocument(xrefLines(), nil).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, `<p>See <a href="other.html#frag">[other#frag]</a> and <a href="chapters/two.html">Two</a>.</p>`)
+			So(res, ShouldContainSubstring, `<p>See <a href="doc.html#anchor">[doc#anchor]</a>.</p>`)
+			doc, _ = NewDocument(xrefLines(":relfileprefix: ../", ":relfilesuffix: /"), nil).Parse()
+			res, _ = doc.Render()
+			So(res, ShouldContainSubstring, `<p>See <a href="../other/#frag">[other#frag]</a> and <a href="../chapters/two/">Two</a>.</p>`)
+			Convey("Even without fragment, if the path ends with a document suffix", func() {
+				doc, _ := NewDocument([]string{":docfilesuffix: .txt", "", "See xref:other.adoc[], xref:notes.txt[Notes] and <<id.x>>."}, nil).Parse()
+				res, _ := doc.Render()
+				So(res, ShouldContainSubstring, `<p>See <a href="other.html">[other]</a>, <a href="notes.html">Notes</a> and <a href="#id.x">[id.x]</a>.</p>`)
+				messages := doc.Logger().(*MemoryLogger).Messages()
+				So(len(messages), ShouldEqual, 1)
+				So(messages[0].Text, ShouldEqual, "possible invalid reference: id.x")
+			})
+		})
+		Convey("To the current document as internal references", func() {
+			doc, _ := NewDocument(xrefLines(":docname: doc"), nil).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, `<p>See <a href="#anchor">an anchor</a>.</p>`)
+		})
+	})
+
+	Convey("An html5Converter converts footnotes", t, func() {
+		lines := []string{"A footnote:[First *note*.] and footnoteref:[disc,Disclaimer, with comma.] again footnoteref:[disc] and footnoteref:[nope].",
+			"", "[cols=\"1a\"]", "|===", "|Cell footnote:[In a cell.]", "|==="}
+		footnotes := "<div id=\"footnotes\">\n<hr>\n" +
+			"<div class=\"footnote\" id=\"_footnote_1\">\n<a href=\"#_footnoteref_1\">1</a>. First <strong>note</strong>.\n</div>\n" +
+			"<div class=\"footnote\" id=\"_footnote_2\">\n<a href=\"#_footnoteref_2\">2</a>. Disclaimer, with comma.\n</div>\n" +
+			"<div class=\"footnote\" id=\"_footnote_3\">\n<a href=\"#_footnoteref_3\">3</a>. In a cell.\n</div>\n</div>"
+		Convey("Numbered in sequence, and referenced again by their id", func() {
+			doc, _ := NewDocument(lines, nil).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, `<p>A <span class="footnote">[<a id="_footnoteref_1" class="footnote" href="#_footnote_1" title="View footnote.">1</a>]</span>`+
+				` and <span class="footnote" id="_footnote_disc">[<a id="_footnoteref_2" class="footnote" href="#_footnote_2" title="View footnote.">2</a>]</span>`+
+				` again <span class="footnoteref">[<a class="footnote" href="#_footnote_2" title="View footnote.">2</a>]</span>`+
+				` and <span class="footnoteref red" title="Unresolved footnote reference.">[nope]</span>.</p>`)
+			So(res, ShouldContainSubstring, `<p>Cell <span class="footnote">[<a id="_footnoteref_3" class="footnote" href="#_footnote_3" title="View footnote.">3</a>]</span></p>`)
+			So(res, ShouldEndWith, "</table>\n"+footnotes+"\n")
+			So(doc.Logger().(*MemoryLogger).Messages()[0].Text, ShouldEqual, "invalid footnote reference: nope")
+			again, _ := doc.Render()
+			So(again, ShouldEqual, res)
+		})
+		Convey("At the end of the content of a full document, unless nofootnotes is set", func() {
+			doc, _ := NewDocument(lines, map[string]string{"header_footer": "true"}).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, "</table>\n</div>\n"+footnotes+"\n<div id=\"footer\">")
+			doc, _ = NewDocument(append([]string{":nofootnotes:"}, lines...), nil).Parse()
+			res, _ = doc.Render()
+			So(res, ShouldNotContainSubstring, `<div id="footnotes">`)
+		})
+		Convey("Including the footnotes of the section titles, numbered in the order of the document", func() {
+			doc, _ := NewDocument([]string{"== About footnote:[Title note.]", "", "Body footnote:[Body note.]"}, nil).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, `About <span class="footnote">[<a id="_footnoteref_1" class="footnote" href="#_footnote_1" title="View footnote.">1</a>]</span></h2>`)
+			So(res, ShouldContainSubstring, `<p>Body <span class="footnote">[<a id="_footnoteref_2" class="footnote" href="#_footnote_2" title="View footnote.">2</a>]</span></p>`)
+			So(res, ShouldEndWith, "<div class=\"footnote\" id=\"_footnote_1\">\n<a href=\"#_footnoteref_1\">1</a>. Title note.\n</div>\n"+
+				"<div class=\"footnote\" id=\"_footnote_2\">\n<a href=\"#_footnoteref_2\">2</a>. Body note.\n</div>\n</div>\n")
+			again, _ := doc.Render()
+			So(again, ShouldEqual, res)
+		})
+	})
+
+	Convey("An html5Converter converts the index section", t, func() {
+		lines := []string{":sectnums:", "", "Some ((Tigers)).", "", "== Big cats", "", "The (((Big cats,Lions))) and indexterm2:[zebras].",
+			"", "== Others", "", "((Tigers)) and (((Big cats,Lions))) and (((42))).", "", "[index]", "== Index"}
+		doc, _ := NewDocument(lines, nil).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, `<p>Some <a id="_indexterm_1"></a>Tigers.</p>`)
+		So(res, ShouldContainSubstring, `<p>The <a id="_indexterm_2"></a> and <a id="_indexterm_3"></a>zebras.</p>`)
+		So(res, ShouldEndWith, "<h2 id=\"_index\">Index</h2>\n<div class=\"sectionbody\">\n<div class=\"index\">\n"+
+			"<div class=\"indexcategory\">\n<div class=\"title\">@</div>\n<ul>\n<li>42, <a href=\"#_indexterm_6\">2. Others</a></li>\n</ul>\n</div>\n"+
+			"<div class=\"indexcategory\">\n<div class=\"title\">B</div>\n<ul>\n<li>Big cats\n<ul>\n"+
+			"<li>Lions, <a href=\"#_indexterm_2\">1. Big cats</a>, <a href=\"#_indexterm_5\">2. Others</a></li>\n</ul>\n</li>\n</ul>\n</div>\n"+
+			"<div class=\"indexcategory\">\n<div class=\"title\">T</div>\n<ul>\n"+
+			"<li>Tigers, <a href=\"#_indexterm_1\">[1]</a>, <a href=\"#_indexterm_4\">2. Others</a></li>\n</ul>\n</div>\n"+
+			"<div class=\"indexcategory\">\n<div class=\"title\">Z</div>\n<ul>\n<li>zebras, <a href=\"#_indexterm_3\">1. Big cats</a></li>\n</ul>\n</div>\n"+
+			"</div>\n</div>\n</div>\n")
+
+		Convey("Wherever the index section is, with the terms of the section titles", func() {
+			lines := []string{"[index]", "== Index", "", "== About ((Wolves))", "", "Some ((Tigers))."}
+			doc, _ := NewDocument(lines, nil).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldStartWith, "<div class=\"sect1\">\n<h2 id=\"_index\">Index</h2>\n<div class=\"sectionbody\">\n<div class=\"index\">\n"+
+				"<div class=\"indexcategory\">\n<div class=\"title\">T</div>\n<ul>\n<li>Tigers, <a href=\"#_indexterm_2\">About Wolves</a></li>\n</ul>\n</div>\n"+
+				"<div class=\"indexcategory\">\n<div class=\"title\">W</div>\n<ul>\n<li>Wolves, <a href=\"#_indexterm_1\">About Wolves</a></li>\n</ul>\n</div>\n"+
+				"</div>\n</div>\n</div>\n")
+			So(res, ShouldContainSubstring, `<h2 id="_about_wolves">About <a id="_indexterm_1"></a>Wolves</h2>`)
+			So(res, ShouldContainSubstring, `<p>Some <a id="_indexterm_2"></a>Tigers.</p>`)
+			again, _ := doc.Render()
+			So(again, ShouldEqual, res)
+		})
+	})
+}
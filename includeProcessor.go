@@ -0,0 +1,95 @@
+package asciidocgo
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/VonC/asciidocgo/consts/severity"
+)
+
+/* An include processor provides the content of the include directives
+whose target it handles ('include::db:snippet-7[]'), instead of the
+file system.
+Handles checks if the processor provides the content of target.
+Process pushes the content of target onto reader (Reader.PushInclude),
+or reports why it cannot, with the logger of document.
+document   - the Document being parsed
+reader     - the Reader of the document, the directive being consumed
+target     - the String target of the directive, attributes substituted
+attributes - the parsed attributes of the directive
+An include processor is only used if the safe mode of the document is
+below SECURE (in which an include directive becomes a link). */
+type IncludeProcessor interface {
+	Handles(target string) bool
+	Process(document *Document, reader *Reader, target string, attributes map[string]interface{})
+}
+
+/* An include processor providing the contents of a map, by name,
+for the targets made of a prefix and of a name
+ Examples
+   NewMapIncludeProcessor("db:", map[string]string{"snippet-7": "..."})
+   handles 'include::db:snippet-7[]' */
+type mapIncludeProcessor struct {
+	prefix   string
+	contents map[string]string
+}
+
+/* Initialize an include processor for the targets 'prefix+name', name
+being a key of contents */
+func NewMapIncludeProcessor(prefix string, contents map[string]string) IncludeProcessor {
+	return &mapIncludeProcessor{prefix, contents}
+}
+
+func (mip *mapIncludeProcessor) Handles(target string) bool {
+	_, ok := mip.contents[strings.TrimPrefix(target, mip.prefix)]
+	return strings.HasPrefix(target, mip.prefix) && ok
+}
+
+func (mip *mapIncludeProcessor) Process(document *Document, reader *Reader, target string, attributes map[string]interface{}) {
+	reader.PushInclude(mip.contents[strings.TrimPrefix(target, mip.prefix)], "", target, attributes)
+}
+
+/* An include processor reading the files of a file system (fs.FS),
+for the targets made of a prefix and of a path in that file system
+(fs.FS paths cannot escape the file system, which acts as a jail).
+The include directives of such a file are processed like any other:
+their targets need the prefix (and a full path) to be read from the
+same file system. */
+type fsIncludeProcessor struct {
+	prefix string
+	fsys   fs.FS
+}
+
+/* Initialize an include processor for the targets 'prefix+path', path
+being a valid path of a file of fsys (with an empty prefix, it handles
+all the targets which are files of fsys) */
+func NewFSIncludeProcessor(prefix string, fsys fs.FS) IncludeProcessor {
+	return &fsIncludeProcessor{prefix, fsys}
+}
+
+func (fip *fsIncludeProcessor) Handles(target string) bool {
+	if !strings.HasPrefix(target, fip.prefix) {
+		return false
+	}
+	name := strings.TrimPrefix(target, fip.prefix)
+	if !fs.ValidPath(name) {
+		return false
+	}
+	info, err := fs.Stat(fip.fsys, name)
+	return err == nil && !info.IsDir()
+}
+
+func (fip *fsIncludeProcessor) Process(document *Document, reader *Reader, target string, attributes map[string]interface{}) {
+	name := strings.TrimPrefix(target, fip.prefix)
+	data, err := fs.ReadFile(fip.fsys, name)
+	if err != nil {
+		logMessage(document.Logger(), severity.ERROR, fmt.Sprintf("include file not readable: %v", target), reader.Cursor())
+		return
+	}
+	content, err := decodeIncludeData(data, attributeString(attributes, "encoding"))
+	if err != nil {
+		logMessage(document.Logger(), severity.WARN, fmt.Sprintf("%v, reading include file as UTF-8: %v", err, target), reader.Cursor())
+	}
+	reader.PushInclude(content, "", target, attributes)
+}
@@ -0,0 +1,77 @@
+package asciidocgo
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/VonC/asciidocgo/consts/severity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIncludeProcessor(t *testing.T) {
+
+	Convey("A map include processor handles the names of its map, with its prefix", t, func() {
+		mip := NewMapIncludeProcessor("db:", map[string]string{"a": "A", "b": "B"})
+		So(mip.Handles("db:a"), ShouldBeTrue)
+		So(mip.Handles("db:c"), ShouldBeFalse)
+		So(mip.Handles("a"), ShouldBeFalse)
+		So(NewMapIncludeProcessor("", map[string]string{"a": "A"}).Handles("a"), ShouldBeTrue)
+
+		Convey("And pushes their content", func() {
+			doc := NewDocument([]string{"include::db:b[]", "include::db:a[]"}, map[string]string{"safe": "safe"})
+			doc.UseExtensions(NewRegistry().IncludeProcessor(mip))
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{"B", "A"})
+		})
+		Convey("But not a content which includes itself", func() {
+			mip := NewMapIncludeProcessor("db:", map[string]string{"s7": "S7\ninclude::db:s7[]", "a": "include::db:b[]", "b": "include::db:a[]"})
+			doc := NewDocument([]string{"include::db:s7[]", "include::db:a[]"}, map[string]string{"safe": "safe"})
+			doc.UseExtensions(NewRegistry().IncludeProcessor(mip))
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{"S7", "Unresolved directive in db:s7 - include::db:s7[]", "Unresolved directive in db:b - include::db:a[]"})
+			messages := doc.Logger().(*MemoryLogger).Messages()
+			So(len(messages), ShouldEqual, 2)
+			So(messages[0].String(), ShouldEqual, "asciidocgo: ERROR: db:s7: line 2: circular include: db:s7")
+			So(messages[1].String(), ShouldEqual, "asciidocgo: ERROR: db:b: line 1: circular include: db:a")
+		})
+	})
+
+	Convey("A fs.FS include processor handles the files of its file system", t, func() {
+		fsys := fstest.MapFS{
+			"docs/part.adoc":  &fstest.MapFile{Data: []byte("Part.\ninclude::fs:docs/note.adoc[]")},
+			"docs/note.adoc":  &fstest.MapFile{Data: []byte("Note.")},
+			"docs/latin1.txt": &fstest.MapFile{Data: []byte{'c', 'a', 'f', 0xe9}},
+		}
+		fip := NewFSIncludeProcessor("fs:", fsys)
+		So(fip.Handles("fs:docs/part.adoc"), ShouldBeTrue)
+		So(fip.Handles("fs:docs"), ShouldBeFalse)
+		So(fip.Handles("fs:docs/missing.adoc"), ShouldBeFalse)
+		So(fip.Handles("fs:../docs/part.adoc"), ShouldBeFalse)
+		So(fip.Handles("fs:/docs/part.adoc"), ShouldBeFalse)
+		So(fip.Handles("docs/part.adoc"), ShouldBeFalse)
+
+		Convey("And pushes their decoded content, with their own includes", func() {
+			doc := NewDocument([]string{"include::fs:docs/part.adoc[]", "include::fs:docs/latin1.txt[encoding=iso-8859-1]"}, map[string]string{"safe": "safe"})
+			doc.UseExtensions(NewRegistry().IncludeProcessor(fip))
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{"Part.", "Note.", "café"})
+			So(len(doc.Logger().(*MemoryLogger).Messages()), ShouldEqual, 0)
+		})
+		Convey("Or reports the files it cannot read", func() {
+			doc := NewDocument([]string{"include::fs:docs/part.adoc[]"}, map[string]string{"safe": "safe"})
+			doc.UseExtensions(NewRegistry().IncludeProcessor(NewFSIncludeProcessor("fs:", failingFS{fsys})))
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{})
+			messages := doc.Logger().(*MemoryLogger).Messages()
+			So(len(messages), ShouldEqual, 1)
+			So(messages[0].Severity, ShouldEqual, severity.ERROR)
+			So(messages[0].Text, ShouldEqual, "include file not readable: fs:docs/part.adoc")
+		})
+	})
+}
+
+// A file system whose files can be stated, but not read
+type failingFS struct {
+	fstest.MapFS
+}
+
+func (ffs failingFS) ReadFile(name string) ([]byte, error) {
+	return nil, fs.ErrPermission
+}
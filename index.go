@@ -0,0 +1,210 @@
+package asciidocgo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/VonC/asciidocgo/consts/context"
+)
+
+/* A term of the index of a document, with the locations where it occurs
+and its subterms: the secondary terms of a primary term, and the tertiary
+terms of a secondary term.
+ Examples
+   indexterm:[Big cats,Lions]  (the primary term 'Big cats', whose
+                                secondary term 'Lions' has a location)
+   indexterm2:[Tigers] or ((Tigers))  (a visible primary term) */
+type IndexTerm struct {
+	Name      string
+	Locations []*IndexLocation
+	subterms  map[string]*IndexTerm
+}
+
+/* An occurrence of an index term in a document: the id of the anchor
+marking it in the converted document, and the section in which it occurs
+(nil before the first section, or inside a nested document) */
+type IndexLocation struct {
+	Id      string
+	Section *Section
+}
+
+/* A category of the index of a document: the primary terms starting
+with the same letter (the '@' category gathering the terms which do not
+start with a letter) */
+type IndexCategory struct {
+	Name  string
+	Terms []*IndexTerm
+}
+
+func newIndexTerm(name string) *IndexTerm {
+	return &IndexTerm{name, nil, make(map[string]*IndexTerm)}
+}
+
+// The subterms of this term, sorted alphabetically
+func (it *IndexTerm) Subterms() []*IndexTerm {
+	return sortedIndexTerms(it.subterms)
+}
+
+// Get the subterm of this name, added to the subterms if needed
+func (it *IndexTerm) subterm(name string) *IndexTerm {
+	term, ok := it.subterms[name]
+	if !ok {
+		term = newIndexTerm(name)
+		it.subterms[name] = term
+	}
+	return term
+}
+
+// Sort terms alphabetically, ignoring the case (then considering it)
+func sortedIndexTerms(terms map[string]*IndexTerm) []*IndexTerm {
+	res := make([]*IndexTerm, 0, len(terms))
+	for _, term := range terms {
+		res = append(res, term)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		a, b := strings.ToLower(res[i].Name), strings.ToLower(res[j].Name)
+		if a == b {
+			return res[i].Name < res[j].Name
+		}
+		return a < b
+	})
+	return res
+}
+
+/* The name of the category of a primary term: its first letter,
+in upper case, or '@' if it does not start with a letter */
+func indexCategoryName(term string) string {
+	r, _ := utf8.DecodeRuneInString(term)
+	if !unicode.IsLetter(r) {
+		return "@"
+	}
+	return string(unicode.ToUpper(r))
+}
+
+/* The catalog of the index terms of a document, registered (with their
+location) in the order in which the document is converted.
+The documents nested in a document (like the content of an AsciiDoc
+table cell) register their terms in the catalog of their parent */
+type indexCatalog struct {
+	terms     *IndexTerm
+	locations int
+}
+
+func newIndexCatalog() *indexCatalog {
+	return &indexCatalog{newIndexTerm(""), 0}
+}
+
+/* Register an occurrence of index terms: its location is added to the last
+of the terms (a primary term, optionally followed by its secondary term,
+and by the tertiary term of that secondary term; any other term is
+ignored).
+Returns the new location (nil without term) */
+func (ic *indexCatalog) Register(terms []string, section *Section) *IndexLocation {
+	term := ic.terms
+	for i, name := range terms {
+		if name = strings.TrimSpace(name); i >= 3 || name == "" {
+			break
+		}
+		term = term.subterm(name)
+	}
+	if term == ic.terms {
+		return nil
+	}
+	ic.locations++
+	location := &IndexLocation{fmt.Sprintf("_indexterm_%d", ic.locations), section}
+	term.Locations = append(term.Locations, location)
+	return location
+}
+
+// The primary terms, sorted alphabetically
+func (ic *indexCatalog) Terms() []*IndexTerm {
+	return ic.terms.Subterms()
+}
+
+/* Forget the registered terms, before the document is converted
+again */
+func (ic *indexCatalog) Reset() {
+	ic.terms, ic.locations = newIndexTerm(""), 0
+}
+
+/* Get the index terms of this document: the primary terms, sorted
+alphabetically, with their locations and subterms.
+The terms are registered while the document is converted (the ones of
+the section titles included): the index contains the terms of the whole
+document once it is rendered.
+The HTML5 converter converts the index of an index section after the
+rest of the document, wherever that section is.
+ Examples
+   doc.Render()
+   for _, term := range doc.IndexTerms() {
+     fmt.Println(term.Name, len(term.Locations), len(term.Subterms()))
+   } */
+func (d *Document) IndexTerms() []*IndexTerm {
+	return d.index.Terms()
+}
+
+// Get the primary terms of the index of this document, by category
+func (d *Document) IndexCategories() []*IndexCategory {
+	var res []*IndexCategory
+	categories := make(map[string]*IndexCategory)
+	for _, term := range d.IndexTerms() {
+		name := indexCategoryName(term.Name)
+		category, ok := categories[name]
+		if !ok {
+			category = &IndexCategory{Name: name}
+			categories[name] = category
+			res = append(res, category)
+		}
+		category.Terms = append(category.Terms, term)
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+/* Check whether this document (or the document in which it is nested)
+has an index section: a section with the 'index' style */
+func (d *Document) HasIndex() bool {
+	if d.parentDoc != nil {
+		return d.parentDoc.HasIndex()
+	}
+	return findSection(d.abstractBlock, func(s *Section) bool { return s.IsSpecial() && s.SectName() == "index" }) != nil
+}
+
+/* Register an occurrence of index terms in the node of this document
+(see indexCatalog.Register).
+Returns the id of the anchor of the occurrence ("" without term) */
+func (d *Document) registerIndexTerm(terms []string, node AbstractNodable) string {
+	var section *Section
+	if an, ok := node.(*abstractNode); ok {
+		for an != nil && an.Context() != context.Section {
+			an = an.Parent()
+		}
+		if an != nil {
+			section = findSection(d.abstractBlock, func(s *Section) bool { return s.abstractNode == an })
+		}
+	}
+	if location := d.index.Register(terms, section); location != nil {
+		return location.Id
+	}
+	return ""
+}
+
+// Find the first section of block (or of its sections) matching a criteria
+func findSection(block *abstractBlock, matches func(*Section) bool) *Section {
+	for _, child := range block.Sections() {
+		section, ok := child.Node().(*Section)
+		if !ok {
+			continue
+		}
+		if matches(section) {
+			return section
+		}
+		if res := findSection(section.abstractBlock, matches); res != nil {
+			return res
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package asciidocgo
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIndex(t *testing.T) {
+
+	Convey("An index catalog registers the locations of the last of the terms", t, func() {
+		ic := newIndexCatalog()
+		section := newSection(nil, 1, false)
+		So(ic.Register([]string{"Big cats", "Tigers", "Bengal", "ignored"}, section).Id, ShouldEqual, "_indexterm_1")
+		So(ic.Register([]string{"Big cats", " Lions "}, nil).Id, ShouldEqual, "_indexterm_2")
+		So(ic.Register([]string{"zebras"}, nil).Id, ShouldEqual, "_indexterm_3")
+		So(ic.Register([]string{" "}, nil), ShouldBeNil)
+		terms := ic.Terms()
+		So(len(terms), ShouldEqual, 2)
+		So(terms[0].Name, ShouldEqual, "Big cats")
+		So(len(terms[0].Locations), ShouldEqual, 0)
+		subterms := terms[0].Subterms()
+		So(len(subterms), ShouldEqual, 2)
+		So(subterms[0].Name, ShouldEqual, "Lions")
+		So(subterms[1].Subterms()[0].Name, ShouldEqual, "Bengal")
+		So(subterms[1].Subterms()[0].Locations[0].Section, ShouldEqual, section)
+		So(len(subterms[1].Subterms()[0].Subterms()), ShouldEqual, 0)
+
+		Convey("And forgets them before a new conversion", func() {
+			ic.Reset()
+			So(len(ic.Terms()), ShouldEqual, 0)
+			So(ic.Register([]string{"zebras"}, nil).Id, ShouldEqual, "_indexterm_1")
+		})
+	})
+
+	Convey("A Document collects its index terms when converted", t, func() {
+		lines := []string{"Some ((Tigers)).", "", "== Big cats", "", "The (((Big cats,Lions))) and indexterm2:[zebras] and (((42))).",
+			"", "[cols=\"1a\"]", "|===", "|((tigers))", "|==="}
+		doc, _ := NewDocument(lines, nil).Parse()
+		So(doc.HasIndex(), ShouldBeFalse)
+		So(len(doc.IndexTerms()), ShouldEqual, 0)
+		doc.Render()
+		terms := doc.IndexTerms()
+		So(len(terms), ShouldEqual, 5)
+		So(terms[0].Name, ShouldEqual, "42")
+		So(terms[2].Name, ShouldEqual, "Tigers")
+		So(terms[2].Locations[0].Section, ShouldBeNil)
+		So(terms[3].Name, ShouldEqual, "tigers")
+		So(terms[4].Name, ShouldEqual, "zebras")
+		So(terms[1].Subterms()[0].Locations[0].Section.Title(), ShouldEqual, "Big cats")
+		categories := doc.IndexCategories()
+		So(len(categories), ShouldEqual, 4)
+		So(categories[0].Name, ShouldEqual, "@")
+		So(categories[2].Name, ShouldEqual, "T")
+		So(len(categories[2].Terms), ShouldEqual, 2)
+		doc.Render()
+		So(len(doc.IndexTerms()), ShouldEqual, 5)
+
+		Convey("And has an index if it has an index section", func() {
+			doc, _ := NewDocument(append(lines, "", "[index]", "== Index"), nil).Parse()
+			So(doc.HasIndex(), ShouldBeTrue)
+			So(newInnerDocument([]string{}, doc, nil).HasIndex(), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,68 @@
+package asciidocgo
+
+import "github.com/VonC/asciidocgo/consts/context"
+
+/* Methods for managing inline elements in AsciiDoc block,
+such as quoted text, anchors, images or footnotes.
+An Inline is built by the substitutors, and converted right away
+by the renderer of its document, using the 'inline_<context>'
+template name ('inline_quoted', 'inline_anchor', ...). */
+type Inline struct {
+	*abstractNode
+	text       string
+	typeInline string
+	target     string
+}
+
+/* Initialize an Inline node.
+parent  - The parent node (the block the substitutions are applied to).
+context - The Symbol context name for the type of content.
+text    - The String text of the inline element (may be empty).
+opts    - The type, target, id and attributes of the inline element
+(may be nil) */
+func newInline(parent *abstractNode, c context.Context, text string, opts *OptionsInline) *Inline {
+	inline := &Inline{newAbstractNode(parent, c), text, "", ""}
+	if opts != nil {
+		inline.typeInline = opts.typeInline
+		inline.target = opts.target
+		inline.SetId(opts.id)
+		if opts.attributes != nil {
+			inline.UpdateAttributes(opts.attributes)
+		}
+	}
+	return inline
+}
+
+/* The String text of the inline element */
+func (i *Inline) Text() string {
+	return i.text
+}
+
+/* The String type of the inline element ('Strong', 'link', 'xref', ...) */
+func (i *Inline) Type() string {
+	return i.typeInline
+}
+
+/* The String target of the inline element (a link, an image or an id) */
+func (i *Inline) Target() string {
+	return i.target
+}
+
+/* The name of the template rendering this inline element */
+func (i *Inline) TemplateName() string {
+	return "inline_" + i.Context().String()
+}
+
+/* Convert the inline element with the renderer of its document */
+func (i *Inline) Convert() string {
+	return i.Renderer().Render(i.TemplateName(), i, nil)
+}
+
+/* Makes Inline nodes, used by the substitutors to convert
+quoted text, macros and anchors */
+type inlineMaker struct{}
+
+func (im *inlineMaker) NewInline(parent AbstractNodable, c context.Context, text string, opts *OptionsInline) Convertable {
+	node, _ := parent.(*abstractNode)
+	return newInline(node, c, text, opts)
+}
@@ -0,0 +1,46 @@
+package asciidocgo
+
+import (
+	"testing"
+
+	"github.com/VonC/asciidocgo/consts/context"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInline(t *testing.T) {
+
+	Convey("An Inline can be initialized", t, func() {
+		Convey("By default, an Inline has only a context and a text", func() {
+			i := newInline(nil, context.Quoted, "text", nil)
+			So(i.Context(), ShouldEqual, context.Quoted)
+			So(i.Text(), ShouldEqual, "text")
+			So(i.Type(), ShouldEqual, "")
+			So(i.Target(), ShouldEqual, "")
+			So(i.TemplateName(), ShouldEqual, "inline_quoted")
+		})
+		Convey("An Inline can have a type, a target, an id and attributes", func() {
+			opts := &OptionsInline{id: "id", typeInline: "xref", target: "#top", attributes: map[string]interface{}{"refid": "top"}}
+			i := newInline(nil, context.Anchor, "", opts)
+			So(i.Type(), ShouldEqual, "xref")
+			So(i.Target(), ShouldEqual, "#top")
+			So(i.Id(), ShouldEqual, "id")
+			So(i.Attr("refid", nil, false), ShouldEqual, "top")
+		})
+	})
+
+	Convey("An Inline can be converted", t, func() {
+		Convey("Without document, an Inline converts to nothing", func() {
+			So(newInline(nil, context.Quoted, "text", nil).Convert(), ShouldEqual, "")
+		})
+		Convey("With a document, an Inline is converted by the document renderer", func() {
+			doc := NewDocument([]string{}, nil)
+			i := newInline(doc.abstractNode, context.Quoted, "text", &OptionsInline{typeInline: "strong"})
+			So(i.Convert(), ShouldEqual, "<strong>text</strong>")
+		})
+		Convey("The inline maker builds Inline nodes for the substitutors", func() {
+			doc := NewDocument([]string{}, nil)
+			c := (&inlineMaker{}).NewInline(doc.abstractNode, context.Button, "OK", nil)
+			So(c.Convert(), ShouldEqual, `<b class="button">OK</b>`)
+		})
+	})
+}
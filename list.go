@@ -0,0 +1,46 @@
+package asciidocgo
+
+import "github.com/VonC/asciidocgo/consts/context"
+
+/* Methods for managing AsciiDoc lists: unordered (ulist), ordered
+(olist), description (dlist) and callout (colist) lists.
+The items of a list are its blocks.
+The items of a description list are its descriptions, each one holding
+the terms it describes.
+
+Examples
+
+  list = Asciidoctor::List.new(parent, :ulist)
+  list << Asciidoctor::ListItem.new(list, 'foo')
+  list.items.size
+  => 1 */
+type List struct {
+	*abstractBlock
+}
+
+/* Initialize an Asciidoctor::List object.
+parent  - The parent Asciidoc Object.
+context - The Symbol context name for the type of list
+(ulist, olist, dlist or colist) */
+func newList(parent *abstractBlock, c context.Context) *List {
+	ab := newAbstractBlock(parent, c)
+	list := &List{ab}
+	ab.MainNode(list)
+	return list
+}
+
+/* The items of this list */
+func (l *List) Items() []*ListItem {
+	res := []*ListItem{}
+	for _, block := range l.Blocks() {
+		if item, ok := block.Node().(*ListItem); ok {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+/* Check whether this list has any item */
+func (l *List) HasItems() bool {
+	return len(l.Items()) > 0
+}
@@ -0,0 +1,54 @@
+package asciidocgo
+
+import "github.com/VonC/asciidocgo/consts/context"
+
+/* Methods for managing items of an AsciiDoc list.
+A list item has a text (the text following its marker, and the lines
+adjacent to it), and may hold blocks, either nested lists or blocks
+attached with a list continuation ('+').
+An item of a description list is the description of one or several
+terms, themselves list items.
+
+Examples
+
+  list_item = Asciidoctor::ListItem.new(list, 'foo *bar*')
+  list_item.text
+  => "foo <strong>bar</strong>" */
+type ListItem struct {
+	*abstractBlock
+	text   string
+	marker string
+	terms  []*ListItem
+}
+
+/* Initialize an Asciidoctor::ListItem object.
+parent - The parent list of this item.
+text   - The String text of this item (may be empty) */
+func newListItem(parent *abstractBlock, text string) *ListItem {
+	ab := newAbstractBlock(parent, context.ListItem)
+	ab.subs = values(subs[sub.normal])
+	item := &ListItem{ab, text, "", nil}
+	ab.MainNode(item)
+	return item
+}
+
+/* Get the text of this item, with the normal substitutions applied */
+func (li *ListItem) Text() string {
+	return li.ApplySubs(li.text, li.subArray(), false)
+}
+
+/* Check whether this item has any text */
+func (li *ListItem) HasText() bool {
+	return li.text != ""
+}
+
+/* The marker of this item, as found in the source
+('*', '..', '1.', or the callout number) */
+func (li *ListItem) Marker() string {
+	return li.marker
+}
+
+/* The terms described by this item, for an item of a description list */
+func (li *ListItem) Terms() []*ListItem {
+	return li.terms
+}
@@ -0,0 +1,27 @@
+package asciidocgo
+
+import (
+	"testing"
+
+	"github.com/VonC/asciidocgo/consts/context"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestListItem(t *testing.T) {
+
+	Convey("A ListItem can be initialized", t, func() {
+		li := newListItem(nil, "")
+		So(li.Context(), ShouldEqual, context.ListItem)
+		So(li.HasText(), ShouldBeFalse)
+		So(li.Marker(), ShouldEqual, "")
+		So(li.Terms(), ShouldBeNil)
+		So(li.HasBlocks(), ShouldBeFalse)
+	})
+
+	Convey("A ListItem text has the normal substitutions applied", t, func() {
+		doc := NewDocument([]string{}, nil)
+		li := newListItem(doc.abstractBlock, "foo *bar* & <baz>")
+		So(li.HasText(), ShouldBeTrue)
+		So(li.Text(), ShouldEqual, "foo <strong>bar</strong> &amp; &lt;baz&gt;")
+	})
+}
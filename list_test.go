@@ -0,0 +1,29 @@
+package asciidocgo
+
+import (
+	"testing"
+
+	"github.com/VonC/asciidocgo/consts/context"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestList(t *testing.T) {
+
+	Convey("A List can be initialized", t, func() {
+		l := newList(nil, context.Ulist)
+		So(l.Context(), ShouldEqual, context.Ulist)
+		So(l.TemplateName(), ShouldEqual, "block_ulist")
+		So(l.Node(), ShouldEqual, l)
+		So(l.HasItems(), ShouldBeFalse)
+	})
+
+	Convey("A List has its list items as blocks", t, func() {
+		l := newList(nil, context.Olist)
+		l.AppendBlock(newListItem(l.abstractBlock, "a").abstractBlock)
+		l.AppendBlock(newBlock(l.abstractBlock, context.Paragraph, nil).abstractBlock)
+		l.AppendBlock(newListItem(l.abstractBlock, "b").abstractBlock)
+		So(l.HasItems(), ShouldBeTrue)
+		So(len(l.Items()), ShouldEqual, 2)
+		So(l.Items()[1].Text(), ShouldEqual, "b")
+	})
+}
@@ -0,0 +1,93 @@
+package asciidocgo
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/VonC/asciidocgo/consts/severity"
+)
+
+/* A diagnostic message reported while processing a document:
+its severity, its text, and the source file and line it is about
+(File is empty and LineNo is 0 when not known) */
+type LogMessage struct {
+	Severity severity.Severity
+	Text     string
+	File     string
+	LineNo   int
+}
+
+/* Print a message like Asciidoctor does:
+'asciidocgo: WARNING: file.adoc: line 3: text' */
+func (m *LogMessage) String() string {
+	location := ""
+	if m.File != "" {
+		location = m.File + ": "
+	}
+	if m.LineNo > 0 {
+		location = location + fmt.Sprintf("line %d: ", m.LineNo)
+	}
+	return fmt.Sprintf("asciidocgo: %v: %s%s", m.Severity, location, m.Text)
+}
+
+/* A Logger receives the diagnostic messages of a document,
+instead of having them printed (or lost) */
+type Logger interface {
+	Log(message *LogMessage)
+}
+
+/* Report a message to logger (dropped if logger is nil),
+located at cursor (if not nil) */
+func logMessage(logger Logger, s severity.Severity, text string, cursor *Cursor) {
+	if logger == nil {
+		return
+	}
+	message := &LogMessage{Severity: s, Text: text}
+	if cursor != nil {
+		message.File, message.LineNo = cursor.Path(), cursor.LineNo()
+	}
+	logger.Log(message)
+}
+
+/* A Logger keeping the messages in memory, in the order they were reported.
+This is the default logger of a Document */
+type MemoryLogger struct {
+	messages []*LogMessage
+}
+
+func NewMemoryLogger() *MemoryLogger {
+	return &MemoryLogger{[]*LogMessage{}}
+}
+
+func (ml *MemoryLogger) Log(message *LogMessage) {
+	ml.messages = append(ml.messages, message)
+}
+
+/* The messages reported so far */
+func (ml *MemoryLogger) Messages() []*LogMessage {
+	return ml.messages
+}
+
+/* The messages reported so far with at least the severity s */
+func (ml *MemoryLogger) MessagesAtLeast(s severity.Severity) []*LogMessage {
+	res := []*LogMessage{}
+	for _, message := range ml.messages {
+		if message.Severity >= s {
+			res = append(res, message)
+		}
+	}
+	return res
+}
+
+/* A Logger printing each message on its own line (on os.Stderr for instance) */
+type WriterLogger struct {
+	w io.Writer
+}
+
+func NewWriterLogger(w io.Writer) *WriterLogger {
+	return &WriterLogger{w}
+}
+
+func (wl *WriterLogger) Log(message *LogMessage) {
+	fmt.Fprintln(wl.w, message.String())
+}
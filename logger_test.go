@@ -0,0 +1,39 @@
+package asciidocgo
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/VonC/asciidocgo/consts/severity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLogger(t *testing.T) {
+
+	Convey("A LogMessage prints its severity, its location and its text", t, func() {
+		So((&LogMessage{Severity: severity.WARN, Text: "text"}).String(), ShouldEqual, "asciidocgo: WARNING: text")
+		So((&LogMessage{severity.ERROR, "text", "doc.adoc", 3}).String(), ShouldEqual, "asciidocgo: ERROR: doc.adoc: line 3: text")
+		So((&LogMessage{Severity: severity.INFO, Text: "text", LineNo: 2}).String(), ShouldEqual, "asciidocgo: INFO: line 2: text")
+	})
+
+	Convey("A MemoryLogger keeps the messages in order", t, func() {
+		logger := NewMemoryLogger()
+		So(len(logger.Messages()), ShouldEqual, 0)
+		logMessage(logger, severity.WARN, "first", nil)
+		logMessage(logger, severity.ERROR, "second", NewCursor("/a/doc.adoc", "/a", "doc.adoc", 5))
+		logMessage(nil, severity.ERROR, "dropped", nil)
+		So(len(logger.Messages()), ShouldEqual, 2)
+		So(logger.Messages()[1].File, ShouldEqual, "doc.adoc")
+		So(logger.Messages()[1].LineNo, ShouldEqual, 5)
+		So(len(logger.MessagesAtLeast(severity.ERROR)), ShouldEqual, 1)
+		So(logger.MessagesAtLeast(severity.ERROR)[0].Text, ShouldEqual, "second")
+	})
+
+	Convey("A WriterLogger prints each message on its own line", t, func() {
+		var out bytes.Buffer
+		logger := NewWriterLogger(&out)
+		logger.Log(&LogMessage{Severity: severity.WARN, Text: "first"})
+		logger.Log(&LogMessage{Severity: severity.ERROR, Text: "second"})
+		So(out.String(), ShouldEqual, "asciidocgo: WARNING: first\nasciidocgo: ERROR: second\n")
+	})
+}
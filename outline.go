@@ -0,0 +1,75 @@
+package asciidocgo
+
+import (
+	"strconv"
+
+	"github.com/VonC/asciidocgo/consts/regexps"
+)
+
+/* An entry of the outline of a document: one of its sections, and
+the entries of the subsections of that section.
+Title is the converted title of the section, with its caption (like
+'Appendix A: '), but without its number, nor its anchors.
+SectNum is the number of the section ('2.1.'), "" if the section is not
+numbered (or if its level is above the sectnumlevels attribute). */
+type OutlineEntry struct {
+	Id      string
+	Title   string
+	SectNum string
+	Level   int
+	Section *Section
+	Entries []*OutlineEntry
+}
+
+/* Get the outline of this document: the tree of its sections, to build
+a table of contents or a navigation menu.
+ Examples
+   for _, entry := range doc.Outline() {
+     fmt.Println(entry.SectNum, entry.Title, "#"+entry.Id, len(entry.Entries))
+   }
+Returns the entries of the top-level sections (nil without section) */
+func (d *Document) Outline() []*OutlineEntry {
+	return outlineEntries(d.abstractBlock, intAttr(d, "sectnumlevels", 3))
+}
+
+// The outline entries of the sections of block
+func outlineEntries(block *abstractBlock, sectnumlevels int) []*OutlineEntry {
+	var res []*OutlineEntry
+	for _, child := range block.Sections() {
+		section, ok := child.Node().(*Section)
+		if !ok {
+			continue
+		}
+		entry := newOutlineEntry(section, sectnumlevels)
+		entry.Entries = outlineEntries(section.abstractBlock, sectnumlevels)
+		res = append(res, entry)
+	}
+	return res
+}
+
+// The title of the entry, preceded by its section number (if any)
+func (oe *OutlineEntry) numberedTitle() string {
+	if oe.SectNum == "" {
+		return oe.Title
+	}
+	return oe.SectNum + " " + oe.Title
+}
+
+// The outline entry of a section, without the entries of its subsections
+func newOutlineEntry(section *Section, sectnumlevels int) *OutlineEntry {
+	entry := &OutlineEntry{Id: section.Id(), Level: section.Level(), Section: section}
+	entry.Title = regexps.DropAnchorRx.ReplaceAllString(section.CaptionedTitle(), "")
+	if section.IsNumbered() && section.Caption() == "" && section.Level() <= sectnumlevels {
+		entry.SectNum = section.SectNum()
+	}
+	return entry
+}
+
+/* Get the Integer value of an attribute of the document
+(defaultValue if the attribute is not set, or is not a number) */
+func intAttr(doc Documentable, name string, defaultValue int) int {
+	if val, err := strconv.Atoi(stringAttr(doc, name, "")); err == nil {
+		return val
+	}
+	return defaultValue
+}
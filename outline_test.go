@@ -0,0 +1,37 @@
+package asciidocgo
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOutline(t *testing.T) {
+	Convey("A Document has an outline, the tree of its sections", t, func() {
+		lines := []string{"= Title", ":sectnums:", ":sectnumlevels: 2", "", "== One", "", "=== One [[a]]Two", "", "==== Deep", "", "[appendix]", "== App"}
+		doc, _ := NewDocument(lines, nil).Parse()
+		outline := doc.Outline()
+		So(len(outline), ShouldEqual, 2)
+		So(outline[0].Id, ShouldEqual, "_one")
+		So(outline[0].Title, ShouldEqual, "One")
+		So(outline[0].SectNum, ShouldEqual, "1.")
+		So(outline[0].Level, ShouldEqual, 1)
+		So(outline[0].Section, ShouldEqual, doc.Blocks()[0].Node())
+		So(outline[0].Entries[0].Title, ShouldEqual, "One Two")
+		So(outline[0].Entries[0].SectNum, ShouldEqual, "1.1.")
+		So(outline[0].Entries[0].Entries[0].SectNum, ShouldEqual, "")
+		So(len(outline[0].Entries[0].Entries[0].Entries), ShouldEqual, 0)
+		So(outline[1].Title, ShouldEqual, "Appendix A: App")
+		So(outline[1].SectNum, ShouldEqual, "")
+		So(NewDocument([]string{"No section."}, nil).Outline(), ShouldBeNil)
+	})
+
+	Convey("An integer attribute has a default value", t, func() {
+		doc := NewDocument([]string{}, nil)
+		doc.setAttr("toclevels", "3", true)
+		doc.setAttr("sectnumlevels", "none", true)
+		So(intAttr(doc, "toclevels", 2), ShouldEqual, 3)
+		So(intAttr(doc, "sectnumlevels", 3), ShouldEqual, 3)
+		So(intAttr(doc, "missing", 1), ShouldEqual, 1)
+	})
+}
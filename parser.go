@@ -0,0 +1,1618 @@
+package asciidocgo
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/VonC/asciidocgo/consts/compliance"
+	"github.com/VonC/asciidocgo/consts/contentModel"
+	"github.com/VonC/asciidocgo/consts/context"
+	"github.com/VonC/asciidocgo/consts/regexps"
+	"github.com/VonC/asciidocgo/consts/severity"
+)
+
+/* Methods to parse lines of AsciiDoc into an object hierarchy
+representing the structure of the document.
+All methods are stateless: they take the lines to process and the
+node to which the blocks built from those lines should be appended. */
+type parser struct {
+}
+
+func newParser() *parser {
+	return &parser{}
+}
+
+/* The context of each delimited block, by delimiter leader.
+A delimiter can be longer than its leader (e.g. '------'),
+in which case the closing delimiter must be of the same length. */
+var delimitedBlocks = map[string]context.Context{
+	"--":   context.Open,
+	"----": context.Listing,
+	"....": context.Literal,
+	"====": context.Example,
+	"****": context.Sidebar,
+	"____": context.Quote,
+	"++++": context.Pass,
+	"////": context.Comment,
+	"|===": context.Table,
+	",===": context.Table,
+	":===": context.Table,
+	"!===": context.Table,
+}
+
+/* Parse the document header, if any, then the sections and blocks
+of the document body, and append them to the document.
+reader - the Reader holding the AsciiDoc source lines
+doc    - the Document to populate
+returns the first error encountered while parsing, if any */
+func (p *parser) parseDocument(reader *Reader, doc *Document) error {
+	reader.SkipBlankLines()
+	// block attributes above the first block, if there is no header
+	attributes := p.parseDocumentHeader(reader, doc)
+	if !doc.IsNested() {
+		doc.updateTocAttributes()
+	}
+	parents := []*abstractBlock{doc.abstractBlock}
+	for reader.SkipBlankLines(); reader.HasMoreLines(); reader.SkipBlankLines() {
+		for name, value := range p.parseBlockMetadataLines(reader, doc) {
+			attributes[name] = value
+		}
+		cursor := reader.Cursor()
+		if level, title, ok := p.nextSectionTitle(reader); ok {
+			level = levelWithOffset(level, doc)
+			// close the sections of the same or a deeper level
+			for len(parents) > 1 && parents[len(parents)-1].Level() >= level {
+				parents = parents[:len(parents)-1]
+			}
+			parent := parents[len(parents)-1]
+			section := p.initializeSection(parent, level, title, attributes, doc)
+			section.setSourceLocation(cursor)
+			parent.AppendBlock(section.abstractBlock)
+			parents = append(parents, section.abstractBlock)
+			attributes = make(map[string]interface{})
+			continue
+		}
+		parent := parents[len(parents)-1]
+		if block := p.nextBlock(reader, parent, attributes); block != nil {
+			parent.AppendBlock(block)
+		}
+		attributes = make(map[string]interface{})
+	}
+	return nil
+}
+
+/* Shift the level of a section title by the 'leveloffset' attribute
+of the document (set by an attribute entry, or by the leveloffset
+attribute of an include directive).
+The level cannot go below 0 */
+func levelWithOffset(level int, doc *Document) int {
+	offset, _ := strconv.Atoi(fmt.Sprint(doc.Attr("leveloffset", "0", false)))
+	if level += offset; level < 0 {
+		return 0
+	}
+	return level
+}
+
+/* Resolve a leveloffset value relative to the current one,
+when it starts with '+' or '-'
+ Examples
+   resolveLevelOffset("1", "+1")
+   => "2"
+   resolveLevelOffset("1", "0")
+   => "0" */
+func resolveLevelOffset(current, value string) string {
+	if strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-") {
+		currentOffset, _ := strconv.Atoi(current)
+		offset, _ := strconv.Atoi(value)
+		return strconv.Itoa(currentOffset + offset)
+	}
+	return value
+}
+
+/* Build a section from its title and its block attributes,
+giving it a section name, a number and an id.
+parent     - the parent block of the section
+level      - the Integer level of the section
+title      - the String title of the section
+attributes - the block attributes defined above the section title
+(the first positional attribute being the style of a special section,
+like 'appendix')
+doc        - the Document in which the section id is registered */
+func (p *parser) initializeSection(parent *abstractBlock, level int, title string, attributes map[string]interface{}, doc *Document) *Section {
+	numbered := doc.HasAttr("sectnums", nil, false) || doc.HasAttr("numbered", nil, false)
+	section := newSection(parent, level, numbered)
+	section.SetTitle(title)
+	if style, ok := attributes["style"].(string); ok && style != "" {
+		section.SetSectName(style)
+		section.SetSpecial(true)
+		if style == "abstract" && doc.DocType() == "book" {
+			section.SetSectName("sect1")
+			section.SetSpecial(false)
+			section.SetLevel(1)
+		}
+	} else if level == 0 && doc.DocType() == "book" {
+		section.SetSectName("part")
+	} else {
+		section.SetSectName("sect" + strconv.Itoa(level))
+	}
+	if (section.IsSpecial() && section.SectName() != "appendix") || section.SectName() == "part" {
+		section.SetNumbered(false)
+	}
+	if id, ok := attributes["id"].(string); ok && id != "" {
+		section.SetId(id)
+	} else {
+		section.SetId(section.GenerateId())
+	}
+	if section.Id() != "" {
+		reftext, _ := attributes["reftext"].(string)
+		if reftext == "" {
+			reftext = section.Title()
+		}
+		doc.Register("ids", []string{section.Id(), reftext})
+	}
+	for name, value := range attributes {
+		section.setAttr(name, value, true)
+	}
+	return section
+}
+
+/* Parse the lines of metadata above a block or a section:
+block anchors ([[id,reftext]]), attribute lists ([style, ...]) and
+block titles (.Title).
+Attribute entries (:name: value) are stored in the document.
+Blank lines and single-line comments between them are skipped.
+Returns the block attributes: 'id', 'reftext', 'title', 'style',
+the positional attributes ('1', '2', ...) and named attributes */
+func (p *parser) parseBlockMetadataLines(reader *Reader, doc *Document) map[string]interface{} {
+	attributes := make(map[string]interface{})
+	for reader.SkipBlankLines(); reader.HasMoreLines(); reader.SkipBlankLines() {
+		line, _ := reader.PeekLine()
+		if p.processAttributeEntry(reader, doc, nil) {
+			continue
+		}
+		if anchor := regexps.NewBlockAnchorRxres(line); anchor.HasAnyMatch() {
+			if anchor.BlockAnchorId() != "" {
+				attributes["id"] = anchor.BlockAnchorId()
+				if anchor.BlockAnchorRefText() != "" {
+					attributes["reftext"] = anchor.BlockAnchorRefText()
+				}
+			}
+		} else if attrList := regexps.NewBlockAttributeListRxres(line); attrList.HasAnyMatch() {
+			NewAttributeList(attrList.BlockAttributeList(), nil, "").ParseInto(attributes, []string{})
+			parseStyleAttribute(attributes)
+		} else if title := regexps.BlockTitleRx.FindStringSubmatch(line); title != nil {
+			attributes["title"] = title[1]
+		} else if !regexps.CommentLineRx.MatchString(line) {
+			break
+		}
+		reader.Advance()
+	}
+	return attributes
+}
+
+/* Parse the first positional attribute into the style attribute,
+expanding its shorthand notation for the id (#id), the roles (.role)
+and the options (%option).
+ Examples
+   [appendix#install.important%header]
+   # style: appendix, id: install, role: important, header-option
+Returns the style, which is also stored in attributes */
+func parseStyleAttribute(attributes map[string]interface{}) string {
+	first, ok := attributes["1"].(string)
+	if !ok || first == "" || strings.ContainsAny(first, " \t") {
+		return ""
+	}
+	end := strings.IndexAny(first, "#.%")
+	if end < 0 {
+		attributes["style"] = first
+		return first
+	}
+	style := first[:end]
+	if style != "" {
+		attributes["style"] = style
+	}
+	roles := []string{}
+	for rest := first[end:]; rest != ""; {
+		next := strings.IndexAny(rest[1:], "#.%")
+		value := rest[1:]
+		if next >= 0 {
+			value = rest[1 : next+1]
+		}
+		switch rest[0] {
+		case '#':
+			attributes["id"] = value
+		case '.':
+			roles = append(roles, value)
+		case '%':
+			attributes[value+"-option"] = ""
+		}
+		if next < 0 {
+			break
+		}
+		rest = rest[next+1:]
+	}
+	if len(roles) > 0 {
+		attributes["role"] = strings.Join(roles, " ")
+	}
+	return style
+}
+
+/* Parse the document header: the block metadata lines (including
+attribute entries) above the document title, the level-0 section title,
+and the header metadata (author line, revision line and attribute
+entries) which follows it, up to the first blank line.
+Consumes the header lines from the reader, if there is a header.
+Returns the block attributes found above the first line, when it
+is not a document title (they apply to the first block) */
+func (p *parser) parseDocumentHeader(reader *Reader, doc *Document) map[string]interface{} {
+	attributes := p.parseBlockMetadataLines(reader, doc)
+	cursor := reader.Cursor()
+	level, title, ok := p.peekSectionTitle(reader)
+	if !ok || level != 0 {
+		return attributes
+	}
+	p.nextSectionTitle(reader)
+	header := newSection(doc.abstractBlock, 0, false)
+	header.SetTitle(title)
+	header.setSourceLocation(cursor)
+	if id, ok := attributes["id"].(string); ok {
+		header.SetId(id)
+		doc.SetId(id)
+	}
+	doc.header = header
+	assignedDoctitle, _ := doc.Attr("doctitle", "", false).(string)
+	doc.setAttr("doctitle", header.Title(), true)
+	p.parseHeaderMetadata(reader, doc)
+	if assignedDoctitle != "" {
+		// restore doctitle attribute to its original assignment
+		doc.setAttr("doctitle", assignedDoctitle, true)
+	}
+	reader.ReadLinesUntil(isBlankLine)
+	return make(map[string]interface{})
+}
+
+/* Parse the metadata lines which follow the document title:
+the author line, the revision line and attribute entries.
+The author and revision information are stored as document attributes,
+unless they are already defined.
+ Examples
+   = Document Title
+   Doc Writer <doc.writer@asciidoc.org>; Junior Writer <junior@asciidoctor.org>
+   v1.0, 2013-01-01: Ring in the new year release
+   :toc:
+Returns the metadata parsed from the author and revision lines */
+func (p *parser) parseHeaderMetadata(reader *Reader, doc *Document) map[string]string {
+	p.processAttributeEntries(reader, doc)
+	metadata := make(map[string]string)
+	implicitAuthor, implicitAuthors := "", ""
+	if !reader.IsNextLineEmpty() {
+		authorLine, _ := reader.ReadLine()
+		for key, val := range processAuthors(authorLine, false, true) {
+			metadata[key] = val
+			if !doc.HasAttr(key, nil, false) {
+				doc.setAttr(key, doc.ApplyHeaderSubs(val), true)
+			}
+		}
+		implicitAuthor, _ = doc.Attr("author", "", false).(string)
+		implicitAuthors, _ = doc.Attr("authors", "", false).(string)
+		p.processAttributeEntries(reader, doc)
+		if !reader.IsNextLineEmpty() {
+			revLine, _ := reader.PeekLine()
+			if rev := regexps.NewRevisionInfoLineRxres(revLine); rev.HasAnyMatch() {
+				reader.Advance()
+				revMetadata := make(map[string]string)
+				if rev.HasGroup(1) {
+					revMetadata["revnumber"] = rev.RevNumber()
+				}
+				if component := rev.RevDate(); component != "" {
+					// version must begin with 'v' if date is absent
+					if !rev.HasGroup(1) && strings.HasPrefix(component, "v") {
+						revMetadata["revnumber"] = component[1:]
+					} else {
+						revMetadata["revdate"] = component
+					}
+				}
+				if rev.HasGroup(3) {
+					revMetadata["revremark"] = rev.RevRemark()
+				}
+				for key, val := range revMetadata {
+					metadata[key] = val
+					if !doc.HasAttr(key, nil, false) {
+						doc.setAttr(key, doc.ApplyHeaderSubs(val), true)
+					}
+				}
+			}
+		}
+		p.processAttributeEntries(reader, doc)
+	}
+	// process the author attribute entries that override
+	// (or stand in for) the implicit author line
+	var authorMetadata map[string]string
+	if doc.HasAttr("author", nil, false) {
+		if author, _ := doc.Attr("author", "", false).(string); author != implicitAuthor {
+			authorMetadata = processAuthors(author, true, false)
+		}
+	} else if doc.HasAttr("authors", nil, false) {
+		if authors, _ := doc.Attr("authors", "", false).(string); authors != implicitAuthors {
+			authorMetadata = processAuthors(authors, true, true)
+		}
+	} else {
+		// only use the indexed author attributes if a value differs
+		// from the author line
+		entries, explicit := []string{}, false
+		for key := "author_1"; doc.HasAttr(key, nil, false); key = "author_" + strconv.Itoa(len(entries)+1) {
+			entry, _ := doc.Attr(key, "", false).(string)
+			explicit = explicit || entry != metadata[key]
+			entries = append(entries, entry)
+		}
+		if explicit {
+			authorMetadata = processAuthors(strings.Join(entries, "; "), true, len(entries) > 1)
+		}
+	}
+	for key, val := range authorMetadata {
+		doc.setAttr(key, val, true)
+	}
+	if !doc.HasAttr("email", nil, false) && doc.HasAttr("email_1", nil, false) {
+		doc.setAttr("email", doc.Attr("email_1", nil, false), true)
+	}
+	return metadata
+}
+
+/* Parse the author line into a map of author metadata:
+author, authorinitials, firstname, middlename, lastname and email,
+suffixed by the (1-based) index of the author starting at the second
+author ('firstname_2').
+The attributes for the first author are also suffixed ('firstname_1')
+if there are several authors. authorcount and authors (the list of
+author names) are also defined.
+authorLine - the String author line
+namesOnly  - a Boolean indicating whether to only split the names
+(no email is expected)
+multiple   - a Boolean indicating whether the line can hold several
+authors, separated by ';'
+Returns the map of author metadata */
+func processAuthors(authorLine string, namesOnly, multiple bool) map[string]string {
+	metadata := make(map[string]string)
+	keys := []string{"author", "authorinitials", "firstname", "middlename", "lastname", "email"}
+	entries := []string{authorLine}
+	if multiple {
+		entries = strings.Split(authorLine, ";")
+	}
+	for idx, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		keyMap := make(map[string]string)
+		for _, key := range keys {
+			keyMap[key] = key
+			if idx > 0 {
+				keyMap[key] = key + "_" + strconv.Itoa(idx+1)
+			}
+		}
+		var segments []string
+		if namesOnly {
+			segments = strings.Fields(entry)
+			if len(segments) > 3 {
+				segments = append(segments[:2], strings.Join(segments[2:], " "))
+			}
+		} else if match := regexps.AuthorInfoLineRx.FindStringSubmatch(entry); match != nil {
+			segments = match[1:]
+		}
+		if segments != nil {
+			segments = append(segments, "", "", "")
+			fname := strings.Replace(segments[0], "_", " ", -1)
+			metadata[keyMap["firstname"]] = fname
+			metadata[keyMap["author"]] = fname
+			metadata[keyMap["authorinitials"]] = initial(fname)
+			if segments[1] != "" && segments[2] != "" {
+				mname := strings.Replace(segments[1], "_", " ", -1)
+				lname := strings.Replace(segments[2], "_", " ", -1)
+				metadata[keyMap["middlename"]] = mname
+				metadata[keyMap["lastname"]] = lname
+				metadata[keyMap["author"]] = fname + " " + mname + " " + lname
+				metadata[keyMap["authorinitials"]] = initial(fname) + initial(mname) + initial(lname)
+			} else if segments[1] != "" {
+				lname := strings.Replace(segments[1], "_", " ", -1)
+				metadata[keyMap["lastname"]] = lname
+				metadata[keyMap["author"]] = fname + " " + lname
+				metadata[keyMap["authorinitials"]] = initial(fname) + initial(lname)
+			}
+			if !namesOnly && segments[3] != "" {
+				metadata[keyMap["email"]] = segments[3]
+			}
+		} else {
+			fname := strings.Join(strings.Fields(entry), " ")
+			metadata[keyMap["author"]] = fname
+			metadata[keyMap["firstname"]] = fname
+			metadata[keyMap["authorinitials"]] = initial(fname)
+		}
+		metadata["authorcount"] = strconv.Itoa(idx + 1)
+		// only assign the _1 attributes if there are multiple authors
+		if idx == 1 {
+			for _, key := range keys {
+				if val, ok := metadata[key]; ok {
+					metadata[key+"_1"] = val
+				}
+			}
+		}
+		if idx == 0 {
+			metadata["authors"] = metadata[keyMap["author"]]
+		} else {
+			metadata["authors"] = metadata["authors"] + ", " + metadata[keyMap["author"]]
+		}
+	}
+	return metadata
+}
+
+// Get the first character of a name
+func initial(name string) string {
+	for _, r := range name {
+		return string(r)
+	}
+	return ""
+}
+
+/* Process consecutive attribute entries (and comment lines),
+storing them in the document.
+Returns true if any line was consumed */
+func (p *parser) processAttributeEntries(reader *Reader, doc *Document) bool {
+	processed := false
+	for reader.HasMoreLines() {
+		line, _ := reader.PeekLine()
+		if regexps.CommentLineRx.MatchString(line) {
+			reader.Advance()
+		} else if !p.processAttributeEntry(reader, doc, nil) {
+			break
+		}
+		processed = true
+	}
+	return processed
+}
+
+/* Process the attribute entry on the next line of reader, if any.
+A value ending with a line continuation (' +' or ' \') continues
+on the next line.
+ Examples
+   :name: value
+   :name!:
+   :long: a value which continues \
+          on the next line
+The attribute is stored in the document, and in attrs (if not nil).
+Returns true if the line was an attribute entry */
+func (p *parser) processAttributeEntry(reader *Reader, doc *Document, attrs map[string]interface{}) bool {
+	line, _ := reader.PeekLine()
+	entry := regexps.NewAttributeEntryRxres(line)
+	if !entry.HasAnyMatch() {
+		return false
+	}
+	reader.Advance()
+	name, value := entry.AttributeName(), entry.AttributeValue()
+	if continuation := lineContinuation(value); continuation != "" {
+		value = strings.TrimRight(strings.TrimSuffix(value, continuation), " \t")
+		for reader.HasMoreLines() {
+			nextLine, _ := reader.PeekLine()
+			nextLine = strings.TrimSpace(nextLine)
+			if nextLine == "" {
+				break
+			}
+			reader.Advance()
+			keepOpen := strings.HasSuffix(nextLine, continuation)
+			if keepOpen {
+				nextLine = strings.TrimRight(strings.TrimSuffix(nextLine, continuation), " \t")
+			}
+			value = value + " " + nextLine
+			if !keepOpen {
+				break
+			}
+		}
+	}
+	var store SubstDocumentable
+	if doc != nil {
+		store = &substDocument{doc}
+	}
+	p.storeAttribute(name, value, store, attrs)
+	return true
+}
+
+/* Get the line continuation which ends a value ("+" or "\"),
+or "" if the value does not continue on the next line */
+func lineContinuation(value string) string {
+	for _, continuation := range []string{" \\", " +"} {
+		if strings.HasSuffix(value, continuation) {
+			return continuation[1:]
+		}
+	}
+	return ""
+}
+
+/* Store the attribute in the document and in the attributes map.
+A name ending (or starting) with '!' unsets the attribute.
+The 'numbered' attribute is an alias of 'sectnums'.
+name  - the String name of the attribute to store
+value - the String value of the attribute to store
+doc   - the document in which to store the attribute (may be nil)
+attrs - the attributes map in which to store the attribute (may be nil)
+Returns the resolved name and value (value being "" if the attribute
+was unset) */
+func (p *parser) storeAttribute(name string, value string, doc SubstDocumentable, attrs map[string]interface{}) (string, string) {
+	unset := false
+	if strings.HasSuffix(name, "!") {
+		// an unset value signals the attribute should be deleted (undefined)
+		name, unset = name[:len(name)-1], true
+	} else if strings.HasPrefix(name, "!") {
+		name, unset = name[1:], true
+	}
+	name = sanitizeAttributeName(name)
+	if name == "numbered" {
+		name = "sectnums"
+	}
+	if name == "leveloffset" && !unset && doc != nil {
+		current, _ := doc.Attr(name, "0", false).(string)
+		value = resolveLevelOffset(current, value)
+	}
+	if unset {
+		value = ""
+	}
+	accessible := true
+	if store, ok := doc.(attributeStore); ok {
+		if unset {
+			accessible = store.DeleteAttribute(name)
+		} else {
+			accessible = store.SetAttribute(name, value)
+			value, _ = doc.Attr(name, "", false).(string)
+		}
+	}
+	if accessible && attrs != nil {
+		if unset {
+			attrs[name] = nil
+		} else {
+			attrs[name] = value
+		}
+	}
+	return name, value
+}
+
+/* A document in which attributes can be set or unset */
+type attributeStore interface {
+	SetAttribute(name, value string) bool
+	DeleteAttribute(name string) bool
+}
+
+/* Strip the invalid characters from an attribute name,
+and convert it to lowercase
+ Examples
+   sanitizeAttributeName("Foo Bar")
+   => "foobar" */
+func sanitizeAttributeName(name string) string {
+	return strings.ToLower(regexps.InvalidAttributeNameCharsRx.ReplaceAllString(name, ""))
+}
+
+/* Parse the blocks of a compound block (example, sidebar, ...)
+from the lines of reader, and append them to parent.
+Section titles are not allowed in a delimited block:
+they are parsed as paragraphs. */
+func (p *parser) parseBlocks(reader *Reader, parent *abstractBlock) {
+	doc, _ := parent.Document().(*Document)
+	for reader.SkipBlankLines(); reader.HasMoreLines(); reader.SkipBlankLines() {
+		attributes := p.parseBlockMetadataLines(reader, doc)
+		if block := p.nextBlock(reader, parent, attributes); block != nil {
+			parent.AppendBlock(block)
+		}
+	}
+}
+
+/* Check if the next lines of reader are a section title,
+in either the single-line (Atx) or the two-line (Setext) style.
+The two-line style is only recognized if
+compliance.UnderlineStyleSectionTitles() is enabled.
+Returns the level and title of the section, and true if found.
+The reader is left untouched. */
+func (p *parser) peekSectionTitle(reader *Reader) (int, string, bool) {
+	lines := reader.PeekLines(2)
+	if len(lines) == 0 {
+		return -1, "", false
+	}
+	if atx := regexps.NewAtxSectionRxres(lines[0]); atx.HasAnyMatch() {
+		return atx.AtxSectionLevel(), atx.AtxSectionTitle(), true
+	}
+	if len(lines) == 2 && compliance.UnderlineStyleSectionTitles() && !isDelimiterLine(lines[0]) {
+		if level, ok := regexps.SetextSectionLevel(lines[0], lines[1]); ok {
+			return level, lines[0], true
+		}
+	}
+	return -1, "", false
+}
+
+/* Same as peekSectionTitle, but consumes the section title lines */
+func (p *parser) nextSectionTitle(reader *Reader) (int, string, bool) {
+	level, title, ok := p.peekSectionTitle(reader)
+	if ok {
+		if line, _ := reader.ReadLine(); !regexps.AtxSectionRx.MatchString(line) {
+			// skip the underline of a two-line section title
+			reader.Advance()
+		}
+	}
+	return level, title, ok
+}
+
+/* Parse the next block from reader: a delimited block, a table, a list,
+a literal paragraph or a paragraph.
+The block attributes (parsed from the metadata lines above the block)
+are applied to the block.
+Leading blank lines and single-line comments are skipped.
+Returns nil if there is no more block to read, or if the block is
+a comment block. */
+func (p *parser) nextBlock(reader *Reader, parent *abstractBlock, attributes map[string]interface{}) *abstractBlock {
+	for reader.SkipBlankLines(); reader.HasMoreLines(); reader.SkipBlankLines() {
+		line, _ := reader.PeekLine()
+		if regexps.CommentLineRx.MatchString(line) {
+			reader.Advance()
+			continue
+		}
+		cursor := reader.Cursor()
+		if delimitedBlocks[delimiterLeader(line)] == context.Table {
+			table := p.nextTable(reader, parent, attributes)
+			table.setSourceLocation(cursor)
+			applyBlockAttributes(table.abstractBlock, attributes)
+			if table.HasTitle() {
+				table.AssignCaption("", "table")
+			}
+			return table.abstractBlock
+		}
+		if list := p.nextList(reader, parent, line); list != nil {
+			list.setSourceLocation(cursor)
+			applyBlockAttributes(list.abstractBlock, attributes)
+			return list.abstractBlock
+		}
+		if ab, ok := p.nextExtensionBlock(reader, parent, line, attributes); ok {
+			if ab != nil {
+				ab.setSourceLocation(cursor)
+			}
+			return ab
+		}
+		var block *Block
+		switch {
+		case isDelimiterLine(line):
+			block = p.nextDelimitedBlock(reader, parent, attributes)
+		case isLiteralParagraphLine(line):
+			lines := reader.ReadLinesUntil(isBlankLine)
+			block = newBlock(parent, context.Literal, resetBlockIndent(lines))
+		case regexps.BlockImageRx.MatchString(line):
+			reader.Advance()
+			block = p.nextBlockImage(line, parent, attributes)
+		case regexps.BlockTocRx.MatchString(line):
+			reader.Advance()
+			block = newBlock(parent, context.Toc, nil)
+			toc := regexps.NewBlockTocRxres(line)
+			NewAttributeList(block.SubAttributes(toc.BlockTocAttributes(), nil), block, "").ParseInto(attributes, nil)
+		default:
+			lines := p.readParagraphLines(reader)
+			block = newBlock(parent, context.Paragraph, lines)
+			if admonition := regexps.AdmonitionParagraphRx.FindStringSubmatch(line); admonition != nil && len(lines) > 0 {
+				attributes["style"] = admonition[1]
+				lines[0] = lines[0][len(admonition[0]):]
+			}
+			catalogInlineAnchors(strings.Join(lines, "\n"), parent)
+		}
+		if block == nil {
+			return nil
+		}
+		block.setSourceLocation(cursor)
+		if block.HasSub("callouts") && !catalogCallouts(block.Source(), block.Document()) {
+			// no need to look for callouts
+			block.RemoveSub("callouts")
+		}
+		style, _ := attributes["style"].(string)
+		switch style {
+		case "source":
+			rekeyAttributes(attributes, []string{"", "language", "linenums"})
+		case "quote", "verse":
+			rekeyAttributes(attributes, []string{"", "attribution", "citetitle"})
+			if block.Context() == context.Paragraph {
+				// a quote keeps the simple content of the paragraph, a verse its lines
+				if style == "verse" {
+					block.SetContext(context.Verse)
+					block.SetContentModel(contentmodel.Verbatim)
+				} else {
+					block.SetContext(context.Quote)
+				}
+			}
+		}
+		applyBlockAttributes(block.abstractBlock, attributes)
+		if block.Context() == context.Listing && style == "source" {
+			lockInHighlightSub(block)
+		}
+		if isAdmonitionStyle(style) && (block.Context() == context.Paragraph || block.Context() == context.Example) {
+			block.SetContext(context.Admonition)
+			name := strings.ToLower(style)
+			block.setAttr("name", name, true)
+			if doc := block.Document(); doc != nil {
+				block.setAttr("textlabel", doc.Attr(name+"-caption", style, false), true)
+			}
+		}
+		if block.HasTitle() {
+			switch block.Context() {
+			case context.Example, context.Listing:
+				block.AssignCaption("", block.Context().String())
+			case context.Image:
+				block.AssignCaption("", "figure")
+			}
+		}
+		return block.abstractBlock
+	}
+	return nil
+}
+
+/* Parse the next block with an extension of the document, if any
+applies to it:
+ - a block macro processor, for a block macro line ('gist::123[]');
+ - a block processor, for a paragraph or a delimited block whose
+   style is the name of the processor ('[shout]').
+Returns the block built by the extension (nil if it built none),
+and false if no extension applies (the reader is left untouched). */
+func (p *parser) nextExtensionBlock(reader *Reader, parent *abstractBlock, line string, attributes map[string]interface{}) (*abstractBlock, bool) {
+	doc, _ := parent.Document().(*Document)
+	if doc == nil {
+		return nil, false
+	}
+	extensions := doc.Extensions()
+	var res BlockNode
+	if macro := regexps.NewGenericBlockMacroRxres(line); macro.HasAnyMatch() {
+		extension := extensions.blockMacroFor(macro.MacroName())
+		if extension == nil {
+			return nil, false
+		}
+		reader.Advance()
+		target := parent.SubAttributes(macro.MacroTarget(), nil)
+		NewAttributeList(parent.SubAttributes(macro.MacroAttributes(), nil), parent, "").ParseInto(attributes, extension.options.PosAttrs)
+		res = extension.processor.Process(blockNodeOf(parent), target, attributes)
+	} else {
+		style, _ := attributes["style"].(string)
+		c := context.Paragraph
+		if isDelimiterLine(line) {
+			c = delimitedBlocks[delimiterLeader(line)]
+		} else if isLiteralParagraphLine(line) || regexps.BlockImageRx.MatchString(line) || regexps.BlockTocRx.MatchString(line) {
+			return nil, false
+		}
+		extension := extensions.blockFor(style, c)
+		if extension == nil {
+			return nil, false
+		}
+		var lines []string
+		cursor := reader.Cursor()
+		if c == context.Paragraph {
+			lines = p.readParagraphLines(reader)
+		} else {
+			delimiter, _ := reader.ReadLine()
+			cursor = reader.Cursor()
+			lines, _ = reader.ReadLinesUntilDelimiter(delimiter)
+		}
+		rekeyAttributes(attributes, append([]string{""}, extension.options.PosAttrs...))
+		res = extension.processor.Process(blockNodeOf(parent), newReaderAt(lines, cursor), attributes)
+	}
+	if res == nil {
+		return nil, true
+	}
+	return res.blockOf(), true
+}
+
+/* Build an image block from an image block macro line.
+The positional attributes of the macro are the alt text,
+the width and the height of the image.
+The alt text defaults to the name of the image file, without extension */
+func (p *parser) nextBlockImage(line string, parent *abstractBlock, attributes map[string]interface{}) *Block {
+	image := regexps.NewBlockImageRxres(line)
+	block := newBlock(parent, context.Image, nil)
+	target := block.SubAttributes(image.BlockImageTarget(), nil)
+	NewAttributeList(block.SubAttributes(image.BlockImageAttributes(), nil), block, "").ParseInto(attributes, []string{"alt", "width", "height"})
+	attributes["target"] = target
+	if alt, _ := attributes["alt"].(string); alt == "" {
+		attributes["alt"] = strings.TrimSuffix(filepath.Base(target), filepath.Ext(target))
+	}
+	if doc := block.Document(); doc != nil {
+		doc.Register("images", []string{target})
+	}
+	return block
+}
+
+/* Parse a table, starting at its opening delimiter line: '|===' (psv,
+cells separated by '|'), ',===' (csv), ':===' (dsv) or '!===' (psv
+with '!' as separator, for a table nested in an AsciiDoc cell).
+ - the columns are created from the cols attribute, if any, or else
+   from the cells of the first line;
+ - a psv cell starts with a cell spec for its spans, alignments and
+   style ('2+^m|');
+ - the first line is an implicit header row if it is followed by a
+   blank line (unless the header or noheader option is set).
+Comment lines in the table are skipped. */
+func (p *parser) nextTable(reader *Reader, parent *abstractBlock, attributes map[string]interface{}) *Table {
+	delimiter, _ := reader.ReadLine()
+	cursor := reader.Cursor()
+	lines, _ := reader.ReadLinesUntilDelimiter(delimiter)
+	tableLines := []string{}
+	for _, line := range lines {
+		if !regexps.CommentLineRx.MatchString(line) {
+			tableLines = append(tableLines, line)
+		}
+	}
+	switch delimiter[0] {
+	case ',':
+		attributes["format"] = "csv"
+	case ':':
+		attributes["format"] = "dsv"
+	case '!':
+		if _, ok := attributes["separator"]; !ok {
+			attributes["separator"] = "!"
+		}
+	}
+	tableReader := newReaderAt(tableLines, cursor)
+	table := newTable(parent, attributes)
+	explicitColspecs := false
+	if cols, ok := attributes["cols"].(string); ok {
+		if colspecs := parseColspecs(cols); len(colspecs) > 0 {
+			table.createColumns(colspecs)
+			explicitColspecs = true
+		}
+	}
+	skipped := tableReader.SkipBlankLines()
+	ctx := newTableParserContext(table, attributes)
+	_, header := attributes["header-option"]
+	_, noheader := attributes["noheader-option"]
+	implicitHeader := skipped == 0 && !header && !noheader
+	// the number of blank lines after the first line, -1 if not tracked
+	implicitHeaderBoundary := -1
+	for loopIdx := 0; tableReader.HasMoreLines(); loopIdx++ {
+		ctx.cursor = tableReader.Cursor()
+		line, _ := tableReader.ReadLine()
+		hasLine := true
+		if loopIdx > 0 && line == "" {
+			hasLine = false
+			if implicitHeaderBoundary >= 0 {
+				implicitHeaderBoundary++
+			}
+		} else if ctx.format == "psv" {
+			if strings.HasPrefix(line, ctx.delimiter) {
+				line = line[len(ctx.delimiter):]
+				// push empty cell spec if cell boundary appears at start of line
+				ctx.closeOpenCell(nil)
+				implicitHeaderBoundary = -1
+			} else if cellspec, rest := parseCellspec(line, true, ctx.delimiter); cellspec != nil {
+				line = rest
+				ctx.closeOpenCell(cellspec)
+				implicitHeaderBoundary = -1
+			} else if implicitHeaderBoundary >= 0 && implicitHeaderBoundary == loopIdx {
+				// the cell continues from the previous line, across the blank lines
+				implicitHeader, implicitHeaderBoundary = false, -1
+			}
+		}
+		if loopIdx == 0 && implicitHeader {
+			if next, ok := tableReader.PeekLine(); ok && next == "" {
+				implicitHeaderBoundary = 1
+			} else {
+				implicitHeader = false
+			}
+		}
+		for {
+			index := -1
+			if hasLine {
+				index = strings.Index(line, ctx.delimiter)
+			}
+			if index < 0 {
+				// no other delimiters: the line goes to the buffer
+				ctx.buffer = ctx.buffer + line + "\n"
+				switch ctx.format {
+				case "csv":
+					ctx.buffer = strings.TrimRight(ctx.buffer, " \t\n") + " "
+					if ctx.bufferHasUnclosedQuotes("") {
+						if implicitHeaderBoundary >= 0 && loopIdx == 0 {
+							implicitHeader, implicitHeaderBoundary = false, -1
+						}
+						ctx.cellOpen = true
+					} else {
+						ctx.closeCell(true)
+					}
+				case "dsv":
+					ctx.closeCell(true)
+				default:
+					ctx.cellOpen = true
+				}
+				break
+			}
+			pre, post := line[:index], line[index+len(ctx.delimiter):]
+			if ctx.format == "csv" {
+				if ctx.bufferHasUnclosedQuotes(pre) {
+					// the delimiter is quoted
+					ctx.buffer = ctx.buffer + pre + ctx.delimiter
+					if line = post; line == "" {
+						break
+					}
+					continue
+				}
+				ctx.buffer = ctx.buffer + pre
+			} else {
+				if strings.HasSuffix(pre, `\`) {
+					// skip over escaped delimiter
+					ctx.buffer = ctx.buffer + pre[:len(pre)-1] + ctx.delimiter
+					if line = post; line == "" {
+						ctx.buffer = ctx.buffer + "\n"
+						ctx.cellOpen = true
+						break
+					}
+					continue
+				}
+				if ctx.format == "psv" {
+					cellspec, text := parseCellspec(pre, false, "")
+					ctx.pushCellspec(cellspec)
+					pre = text
+				}
+				ctx.buffer = ctx.buffer + pre
+			}
+			// an empty line left is kept, for an empty cell found at end of line
+			line = post
+			hasLine = line != ""
+			ctx.closeCell(false)
+		}
+		if !ctx.cellOpen {
+			ctx.closeOpenCell(nil)
+		} else if !tableReader.HasMoreLines() {
+			ctx.closeCell(true)
+		}
+	}
+	if !table.HasAttr("colcount", nil, false) {
+		table.setAttr("colcount", len(table.columns), true)
+	}
+	if len(table.columns) > 0 && !explicitColspecs {
+		table.assignColumnWidths(0)
+	}
+	if implicitHeader {
+		table.hasHeaderOption = true
+		attributes["header-option"] = ""
+	}
+	table.partitionHeaderFooter(attributes)
+	return table
+}
+
+/* Parse the cols attribute of a table into column specs:
+each one has a width (1 by default) and may have a horizontal alignment
+(halign), a vertical alignment (valign) and a style.
+A spec can be repeated ('3*'); a single number is the number of columns.
+ Examples
+   parseColspecs("1,2a,3*^m")
+   parseColspecs("3")
+The specs are separated by ',' (or ';') */
+func parseColspecs(records string) []map[string]interface{} {
+	records = strings.Replace(records, " ", "", -1)
+	specs := []map[string]interface{}{}
+	if count, err := strconv.Atoi(records); err == nil {
+		for i := 0; i < count; i++ {
+			specs = append(specs, map[string]interface{}{"width": 1})
+		}
+		return specs
+	}
+	separator := ";"
+	if strings.Contains(records, ",") {
+		separator = ","
+	}
+	for _, record := range strings.Split(records, separator) {
+		if record == "" {
+			specs = append(specs, map[string]interface{}{"width": 1})
+			continue
+		}
+		m := regexps.ColumnSpecRx.FindStringSubmatch(record)
+		if m == nil {
+			continue
+		}
+		spec := map[string]interface{}{"width": 1}
+		parseCellAlignments(m[2], spec)
+		if m[3] != "" {
+			spec["width"], _ = strconv.Atoi(strings.TrimSuffix(m[3], "%"))
+		}
+		if style, ok := regexps.TableCellStyles[m[4]]; ok {
+			spec["style"] = style
+		}
+		repeat := 1
+		if m[1] != "" {
+			repeat, _ = strconv.Atoi(m[1])
+		}
+		for i := 0; i < repeat; i++ {
+			copied := map[string]interface{}{}
+			for name, value := range spec {
+				copied[name] = value
+			}
+			specs = append(specs, copied)
+		}
+	}
+	return specs
+}
+
+/* Parse the horizontal and vertical alignments of a cell spec
+('<', '^.>', '.^') into the halign and valign attributes of spec */
+func parseCellAlignments(alignments string, spec map[string]interface{}) {
+	if alignments == "" {
+		return
+	}
+	parts := strings.SplitN(alignments, ".", 2)
+	if halign, ok := regexps.TableCellHorzAlignments[parts[0]]; ok {
+		spec["halign"] = halign
+	}
+	if len(parts) > 1 {
+		if valign, ok := regexps.TableCellVertAlignments[parts[1]]; ok {
+			spec["valign"] = valign
+		}
+	}
+}
+
+/* Parse the cell spec of a psv table cell: its colspan and rowspan
+('2.3+'), its duplication factor ('3*'), its alignments and its style.
+start     - true to look for the spec at the start of line (before the
+first delimiter), false to look for it at the end of line (which is
+then the text of the previous cell)
+delimiter - the cell delimiter (only used at the start of line)
+Returns the spec (nil if there is no spec at the start of line)
+and the rest of the line */
+func parseCellspec(line string, start bool, delimiter string) (map[string]interface{}, string) {
+	var m []string
+	rest := ""
+	if start {
+		index := strings.Index(line, delimiter)
+		if index < 0 {
+			return nil, line
+		}
+		if m = regexps.CellSpecStartRx.FindStringSubmatch(line[:index]); m == nil {
+			return nil, line
+		}
+		rest = line[index+len(delimiter):]
+		if m[0] == "" {
+			return map[string]interface{}{}, rest
+		}
+	} else {
+		loc := regexps.CellSpecEndRx.FindStringSubmatchIndex(line)
+		if loc == nil {
+			return map[string]interface{}{}, line
+		}
+		if strings.TrimSpace(line[loc[0]:loc[1]]) == "" {
+			return map[string]interface{}{}, strings.TrimRight(line, " \t")
+		}
+		m = regexps.CellSpecEndRx.FindStringSubmatch(line)
+		rest = line[:loc[0]]
+	}
+	spec := map[string]interface{}{}
+	if m[1] != "" {
+		parts := strings.SplitN(m[1], ".", 2)
+		colspec, rowspec := 1, 1
+		if parts[0] != "" {
+			colspec, _ = strconv.Atoi(parts[0])
+		}
+		if len(parts) > 1 && parts[1] != "" {
+			rowspec, _ = strconv.Atoi(parts[1])
+		}
+		if m[2] == "+" {
+			if colspec != 1 {
+				spec["colspan"] = colspec
+			}
+			if rowspec != 1 {
+				spec["rowspan"] = rowspec
+			}
+		} else if m[2] == "*" && colspec != 1 {
+			spec["repeatcol"] = colspec
+		}
+	}
+	parseCellAlignments(m[3], spec)
+	if style, ok := regexps.TableCellStyles[m[4]]; ok {
+		spec["style"] = style
+	}
+	return spec, rest
+}
+
+/* Parse the list starting at line, if line is a list item:
+an unordered, ordered, description or callout list.
+Returns nil (without consuming any line) if line is not a list item */
+func (p *parser) nextList(reader *Reader, parent *abstractBlock, line string) *List {
+	switch {
+	case regexps.UnorderedListRx.MatchString(line):
+		return p.nextOutlineList(reader, parent, context.Ulist, regexps.UnorderedListRx)
+	case regexps.OrderedListRx.MatchString(line):
+		return p.nextOutlineList(reader, parent, context.Olist, regexps.OrderedListRx)
+	case regexps.NewDescriptionListRxres(line).HasAnyMatch():
+		return p.nextDescriptionList(reader, parent)
+	case regexps.CalloutListRx.MatchString(line):
+		return p.nextOutlineList(reader, parent, context.Colist, regexps.CalloutListRx)
+	}
+	return nil
+}
+
+/* Check if a line is an item of a list which can be nested in a list
+item: an unordered, ordered or description list (not a callout list) */
+func isNestableListItemLine(line string) bool {
+	return regexps.UnorderedListRx.MatchString(line) ||
+		regexps.OrderedListRx.MatchString(line) ||
+		regexps.NewDescriptionListRxres(line).HasAnyMatch()
+}
+
+/* Parse an unordered, ordered or callout list: its items are the
+consecutive lines matching rx with a sibling marker (the same marker as
+the first item, or the same kind of numbering for an ordered list).
+A list item with a different marker starts a nested list.
+Items of an unordered list starting with a checkbox ('[ ]', '[x]')
+make it a checklist. */
+func (p *parser) nextOutlineList(reader *Reader, parent *abstractBlock, c context.Context, rx *regexp.Regexp) *List {
+	list := newList(parent, c)
+	line, _ := reader.PeekLine()
+	first := regexps.NewListItemRxres(line, rx).ListItemMarker()
+	isSibling := func(line string) bool {
+		item := regexps.NewListItemRxres(line, rx)
+		return item.HasAnyMatch() && (c == context.Colist || listMarkerKind(item.ListItemMarker()) == listMarkerKind(first))
+	}
+	if c == context.Colist {
+		list.SetStyle("arabic")
+		list.setAttr("style", "arabic", true)
+	} else if c == context.Olist {
+		style, start := orderedListStyle(first)
+		list.SetStyle(style)
+		list.setAttr("style", style, true)
+		if start != 1 {
+			list.setAttr("start", strconv.Itoa(start), true)
+		}
+	}
+	for reader.HasMoreLines() {
+		line, _ := reader.PeekLine()
+		if !isSibling(line) {
+			break
+		}
+		cursor := reader.Cursor()
+		reader.Advance()
+		match := regexps.NewListItemRxres(line, rx)
+		text, marker := match.ListItemText(), match.ListItemMarker()
+		if c == context.Colist && marker == "." {
+			marker = strconv.Itoa(len(list.Blocks()) + 1)
+		}
+		var checkbox []string
+		if c == context.Ulist {
+			checkbox = regexps.ChecklistItemRx.FindStringSubmatch(text)
+			if checkbox != nil {
+				text = checkbox[2]
+			}
+		}
+		item := p.nextListItem(reader, list, text, cursor, isSibling)
+		item.marker = marker
+		if checkbox != nil {
+			list.SetOption("checklist")
+			item.setAttr("checkbox", "", true)
+			if checkbox[1] != " " {
+				item.setAttr("checked", "", true)
+			}
+		}
+		list.AppendBlock(item.abstractBlock)
+		if c == context.Colist {
+			p.assignCalloutIds(list, item, cursor)
+		}
+		reader.SkipBlankLines()
+	}
+	if doc, ok := list.Document().(*Document); ok && c == context.Colist {
+		doc.callouts.NextList()
+	}
+	return list
+}
+
+/* Associate a callout list item to the callouts registered in the
+verbatim blocks before its list (coids attribute).
+A list item out of sequence, or without callout referring to it,
+is reported. */
+func (p *parser) assignCalloutIds(list *List, item *ListItem, cursor *Cursor) {
+	doc, ok := list.Document().(*Document)
+	if !ok {
+		return
+	}
+	ordinal := len(list.Items())
+	if item.marker != strconv.Itoa(ordinal) {
+		logMessage(doc.Logger(), severity.WARN, fmt.Sprintf("callout list item index: expected %d got %s", ordinal, item.marker), cursor)
+	}
+	if coids := doc.callouts.CalloutIds(ordinal); coids != "" {
+		item.setAttr("coids", coids, true)
+	} else {
+		logMessage(doc.Logger(), severity.WARN, fmt.Sprintf("no callouts refer to list item %d", ordinal), cursor)
+	}
+}
+
+/* Replace the specialcharacters substitution of a source block by the
+highlight substitution, when the block has a language and the
+source-highlighter of an html document is registered: the source
+highlighter escapes the special characters itself */
+func lockInHighlightSub(block *Block) {
+	doc := block.Document()
+	if doc == nil || !block.HasAttr("language", nil, false) || doc.Attr("basebackend", nil, false) != "html" {
+		return
+	}
+	if name, _ := doc.Attr("source-highlighter", nil, false).(string); SourceHighlighterFor(name) == nil {
+		return
+	}
+	for i, asub := range block.subs {
+		if asub == string(subsSpecialCharacters) {
+			block.subs[i] = string(subsHighlight)
+		}
+	}
+}
+
+/* Register the callouts found in the source of a verbatim block
+in the callouts catalog of the document.
+Returns true if any callout was found, even an escaped one,
+so that it can be unescaped when the block is converted */
+func catalogCallouts(text string, document Documentable) bool {
+	if !strings.Contains(text, "<") {
+		return false
+	}
+	reres := regexps.NewCalloutScanRxres(text)
+	doc, _ := document.(*Document)
+	for ; reres.HasNext(); reres.Next() {
+		if doc != nil && !reres.IsEscaped() {
+			doc.callouts.Register(reres.CalloutNumber())
+		}
+	}
+	return reres.HasAnyMatch()
+}
+
+/* Get the kind of a list marker, to find the siblings of a list item:
+the marker itself ('*', '-', '..'), or the numbering style of an explicitly
+numbered item ('1.' and '2.' are both "arabic") */
+func listMarkerKind(marker string) string {
+	if strings.Trim(marker, ".") != "" && strings.ContainsAny(marker[len(marker)-1:], ".)") {
+		style, _ := orderedListStyle(marker)
+		return style
+	}
+	return marker
+}
+
+/* Get the numbering style of an ordered list from the marker of its
+first item, and the number of that first item.
+Implicit numbering ('.', '..') follows the nesting level
+(arabic, loweralpha, lowerroman, upperalpha, upperroman).
+ Examples
+   orderedListStyle("..")
+   => "loweralpha", 1
+   orderedListStyle("c.")
+   => "loweralpha", 3 */
+func orderedListStyle(marker string) (string, int) {
+	if strings.Trim(marker, ".") == "" {
+		return regexps.ORDERED_LIST_STYLES[(len(marker)-1)%len(regexps.ORDERED_LIST_STYLES)], 1
+	}
+	numeral := marker[:len(marker)-1]
+	switch {
+	case strings.HasSuffix(marker, ")"):
+		style := "lowerroman"
+		if strings.ToUpper(numeral) == numeral {
+			style = "upperroman"
+		}
+		return style, romanToInt(numeral)
+	case numeral[0] >= '0' && numeral[0] <= '9':
+		start, _ := strconv.Atoi(numeral)
+		return "arabic", start
+	case numeral[0] >= 'a' && numeral[0] <= 'z':
+		return "loweralpha", int(numeral[0]-'a') + 1
+	}
+	return "upperalpha", int(numeral[0]-'A') + 1
+}
+
+// Convert a roman numeral (made of i, v and x) to an integer
+func romanToInt(roman string) int {
+	values := map[byte]int{'i': 1, 'v': 5, 'x': 10}
+	roman = strings.ToLower(roman)
+	res := 0
+	for i := 0; i < len(roman); i++ {
+		value := values[roman[i]]
+		if i+1 < len(roman) && values[roman[i+1]] > value {
+			res -= value
+		} else {
+			res += value
+		}
+	}
+	return res
+}
+
+/* Parse a description list: its items are the consecutive terms using
+the same marker as the first one ('::', ':::', '::::' or ';;').
+Consecutive terms without description share the next description. */
+func (p *parser) nextDescriptionList(reader *Reader, parent *abstractBlock) *List {
+	list := newList(parent, context.Dlist)
+	line, _ := reader.PeekLine()
+	marker := regexps.NewDescriptionListRxres(line).DescriptionMarker()
+	isSibling := func(line string) bool {
+		item := regexps.NewDescriptionListRxres(line)
+		return item.HasAnyMatch() && item.DescriptionMarker() == marker
+	}
+	for reader.HasMoreLines() {
+		line, _ := reader.PeekLine()
+		if !isSibling(line) {
+			break
+		}
+		cursor := reader.Cursor()
+		terms, description := []*ListItem{}, ""
+		for reader.HasMoreLines() && description == "" {
+			line, _ := reader.PeekLine()
+			if len(terms) > 0 && !isSibling(line) {
+				break
+			}
+			reader.Advance()
+			match := regexps.NewDescriptionListRxres(line)
+			term := newListItem(list.abstractBlock, match.Term())
+			catalogInlineAnchors(match.Term(), list.abstractBlock)
+			term.marker = marker
+			terms = append(terms, term)
+			description = match.Description()
+		}
+		item := p.nextListItem(reader, list, description, cursor, isSibling)
+		item.marker = marker
+		item.terms = terms
+		list.AppendBlock(item.abstractBlock)
+		reader.SkipBlankLines()
+	}
+	return list
+}
+
+/* Parse the lines of a list item which follow its marker line:
+the lines adjacent to the marker line are part of the text of the item,
+the other ones are parsed into the blocks of the item.
+text      - the String text following the marker of the item
+cursor    - the position of the marker line
+isSibling - checks whether a line is an item of the same list */
+func (p *parser) nextListItem(reader *Reader, list *List, text string, cursor *Cursor, isSibling func(string) bool) *ListItem {
+	buffer := p.readLinesForListItem(reader, isSibling)
+	textLines := []string{}
+	if text != "" {
+		textLines = append(textLines, text)
+	}
+	i := 0
+	for ; i < len(buffer); i++ {
+		line := buffer[i]
+		if isBlankLine(line) || line == "+" || isDelimiterLine(line) || isNestableListItemLine(line) {
+			break
+		}
+		if !regexps.CommentLineRx.MatchString(line) {
+			textLines = append(textLines, strings.TrimLeft(line, " \t"))
+		}
+	}
+	item := newListItem(list.abstractBlock, strings.Join(textLines, "\n"))
+	catalogInlineAnchors(item.text, list.abstractBlock)
+	item.setSourceLocation(cursor)
+	if rest := buffer[i:]; len(rest) > 0 {
+		p.parseBlocks(newReaderAt(rest, NewCursor(cursor.file, cursor.dir, cursor.path, cursor.lineno+1+i)), item.abstractBlock)
+	}
+	return item
+}
+
+/* Read the lines belonging to the current list item, up to the next
+sibling item, or up to the end of the list.
+ - a list continuation ('+') attaches the next block to the item,
+   including a delimited block;
+ - a delimited block which is not attached ends the list;
+ - after blank lines, a nested list item or an indented (literal)
+   paragraph still belongs to the item.
+The list continuations of the item itself are replaced by blank lines,
+while the ones in a nested list are kept for the nested list items. */
+func (p *parser) readLinesForListItem(reader *Reader, isSibling func(string) bool) []string {
+	buffer := []string{}
+	continuation, withinNested := false, false
+	for reader.HasMoreLines() {
+		line, _ := reader.PeekLine()
+		if isSibling(line) {
+			break
+		}
+		if isDelimiterLine(line) {
+			if !continuation {
+				break
+			}
+			reader.Advance()
+			lines, _ := reader.ReadLinesUntilDelimiter(line)
+			buffer = append(append(append(buffer, line), lines...), line)
+			continuation = false
+			continue
+		}
+		if line == "+" {
+			reader.Advance()
+			if withinNested {
+				buffer = append(buffer, line)
+			} else {
+				buffer = append(buffer, "")
+			}
+			continuation = true
+			continue
+		}
+		if isBlankLine(line) {
+			reader.SkipBlankLines()
+			if !reader.HasMoreLines() {
+				break
+			}
+			next, _ := reader.PeekLine()
+			if isSibling(next) || !(isNestableListItemLine(next) || isLiteralParagraphLine(next)) {
+				break
+			}
+			buffer = append(buffer, "")
+			continuation = false
+			continue
+		}
+		if isNestableListItemLine(line) {
+			withinNested = true
+		}
+		reader.Advance()
+		buffer = append(buffer, line)
+		// block metadata lines keep the next block attached
+		continuation = continuation &&
+			(regexps.BlockAttributeListRx.MatchString(line) || regexps.BlockTitleRx.MatchString(line) || regexps.BlockAnchorRx.MatchString(line))
+	}
+	return buffer
+}
+
+/* Check whether style is the style of an admonition
+(NOTE, TIP, IMPORTANT, WARNING or CAUTION) */
+func isAdmonitionStyle(style string) bool {
+	for _, admonition := range regexps.ADMONITION_STYLES {
+		if style == admonition {
+			return true
+		}
+	}
+	return false
+}
+
+/* Apply the block attributes parsed from the metadata lines to a block:
+its title, style and id (registered in the document, with the reftext
+attribute or else the title as reference text), and all the other
+attributes */
+func applyBlockAttributes(ab *abstractBlock, attributes map[string]interface{}) {
+	for name, value := range attributes {
+		switch name {
+		case "title":
+			ab.setTitle(value.(string))
+		case "style":
+			ab.SetStyle(value.(string))
+			ab.setAttr(name, value, true)
+		case "id":
+			ab.SetId(value.(string))
+			if doc := ab.Document(); doc != nil {
+				reftext, _ := attributes["reftext"].(string)
+				if reftext == "" {
+					reftext, _ = attributes["title"].(string)
+				}
+				doc.Register("ids", []string{ab.Id(), reftext})
+			}
+		default:
+			ab.setAttr(name, value, true)
+		}
+	}
+}
+
+/* Register the inline anchors of the text of a block in the references
+of its document, before any text is converted: the cross references
+preceding an anchor resolve to it as well.
+  [[[id]]]                a bibliography entry, with the reference text "[id]"
+  [[id]] or [[id,text]]   an anchor, with the reference text "[id]" or text
+  anchor:id[] or anchor:id[text]
+Escaped anchors are not registered */
+func catalogInlineAnchors(text string, parent *abstractBlock) {
+	doc := parent.Document()
+	if doc == nil || !strings.Contains(text, "[[") && !strings.Contains(text, "anchor:") {
+		return
+	}
+	text = regexps.InlineBiblioAnchorRx.ReplaceAllStringFunc(text, func(anchor string) string {
+		if strings.HasPrefix(anchor, `\`) {
+			return anchor[1:]
+		}
+		doc.Register("ids", []string{regexps.NewInlineBiblioAnchorRxres(anchor).BibId()})
+		return ""
+	})
+	for _, match := range regexps.InlineAnchorRx.FindAllStringSubmatch(text, -1) {
+		if strings.HasPrefix(match[0], `\`) {
+			continue
+		}
+		if match[1] != "" {
+			doc.Register("ids", []string{match[1], match[2]})
+		} else {
+			doc.Register("ids", []string{match[3], match[4]})
+		}
+	}
+}
+
+/* Read the lines of a paragraph, up to the next blank line,
+section title or (if compliance.BlockTerminatesParagraph() is enabled)
+block delimiter.
+Single-line comments in the paragraph are dropped. */
+func (p *parser) readParagraphLines(reader *Reader) []string {
+	lines := []string{}
+	for reader.HasMoreLines() {
+		line, _ := reader.PeekLine()
+		if isBlankLine(line) || (len(lines) > 0 && regexps.AtxSectionRx.MatchString(line)) {
+			break
+		}
+		if len(lines) > 0 && compliance.BlockTerminatesParagraph() && isDelimiterLine(line) {
+			break
+		}
+		reader.Advance()
+		if !regexps.CommentLineRx.MatchString(line) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+/* Parse a delimited block, starting at its opening delimiter line.
+The content of compound blocks (example, sidebar, quote, open)
+is parsed into nested blocks; the content of the other blocks
+(including a quote block with the verse style) is kept as is.
+Returns nil for a comment block. */
+func (p *parser) nextDelimitedBlock(reader *Reader, parent *abstractBlock, attributes map[string]interface{}) *Block {
+	delimiter, _ := reader.ReadLine()
+	c := delimitedBlocks[delimiterLeader(delimiter)]
+	cursor := reader.Cursor()
+	lines, _ := reader.ReadLinesUntilDelimiter(delimiter)
+	if c == context.Quote && attributes["style"] == "verse" {
+		// the lines of a verse are kept as they are
+		c = context.Verse
+	}
+	switch c {
+	case context.Comment:
+		return nil
+	case context.Example, context.Sidebar, context.Quote, context.Open:
+		block := newBlock(parent, c, nil)
+		p.parseBlocks(newReaderAt(lines, cursor), block.abstractBlock)
+		return block
+	}
+	return newBlock(parent, c, lines)
+}
+
+/* Return the delimited block leader of a line ('----' for '-------'),
+or "" if the line is not a block delimiter */
+func delimiterLeader(line string) string {
+	if line == "--" {
+		return line
+	}
+	if len(line) < 4 {
+		return ""
+	}
+	leader := line[:4]
+	if _, ok := delimitedBlocks[leader]; !ok || strings.Trim(line[1:], line[1:2]) != "" {
+		return ""
+	}
+	return leader
+}
+
+// Check if a line opens or closes a delimited block
+func isDelimiterLine(line string) bool {
+	return delimiterLeader(line) != ""
+}
+
+// Check if a line starts a literal paragraph (it is indented)
+func isLiteralParagraphLine(line string) bool {
+	return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+}
+
+/* Remove the indentation shared by all the (non-blank) lines,
+so that the least indented line starts at the first column */
+func resetBlockIndent(lines []string) []string {
+	indent := -1
+	for _, line := range lines {
+		if isBlankLine(line) {
+			continue
+		}
+		lineIndent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || lineIndent < indent {
+			indent = lineIndent
+		}
+	}
+	res := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			line = line[indent:]
+		} else {
+			line = strings.TrimLeft(line, " \t")
+		}
+		res[i] = line
+	}
+	return res
+}
+
+// Check if a line is empty or only made of whitespaces
+func isBlankLine(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
@@ -0,0 +1,547 @@
+package asciidocgo
+
+import (
+	"testing"
+
+	"github.com/VonC/asciidocgo/consts/compliance"
+	"github.com/VonC/asciidocgo/consts/contentModel"
+	"github.com/VonC/asciidocgo/consts/context"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParser(t *testing.T) {
+
+	Convey("A parser can parse a document header", t, func() {
+		doc := NewDocument([]string{}, nil)
+		p := newParser()
+		Convey("No level-0 title means no header", func() {
+			reader := NewReader([]string{"== Section"}, "")
+			p.parseDocumentHeader(reader, doc)
+			So(reader.LineNo(), ShouldEqual, 1)
+			So(doc.Header(), ShouldBeNil)
+		})
+		Convey("A level-0 title is the document title, and header lines are consumed", func() {
+			reader := NewReader([]string{"= Title", "Author Name", "", "paragraph"}, "guide.adoc")
+			p.parseDocumentHeader(reader, doc)
+			So(reader.LineNo(), ShouldEqual, 3)
+			So(doc.Header().Title(), ShouldEqual, "Title")
+			So(doc.Header().Level(), ShouldEqual, 0)
+			So(doc.Header().SourceLocation().String(), ShouldEqual, "guide.adoc:1")
+			So(doc.Attr("doctitle", nil, false), ShouldEqual, "Title")
+			So(doc.Attr("author", nil, false), ShouldEqual, "Author Name")
+		})
+		Convey("The header holds authors, a revision and attribute entries", func() {
+			lines := []string{":description: before the title", "= Title",
+				"Doc Writer <doc@example.com>; Junior_Jr M. Writer",
+				"v1.0, 2013-01-01: First release",
+				":toc:", "// comment", ":sectnums!:", ":Long Name: a value \\", "  on two lines", "", "paragraph"}
+			doc.setAttr("sectnums", "", true)
+			attributes := p.parseDocumentHeader(NewReader(lines, ""), doc)
+			So(len(attributes), ShouldEqual, 0)
+			So(doc.Attr("description", nil, false), ShouldEqual, "before the title")
+			So(doc.Attr("author", nil, false), ShouldEqual, "Doc Writer")
+			So(doc.Attr("authorinitials", nil, false), ShouldEqual, "DW")
+			So(doc.Attr("email", nil, false), ShouldEqual, "doc@example.com")
+			So(doc.Attr("author_1", nil, false), ShouldEqual, "Doc Writer")
+			So(doc.Attr("firstname_2", nil, false), ShouldEqual, "Junior Jr")
+			So(doc.Attr("middlename_2", nil, false), ShouldEqual, "M.")
+			So(doc.Attr("authorcount", nil, false), ShouldEqual, "2")
+			So(doc.Attr("authors", nil, false), ShouldEqual, "Doc Writer, Junior Jr M. Writer")
+			So(doc.Attr("revnumber", nil, false), ShouldEqual, "1.0")
+			So(doc.Attr("revdate", nil, false), ShouldEqual, "2013-01-01")
+			So(doc.Attr("revremark", nil, false), ShouldEqual, "First release")
+			So(doc.HasAttr("toc", nil, false), ShouldBeTrue)
+			So(doc.HasAttr("sectnums", nil, false), ShouldBeFalse)
+			So(doc.Attr("longname", nil, false), ShouldEqual, "a value on two lines")
+		})
+		Convey("An author attribute entry replaces the author line", func() {
+			lines := []string{"= Title", ":author: Jane Q Public", ":email: jane@example.com"}
+			p.parseDocumentHeader(NewReader(lines, ""), doc)
+			So(doc.Attr("firstname", nil, false), ShouldEqual, "Jane")
+			So(doc.Attr("lastname", nil, false), ShouldEqual, "Public")
+			So(doc.Attr("authorinitials", nil, false), ShouldEqual, "JQP")
+			So(doc.Attr("email", nil, false), ShouldEqual, "jane@example.com")
+		})
+		Convey("Block attributes above a document without title apply to the first block", func() {
+			attributes := p.parseDocumentHeader(NewReader([]string{"[[first]]", "paragraph"}, ""), doc)
+			So(attributes["id"], ShouldEqual, "first")
+			So(doc.Header(), ShouldBeNil)
+		})
+	})
+
+	Convey("A parser can store attributes", t, func() {
+		doc := NewDocument([]string{}, nil)
+		doc.overrideAttribute("locked", "cli")
+		p := newParser()
+		attrs := make(map[string]interface{})
+		name, value := p.storeAttribute("Foo Bar", "baz", &substDocument{doc}, attrs)
+		So(name, ShouldEqual, "foobar")
+		So(value, ShouldEqual, "baz")
+		So(attrs["foobar"], ShouldEqual, "baz")
+		So(doc.Attr("foobar", nil, false), ShouldEqual, "baz")
+		name, _ = p.storeAttribute("numbered", "", &substDocument{doc}, attrs)
+		So(name, ShouldEqual, "sectnums")
+		p.storeAttribute("!foobar", "", &substDocument{doc}, attrs)
+		So(doc.HasAttr("foobar", nil, false), ShouldBeFalse)
+		So(attrs["foobar"], ShouldBeNil)
+		Convey("Attributes locked by the API are not changed", func() {
+			p.storeAttribute("locked", "doc", &substDocument{doc}, attrs)
+			So(doc.Attr("locked", nil, false), ShouldEqual, "cli")
+			_, has := attrs["locked"]
+			So(has, ShouldBeFalse)
+		})
+		Convey("Attributes can be stored without a document", func() {
+			name, value = p.storeAttribute("foo", "bar", nil, attrs)
+			So(attrs["foo"], ShouldEqual, "bar")
+		})
+	})
+
+	Convey("A parser can process author lines", t, func() {
+		authors := processAuthors("Stuart Rackham <founder@asciidoc.org>", false, true)
+		So(authors["firstname"], ShouldEqual, "Stuart")
+		So(authors["lastname"], ShouldEqual, "Rackham")
+		So(authors["email"], ShouldEqual, "founder@asciidoc.org")
+		So(authors["authorcount"], ShouldEqual, "1")
+		_, has := authors["author_1"]
+		So(has, ShouldBeFalse)
+		authors = processAuthors("<not a name>", false, false)
+		So(authors["author"], ShouldEqual, "<not a name>")
+		So(authors["authorinitials"], ShouldEqual, "<")
+		authors = processAuthors("Jean Paul de la Fontaine", true, false)
+		So(authors["lastname"], ShouldEqual, "de la Fontaine")
+	})
+
+	Convey("A parser can nest sections according to their level", t, func() {
+		doc := NewDocument([]string{}, nil)
+		lines := []string{"== A", "", "=== A.1", "para", "==== A.1.1", "== B", "para B"}
+		So(newParser().parseDocument(NewReader(lines, "guide.adoc"), doc), ShouldBeNil)
+		So(len(doc.Sections()), ShouldEqual, 2)
+		a := doc.Sections()[0]
+		So(a.Title(), ShouldEqual, "A")
+		So(a.Level(), ShouldEqual, 1)
+		So(len(a.Sections()), ShouldEqual, 1)
+		a1 := a.Sections()[0]
+		So(a1.Level(), ShouldEqual, 2)
+		So(len(a1.Blocks()), ShouldEqual, 2)
+		So(a1.Blocks()[0].Context(), ShouldEqual, context.Paragraph)
+		So(a1.Sections()[0].Title(), ShouldEqual, "A.1.1")
+		b := doc.Sections()[1]
+		So(b.Title(), ShouldEqual, "B")
+		So(len(b.Blocks()), ShouldEqual, 1)
+		So(b.SourceLocation().String(), ShouldEqual, "guide.adoc:6")
+		So(b.Blocks()[0].SourceLocation().String(), ShouldEqual, "guide.adoc:7")
+	})
+
+	Convey("A parser splits paragraphs on blank lines", t, func() {
+		doc := NewDocument([]string{}, nil)
+		newParser().parseDocument(NewReader([]string{"line 1", "line 2", "", "  ", "line 3"}, ""), doc)
+		So(len(doc.Blocks()), ShouldEqual, 2)
+		So(doc.Blocks()[0].Document(), ShouldEqual, doc)
+	})
+
+	Convey("A parser can detect blank lines", t, func() {
+		So(isBlankLine(""), ShouldBeTrue)
+		So(isBlankLine(" \t"), ShouldBeTrue)
+		So(isBlankLine(" a"), ShouldBeFalse)
+	})
+
+	Convey("A parser can parse two-line section titles", t, func() {
+		lines := []string{"Document Title", "==============", "", "Section A", "---------", "para", "", "Section A.1", "~~~~~~~~~~~"}
+		Convey("When underline style section titles are enabled", func() {
+			doc := NewDocument([]string{}, nil)
+			newParser().parseDocument(NewReader(lines, ""), doc)
+			So(doc.Header().Title(), ShouldEqual, "Document Title")
+			So(len(doc.Sections()), ShouldEqual, 1)
+			a := doc.Sections()[0]
+			So(a.Title(), ShouldEqual, "Section A")
+			So(a.Level(), ShouldEqual, 1)
+			So(a.Blocks()[1].Level(), ShouldEqual, 2)
+			So(a.Sections()[0].SourceLocation().LineNo(), ShouldEqual, 8)
+		})
+		Convey("But not when they are disabled", func() {
+			compliance.SetUnderlineStyleSectionTitles(false)
+			defer compliance.SetUnderlineStyleSectionTitles(true)
+			doc := NewDocument([]string{}, nil)
+			newParser().parseDocument(NewReader(lines, ""), doc)
+			So(doc.Header(), ShouldBeNil)
+			So(len(doc.Sections()), ShouldEqual, 0)
+			So(doc.Blocks()[0].Context(), ShouldEqual, context.Paragraph)
+		})
+	})
+
+	Convey("A parser can parse literal paragraphs", t, func() {
+		doc := NewDocument([]string{}, nil)
+		newParser().parseDocument(NewReader([]string{"para", "", "   literal", "     indented", "", "para"}, ""), doc)
+		So(len(doc.Blocks()), ShouldEqual, 3)
+		So(doc.Blocks()[1].Context(), ShouldEqual, context.Literal)
+		So(doc.Blocks()[1].Node().(*Block).Lines(), ShouldResemble, []string{"literal", "  indented"})
+	})
+
+	Convey("A parser can parse delimited blocks", t, func() {
+		lines := []string{
+			"----", "code", "", "== not a section", "----",
+			"....", "literal", "....",
+			"++++", "<p>raw</p>", "++++",
+			"////", "comment", "////",
+			"// line comment",
+			"======", "example", "", "****", "sidebar", "****", "======",
+			"____", "quote", "____",
+			"--", "open", "--",
+			"paragraph", "----", "unterminated"}
+		doc := NewDocument([]string{}, nil)
+		newParser().parseDocument(NewReader(lines, "guide.adoc"), doc)
+		blocks := doc.Blocks()
+		So(len(blocks), ShouldEqual, 8)
+		So(blocks[0].Context(), ShouldEqual, context.Listing)
+		So(blocks[0].Node().(*Block).Lines(), ShouldResemble, []string{"code", "", "== not a section"})
+		So(blocks[1].Context(), ShouldEqual, context.Literal)
+		So(blocks[2].Context(), ShouldEqual, context.Pass)
+		So(blocks[3].Context(), ShouldEqual, context.Example)
+		So(blocks[3].SourceLocation().String(), ShouldEqual, "guide.adoc:16")
+		So(len(blocks[3].Blocks()), ShouldEqual, 2)
+		So(blocks[3].Blocks()[1].Context(), ShouldEqual, context.Sidebar)
+		So(blocks[3].Blocks()[1].SourceLocation().String(), ShouldEqual, "guide.adoc:19")
+		So(blocks[3].Blocks()[1].Blocks()[0].SourceLocation().String(), ShouldEqual, "guide.adoc:20")
+		So(blocks[4].Context(), ShouldEqual, context.Quote)
+		So(blocks[5].Context(), ShouldEqual, context.Open)
+		So(blocks[6].Context(), ShouldEqual, context.Paragraph)
+		So(blocks[7].Context(), ShouldEqual, context.Listing)
+		So(blocks[7].Node().(*Block).Lines(), ShouldResemble, []string{"unterminated"})
+	})
+
+	Convey("A parser can detect block delimiters", t, func() {
+		So(delimiterLeader("--"), ShouldEqual, "--")
+		So(delimiterLeader("-------"), ShouldEqual, "----")
+		So(delimiterLeader("---"), ShouldEqual, "")
+		So(delimiterLeader("----+"), ShouldEqual, "")
+		So(delimiterLeader("abcd"), ShouldEqual, "")
+		So(isDelimiterLine("____"), ShouldBeTrue)
+	})
+
+	Convey("A parser initializes sections with a name, a number and an id", t, func() {
+		lines := []string{"== Intro", "", "=== Intro", "", "[[custom,Custom Text]]", "== Custom",
+			"", "[appendix]", "== Extra", "", "=== Details", "", "[glossary]", "== Terms"}
+		doc := NewDocument([]string{}, map[string]string{})
+		doc.setAttr("sectnums", "", true)
+		newParser().parseDocument(NewReader(lines, ""), doc)
+		sections := doc.Sections()
+		So(len(sections), ShouldEqual, 4)
+		intro := sections[0].Node().(*Section)
+		So(intro.Id(), ShouldEqual, "_intro")
+		So(intro.SectName(), ShouldEqual, "sect1")
+		So(intro.IsNumbered(), ShouldBeTrue)
+		So(intro.SectNum(), ShouldEqual, "1.")
+		So(intro.Sections()[0].Id(), ShouldEqual, "_intro_2")
+		So(intro.Sections()[0].Node().(*Section).SectNum(), ShouldEqual, "1.1.")
+		custom := sections[1].Node().(*Section)
+		So(custom.Id(), ShouldEqual, "custom")
+		So(doc.References().Get("custom"), ShouldEqual, "Custom Text")
+		So(doc.References().Get("_intro"), ShouldEqual, "Intro")
+		appendix := sections[2].Node().(*Section)
+		So(appendix.SectName(), ShouldEqual, "appendix")
+		So(appendix.IsSpecial(), ShouldBeTrue)
+		So(appendix.IsNumbered(), ShouldBeTrue)
+		So(appendix.SectNum(), ShouldEqual, "A.")
+		So(appendix.Sections()[0].Node().(*Section).IsSpecial(), ShouldBeTrue)
+		So(appendix.Sections()[0].Node().(*Section).SectNum(), ShouldEqual, "A.1.")
+		glossary := sections[3].Node().(*Section)
+		So(glossary.SectName(), ShouldEqual, "glossary")
+		So(glossary.IsNumbered(), ShouldBeFalse)
+		So(glossary.Attr("style", nil, false), ShouldEqual, "glossary")
+	})
+
+	Convey("A parser names level-0 sections of a book 'part'", t, func() {
+		doc := NewDocument([]string{}, nil)
+		doc.setAttr("doctype", "book", true)
+		newParser().parseDocument(NewReader([]string{"= Book", "", "= Part", "", "== Chapter", "", "[abstract]", "== Abstract"}, ""), doc)
+		part := doc.Sections()[0].Node().(*Section)
+		So(part.SectName(), ShouldEqual, "part")
+		So(part.Sections()[0].Node().(*Section).SectName(), ShouldEqual, "sect1")
+		abstract := part.Sections()[1].Node().(*Section)
+		So(abstract.SectName(), ShouldEqual, "sect1")
+		So(abstract.IsSpecial(), ShouldBeFalse)
+	})
+
+	Convey("A parser applies block metadata to blocks", t, func() {
+		lines := []string{"[[para-id]]", ".A title", "// a comment", "[quote, Famous Person]", "paragraph",
+			"", "[source#code.lang.main%linenums, go]", "----", "fmt.Println()", "----", "", "[[ignored]]"}
+		doc := NewDocument([]string{}, nil)
+		newParser().parseDocument(NewReader(lines, ""), doc)
+		blocks := doc.Blocks()
+		So(len(blocks), ShouldEqual, 2)
+		So(blocks[0].Id(), ShouldEqual, "para-id")
+		So(blocks[0].Title(), ShouldEqual, "A title")
+		So(blocks[0].Style(), ShouldEqual, "quote")
+		So(blocks[0].Attr("2", nil, false), ShouldEqual, "Famous Person")
+		So(doc.References().Get("para-id"), ShouldEqual, "A title")
+		So(blocks[1].Id(), ShouldEqual, "code")
+		So(blocks[1].Style(), ShouldEqual, "source")
+		So(blocks[1].Attr("role", nil, false), ShouldEqual, "lang main")
+		So(blocks[1].HasAttr("linenums-option", nil, false), ShouldBeTrue)
+		So(blocks[1].Attr("2", nil, false), ShouldEqual, "go")
+	})
+
+	Convey("A parser registers the inline anchors of paragraphs and list items", t, func() {
+		lines := []string{"A [[first]]paragraph with [[second,Second Anchor]] and anchor:third[Third Anchor].",
+			"An \\[[escaped]] anchor.", "", "* [[[bib]]] A book", "* anchor:item[]", "",
+			"[[term]]Term:: description"}
+		doc := NewDocument([]string{}, nil)
+		newParser().parseDocument(NewReader(lines, ""), doc)
+		references := doc.References()
+		So(references.Get("first"), ShouldEqual, "[first]")
+		So(references.Get("second"), ShouldEqual, "Second Anchor")
+		So(references.Get("third"), ShouldEqual, "Third Anchor")
+		So(references.HasId("escaped"), ShouldBeFalse)
+		So(references.Get("bib"), ShouldEqual, "[bib]")
+		So(references.Get("item"), ShouldEqual, "[item]")
+		So(references.Get("term"), ShouldEqual, "[term]")
+	})
+
+	Convey("A parser can parse the style shorthand", t, func() {
+		attrs := map[string]interface{}{"1": "#id"}
+		So(parseStyleAttribute(attrs), ShouldEqual, "")
+		So(attrs["id"], ShouldEqual, "id")
+		So(parseStyleAttribute(map[string]interface{}{"1": "a b"}), ShouldEqual, "")
+		So(parseStyleAttribute(map[string]interface{}{}), ShouldEqual, "")
+	})
+
+	Convey("A parser can parse admonitions, images and source blocks", t, func() {
+		lines := []string{"NOTE: a note", "", "[TIP]", "====", "a tip", "====", "",
+			"image::images/tiger.png[]", "", ".Sunset", "image::sunset.jpg[Sunset, 200]", "",
+			"[source, go]", "----", "fmt.Println()", "----", "", "[quote, Someone, Book]", "____", "quoted", "____"}
+		doc := NewDocument([]string{}, nil)
+		newParser().parseDocument(NewReader(lines, ""), doc)
+		blocks := doc.Blocks()
+		So(len(blocks), ShouldEqual, 6)
+		So(blocks[0].Context(), ShouldEqual, context.Admonition)
+		So(blocks[0].Attr("name", nil, false), ShouldEqual, "note")
+		So(blocks[0].Attr("textlabel", nil, false), ShouldEqual, "Note")
+		So(blocks[0].Node().(*Block).Lines(), ShouldResemble, []string{"a note"})
+		So(blocks[1].Context(), ShouldEqual, context.Admonition)
+		So(blocks[1].Attr("name", nil, false), ShouldEqual, "tip")
+		So(len(blocks[1].Blocks()), ShouldEqual, 1)
+		So(blocks[2].Context(), ShouldEqual, context.Image)
+		So(blocks[2].Attr("target", nil, false), ShouldEqual, "images/tiger.png")
+		So(blocks[2].Attr("alt", nil, false), ShouldEqual, "tiger")
+		So(blocks[3].Attr("alt", nil, false), ShouldEqual, "Sunset")
+		So(blocks[3].Attr("width", nil, false), ShouldEqual, "200")
+		So(blocks[3].CaptionedTitle(), ShouldEqual, "Figure 1. Sunset")
+		So(blocks[4].Attr("language", nil, false), ShouldEqual, "go")
+		So(blocks[5].Attr("attribution", nil, false), ShouldEqual, "Someone")
+		So(blocks[5].Attr("citetitle", nil, false), ShouldEqual, "Book")
+	})
+
+	Convey("A parser can parse verses, and quote or verse paragraphs", t, func() {
+		lines := []string{"[verse, Poet, Poem]", "____", "The fog comes", "  on little cat feet.", "____", "",
+			"[quote, Someone, Book]", "A quoted *paragraph*.", "", "[verse, Poet]", "A verse", "  paragraph."}
+		doc := NewDocument([]string{}, nil)
+		newParser().parseDocument(NewReader(lines, ""), doc)
+		blocks := doc.Blocks()
+		So(len(blocks), ShouldEqual, 3)
+		So(blocks[0].Context(), ShouldEqual, context.Verse)
+		So(blocks[0].ContentModel(), ShouldEqual, contentmodel.Verbatim)
+		So(blocks[0].Node().(*Block).Lines(), ShouldResemble, []string{"The fog comes", "  on little cat feet."})
+		So(blocks[0].Attr("attribution", nil, false), ShouldEqual, "Poet")
+		So(blocks[0].Attr("citetitle", nil, false), ShouldEqual, "Poem")
+		So(blocks[1].Context(), ShouldEqual, context.Quote)
+		So(blocks[1].ContentModel(), ShouldEqual, contentmodel.Simple)
+		So(blocks[1].Attr("attribution", nil, false), ShouldEqual, "Someone")
+		So(blocks[1].Attr("citetitle", nil, false), ShouldEqual, "Book")
+		So(blocks[1].Node().(*Block).Content(), ShouldEqual, "A quoted <strong>paragraph</strong>.")
+		So(blocks[2].Context(), ShouldEqual, context.Verse)
+		So(blocks[2].ContentModel(), ShouldEqual, contentmodel.Verbatim)
+		So(blocks[2].Attr("attribution", nil, false), ShouldEqual, "Poet")
+		So(blocks[2].Node().(*Block).Content(), ShouldEqual, "A verse\n  paragraph.")
+	})
+
+	Convey("A parser can parse unordered and ordered lists", t, func() {
+		lines := []string{"* a", "continued", "** nested", "+", "attached", "* b", "+", "----", "code", "", "more", "----", "",
+			"* c", "", "  literal", "", "para", "", ". one", ".. two", "... three", "", "[%reversed]", "3. three", "4. four", "",
+			"//", "b. bee", "", "//", "iv) four", "", "//", "* [x] done", "* [ ] todo"}
+		doc := NewDocument([]string{}, nil)
+		newParser().parseDocument(NewReader(lines, ""), doc)
+		blocks := doc.Blocks()
+		So(len(blocks), ShouldEqual, 7)
+		So(blocks[0].Context(), ShouldEqual, context.Ulist)
+		items := blocks[0].Node().(*List).Items()
+		So(len(items), ShouldEqual, 3)
+		So(items[0].Marker(), ShouldEqual, "*")
+		So(items[0].Text(), ShouldEqual, "a\ncontinued")
+		nested := items[0].Blocks()[0].Node().(*List)
+		So(nested.Items()[0].Marker(), ShouldEqual, "**")
+		So(nested.Items()[0].Blocks()[0].Context(), ShouldEqual, context.Paragraph)
+		So(items[1].Blocks()[0].Context(), ShouldEqual, context.Listing)
+		So(items[1].Blocks()[0].Node().(*Block).Lines(), ShouldResemble, []string{"code", "", "more"})
+		So(items[2].Blocks()[0].Context(), ShouldEqual, context.Literal)
+		So(items[2].SourceLocation().LineNo(), ShouldEqual, 14)
+		So(blocks[1].Context(), ShouldEqual, context.Paragraph)
+
+		So(blocks[2].Style(), ShouldEqual, "arabic")
+		two := blocks[2].Node().(*List).Items()[0].Blocks()[0]
+		So(two.Style(), ShouldEqual, "loweralpha")
+		So(two.Node().(*List).Items()[0].Blocks()[0].Style(), ShouldEqual, "lowerroman")
+		So(blocks[3].Attr("start", nil, false), ShouldEqual, "3")
+		So(blocks[3].HasOption("reversed"), ShouldBeTrue)
+		So(len(blocks[3].Blocks()), ShouldEqual, 2)
+		So(blocks[4].Style(), ShouldEqual, "loweralpha")
+		So(blocks[4].Attr("start", nil, false), ShouldEqual, "2")
+		So(blocks[5].Style(), ShouldEqual, "lowerroman")
+		So(blocks[5].Attr("start", nil, false), ShouldEqual, "4")
+
+		So(blocks[6].HasOption("checklist"), ShouldBeTrue)
+		items = blocks[6].Node().(*List).Items()
+		So(items[0].Text(), ShouldEqual, "done")
+		So(items[0].HasAttr("checked", nil, false), ShouldBeTrue)
+		So(items[1].HasAttr("checkbox", nil, false), ShouldBeTrue)
+		So(items[1].HasAttr("checked", nil, false), ShouldBeFalse)
+	})
+
+	Convey("A parser can parse description and callout lists", t, func() {
+		lines := []string{"[horizontal]", "CPU:: The brain", "RAM::", "Memory::", "Stores", "  data", "Disk:::", "nested", "",
+			"Empty::", "", "//", "Other;; semicolons", "", "<1> first", "<.> second", "", "//", "* not nested"}
+		doc := NewDocument([]string{}, nil)
+		newParser().parseDocument(NewReader(lines, ""), doc)
+		blocks := doc.Blocks()
+		So(len(blocks), ShouldEqual, 4)
+		So(blocks[0].Context(), ShouldEqual, context.Dlist)
+		So(blocks[0].Style(), ShouldEqual, "horizontal")
+		items := blocks[0].Node().(*List).Items()
+		So(len(items), ShouldEqual, 3)
+		So(items[0].Terms()[0].Text(), ShouldEqual, "CPU")
+		So(items[0].Text(), ShouldEqual, "The brain")
+		So(len(items[1].Terms()), ShouldEqual, 2)
+		So(items[1].Terms()[1].Text(), ShouldEqual, "Memory")
+		So(items[1].Text(), ShouldEqual, "Stores\ndata")
+		So(items[1].Blocks()[0].Context(), ShouldEqual, context.Dlist)
+		So(items[2].HasText(), ShouldBeFalse)
+		So(blocks[1].Context(), ShouldEqual, context.Dlist)
+		So(blocks[2].Context(), ShouldEqual, context.Colist)
+		items = blocks[2].Node().(*List).Items()
+		So(items[0].Marker(), ShouldEqual, "1")
+		So(items[1].Marker(), ShouldEqual, "2")
+		So(blocks[3].Context(), ShouldEqual, context.Ulist)
+	})
+
+	Convey("A parser can catalog the callouts of verbatim blocks", t, func() {
+		lines := []string{"----", "a <1> <2>", "b <2>", `c \<3>`, "----", "<1> one", "<2> two", "<4> four", "", "....", "no callout", "....", "", "----", "d <1>", "----", "<.> again"}
+		doc := NewDocument([]string{}, nil)
+		newParser().parseDocument(NewReader(lines, ""), doc)
+		blocks := doc.Blocks()
+		So(blocks[0].HasSub("callouts"), ShouldBeTrue)
+		So(blocks[2].HasSub("callouts"), ShouldBeFalse)
+		items := blocks[1].Node().(*List).Items()
+		So(items[0].Attr("coids", nil, false), ShouldEqual, "CO1-1")
+		So(items[1].Attr("coids", nil, false), ShouldEqual, "CO1-2 CO1-3")
+		So(items[2].HasAttr("coids", nil, false), ShouldBeFalse)
+		So(blocks[4].Node().(*List).Items()[0].Attr("coids", nil, false), ShouldEqual, "CO2-1")
+		messages := doc.Logger().(*MemoryLogger).Messages()
+		So(len(messages), ShouldEqual, 2)
+		So(messages[0].String(), ShouldEqual, "asciidocgo: WARNING: line 8: callout list item index: expected 3 got 4")
+		So(messages[1].String(), ShouldEqual, "asciidocgo: WARNING: line 8: no callouts refer to list item 3")
+		So(catalogCallouts("no callout", nil), ShouldBeFalse)
+		So(catalogCallouts(`\<1>`, nil), ShouldBeTrue)
+	})
+
+	Convey("A parser can parse psv, csv and dsv tables", t, func() {
+		lines := []string{".Sizes", `[cols="1,2a,>3m",options="footer"]`, "|===", "|Name |Doc |Code", "", "|a |*strong* cell", "", "* item", "|x := 1",
+			"", "2+|span |z", ".2+^.>s|rowspan |b |c", "|d |e", "// comment", `|f1 \| f2|f3 |f4`, "|===", "", ",===", `a,"b,c"`, `1,"2 ""q"""`, ",===",
+			"", ":===", `c\:x:d`, ":==="}
+		doc := NewDocument([]string{}, nil)
+		newParser().parseDocument(NewReader(lines, ""), doc)
+		blocks := doc.Blocks()
+		So(len(blocks), ShouldEqual, 3)
+		So(blocks[0].Context(), ShouldEqual, context.Table)
+		table := blocks[0].Node().(*Table)
+		So(table.CaptionedTitle(), ShouldEqual, "Table 1. Sizes")
+		So(table.HasAttr("header-option", nil, false), ShouldBeTrue)
+		So(table.Attr("rowcount", nil, false), ShouldEqual, 6)
+		So(len(table.Columns()), ShouldEqual, 3)
+		So(table.Columns()[2].Attr("halign", nil, false), ShouldEqual, "right")
+		So(table.Columns()[2].Attr("colpcwidth", nil, false), ShouldEqual, "50.0001")
+		rows := table.Rows()
+		So(rows.Head()[0][2].Text(), ShouldEqual, "Code")
+		So(rows.Head()[0][1].InnerDocument(), ShouldBeNil)
+		So(len(rows.Body()), ShouldEqual, 4)
+		inner := rows.Body()[0][1].InnerDocument()
+		So(inner.IsNested(), ShouldBeTrue)
+		So(len(inner.Blocks()), ShouldEqual, 2)
+		So(inner.Blocks()[1].Context(), ShouldEqual, context.Ulist)
+		So(rows.Body()[1][0].Colspan(), ShouldEqual, 2)
+		So(rows.Body()[1][1].Style(), ShouldEqual, "monospaced")
+		rowspan := rows.Body()[2][0]
+		So(rowspan.Rowspan(), ShouldEqual, 2)
+		So(rowspan.Style(), ShouldEqual, "strong")
+		So(rowspan.Attr("halign", nil, false), ShouldEqual, "center")
+		So(rowspan.Attr("valign", nil, false), ShouldEqual, "bottom")
+		So(len(rows.Body()[3]), ShouldEqual, 2)
+		So(rows.Body()[3][0].Column(), ShouldEqual, table.Columns()[1])
+		So(rows.Foot()[0][0].Text(), ShouldEqual, "f1 | f2")
+
+		csv := blocks[1].Node().(*Table).Rows()
+		So(csv.Head(), ShouldBeEmpty)
+		So(csv.Body()[0][1].Text(), ShouldEqual, "b,c")
+		So(csv.Body()[1][1].Text(), ShouldEqual, `2 "q"`)
+		dsv := blocks[2].Node().(*Table).Rows()
+		So(dsv.Body()[0][0].Text(), ShouldEqual, "c:x")
+
+		Convey("A nested table uses '!' as separator", func() {
+			lines := []string{`[cols="a,1"]`, "|===", "|outer", "!===", "!x !y", "!===", "|z", "|==="}
+			doc, _ := NewDocument(lines, nil).Parse()
+			inner := doc.Blocks()[0].Node().(*Table).Rows().Body()[0][0].InnerDocument()
+			So(inner.Blocks()[1].Context(), ShouldEqual, context.Table)
+			So(len(inner.Blocks()[1].Node().(*Table).Columns()), ShouldEqual, 2)
+		})
+		Convey("A cell continued after a blank line cancels the implicit header", func() {
+			lines := []string{"|===", "|a |b", "", "more", "|c |d", "|==="}
+			doc, _ := NewDocument(lines, nil).Parse()
+			rows := doc.Blocks()[0].Node().(*Table).Rows()
+			So(rows.Head(), ShouldBeEmpty)
+			So(rows.Body()[0][1].Content(), ShouldResemble, []string{"b", "more"})
+		})
+		Convey("Table errors are reported", func() {
+			lines := []string{"[cols=\"1,1\",format=xsv]", "|===", "text |a 3+|b", "|c", "|==="}
+			doc, _ := NewDocument(lines, nil).Parse()
+			messages := doc.Logger().(*MemoryLogger).Messages()
+			So(len(messages), ShouldEqual, 4)
+			So(messages[0].Text, ShouldEqual, "illegal table format: xsv")
+			So(messages[1].String(), ShouldEqual, "asciidocgo: ERROR: line 4: dropping cell because it exceeds specified number of columns")
+			So(messages[2].Text, ShouldEqual, "table missing leading separator, recovering automatically")
+		})
+	})
+
+	Convey("A parser can parse table column specs and cell specs", t, func() {
+		So(parseColspecs("3"), ShouldResemble, []map[string]interface{}{{"width": 1}, {"width": 1}, {"width": 1}})
+		So(parseColspecs("1, 2*^.>25%a"), ShouldResemble, []map[string]interface{}{{"width": 1},
+			{"width": 25, "halign": "center", "valign": "bottom", "style": "asciidoc"},
+			{"width": 25, "halign": "center", "valign": "bottom", "style": "asciidoc"}})
+		So(parseColspecs(";.<"), ShouldResemble, []map[string]interface{}{{"width": 1}, {"width": 1, "valign": "top"}})
+		spec, rest := parseCellspec("2.3+<s| text", true, "|")
+		So(spec, ShouldResemble, map[string]interface{}{"colspan": 2, "rowspan": 3, "halign": "left", "style": "strong"})
+		So(rest, ShouldEqual, " text")
+		spec, rest = parseCellspec("text| other", true, "|")
+		So(spec, ShouldBeNil)
+		spec, rest = parseCellspec("| other", true, "|")
+		So(spec, ShouldBeEmpty)
+		So(rest, ShouldEqual, " other")
+		spec, rest = parseCellspec("previous 3*", false, "")
+		So(spec, ShouldResemble, map[string]interface{}{"repeatcol": 3})
+		So(rest, ShouldEqual, "previous")
+		spec, rest = parseCellspec("previous  ", false, "")
+		So(spec, ShouldBeEmpty)
+		So(rest, ShouldEqual, "previous")
+	})
+
+	Convey("A parser can get the numbering style of an ordered list", t, func() {
+		style, start := orderedListStyle("......")
+		So(style, ShouldEqual, "arabic")
+		So(start, ShouldEqual, 1)
+		style, start = orderedListStyle("C.")
+		So(style, ShouldEqual, "upperalpha")
+		So(start, ShouldEqual, 3)
+		style, start = orderedListStyle("XIV)")
+		So(style, ShouldEqual, "upperroman")
+		So(start, ShouldEqual, 14)
+		So(listMarkerKind("1."), ShouldEqual, listMarkerKind("2."))
+		So(listMarkerKind("**"), ShouldEqual, "**")
+	})
+}
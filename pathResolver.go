@@ -3,6 +3,8 @@ package asciidocgo
 import (
 	"errors"
 	"fmt"
+	"io/fs"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
@@ -10,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/VonC/asciidocgo/consts/regexps"
+	"github.com/VonC/asciidocgo/consts/severity"
 )
 
 var testpr = ""
@@ -114,10 +117,21 @@ Examples:
       puts e.message
     end
     => Start path /etc is outside of jail: /path/to/docs'
+
+A PathResolver can also resolve the paths of a file system (fs.FS, like an
+embed.FS or a fstest.MapFS), instead of the paths of the system: those paths
+are rooted at '/' (the root of the file system, which is also the working
+directory), and can never resolve outside of that root.
+
+    resolver = NewFSPathResolver(fsys)
+    resolver.system_path('images', '/docs')
+    => '/docs/images'
 */
 type PathResolver struct {
 	fileSeparator byte
 	workingDir    string
+	logger        Logger
+	fsys          fs.FS
 }
 
 func (pr *PathResolver) FileSeparator() byte {
@@ -127,6 +141,14 @@ func (pr *PathResolver) WorkingDir() string {
 	return pr.workingDir
 }
 
+// Error returned when a path resolves outside of its jail
+type JailError struct {
+	msg string // description of error
+}
+
+// Print description of a jail error
+func (e *JailError) Error() string { return e.msg }
+
 /* Construct a new instance of PathResolver, optionally specifying
 the file separator (to override the system default) and
 the working directory (to override the present working directory).
@@ -157,7 +179,64 @@ func NewPathResolver(fileSeparator byte, workingDir string) *PathResolver {
 		panic(err)
 	}
 	workingDir = wd
-	return &PathResolver{fileSeparator, workingDir}
+	return &PathResolver{fileSeparator, workingDir, nil, nil}
+}
+
+/* Construct a new instance of PathResolver for the paths of the file
+system fsys: '/' is its working directory, and the root of fsys */
+func NewFSPathResolver(fsys fs.FS) *PathResolver {
+	return &PathResolver{'/', "/", nil, fsys}
+}
+
+/* The file system of the paths of this resolver
+(nil for the paths of the system) */
+func (pr *PathResolver) FS() fs.FS {
+	return pr.fsys
+}
+
+/* Read the content of the file at path, resolved by this resolver:
+from its file system if it has one, from the system otherwise */
+func (pr *PathResolver) ReadFile(path string) ([]byte, error) {
+	return readFile(pr.fsys, path)
+}
+
+/* Read the content of the file at path, from fsys if not nil.
+A path of fsys is rooted at '/', and must name a file inside fsys
+(no '..' segment) */
+func readFile(fsys fs.FS, path string) ([]byte, error) {
+	if fsys == nil {
+		return ioutil.ReadFile(path)
+	}
+	name := strings.TrimPrefix(Posixfy(path), "/")
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrInvalid}
+	}
+	return fs.ReadFile(fsys, name)
+}
+
+/* Read the entries of the directory at path, from fsys if not nil
+(see readFile) */
+func readDir(fsys fs.FS, path string) ([]fs.DirEntry, error) {
+	if fsys == nil {
+		return os.ReadDir(path)
+	}
+	name := strings.TrimPrefix(Posixfy(path), "/")
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrInvalid}
+	}
+	return fs.ReadDir(fsys, name)
+}
+
+/* Report the warnings (like an auto-recovered illegal path) to logger.
+Without logger, warnings are ignored */
+func (pr *PathResolver) SetLogger(logger Logger) {
+	pr.logger = logger
 }
 
 /*Check if the specified path is an absolute root path
@@ -289,34 +368,42 @@ opts   - an optional Hash of options to control processing (default: {}):
   * :target_name is used in messages to refer to the path being resolved
 returns a String path that joins the target path with the start path with
 any parent references resolved and self references removed and enforces
-that the resolved path be contained within the jail, if provided
+that the resolved path be contained within the jail, if provided.
+returns a JailError (instead of raising a SecurityError) if the jail is not
+an absolute path, or if the path is outside of the jail and cannot be
+recovered
+The root of the file system of the resolver, if any, is the default jail.
 */
-func (pr *PathResolver) SystemPath(target, start, jail string, canrecover bool, targetName string) string {
+func (pr *PathResolver) SystemPath(target, start, jail string, canrecover bool, targetName string) (string, error) {
+	if jail == "" && pr.fsys != nil {
+		// nothing exists outside of the root of a file system
+		jail = "/"
+	}
 	if jail != "" && !IsRoot(jail) {
-		panic(fmt.Sprintf("Jail is not an absolute path: %v", jail))
+		return "", &JailError{fmt.Sprintf("Jail is not an absolute path: %v", jail)}
 	}
 	jail = Posixfy(jail)
 	targetSegments, targetRoot, _ := PartitionPath(target, false)
 	if len(targetSegments) == 0 {
 		if start == "" {
 			if jail == "" {
-				return Posixfy(pr.WorkingDir())
+				return Posixfy(pr.WorkingDir()), nil
 			}
 		} else if IsRoot(start) {
 			if jail == "" {
-				return ExpandPath(start)
+				return ExpandPath(start), nil
 			}
 		} else {
 			return pr.SystemPath(start, jail, jail, canrecover, targetName)
 		}
 	}
 
-	if targetRoot != "" && targetRoot != "." {
-		resolvedTarget := JoinPath(targetSegments, targetRoot)
+	if (targetRoot != "" && targetRoot != ".") || IsRoot(Posixfy(target)) {
+		resolvedTarget := joinSystemPath(targetSegments, targetRoot)
 		// if target is absolute and a sub-directory of jail, or
 		// a jail is not in place, let it slide
-		if jail == "" || strings.HasPrefix(resolvedTarget, jail) {
-			return resolvedTarget
+		if jail == "" || isInJail(resolvedTarget, jail) {
+			return resolvedTarget, nil
 		}
 	}
 
@@ -329,10 +416,13 @@ func (pr *PathResolver) SystemPath(target, start, jail string, canrecover bool,
 	} else if IsRoot(start) {
 		start = Posixfy(start)
 	} else {
-		start = pr.SystemPath(start, jail, jail, true, targetName)
+		var err error
+		if start, err = pr.SystemPath(start, jail, jail, true, targetName); err != nil {
+			return "", err
+		}
 	}
 	if testpr == "test_SystemPath_start" {
-		return start
+		return start, nil
 	}
 
 	jailSegments := []string{}
@@ -345,12 +435,12 @@ func (pr *PathResolver) SystemPath(target, start, jail string, canrecover bool,
 		startSegments = make([]string, len(jailSegments))
 		copy(startSegments, jailSegments)
 	} else if jail != "" {
-		if !strings.HasPrefix(start, jail) {
+		if !isInJail(start, jail) {
 			aTargetName := targetName
 			if targetName == "" {
 				aTargetName = "Start path"
 			}
-			panic(fmt.Sprintf("%v '%v' is outside of jail: '%v' (disallowed in safe mode)", aTargetName, start, jail))
+			return "", &JailError{fmt.Sprintf("%v '%v' is outside of jail: '%v' (disallowed in safe mode)", aTargetName, start, jail)}
 		}
 		startSegments, startRoot, _ = PartitionPath(start, false)
 		jailSegments, jailRoot, _ = PartitionPath(jail, false)
@@ -360,7 +450,7 @@ func (pr *PathResolver) SystemPath(target, start, jail string, canrecover bool,
 	}
 
 	if testpr == "test_SystemPath_segments" {
-		return fmt.Sprintf("jail='%v', jailRoot='%v', jailSegments '%v', startRoot='%v', startSegments '%v'", jail, jailRoot, jailSegments, startRoot, startSegments)
+		return fmt.Sprintf("jail='%v', jailRoot='%v', jailSegments '%v', startRoot='%v', startSegments '%v'", jail, jailRoot, jailSegments, startRoot, startSegments), nil
 	}
 
 	resolvedSegments := make([]string, len(startSegments))
@@ -378,9 +468,11 @@ func (pr *PathResolver) SystemPath(target, start, jail string, canrecover bool,
 				if lr > len(jailSegments) {
 					resolvedSegments = resolvedSegments[:lr-1]
 				} else if !canrecover {
-					panic(fmt.Sprintf("%v '%v' refers to location outside jail: '%v' (disallowed in safe mode)", aTargetName, target, jail))
+					return "", &JailError{fmt.Sprintf("%v '%v' refers to location outside jail: '%v' (disallowed in safe mode)", aTargetName, target, jail)}
 				} else if !warned {
-					fmt.Errorf("asciidoctor: WARNING: %v '%v' has illegal reference to ancestor of jail, auto-recovering", aTargetName, target)
+					if pr.logger != nil {
+						pr.logger.Log(&LogMessage{Severity: severity.WARN, Text: fmt.Sprintf("%v '%v' has illegal reference to ancestor of jail, auto-recovering", aTargetName, target)})
+					}
 					warned = true
 				}
 			} else {
@@ -391,7 +483,25 @@ func (pr *PathResolver) SystemPath(target, start, jail string, canrecover bool,
 		}
 	}
 
-	return JoinPath(resolvedSegments, jailRoot)
+	if jailRoot == "" && IsRoot(start) {
+		return joinSystemPath(resolvedSegments, jailRoot), nil
+	}
+	return JoinPath(resolvedSegments, jailRoot), nil
+}
+
+/* Join the segments of an absolute system path with its root.
+A posix path has an empty root: the path then starts with '/' */
+func joinSystemPath(segments []string, root string) string {
+	if root == "" {
+		return "/" + JoinPath(segments, "")
+	}
+	return JoinPath(segments, root)
+}
+
+/* Check whether a posix path is the jail, or inside the jail (comparing
+whole segments: '/base-evil' is not inside the jail '/base') */
+func isInJail(path, jail string) bool {
+	return path == jail || strings.HasPrefix(path, strings.TrimSuffix(jail, "/")+"/")
 }
 
 /* Resolve a web path from the target and start paths.
@@ -1,8 +1,10 @@
 package asciidocgo
 
 import (
+	"fmt"
 	"os"
 	"testing"
+	"testing/fstest"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -204,81 +206,72 @@ func TestPathResolver(t *testing.T) {
 	Convey("A Partition can resolve a system path from the target and start paths (internal tests)", t, func() {
 		testpr = ""
 		pr := NewPathResolver(0, "C:/a/working/dir")
-		Convey("A Non-absolute jail path should panic", func() {
-			recovered := false
-			defer func() {
-				r := recover()
-				recovered = true
-				So(recovered, ShouldBeTrue)
-				So(r, ShouldEqual, "Jail is not an absolute path: c")
-			}()
-			_ = pr.SystemPath("a", "b", "c", false, "")
+		Convey("A Non-absolute jail path is a JailError", func() {
+			res, err := pr.SystemPath("a", "b", "c", false, "")
+			So(res, ShouldEqual, "")
+			So(err, ShouldHaveSameTypeAs, &JailError{})
+			So(fmt.Sprint(err), ShouldEqual, "Jail is not an absolute path: c")
 		})
 		/*
 			Convey("A system path with no start resolves from the root", func() {
-				So(pr.SystemPath("images", "", "", false, ""), ShouldEqual, "")
-				So(pr.SystemPath("../images", "", "", false, ""), ShouldEqual, "")
-				So(pr.SystemPath("/etc/images", "", "", false, ""), ShouldEqual, "")
+				So(pathOf(pr.SystemPath("images", "", "", false, "")), ShouldEqual, "")
+				So(pathOf(pr.SystemPath("../images", "", "", false, "")), ShouldEqual, "")
+				So(pathOf(pr.SystemPath("/etc/images", "", "", false, "")), ShouldEqual, "")
 			})*/
 		Convey("Empty target segment and empty start and empty jail means working dir", func() {
-			So(pr.SystemPath("", "", "", false, ""), ShouldEqual, "C:/a/working/dir")
+			So(pathOf(pr.SystemPath("", "", "", false, "")), ShouldEqual, "C:/a/working/dir")
 		})
 		Convey("Empty target segment, non-empty root start and empty jail means expanded start path", func() {
-			So(pr.SystemPath("", "C:\\start/../b", "", false, ""), ShouldEqual, "C:/start/../b")
+			So(pathOf(pr.SystemPath("", "C:\\start/../b", "", false, "")), ShouldEqual, "C:/start/../b")
 		})
 		Convey("Empty target segment, non-empty non-root start means system path start", func() {
-			So(pr.SystemPath("", "start/../b", "", false, ""), ShouldEqual, "C:/a/working/dir/b")
-			So(pr.SystemPath("", "start/../b", "C:\\", false, ""), ShouldEqual, "C:/b")
-			So(pr.SystemPath("start/../b", "C:\\", "C:\\", false, ""), ShouldEqual, "C:/b")
+			So(pathOf(pr.SystemPath("", "start/../b", "", false, "")), ShouldEqual, "C:/a/working/dir/b")
+			So(pathOf(pr.SystemPath("", "start/../b", "C:\\", false, "")), ShouldEqual, "C:/b")
+			So(pathOf(pr.SystemPath("start/../b", "C:\\", "C:\\", false, "")), ShouldEqual, "C:/b")
 		})
 		Convey("Non-Empty target segments starting with jail (or empty jail) returns target", func() {
-			So(pr.SystemPath("C:/start/b", "", "", false, ""), ShouldEqual, "C:/start/b")
-			So(pr.SystemPath("C:/start/b", "C:\\start", "", false, ""), ShouldEqual, "C:/start/b")
-			So(pr.SystemPath("C:/start/b", "C:\\start/", "", false, ""), ShouldEqual, "C:/start/b")
+			So(pathOf(pr.SystemPath("C:/start/b", "", "", false, "")), ShouldEqual, "C:/start/b")
+			So(pathOf(pr.SystemPath("C:/start/b", "C:\\start", "", false, "")), ShouldEqual, "C:/start/b")
+			So(pathOf(pr.SystemPath("C:/start/b", "C:\\start/", "", false, "")), ShouldEqual, "C:/start/b")
 		})
 
 		Convey("Empty start and jail means start is working dir", func() {
 			testpr = "test_SystemPath_start"
-			So(pr.SystemPath("a/b1", "", "", false, ""), ShouldEqual, pr.WorkingDir())
+			So(pathOf(pr.SystemPath("a/b1", "", "", false, "")), ShouldEqual, pr.WorkingDir())
 		})
 		Convey("Empty start and non-empty jail means start is jail", func() {
 			testpr = "test_SystemPath_start"
-			So(pr.SystemPath("a/b1", "", "C:/c/d", false, ""), ShouldEqual, "C:/c/d")
+			So(pathOf(pr.SystemPath("a/b1", "", "C:/c/d", false, "")), ShouldEqual, "C:/c/d")
 		})
 
 		Convey("Non-Empty root start means posixfied start", func() {
 			testpr = "test_SystemPath_start"
-			So(pr.SystemPath("a/b1", "C:\\a/b\\c", "C:/c/d", false, ""), ShouldEqual, "C:/a/b/c")
+			So(pathOf(pr.SystemPath("a/b1", "C:\\a/b\\c", "C:/c/d", false, "")), ShouldEqual, "C:/a/b/c")
 		})
 
 		Convey("Non Empty target segment, non-empty non-root start means system path start with jail", func() {
-			So(pr.SystemPath("a/b2", "start/../b", "C:\\", false, ""), ShouldEqual, "C:/b/a/b2")
+			So(pathOf(pr.SystemPath("a/b2", "start/../b", "C:\\", false, "")), ShouldEqual, "C:/b/a/b2")
 		})
 
 		Convey("Same jail and start means posixfied start", func() {
 			testpr = "test_SystemPath_segments"
-			So(pr.SystemPath("a/b1", "C:\\a/b\\c", "C:\\a/b/c", false, ""), ShouldEqual, "jail='C:/a/b/c', jailRoot='C:', jailSegments '[a b c]', startRoot='', startSegments '[a b c]'")
+			So(pathOf(pr.SystemPath("a/b1", "C:\\a/b\\c", "C:\\a/b/c", false, "")), ShouldEqual, "jail='C:/a/b/c', jailRoot='C:', jailSegments '[a b c]', startRoot='', startSegments '[a b c]'")
 		})
 
-		Convey("Different jail and start means panic if start doesn't include jail", func() {
-			recovered := false
-			defer func() {
-				r := recover()
-				recovered = true
-				So(recovered, ShouldBeTrue)
-				So(r, ShouldEqual, "Start path 'C:/a/b/c' is outside of jail: 'C:/e/b/c' (disallowed in safe mode)")
-			}()
-			_ = pr.SystemPath("a/b1", "C:\\a/b\\c", "C:\\e/b/c", false, "")
+		Convey("Different jail and start means JailError if start doesn't include jail", func() {
+			_, err := pr.SystemPath("a/b1", "C:\\a/b\\c", "C:\\e/b/c", false, "")
+			So(err, ShouldHaveSameTypeAs, &JailError{})
+			So(fmt.Sprint(err), ShouldEqual, "Start path 'C:/a/b/c' is outside of jail: 'C:/e/b/c' (disallowed in safe mode)")
 		})
 
 		Convey("Start must includes jail", func() {
 			testpr = "test_SystemPath_segments"
-			So(pr.SystemPath("a/b1", "C:\\a/b\\c/e/f", "C:\\a/b/c", false, ""), ShouldEqual, "jail='C:/a/b/c', jailRoot='C:', jailSegments '[a b c]', startRoot='C:', startSegments '[a b c e f]'")
+			So(pathOf(pr.SystemPath("a/b1", "C:\\a/b\\c/e/f", "C:\\a/b/c", false, "")), ShouldEqual, "jail='C:/a/b/c', jailRoot='C:', jailSegments '[a b c]', startRoot='C:', startSegments '[a b c e f]'")
 		})
 
 		Convey("Start with empty jail", func() {
 			testpr = "test_SystemPath_segments"
-			So(pr.SystemPath("a/b1", "C:\\a/b\\c/e/f", "", false, ""), ShouldEqual, "jail='', jailRoot='C:', jailSegments '[]', startRoot='C:', startSegments '[a b c e f]'")
+			So(pathOf(pr.SystemPath("a/b1", "C:\\a/b\\c/e/f", "", false, "")), ShouldEqual, "jail='', jailRoot='C:', jailSegments '[]', startRoot='C:', startSegments '[a b c e f]'")
 		})
 	})
 
@@ -289,70 +282,70 @@ func TestPathResolver(t *testing.T) {
 		Convey("Simple non-root target is append to current working dir", func() {
 			// resolver.system_path('images')
 			// => '/path/to/docs/images'
-			So(pr.SystemPath("images", "", "", false, ""), ShouldEqual, "C:/a/working/dir/images")
+			So(pathOf(pr.SystemPath("images", "", "", false, "")), ShouldEqual, "C:/a/working/dir/images")
 		})
 
 		Convey("dot-dot target current working dir back one folder up", func() {
 			// resolver.system_path('../images')
 			// => '/path/to/images'
-			So(pr.SystemPath("../images", "", "", false, ""), ShouldEqual, "C:/a/working/images")
+			So(pathOf(pr.SystemPath("../images", "", "", false, "")), ShouldEqual, "C:/a/working/images")
 		})
 
 		Convey("dot-dot target current working dir back one folder up", func() {
 			// resolver.system_path('/etc/images')
 			// => '/etc/images'
-			So(pr.SystemPath("C:/etc/images", "", "", false, ""), ShouldEqual, "C:/etc/images")
+			So(pathOf(pr.SystemPath("C:/etc/images", "", "", false, "")), ShouldEqual, "C:/etc/images")
 		})
 
 		Convey("non-empty target is appended to non-empty start", func() {
 			// resolver.system_path('images', '/etc')
 			// => '/etc/images'
-			So(pr.SystemPath("images", "C:/etc", "", false, ""), ShouldEqual, "C:/etc/images")
+			So(pathOf(pr.SystemPath("images", "C:/etc", "", false, "")), ShouldEqual, "C:/etc/images")
 		})
 
 		Convey("empty target returns non-empty start", func() {
 			// resolver.system_path('', '/etc/images')
 			// => '/etc/images'
-			So(pr.SystemPath("", "C:/etc/images", "", false, ""), ShouldEqual, "C:/etc/images")
+			So(pathOf(pr.SystemPath("", "C:/etc/images", "", false, "")), ShouldEqual, "C:/etc/images")
 		})
 
 		Convey("empty target and empty start returns jail", func() {
 			// resolver.system_path(nil, nil, '/path/to/docs')
 			// => '/path/to/docs'
-			So(pr.SystemPath("", "", "C:/etc/images", false, ""), ShouldEqual, "C:/etc/images")
+			So(pathOf(pr.SystemPath("", "", "C:/etc/images", false, "")), ShouldEqual, "C:/etc/images")
 		})
 
 		Convey("dot_dot target and empty start, returns non-empty jail", func() {
 			// resolver.system_path('..', nil, '/path/to/docs')
 			// => '/path/to/docs'
-			So(pr.SystemPath("..", "", "C:/etc/images", true, ""), ShouldEqual, "C:/etc/images")
+			So(pathOf(pr.SystemPath("..", "", "C:/etc/images", true, "")), ShouldEqual, "C:/etc/images")
 		})
 
 		Convey("dot_dot path target and empty start, returns non-empty jail plus path", func() {
 			// resolver.system_path('../../../css', nil, '/path/to/docs')
 			// => '/path/to/docs/css'
-			So(pr.SystemPath("../../../css", "", "C:/etc/to/images", true, ""), ShouldEqual, "C:/etc/to/images/css")
+			So(pathOf(pr.SystemPath("../../../css", "", "C:/etc/to/images", true, "")), ShouldEqual, "C:/etc/to/images/css")
 		})
 
 		Convey("dot_dot path target and empty start, returns non-empty jail plus path", func() {
 			// resolver.system_path('../../../css', '../../..', '/path/to/docs')
 			// => '/path/to/docs/css'
-			So(pr.SystemPath("../../../css", "", "C:/etc/to/images", true, ""), ShouldEqual, "C:/etc/to/images/css")
+			So(pathOf(pr.SystemPath("../../../css", "", "C:/etc/to/images", true, "")), ShouldEqual, "C:/etc/to/images/css")
 		})
 
 		Convey("dot_dot path target, different start and jail returns jail", func() {
 			// resolver.system_path('..', 'C:\\data\\docs\\assets', 'C:\\data\\docs')
 			//=> 'C:/data/docs'
-			So(pr.SystemPath("..", "C:\\data\\docs\\assets", "C:\\data\\docs", true, ""), ShouldEqual, "C:/data/docs")
+			So(pathOf(pr.SystemPath("..", "C:\\data\\docs\\assets", "C:\\data\\docs", true, "")), ShouldEqual, "C:/data/docs")
 		})
 
 		Convey("dot_dot path target, start including jail returns start+target", func() {
 			// resolver.system_path('..\\..\\css', 'C:\\data\\docs\\assets', 'C:\\data\\docs')
 			// => 'C:/data/docs/css'
-			So(pr.SystemPath("..\\..\\css", "C:\\data\\docs\\assets", "C:\\data\\docs", true, ""), ShouldEqual, "C:/data/docs/css")
+			So(pathOf(pr.SystemPath("..\\..\\css", "C:\\data\\docs\\assets", "C:\\data\\docs", true, "")), ShouldEqual, "C:/data/docs/css")
 		})
 
-		Convey("Different jail and start means panic if start doesn't include jail", func() {
+		Convey("Target outside of jail means JailError without recovery", func() {
 			/*
 					begin
 				     resolver.system_path('../../../css', '../../..', '/path/to/docs', :recover => false)
@@ -362,23 +355,27 @@ func TestPathResolver(t *testing.T) {
 					=> 'path ../../../../../../css refers to location outside jail: /path/to/docs (disallowed in safe mode)'
 
 			*/
-			recovered := false
-			defer func() {
-				r := recover()
-				recovered = true
-				So(recovered, ShouldBeTrue)
-				So(r, ShouldEqual, "path '../../../css' refers to location outside jail: 'C:/path/to/docs' (disallowed in safe mode)")
-			}()
-			_ = pr.SystemPath("../../../css", "../../..", "C:\\path/to/docs", false, "")
+			_, err := pr.SystemPath("../../../css", "../../..", "C:\\path/to/docs", false, "")
+			So(err, ShouldHaveSameTypeAs, &JailError{})
+			So(fmt.Sprint(err), ShouldEqual, "path '../../../css' refers to location outside jail: 'C:/path/to/docs' (disallowed in safe mode)")
+		})
+
+		Convey("An auto-recovered illegal path is reported to the logger", func() {
+			logger := NewMemoryLogger()
+			pr.SetLogger(logger)
+			So(pathOf(pr.SystemPath("../../../css", "", "C:/etc/to/images", true, "stylesheet")), ShouldEqual, "C:/etc/to/images/css")
+			So(len(logger.Messages()), ShouldEqual, 1)
+			So(logger.Messages()[0].String(), ShouldEqual, "asciidocgo: WARNING: stylesheet '../../../css' has illegal reference to ancestor of jail, auto-recovering")
+			pr.SetLogger(nil)
 		})
 
 		Convey("target, including jail but empty start returns target", func() {
 			// resolver.system_path('/path/to/docs/images', nil, '/path/to/docs')
 			//	=> '/path/to/docs/images'
-			So(pr.SystemPath("C:/path/to/docs/images", "", "C:/path/to/docs", false, ""), ShouldEqual, "C:/path/to/docs/images")
+			So(pathOf(pr.SystemPath("C:/path/to/docs/images", "", "C:/path/to/docs", false, "")), ShouldEqual, "C:/path/to/docs/images")
 		})
 
-		Convey("start outside of jail means panic", func() {
+		Convey("start outside of jail means JailError", func() {
 			/*
 
 				begin
@@ -388,14 +385,9 @@ func TestPathResolver(t *testing.T) {
 				  end
 				=> Start path /etc is outside of jail: /path/to/docs'
 			*/
-			recovered := false
-			defer func() {
-				r := recover()
-				recovered = true
-				So(recovered, ShouldBeTrue)
-				So(r, ShouldEqual, "Start path 'C:/etc' is outside of jail: 'C:/path/to/docs' (disallowed in safe mode)")
-			}()
-			_ = pr.SystemPath("images", "C:/etc", "C:/path/to/docs", false, "")
+			_, err := pr.SystemPath("images", "C:/etc", "C:/path/to/docs", false, "")
+			So(err, ShouldHaveSameTypeAs, &JailError{})
+			So(fmt.Sprint(err), ShouldEqual, "Start path 'C:/etc' is outside of jail: 'C:/path/to/docs' (disallowed in safe mode)")
 		})
 	})
 
@@ -490,3 +482,40 @@ func TestPathResolver(t *testing.T) {
 	})
 
 }
+
+// The path resolved by SystemPath, ignoring its error
+func pathOf(path string, err error) string {
+	return path
+}
+
+func TestFSPathResolver(t *testing.T) {
+
+	Convey("A PathResolver can resolve the paths of a file system", t, func() {
+		fsys := fstest.MapFS{"docs/a.txt": &fstest.MapFile{Data: []byte("a")}}
+		pr := NewFSPathResolver(fsys)
+		So(pr.FS(), ShouldEqual, fsys)
+		So(pr.WorkingDir(), ShouldEqual, "/")
+		So(pr.FileSeparator(), ShouldEqual, '/')
+		So(pathOf(pr.SystemPath("a.txt", "docs", "", false, "")), ShouldEqual, "/docs/a.txt")
+		So(pathOf(pr.SystemPath("/docs/a.txt", "", "", false, "")), ShouldEqual, "/docs/a.txt")
+
+		Convey("Which never resolve outside of its root", func() {
+			_, err := pr.SystemPath("../../a.txt", "/docs", "", false, "")
+			So(err.Error(), ShouldEqual, "path '../../a.txt' refers to location outside jail: '/' (disallowed in safe mode)")
+			So(pathOf(pr.SystemPath("../../a.txt", "/docs", "", true, "")), ShouldEqual, "/a.txt")
+			_, err = pr.SystemPath("../a.txt", "/docs", "/docs", false, "")
+			So(err, ShouldNotBeNil)
+		})
+		Convey("And read its files", func() {
+			data, err := pr.ReadFile("/docs/a.txt")
+			So(string(data), ShouldEqual, "a")
+			So(err, ShouldBeNil)
+			_, err = pr.ReadFile("/docs/../../a.txt")
+			So(err.Error(), ShouldEqual, "open /docs/../../a.txt: invalid argument")
+			_, err = pr.ReadFile("/docs/b.txt")
+			So(err, ShouldNotBeNil)
+			data, _ = NewPathResolver(0, "").ReadFile("test/t.txt")
+			So(string(data), ShouldEqual, "test data")
+		})
+	})
+}
@@ -0,0 +1,690 @@
+package asciidocgo
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/VonC/asciidocgo/consts/regexps"
+	"github.com/VonC/asciidocgo/consts/safemode"
+	"github.com/VonC/asciidocgo/consts/severity"
+)
+
+/* The maximum depth of nested includes, unless the 'max-include-depth'
+attribute says otherwise */
+const defaultMaxIncludeDepth = 64
+
+/* Process the preprocessor directives of the lines of a Reader
+as they are reached, on behalf of a document:
+ include::target[attributes]
+ ifdef::names[], ifndef::names[], ifeval::[expression], endif::names[]
+The lines of an included file replace the directive, and are read
+with their own file, directory and line numbers, before the reader
+resumes with the lines which follow the directive.
+The lines of a conditional block whose condition is false are skipped,
+still counting in the line numbers. */
+type preprocessor struct {
+	document *Document
+	// number of lines, at the beginning of the reader lines,
+	// which have already been preprocessed
+	lookAhead        int
+	includeStack     []*includeFrame
+	conditionalStack []*conditionalFrame
+	// set while peeking at lines beyond the next one (see peekLines),
+	// and whether the start or the end of an include file was reached then
+	peeking  bool
+	boundary bool
+}
+
+/* An open conditional block: the target of its directive (to match
+the endif), whether its lines are skipped, and where it starts */
+type conditionalFrame struct {
+	target string
+	skip   bool
+	cursor *Cursor
+}
+
+/* The state of a reader saved while it reads the lines of an include file,
+restored once those lines are read */
+type includeFrame struct {
+	file        string
+	dir         string
+	path        string
+	lines       []string
+	lineno      int
+	leveloffset interface{}
+}
+
+/* Initialize a Reader processing the preprocessor directives of data
+for doc.
+file - the String path of the source file (may be empty) */
+func newPreprocessorReader(doc *Document, data []string, file string) *Reader {
+	reader := NewReader(data, file)
+	reader.preprocessor = &preprocessor{document: doc}
+	return reader
+}
+
+/* Process the directives of the next line of r until it is a regular line
+(or a line already processed), leaving the include files fully read
+(unless peeking: see peekLines) */
+func (p *preprocessor) processNextLine(r *Reader) {
+	for {
+		if len(r.lines) == 0 {
+			if p.peeking && len(p.includeStack) > 0 {
+				p.boundary = true
+				return
+			}
+			if !p.popInclude(r) {
+				p.closeConditionals()
+				return
+			}
+			continue
+		}
+		if p.lookAhead > 0 || !p.processLine(r, r.lines[0]) {
+			return
+		}
+	}
+}
+
+/* Process the directive of line, the next line of r, if any.
+Returns true if the line was consumed, meaning the new next line
+must be processed in turn */
+func (p *preprocessor) processLine(r *Reader, line string) bool {
+	skipping := p.isSkipping()
+	if strings.Contains(line, "::") && strings.HasSuffix(line, "]") {
+		if conditional := regexps.NewConditionalDirectiveRxres(line); conditional.HasAnyMatch() {
+			if !conditional.IsEscaped() {
+				return p.processConditional(r, conditional.Directive(), conditional.ConditionalTarget(), conditional.Delimiter(), conditional.Text())
+			}
+			if !skipping {
+				p.replaceLine(r, line[1:])
+				return false
+			}
+		}
+	}
+	if skipping {
+		p.dropLine(r)
+		return true
+	}
+	if strings.Contains(line, "::") {
+		if include := regexps.NewIncludeDirectiveRxres(line); include.HasAnyMatch() {
+			if include.IsEscaped() {
+				p.replaceLine(r, line[1:])
+				return false
+			}
+			if p.peeking {
+				p.boundary = true
+				return false
+			}
+			return p.processInclude(r, include.IncludeTarget(), include.IncludeAttributes())
+		}
+	}
+	p.lookAhead = 1
+	return false
+}
+
+/* Peek at the next n lines of r, once their directives are processed.
+The peek stops at the start or at the end of an include file (after
+the first line): the lines of an include file are read with their own
+position and leveloffset, which are only set (or restored) once the
+lines before (or the last line of) the include file are actually read.
+Returns as many lines as are available, up to n */
+func (p *preprocessor) peekLines(r *Reader, n int) []string {
+	res := []string{}
+	for len(res) < n && r.HasMoreLines() && !p.boundary {
+		line, _ := r.ReadLine()
+		res = append(res, line)
+		p.peeking = true
+	}
+	p.peeking, p.boundary = false, false
+	r.UnshiftLines(res)
+	return res
+}
+
+/* Replace the next line of r by a line which must not be processed again */
+func (p *preprocessor) replaceLine(r *Reader, line string) {
+	r.lines[0] = line
+	p.lookAhead = 1
+}
+
+/* Consume the next line of r, which still counts in the line numbers */
+func (p *preprocessor) dropLine(r *Reader) {
+	r.lines = r.lines[1:]
+	r.lineno++
+}
+
+/* Report a message about the directive on the next line of r */
+func (p *preprocessor) log(r *Reader, s severity.Severity, text string) {
+	logMessage(p.document.Logger(), s, text, r.Cursor())
+}
+
+/* Process an include directive: replace it by the lines of the target file.
+The target is resolved from the directory of the current file
+(or the base dir of the document), and must be inside the base dir
+unless the document is UNSAFE.
+The attributes select the lines to include:
+  * lines=1..5;10 (a range ending with -1, or with nothing, goes to the end)
+  * tag=name or tags=name1;name2 (the lines between
+    'tag::name[]' and 'end::name[]')
+and adapt them:
+  * leveloffset=+1 (shift the level of the section titles)
+  * indent=2 (reindent the lines, 0 removing their common indentation)
+  * encoding=iso-8859-1 (the encoding of the file, UTF-8 by default)
+In SECURE mode, the directive becomes a link.
+Otherwise, the first include processor of the document extensions which
+handles the target pushes its own content, whatever the target is;
+without one, a URI target becomes a link.
+A target which cannot be resolved or read, or which is already being
+included (a file or a content including itself, directly or not),
+is reported, and the directive replaced by an 'Unresolved directive'
+line; an included file is registered
+in the references of the document, for the cross references to its ids.
+Returns true if the directive line was consumed */
+func (p *preprocessor) processInclude(r *Reader, target, attrlist string) bool {
+	doc := p.document
+	path := r.path
+	if path == "" {
+		path = "<stdin>"
+	}
+	unresolved := fmt.Sprintf("Unresolved directive in %v - include::%v[%v]", path, target, attrlist)
+	target = doc.SubAttributes(target, nil)
+	if doc.Safe() >= safemode.SECURE {
+		p.replaceLine(r, "link:"+target+"[]")
+		return false
+	}
+	maxDepth, err := strconv.Atoi(fmt.Sprint(doc.Attr("max-include-depth", strconv.Itoa(defaultMaxIncludeDepth), false)))
+	if err != nil {
+		maxDepth = defaultMaxIncludeDepth
+	}
+	if len(p.includeStack) >= maxDepth {
+		p.log(r, severity.ERROR, fmt.Sprintf("maximum include depth of %d exceeded", maxDepth))
+		p.replaceLine(r, unresolved)
+		return false
+	}
+	attributes := NewAttributeList(attrlist, nil, ",").Parse(nil)
+	for _, processor := range doc.Extensions().includeProcessors {
+		if processor.Handles(target) {
+			if p.isIncluding(r, "", target) {
+				p.log(r, severity.ERROR, fmt.Sprintf("circular include: %v", target))
+				p.replaceLine(r, unresolved)
+				return false
+			}
+			p.dropLine(r)
+			processor.Process(doc, r, target, attributes)
+			return true
+		}
+	}
+	if regexps.UriSniffRx.MatchString(target) {
+		p.replaceLine(r, "link:"+target+"[]")
+		return false
+	}
+	jail := ""
+	if doc.Safe() >= safemode.SAFE {
+		jail = doc.BaseDir()
+	}
+	start := r.dir
+	if start == "" {
+		start = doc.BaseDir()
+	}
+	pr := doc.pathResolver()
+	file, err := pr.SystemPath(target, start, jail, false, "include file")
+	if err != nil {
+		p.log(r, severity.ERROR, err.Error())
+		p.replaceLine(r, unresolved)
+		return false
+	}
+	if p.isIncluding(r, file, "") {
+		p.log(r, severity.ERROR, fmt.Sprintf("circular include: %v", file))
+		p.replaceLine(r, unresolved)
+		return false
+	}
+	data, err := pr.ReadFile(file)
+	if err != nil {
+		p.log(r, severity.ERROR, fmt.Sprintf("include file not found: %v", file))
+		p.replaceLine(r, unresolved)
+		return false
+	}
+	content, err := decodeIncludeData(data, attributeString(attributes, "encoding"))
+	if err != nil {
+		p.log(r, severity.WARN, fmt.Sprintf("%v, reading include file as UTF-8: %v", err, file))
+	}
+	p.dropLine(r)
+	doc.Register("includes", []string{target})
+	p.pushIncludeContent(r, content, file, includePath(file, doc.BaseDir()), attributes)
+	return true
+}
+
+/* Check whether an include file, or the content of an include processor
+(without file, identified by its path: the target of the directive),
+is the one r is reading, or one of those including it */
+func (p *preprocessor) isIncluding(r *Reader, file, path string) bool {
+	matches := func(f, pth string) bool {
+		if file != "" {
+			return filepath.Clean(f) == filepath.Clean(file)
+		}
+		return f == "" && pth == path
+	}
+	if matches(r.file, r.path) {
+		return true
+	}
+	for _, frame := range p.includeStack {
+		if matches(frame.file, frame.path) {
+			return true
+		}
+	}
+	return false
+}
+
+/* Push the content of an include file (or of an include processor) to r,
+once its lines are selected and adapted by the include attributes */
+func (p *preprocessor) pushIncludeContent(r *Reader, content, file, path string, attributes map[string]interface{}) {
+	lines, lineno := splitLines(content), 1
+	if linesAttr := attributeString(attributes, "lines"); linesAttr != "" {
+		lines, lineno = selectLines(lines, linesAttr)
+	} else if tags := attributeString(attributes, "tags", "tag"); tags != "" {
+		var missing []string
+		lines, lineno, missing = selectTaggedLines(lines, tags)
+		name := file
+		if name == "" {
+			name = path
+		}
+		// the directive is the line before the next one
+		cursor := NewCursor(r.file, r.dir, r.path, r.lineno-1)
+		for _, tag := range missing {
+			logMessage(p.document.Logger(), severity.WARN, fmt.Sprintf("tag '%v' not found in include file: %v", tag, name), cursor)
+		}
+	}
+	if indent := attributeString(attributes, "indent"); indent != "" {
+		if size, err := strconv.Atoi(indent); err == nil && size >= 0 {
+			lines = indentLines(lines, size)
+		}
+	}
+	p.pushInclude(r, lines, file, path, lineno, attributeString(attributes, "leveloffset"))
+}
+
+/* The String value of the first of names set in attributes ("" if none) */
+func attributeString(attributes map[string]interface{}, names ...string) string {
+	for _, name := range names {
+		if value, ok := attributes[name].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+/* The path used to report the lines of an include file:
+relative to the base dir if the file is inside it */
+func includePath(file, baseDir string) string {
+	if rel, err := filepath.Rel(baseDir, file); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return file
+}
+
+/* Insert the lines of an include file before the next line of r (the
+directive is already consumed), saving the state of r to restore it
+once those lines are read.
+An include without file (from an include processor) keeps the directory
+of the current file, to resolve the includes of its lines.
+A leveloffset (absolute, or relative if it starts with '+' or '-')
+applies to the section titles of the include file only */
+func (p *preprocessor) pushInclude(r *Reader, lines []string, file, path string, lineno int, leveloffset string) {
+	frame := &includeFrame{r.file, r.dir, r.path, r.lines, r.lineno, nil}
+	if leveloffset != "" {
+		doc := p.document
+		frame.leveloffset = doc.Attr("leveloffset", nil, false)
+		current, _ := doc.Attr("leveloffset", "0", false).(string)
+		doc.setAttr("leveloffset", resolveLevelOffset(current, leveloffset), true)
+		if frame.leveloffset == nil {
+			// the attribute is undefined again once the file is read
+			frame.leveloffset = false
+		}
+	}
+	p.includeStack = append(p.includeStack, frame)
+	if file != "" {
+		r.dir = filepath.Dir(file)
+	}
+	r.file, r.path = file, path
+	r.lines, r.lineno = lines, lineno
+	p.lookAhead = 0
+}
+
+/* Restore the state of r saved when the last include file was pushed.
+Returns false if r is not reading an include file */
+func (p *preprocessor) popInclude(r *Reader) bool {
+	if len(p.includeStack) == 0 {
+		return false
+	}
+	frame := p.includeStack[len(p.includeStack)-1]
+	p.includeStack = p.includeStack[:len(p.includeStack)-1]
+	r.file, r.dir, r.path = frame.file, frame.dir, frame.path
+	r.lines, r.lineno = frame.lines, frame.lineno
+	switch leveloffset := frame.leveloffset.(type) {
+	case bool:
+		delete(p.document.Attributes(), "leveloffset")
+	case string:
+		p.document.setAttr("leveloffset", leveloffset, true)
+	}
+	p.lookAhead = 0
+	return true
+}
+
+/* Select the lines of an include file listed in linesAttr:
+line numbers and ranges separated by ';' or ','
+ Examples
+   1..5;10
+   "1,3..-1"
+   7..
+Returns the selected lines, in the order of the file,
+and the line number of the first one */
+func selectLines(lines []string, linesAttr string) ([]string, int) {
+	selected := make(map[int]bool)
+	for _, linedef := range strings.FieldsFunc(linesAttr, func(r rune) bool { return r == ';' || r == ',' }) {
+		linedef = strings.TrimSpace(linedef)
+		from, to := linedef, linedef
+		if i := strings.Index(linedef, ".."); i >= 0 {
+			from, to = linedef[:i], linedef[i+2:]
+		}
+		first, err := strconv.Atoi(from)
+		if err != nil {
+			continue
+		}
+		last, err := strconv.Atoi(to)
+		if err != nil || last < 0 {
+			last = len(lines)
+		}
+		for n := first; n <= last && n <= len(lines); n++ {
+			selected[n] = true
+		}
+	}
+	numbers := []int{}
+	for n := range selected {
+		if n > 0 {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	res := []string{}
+	for _, n := range numbers {
+		res = append(res, lines[n-1])
+	}
+	if len(numbers) == 0 {
+		return res, 1
+	}
+	return res, numbers[0]
+}
+
+/* Select the lines of an include file which are inside the regions
+of the tags (separated by ';' or ','), without any tag directive line
+(the ones of the tags nested in those regions included).
+Returns the selected lines, the line number of the first one,
+and the tags which were not found */
+func selectTaggedLines(lines []string, tags string) ([]string, int, []string) {
+	wanted := make(map[string]bool)
+	names := []string{}
+	for _, tag := range strings.FieldsFunc(tags, func(r rune) bool { return r == ';' || r == ',' }) {
+		if tag = strings.TrimSpace(tag); tag != "" && !wanted[tag] {
+			wanted[tag] = true
+			names = append(names, tag)
+		}
+	}
+	found := make(map[string]bool)
+	active := make(map[string]bool)
+	res, lineno := []string{}, 1
+	for i, line := range lines {
+		if strings.Contains(line, "[]") {
+			if m := regexps.TagDirectiveRx.FindStringSubmatch(line); m != nil {
+				if wanted[m[2]] {
+					found[m[2]] = true
+					active[m[2]] = m[1] == "tag"
+				}
+				continue
+			}
+		}
+		for tag := range active {
+			if active[tag] {
+				if len(res) == 0 {
+					lineno = i + 1
+				}
+				res = append(res, line)
+				break
+			}
+		}
+	}
+	missing := []string{}
+	for _, tag := range names {
+		if !found[tag] {
+			missing = append(missing, tag)
+		}
+	}
+	return res, lineno, missing
+}
+
+/* Remove the common indentation of lines, then indent them
+(except blank lines) by size spaces */
+func indentLines(lines []string, size int) []string {
+	res := resetBlockIndent(lines)
+	padding := strings.Repeat(" ", size)
+	for i, line := range res {
+		if !isBlankLine(line) {
+			res[i] = padding + line
+		}
+	}
+	return res
+}
+
+/* Decode the content of an include file from its encoding
+(UTF-8 if empty; UTF-16 and ISO-8859-1 are supported as well).
+Returns the content read as UTF-8, and an error,
+if the encoding is not supported */
+func decodeIncludeData(data []byte, encoding string) (string, error) {
+	switch strings.ToLower(strings.Replace(encoding, "_", "-", -1)) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return string(data), nil
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes), nil
+	case "utf-16", "utf-16be", "utf-16le":
+		littleEndian := strings.HasSuffix(strings.ToLower(encoding), "le")
+		if len(data) >= 2 && data[0] == 0xff && data[1] == 0xfe {
+			littleEndian, data = true, data[2:]
+		} else if len(data) >= 2 && data[0] == 0xfe && data[1] == 0xff {
+			littleEndian, data = false, data[2:]
+		}
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			if littleEndian {
+				units[i] = uint16(data[2*i]) | uint16(data[2*i+1])<<8
+			} else {
+				units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+			}
+		}
+		return string(utf16.Decode(units)), nil
+	}
+	return string(data), fmt.Errorf("unsupported encoding '%v'", encoding)
+}
+
+/* Check whether the lines are currently skipped,
+because of a conditional block whose condition is false */
+func (p *preprocessor) isSkipping() bool {
+	return len(p.conditionalStack) > 0 && p.conditionalStack[len(p.conditionalStack)-1].skip
+}
+
+/* Process a conditional directive on the next line of r.
+ ifdef::name[] keeps the lines up to the endif if the attribute is defined
+ ifdef::name1,name2[] if any of the attributes is defined
+ ifdef::name1+name2[] if all of the attributes are defined
+ ifndef::name[] (and its ',' and '+' forms) keeps them in the opposite cases
+ ifeval::[expression] keeps them if the expression is true
+ endif::name[] (or endif::[]) ends the conditional block
+The single-line form (ifdef::name[text], ifndef::name[text]) keeps
+the text only, and needs no endif.
+Conditional blocks can be nested: inside a skipped block, the nested
+directives are only matched with their endif.
+Returns true if the directive line was consumed */
+func (p *preprocessor) processConditional(r *Reader, directive, target, delimiter, text string) bool {
+	if directive == "endif" {
+		if text != "" {
+			p.log(r, severity.ERROR, fmt.Sprintf("malformed preprocessor directive - text not permitted: endif::%v[%v]", target, text))
+		} else if len(p.conditionalStack) == 0 {
+			p.log(r, severity.ERROR, fmt.Sprintf("unmatched preprocessor directive: endif::%v[]", target))
+		} else if open := p.conditionalStack[len(p.conditionalStack)-1]; target != "" && target != open.target {
+			p.log(r, severity.ERROR, fmt.Sprintf("mismatched preprocessor directive: endif::%v[], expected endif::%v[]", target, open.target))
+		} else {
+			p.conditionalStack = p.conditionalStack[:len(p.conditionalStack)-1]
+		}
+		p.dropLine(r)
+		return true
+	}
+	if p.isSkipping() {
+		// a nested block is skipped as a whole, unless it is single-line
+		if directive == "ifeval" || text == "" {
+			p.conditionalStack = append(p.conditionalStack, &conditionalFrame{target, true, r.Cursor()})
+		}
+		p.dropLine(r)
+		return true
+	}
+	var skip bool
+	switch directive {
+	case "ifdef", "ifndef":
+		skip = !p.isDefined(target, delimiter)
+		if directive == "ifndef" {
+			skip = !skip
+		}
+	case "ifeval":
+		expression := regexps.EvalExpressionRx.FindStringSubmatch(strings.TrimSpace(text))
+		if target != "" || expression == nil {
+			p.log(r, severity.ERROR, fmt.Sprintf("malformed preprocessor directive - invalid expression: ifeval::%v[%v]", target, text))
+			p.dropLine(r)
+			return true
+		}
+		skip = !p.evaluate(expression[1], expression[2], expression[3])
+		text = ""
+	}
+	if text != "" {
+		// single-line form
+		if skip {
+			p.dropLine(r)
+			return true
+		}
+		p.replaceLine(r, text)
+		return false
+	}
+	p.conditionalStack = append(p.conditionalStack, &conditionalFrame{target, skip, r.Cursor()})
+	p.dropLine(r)
+	return true
+}
+
+/* Check whether the attributes of target are defined in the document:
+any of them if separated by ',', all of them if separated by '+' */
+func (p *preprocessor) isDefined(target, delimiter string) bool {
+	if delimiter == "" {
+		return p.document.HasAttr(target, nil, false)
+	}
+	for _, name := range strings.Split(target, delimiter) {
+		defined := p.document.HasAttr(name, nil, false)
+		if delimiter == "," && defined {
+			return true
+		}
+		if delimiter == "+" && !defined {
+			return false
+		}
+	}
+	return delimiter == "+"
+}
+
+/* Report the conditional blocks still open at the end of the lines,
+and close them */
+func (p *preprocessor) closeConditionals() {
+	for _, open := range p.conditionalStack {
+		logMessage(p.document.Logger(), severity.ERROR, fmt.Sprintf("detected unterminated preprocessor conditional directive: %v", open.target), open.cursor)
+	}
+	p.conditionalStack = nil
+}
+
+/* Evaluate the expression of an ifeval directive, comparing the values
+of lhs and rhs with op (==, !=, <=, >=, < or >).
+Values of different types are only equal to themselves (and cannot
+be ordered) */
+func (p *preprocessor) evaluate(lhs, op, rhs string) bool {
+	left, right := p.resolveExpressionValue(lhs), p.resolveExpressionValue(rhs)
+	if op == "==" || op == "!=" {
+		return (left == right) == (op == "==")
+	}
+	var comparison int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return false
+		}
+		comparison = int(sign(l - r))
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return false
+		}
+		comparison = strings.Compare(l, r)
+	default:
+		return false
+	}
+	switch op {
+	case "<":
+		return comparison < 0
+	case "<=":
+		return comparison <= 0
+	case ">":
+		return comparison > 0
+	}
+	return comparison >= 0
+}
+
+/* Resolve a value of an ifeval expression, after substituting its
+attribute references (dropping the missing ones):
+a quoted value is a string, 'true' and 'false' are booleans,
+an empty value is nil, and any other value is a number
+(0 if it does not start with one) */
+func (p *preprocessor) resolveExpressionValue(value string) interface{} {
+	quoted := len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0]
+	if quoted {
+		value = value[1 : len(value)-1]
+	}
+	if strings.Contains(value, "{") {
+		value = p.document.SubAttributes(value, &OptionsParseAttributes{attribute_missing: "drop"})
+	}
+	switch {
+	case quoted:
+		return value
+	case value == "":
+		return nil
+	case value == "true":
+		return true
+	case value == "false":
+		return false
+	case strings.TrimSpace(value) == "":
+		return " "
+	}
+	res, _ := strconv.ParseFloat(leadingNumberRx.FindString(value), 64)
+	return res
+}
+
+/* The number at the beginning of a value */
+var leadingNumberRx, _ = regexp.Compile(`^[+-]?\d+(?:\.\d+)?`)
+
+/* The sign of a difference: -1, 0 or 1 */
+func sign(difference float64) float64 {
+	switch {
+	case difference < 0:
+		return -1
+	case difference > 0:
+		return 1
+	}
+	return 0
+}
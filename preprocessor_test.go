@@ -0,0 +1,314 @@
+package asciidocgo
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/VonC/asciidocgo/consts/severity"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPreprocessor(t *testing.T) {
+
+	includeDocument := func(safe string, lines ...string) *Document {
+		return NewDocument(lines, map[string]string{"safe": safe, "base_dir": "test/include"})
+	}
+
+	Convey("A Document reader replaces an include directive by the lines of the file", t, func() {
+		doc := includeDocument("safe", "before", "include::nested/part.adoc[]", "after")
+		r := doc.Reader()
+		So(r.ReadLines(), ShouldResemble, []string{"before", "=== Part", "", "Part text.", "after"})
+		So(len(doc.Logger().(*MemoryLogger).Messages()), ShouldEqual, 0)
+
+		Convey("Nested includes are resolved from the directory of their file", func() {
+			doc := includeDocument("safe", "include::chapter.adoc[]")
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{"== Chapter", "", "Chapter text.", "", "=== Part", "", "Part text."})
+			So(doc.References().HasInclude("chapter"), ShouldBeTrue)
+			So(doc.References().HasInclude("nested/part"), ShouldBeTrue)
+			So(doc.References().HasInclude("index"), ShouldBeFalse)
+		})
+		Convey("The lines of an include file report their own position", func() {
+			r := includeDocument("safe", "include::chapter.adoc[]", "after").Reader()
+			r.PeekLine()
+			So(r.LineInfo(), ShouldEqual, "chapter.adoc:1")
+			r.ReadLinesUntil(func(line string) bool { return line == "=== Part" })
+			So(r.LineInfo(), ShouldEqual, "nested/part.adoc:1")
+			r.ReadLinesUntil(func(line string) bool { return line == "after" })
+			So(r.LineInfo(), ShouldEqual, "<stdin>:2")
+		})
+		Convey("Peeking at lines processes their directives too", func() {
+			r := includeDocument("safe", "include::nested/part.adoc[]", "after").Reader()
+			So(r.PeekLines(2), ShouldResemble, []string{"=== Part", ""})
+			So(r.LineInfo(), ShouldEqual, "nested/part.adoc:1")
+			Convey("But stops at the start or at the end of an include file", func() {
+				r := includeDocument("safe", "a", "include::nested/part.adoc[]", "after").Reader()
+				So(r.PeekLines(3), ShouldResemble, []string{"a"})
+				So(r.ReadLinesUntil(func(line string) bool { return line == "Part text." }), ShouldResemble, []string{"a", "=== Part", ""})
+				So(r.PeekLines(2), ShouldResemble, []string{"Part text."})
+				So(r.LineInfo(), ShouldEqual, "nested/part.adoc:3")
+				So(r.ReadLines(), ShouldResemble, []string{"Part text.", "after"})
+			})
+		})
+		Convey("An escaped include directive is kept as is", func() {
+			r := includeDocument("safe", "\\include::chapter.adoc[]").Reader()
+			So(r.ReadLines(), ShouldResemble, []string{"include::chapter.adoc[]"})
+		})
+		Convey("The target can reference attributes", func() {
+			doc := includeDocument("safe", "include::{dir}/part.adoc[]")
+			doc.setAttr("dir", "nested", true)
+			So(doc.Reader().ReadLines()[0], ShouldEqual, "=== Part")
+		})
+	})
+
+	Convey("An include directive can select the lines to include", t, func() {
+		Convey("By line numbers and ranges", func() {
+			r := includeDocument("safe", "include::snippet.go[lines=1..2;9]").Reader()
+			So(r.LineNo(), ShouldEqual, 1)
+			So(r.ReadLines(), ShouldResemble, []string{"package main", "", "\tfmt.Println(\"hello\")"})
+			r = includeDocument("safe", "include::snippet.go[lines=\"7..\"]").Reader()
+			r.PeekLine()
+			So(r.LineInfo(), ShouldEqual, "snippet.go:7")
+			So(r.ReadLines(), ShouldResemble, []string{"func main() {", "\t// tag::body[]", "\tfmt.Println(\"hello\")", "\t// end::body[]", "}"})
+			lines, lineno := selectLines([]string{"a", "b", "c"}, "3,1..-1")
+			So(lines, ShouldResemble, []string{"a", "b", "c"})
+			So(lineno, ShouldEqual, 1)
+		})
+		Convey("By tags", func() {
+			r := includeDocument("safe", "include::snippet.go[tag=body]").Reader()
+			r.PeekLine()
+			So(r.LineInfo(), ShouldEqual, "snippet.go:9")
+			So(r.ReadLines(), ShouldResemble, []string{"\tfmt.Println(\"hello\")"})
+			doc := includeDocument("safe", "include::snippet.go[tags=imports;body;missing]")
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{"import \"fmt\"", "\tfmt.Println(\"hello\")"})
+			messages := doc.Logger().(*MemoryLogger).Messages()
+			So(len(messages), ShouldEqual, 1)
+			So(messages[0].Severity, ShouldEqual, severity.WARN)
+			So(messages[0].Text, ShouldStartWith, "tag 'missing' not found in include file: ")
+		})
+		Convey("Without the directives of the tags nested in them", func() {
+			r := includeDocument("safe", "include::tags.xml[tag=outer]").Reader()
+			So(r.ReadLines(), ShouldResemble, []string{"<a/>", "<b/>", "<c/>"})
+			r = includeDocument("safe", "include::tags.xml[tag=inner]").Reader()
+			So(r.ReadLines(), ShouldResemble, []string{"<b/>"})
+		})
+	})
+
+	Convey("An include directive can adapt the included lines", t, func() {
+		Convey("By reindenting them", func() {
+			r := includeDocument("safe", "include::snippet.go[tag=body,indent=2]").Reader()
+			So(r.ReadLines(), ShouldResemble, []string{"  fmt.Println(\"hello\")"})
+			r = includeDocument("safe", "include::snippet.go[tag=body,indent=0]").Reader()
+			So(r.ReadLines(), ShouldResemble, []string{"fmt.Println(\"hello\")"})
+		})
+		Convey("By decoding them", func() {
+			r := includeDocument("safe", "include::latin1.txt[encoding=iso-8859-1]").Reader()
+			So(r.ReadLines(), ShouldResemble, []string{"café"})
+			res, err := decodeIncludeData([]byte{0xff, 0xfe, 'a', 0, 'b', 0}, "utf-16")
+			So(res, ShouldEqual, "ab")
+			So(err, ShouldBeNil)
+			_, err = decodeIncludeData([]byte("a"), "ebcdic")
+			So(err.Error(), ShouldEqual, "unsupported encoding 'ebcdic'")
+		})
+		Convey("By shifting the level of their section titles", func() {
+			doc, _ := includeDocument("safe", "== Top", "", "include::nested/part.adoc[leveloffset=-1]", "", "=== After").Parse()
+			sections := doc.Blocks()
+			So(len(sections), ShouldEqual, 2)
+			So(sections[1].Title(), ShouldEqual, "Part")
+			So(sections[1].Level(), ShouldEqual, 1)
+			So(sections[1].Blocks()[1].Title(), ShouldEqual, "After")
+			So(sections[1].Blocks()[1].Level(), ShouldEqual, 2)
+			So(doc.HasAttr("leveloffset", nil, false), ShouldBeFalse)
+			So(resolveLevelOffset("1", "+1"), ShouldEqual, "2")
+			So(resolveLevelOffset("1", "-2"), ShouldEqual, "-1")
+			So(resolveLevelOffset("1", "0"), ShouldEqual, "0")
+			Convey("Including a section title on the last line of the file", func() {
+				doc, _ := includeDocument("safe", "== Top", "", "include::nested/last.adoc[leveloffset=+1]", "", "== After").Parse()
+				sections := doc.Blocks()
+				So(len(sections), ShouldEqual, 2)
+				So(sections[0].Blocks()[1].Title(), ShouldEqual, "Last Title")
+				So(sections[0].Blocks()[1].Level(), ShouldEqual, 2)
+				So(sections[0].Blocks()[1].SourceLocation().String(), ShouldEqual, "nested/last.adoc:3")
+				So(sections[1].Level(), ShouldEqual, 1)
+				res, _ := doc.Render()
+				So(res, ShouldContainSubstring, "<div class=\"sect2\">\n<h3 id=\"_last_title\">Last Title</h3>")
+			})
+		})
+	})
+
+	Convey("An include directive depends on the safe mode", t, func() {
+		Convey("In SECURE mode, it becomes a link", func() {
+			r := includeDocument("secure", "include::chapter.adoc[]").Reader()
+			So(r.ReadLines(), ShouldResemble, []string{"link:chapter.adoc[]"})
+		})
+		Convey("In SAFE mode, a file outside of the base dir is refused", func() {
+			doc := includeDocument("safe", "include::../t.txt[]")
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{"Unresolved directive in <stdin> - include::../t.txt[]"})
+			messages := doc.Logger().(*MemoryLogger).Messages()
+			So(messages[0].Severity, ShouldEqual, severity.ERROR)
+			So(messages[0].Text, ShouldContainSubstring, "refers to location outside jail")
+			So(messages[0].LineNo, ShouldEqual, 1)
+		})
+		Convey("In UNSAFE mode, a file outside of the base dir is included", func() {
+			r := includeDocument("unsafe", "include::../t.txt[]").Reader()
+			So(r.ReadLines(), ShouldResemble, []string{"test data"})
+		})
+	})
+
+	Convey("An include directive can be processed by an include processor", t, func() {
+		registry := NewRegistry().IncludeProcessor(NewMapIncludeProcessor("db:", map[string]string{
+			"snippet-7": "== Snippet\n\n// tag::a[]\nLine a.\n// end::a[]\nLine b.",
+		}))
+		Convey("Which provides the content of the targets it handles", func() {
+			doc := includeDocument("safe", "before", "include::db:snippet-7[lines=3..4]", "after").UseExtensions(registry)
+			r := doc.Reader()
+			line, _ := r.ReadLine()
+			So(line, ShouldEqual, "before")
+			r.PeekLine()
+			So(r.LineInfo(), ShouldEqual, "db:snippet-7:3")
+			So(r.ReadLines(), ShouldResemble, []string{"// tag::a[]", "Line a.", "after"})
+			So(len(doc.Logger().(*MemoryLogger).Messages()), ShouldEqual, 0)
+		})
+		Convey("With the same attributes as an include file", func() {
+			doc := includeDocument("safe", "include::db:snippet-7[tags=a;missing,indent=2]").UseExtensions(registry)
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{"  Line a."})
+			messages := doc.Logger().(*MemoryLogger).Messages()
+			So(len(messages), ShouldEqual, 1)
+			So(messages[0].String(), ShouldEqual, "asciidocgo: WARNING: line 1: tag 'missing' not found in include file: db:snippet-7")
+			doc, _ = includeDocument("safe", "= Title", "", "include::db:snippet-7[leveloffset=+1]").UseExtensions(registry).Parse()
+			So(doc.Blocks()[0].Level(), ShouldEqual, 2)
+		})
+		Convey("But not in SECURE mode, nor for the targets it does not handle", func() {
+			r := includeDocument("secure", "include::db:snippet-7[]").UseExtensions(registry).Reader()
+			So(r.ReadLines(), ShouldResemble, []string{"link:db:snippet-7[]"})
+			r = includeDocument("safe", "include::nested/part.adoc[]", "include::db:other[]").UseExtensions(registry).Reader()
+			So(r.ReadLines(), ShouldResemble, []string{"=== Part", "", "Part text.", "link:db:other[]"})
+		})
+	})
+
+	Convey("An include directive reads the files of the file system of its document", t, func() {
+		fsys := fstest.MapFS{
+			"secret.adoc":          &fstest.MapFile{Data: []byte("Secret.")},
+			"docs/index.adoc":      &fstest.MapFile{Data: []byte("include::parts/part.adoc[]")},
+			"docs/parts/part.adoc": &fstest.MapFile{Data: []byte("Part.\ninclude::../../secret.adoc[]")},
+			"docs-evil/evil.adoc":  &fstest.MapFile{Data: []byte("Evil.")},
+		}
+		Convey("With its base dir as jail, in SAFE mode", func() {
+			doc := NewDocument([]string{"include::index.adoc[]"}, map[string]string{"safe": "safe", "base_dir": "docs"}).UseFS(fsys)
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{"Part.", "Unresolved directive in parts/part.adoc - include::../../secret.adoc[]"})
+			messages := doc.Logger().(*MemoryLogger).Messages()
+			So(len(messages), ShouldEqual, 1)
+			So(messages[0].String(), ShouldEqual, "asciidocgo: ERROR: parts/part.adoc: line 2: include file '../../secret.adoc' refers to location outside jail: '/docs' (disallowed in safe mode)")
+		})
+		Convey("Not in a sibling directory of its base dir, in SAFE mode", func() {
+			doc := NewDocument([]string{"include::/docs-evil/evil.adoc[]", "include::../docs-evil/evil.adoc[]"}, map[string]string{"safe": "safe", "base_dir": "docs"}).UseFS(fsys)
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{"Unresolved directive in <stdin> - include::/docs-evil/evil.adoc[]",
+				"Unresolved directive in <stdin> - include::../docs-evil/evil.adoc[]"})
+			messages := doc.Logger().(*MemoryLogger).Messages()
+			So(len(messages), ShouldEqual, 2)
+			So(messages[0].Text, ShouldEqual, "include file not found: /docs/docs-evil/evil.adoc")
+			So(messages[1].Text, ShouldEqual, "include file '../docs-evil/evil.adoc' refers to location outside jail: '/docs' (disallowed in safe mode)")
+		})
+		Convey("With its root as jail, in UNSAFE mode", func() {
+			doc := NewDocument([]string{"include::index.adoc[]", "include::../../secret.adoc[]"}, map[string]string{"safe": "unsafe", "base_dir": "docs"}).UseFS(fsys)
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{"Part.", "Secret.", "Unresolved directive in <stdin> - include::../../secret.adoc[]"})
+			So(doc.Logger().(*MemoryLogger).Messages()[0].Text, ShouldEqual, "include file '../../secret.adoc' refers to location outside jail: '/' (disallowed in safe mode)")
+		})
+	})
+
+	Convey("An include directive which cannot be processed is reported", t, func() {
+		Convey("If the file does not exist", func() {
+			doc := includeDocument("safe", "include::missing.adoc[lines=1]")
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{"Unresolved directive in <stdin> - include::missing.adoc[lines=1]"})
+			So(doc.Logger().(*MemoryLogger).Messages()[0].Text, ShouldStartWith, "include file not found: ")
+		})
+		Convey("If the includes are nested too deeply", func() {
+			doc := includeDocument("safe", "include::chapter.adoc[]")
+			doc.setAttr("max-include-depth", "1", true)
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{"== Chapter", "", "Chapter text.", "", "Unresolved directive in chapter.adoc - include::nested/part.adoc[]"})
+			message := doc.Logger().(*MemoryLogger).Messages()[0]
+			So(message.String(), ShouldEqual, "asciidocgo: ERROR: chapter.adoc: line 5: maximum include depth of 1 exceeded")
+		})
+		Convey("If a file includes itself", func() {
+			doc := includeDocument("safe", "include::loop.adoc[]")
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{"Unresolved directive in loop.adoc - include::loop.adoc[]"})
+			messages := doc.Logger().(*MemoryLogger).Messages()
+			So(len(messages), ShouldEqual, 1)
+			So(messages[0].String(), ShouldStartWith, "asciidocgo: ERROR: loop.adoc: line 1: circular include: ")
+			So(messages[0].Text, ShouldEndWith, "loop.adoc")
+		})
+	})
+
+	Convey("A Document renders the included content", t, func() {
+		doc, _ := includeDocument("safe", "[source,go]", "----", "include::snippet.go[tag=body,indent=0]", "----").Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, `data-lang="go">fmt.Println("hello")</code>`)
+	})
+
+	Convey("A Document reader keeps or skips the lines of conditional blocks", t, func() {
+		conditionalDocument := func(lines ...string) *Document {
+			doc := NewDocument(lines, map[string]string{"backend": "html5"})
+			doc.setAttr("sectnumlevels", "3", true)
+			doc.setAttr("env", "site", true)
+			return doc
+		}
+		Convey("ifdef keeps them if the attribute is defined", func() {
+			r := conditionalDocument("a", "ifdef::backend-html5[]", "b", "endif::backend-html5[]", "ifdef::backend-docbook5[]", "c", "endif::[]", "d").Reader()
+			So(r.ReadLines(), ShouldResemble, []string{"a", "b", "d"})
+		})
+		Convey("ifndef keeps them if the attribute is not defined", func() {
+			r := conditionalDocument("ifndef::env-github[]", "a", "endif::env-github[]", "ifndef::env[]", "b", "endif::env[]").Reader()
+			So(r.ReadLines(), ShouldResemble, []string{"a"})
+		})
+		Convey("',' means any of the attributes, '+' all of them", func() {
+			r := conditionalDocument("ifdef::env-github,env[]", "a", "endif::[]", "ifdef::env-github+env[]", "b", "endif::[]",
+				"ifndef::env-github,env[]", "c", "endif::[]", "ifndef::env-github+env[]", "d", "endif::[]").Reader()
+			So(r.ReadLines(), ShouldResemble, []string{"a", "d"})
+		})
+		Convey("The single-line form keeps its text only", func() {
+			r := conditionalDocument("ifdef::env[Site only.]", "ifdef::env-github[GitHub only.]", "ifndef::env-github[Not on GitHub.]", "\\ifdef::env[]").Reader()
+			So(r.ReadLines(), ShouldResemble, []string{"Site only.", "Not on GitHub.", "ifdef::env[]"})
+		})
+		Convey("ifeval keeps them if the expression is true", func() {
+			doc := conditionalDocument("ifeval::[{sectnumlevels} > 2]", "a", "endif::[]", "ifeval::[\"{backend}\" == 'html5']", "b", "endif::[]",
+				"ifeval::[{sectnumlevels} <= 2]", "c", "endif::[]", "ifeval::[\"{missing}\" == \"\"]", "d", "endif::[]", "ifeval::[\"{env}\" < \"site\"]", "e", "endif::[]")
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{"a", "b", "d"})
+			So(len(doc.Logger().(*MemoryLogger).Messages()), ShouldEqual, 0)
+			p := &preprocessor{document: conditionalDocument()}
+			So(p.evaluate("1.5", ">=", "1"), ShouldBeTrue)
+			So(p.evaluate("true", "==", "true"), ShouldBeTrue)
+			So(p.evaluate("'1'", "==", "1"), ShouldBeFalse)
+			So(p.evaluate("'1'", "<", "2"), ShouldBeFalse)
+			So(p.evaluate("'a'", "<", "'b'"), ShouldBeTrue)
+		})
+		Convey("Conditional blocks can be nested", func() {
+			r := conditionalDocument("ifdef::env[]", "a", "ifdef::missing[]", "b", "ifdef::env[]", "c", "endif::env[]", "d", "endif::missing[]", "e", "endif::env[]", "f").Reader()
+			So(r.ReadLines(), ShouldResemble, []string{"a", "e", "f"})
+		})
+		Convey("Skipped lines still count in the line numbers", func() {
+			doc := conditionalDocument("ifdef::missing[]", "a", "include::missing.adoc[]", "endif::[]", "b", "ifdef::env[]", "endif::[]", "endif::[]")
+			r := doc.Reader()
+			line, _ := r.ReadLine()
+			So(line, ShouldEqual, "b")
+			So(r.PrevLineInfo(), ShouldEqual, "<stdin>:5")
+			So(r.HasMoreLines(), ShouldBeFalse)
+			messages := doc.Logger().(*MemoryLogger).Messages()
+			So(len(messages), ShouldEqual, 1)
+			So(messages[0].String(), ShouldEqual, "asciidocgo: ERROR: line 8: unmatched preprocessor directive: endif::[]")
+		})
+		Convey("Malformed or unterminated directives are reported", func() {
+			doc := conditionalDocument("ifdef::env[]", "ifeval::[nothing]", "a", "endif::other[]", "endif::env[text]")
+			So(doc.Reader().ReadLines(), ShouldResemble, []string{"a"})
+			messages := doc.Logger().(*MemoryLogger).Messages()
+			So(len(messages), ShouldEqual, 4)
+			So(messages[0].Text, ShouldEqual, "malformed preprocessor directive - invalid expression: ifeval::[nothing]")
+			So(messages[1].Text, ShouldEqual, "mismatched preprocessor directive: endif::other[], expected endif::env[]")
+			So(messages[2].Text, ShouldEqual, "malformed preprocessor directive - text not permitted: endif::env[text]")
+			So(messages[3].String(), ShouldEqual, "asciidocgo: ERROR: line 1: detected unterminated preprocessor conditional directive: env")
+		})
+		Convey("The attributes set above a directive are taken into account", func() {
+			doc, _ := conditionalDocument(":flag:", "", "ifdef::flag[]", "Flagged.", "endif::flag[]").Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, "<p>Flagged.</p>")
+		})
+	})
+}
@@ -0,0 +1,305 @@
+package asciidocgo
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+/* The position of a line in a source document:
+the file and directory it comes from, and its line number. */
+type Cursor struct {
+	file   string
+	dir    string
+	path   string
+	lineno int
+}
+
+/* Initialize a Cursor.
+file   - the String path of the source file (may be empty)
+dir    - the String directory of the source file (may be empty)
+path   - the String name used to report the position (may be empty)
+lineno - the Integer line number (1-based) */
+func NewCursor(file, dir, path string, lineno int) *Cursor {
+	return &Cursor{file, dir, path, lineno}
+}
+
+/* The String path of the source file */
+func (c *Cursor) File() string {
+	return c.file
+}
+
+/* The String directory of the source file */
+func (c *Cursor) Dir() string {
+	return c.dir
+}
+
+/* The String name used to report the position of a line */
+func (c *Cursor) Path() string {
+	return c.path
+}
+
+/* The Integer line number (1-based) */
+func (c *Cursor) LineNo() int {
+	return c.lineno
+}
+
+/* Get the position of the line as "path:lineno".
+The path defaults to "<stdin>" when the source has no name. */
+func (c *Cursor) String() string {
+	path := c.path
+	if path == "" {
+		path = "<stdin>"
+	}
+	return path + ":" + strconv.Itoa(c.lineno)
+}
+
+/* Methods for retrieving lines from AsciiDoc source files.
+The Reader keeps track of the line number of the next line to be read,
+so that any line it returns can be traced back to its source.
+A Reader created by a Document also processes the preprocessor
+directives (include::) of the lines, as they are reached. */
+type Reader struct {
+	file         string
+	dir          string
+	path         string
+	lines        []string
+	lineno       int
+	preprocessor *preprocessor
+}
+
+/* Initialize the Reader object.
+data - the Array of Strings holding the AsciiDoc source data
+file - the String path of the source file (may be empty, for strings
+or non-file readers) */
+func NewReader(data []string, file string) *Reader {
+	lines := make([]string, len(data))
+	copy(lines, data)
+	reader := &Reader{lines: lines, lineno: 1}
+	if file != "" {
+		reader.file = file
+		reader.dir = filepath.Dir(file)
+		reader.path = filepath.Base(file)
+	}
+	return reader
+}
+
+/* Initialize a Reader over lines which are part of a larger source,
+starting at the position of cursor (used to parse the content of a
+delimited block with accurate line numbers) */
+func newReaderAt(data []string, cursor *Cursor) *Reader {
+	reader := NewReader(data, "")
+	if cursor != nil {
+		reader.file, reader.dir, reader.path = cursor.file, cursor.dir, cursor.path
+		reader.lineno = cursor.lineno
+	}
+	return reader
+}
+
+/* The position of the next line to be read */
+func (r *Reader) Cursor() *Cursor {
+	return NewCursor(r.file, r.dir, r.path, r.lineno)
+}
+
+/* The position of the previous line read, as "path:lineno" */
+func (r *Reader) PrevLineInfo() string {
+	return NewCursor(r.file, r.dir, r.path, r.lineno-1).String()
+}
+
+/* The position of the next line to be read, as "path:lineno" */
+func (r *Reader) LineInfo() string {
+	return r.Cursor().String()
+}
+
+/* The Integer line number of the next line to be read */
+func (r *Reader) LineNo() int {
+	return r.lineno
+}
+
+/* Check whether there are any lines left to read.
+The preprocessor directives of the next line are processed first. */
+func (r *Reader) HasMoreLines() bool {
+	if r.preprocessor != nil {
+		r.preprocessor.processNextLine(r)
+	}
+	return len(r.lines) > 0
+}
+
+/* Check whether the reader has no more lines to read */
+func (r *Reader) IsEOF() bool {
+	return !r.HasMoreLines()
+}
+
+/* Check whether the next line is empty (or only made of whitespaces).
+Returns true if there is no next line */
+func (r *Reader) IsNextLineEmpty() bool {
+	return !r.HasMoreLines() || isBlankLine(r.lines[0])
+}
+
+/* Peek at the next line of source data, without consuming it.
+Returns the String next line and true, or "" and false if there are
+no more lines */
+func (r *Reader) PeekLine() (string, bool) {
+	if !r.HasMoreLines() {
+		return "", false
+	}
+	return r.lines[0], true
+}
+
+/* Peek at the next n lines of source data, without consuming them.
+Returns as many lines as are available, up to n (or, for a Reader
+processing the preprocessor directives, up to the start or the end
+of an include file: see preprocessor.peekLines) */
+func (r *Reader) PeekLines(n int) []string {
+	if r.preprocessor != nil {
+		return r.preprocessor.peekLines(r, n)
+	}
+	if n > len(r.lines) {
+		n = len(r.lines)
+	}
+	if n < 0 {
+		n = 0
+	}
+	res := make([]string, n)
+	copy(res, r.lines[:n])
+	return res
+}
+
+/* Get the next line of source data, consuming it.
+Returns the String next line and true, or "" and false if there are
+no more lines */
+func (r *Reader) ReadLine() (string, bool) {
+	if !r.HasMoreLines() {
+		return "", false
+	}
+	line := r.lines[0]
+	r.lines = r.lines[1:]
+	r.lineno++
+	if r.preprocessor != nil && r.preprocessor.lookAhead > 0 {
+		r.preprocessor.lookAhead--
+	}
+	return line, true
+}
+
+/* Advance to the next line by discarding it.
+Returns true if a line was discarded */
+func (r *Reader) Advance() bool {
+	_, ok := r.ReadLine()
+	return ok
+}
+
+/* Get the remaining lines of source data, consuming them all */
+func (r *Reader) ReadLines() []string {
+	if r.preprocessor != nil {
+		return r.ReadLinesUntil(nil)
+	}
+	res := r.lines
+	r.lineno += len(res)
+	r.lines = []string{}
+	return res
+}
+
+/* Get the remaining lines of source data joined as a String,
+consuming them all */
+func (r *Reader) Read() string {
+	return strings.Join(r.ReadLines(), "\n")
+}
+
+/* Push the String line onto the beginning of the lines to read.
+The line number is moved back accordingly */
+func (r *Reader) UnshiftLine(line string) {
+	r.UnshiftLines([]string{line})
+}
+
+/* Push an Array of lines onto the beginning of the lines to read,
+keeping their order */
+func (r *Reader) UnshiftLines(lines []string) {
+	r.lines = append(append([]string{}, lines...), r.lines...)
+	r.lineno -= len(lines)
+	if r.preprocessor != nil {
+		// lines pushed back have already been preprocessed
+		r.preprocessor.lookAhead += len(lines)
+	}
+}
+
+/* Push the content of an include (from an include processor) onto the
+beginning of the lines to read, as an include file would be: its lines
+are selected and adapted by the lines, tag(s), indent and leveloffset
+attributes, and read with their own path and line numbers (and their
+own preprocessor directives), before the lines which follow.
+content    - the String content to include
+file       - the String path of the content, to resolve its own
+             includes (may be empty, to resolve them as the current
+             file does)
+path       - the String path reported for the lines of content
+attributes - the attributes of the include directive (may be nil) */
+func (r *Reader) PushInclude(content, file, path string, attributes map[string]interface{}) {
+	if r.preprocessor == nil {
+		r.UnshiftLines(splitLines(content))
+		return
+	}
+	r.preprocessor.pushIncludeContent(r, content, file, path, attributes)
+}
+
+/* Replace the next line with the specified line,
+keeping the current line number */
+func (r *Reader) ReplaceLine(line string) {
+	if r.HasMoreLines() {
+		r.lines[0] = line
+	} else {
+		r.lines = []string{line}
+	}
+}
+
+/* Strip off leading blank lines.
+Returns the Integer number of lines skipped */
+func (r *Reader) SkipBlankLines() int {
+	skipped := 0
+	for r.HasMoreLines() && isBlankLine(r.lines[0]) {
+		r.ReadLine()
+		skipped++
+	}
+	return skipped
+}
+
+/* Return all the lines until breakOn returns true for a line,
+or until there are no more lines.
+The line for which breakOn returns true is not consumed */
+func (r *Reader) ReadLinesUntil(breakOn func(line string) bool) []string {
+	res := []string{}
+	for r.HasMoreLines() {
+		line := r.lines[0]
+		if breakOn != nil && breakOn(line) {
+			break
+		}
+		r.ReadLine()
+		res = append(res, line)
+	}
+	return res
+}
+
+/* Return all the lines until the delimiter line is found,
+or until there are no more lines.
+The delimiter line is consumed, but not returned.
+Returns the lines read, and whether the delimiter was found */
+func (r *Reader) ReadLinesUntilDelimiter(delimiter string) ([]string, bool) {
+	res := r.ReadLinesUntil(func(line string) bool { return line == delimiter })
+	return res, r.Advance()
+}
+
+/* Return the String source lines that remain to be read
+(without consuming them) */
+func (r *Reader) Lines() []string {
+	return r.PeekLines(len(r.lines))
+}
+
+/* Get the remaining lines of source data joined as a String,
+without consuming them */
+func (r *Reader) Source() string {
+	return strings.Join(r.lines, "\n")
+}
+
+/* Get the position of the reader, as "path:lineno" */
+func (r *Reader) String() string {
+	return r.LineInfo()
+}
@@ -0,0 +1,111 @@
+package asciidocgo
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReader(t *testing.T) {
+
+	Convey("A Cursor reports a position as path:lineno", t, func() {
+		c := NewCursor("/docs/guide.adoc", "/docs", "guide.adoc", 42)
+		So(c.File(), ShouldEqual, "/docs/guide.adoc")
+		So(c.Dir(), ShouldEqual, "/docs")
+		So(c.Path(), ShouldEqual, "guide.adoc")
+		So(c.LineNo(), ShouldEqual, 42)
+		So(c.String(), ShouldEqual, "guide.adoc:42")
+		So(NewCursor("", "", "", 3).String(), ShouldEqual, "<stdin>:3")
+	})
+
+	Convey("A Reader can be initialized", t, func() {
+		Convey("With no file, it has no path", func() {
+			r := NewReader([]string{"a"}, "")
+			So(r.Cursor().Path(), ShouldEqual, "")
+			So(r.LineNo(), ShouldEqual, 1)
+			So(r.String(), ShouldEqual, "<stdin>:1")
+		})
+		Convey("With a file, it reports lines relative to the file name", func() {
+			r := NewReader([]string{"a"}, "/docs/guide.adoc")
+			So(r.Cursor().File(), ShouldEqual, "/docs/guide.adoc")
+			So(r.Cursor().Dir(), ShouldEqual, "/docs")
+			So(r.LineInfo(), ShouldEqual, "guide.adoc:1")
+		})
+		Convey("It does not modify the data it reads", func() {
+			data := []string{"a", "b"}
+			r := NewReader(data, "")
+			r.ReplaceLine("c")
+			So(data[0], ShouldEqual, "a")
+			So(r.Lines(), ShouldResemble, []string{"c", "b"})
+		})
+	})
+
+	Convey("A Reader can peek at and read lines", t, func() {
+		r := NewReader([]string{"first", "second", "third"}, "guide.adoc")
+		line, ok := r.PeekLine()
+		So(line, ShouldEqual, "first")
+		So(ok, ShouldBeTrue)
+		So(r.LineNo(), ShouldEqual, 1)
+		So(r.PeekLines(2), ShouldResemble, []string{"first", "second"})
+		So(r.PeekLines(10), ShouldResemble, []string{"first", "second", "third"})
+		So(r.PeekLines(-1), ShouldResemble, []string{})
+		line, ok = r.ReadLine()
+		So(line, ShouldEqual, "first")
+		So(r.PrevLineInfo(), ShouldEqual, "guide.adoc:1")
+		So(r.LineInfo(), ShouldEqual, "guide.adoc:2")
+		So(r.Advance(), ShouldBeTrue)
+		So(r.Source(), ShouldEqual, "third")
+		So(r.Read(), ShouldEqual, "third")
+		So(r.LineNo(), ShouldEqual, 4)
+		So(r.HasMoreLines(), ShouldBeFalse)
+		So(r.IsEOF(), ShouldBeTrue)
+		So(r.IsNextLineEmpty(), ShouldBeTrue)
+		line, ok = r.PeekLine()
+		So(ok, ShouldBeFalse)
+		line, ok = r.ReadLine()
+		So(line, ShouldEqual, "")
+		So(ok, ShouldBeFalse)
+		So(r.Advance(), ShouldBeFalse)
+		So(r.LineNo(), ShouldEqual, 4)
+	})
+
+	Convey("A Reader can push lines back", t, func() {
+		r := NewReader([]string{"a", "b", "c"}, "")
+		line, _ := r.ReadLine()
+		r.UnshiftLine(line)
+		So(r.LineNo(), ShouldEqual, 1)
+		So(r.ReadLines(), ShouldResemble, []string{"a", "b", "c"})
+		So(r.LineNo(), ShouldEqual, 4)
+		r.UnshiftLines([]string{"b", "c"})
+		So(r.LineNo(), ShouldEqual, 2)
+		So(r.Lines(), ShouldResemble, []string{"b", "c"})
+		r.ReadLines()
+		r.ReplaceLine("d")
+		So(r.Lines(), ShouldResemble, []string{"d"})
+	})
+
+	Convey("A Reader can skip blank lines", t, func() {
+		r := NewReader([]string{"", "  ", "a", ""}, "")
+		So(r.IsNextLineEmpty(), ShouldBeTrue)
+		So(r.SkipBlankLines(), ShouldEqual, 2)
+		So(r.LineNo(), ShouldEqual, 3)
+		So(r.IsNextLineEmpty(), ShouldBeFalse)
+		So(r.SkipBlankLines(), ShouldEqual, 0)
+	})
+
+	Convey("A Reader can read lines until a condition or a delimiter", t, func() {
+		r := NewReader([]string{"a", "b", "", "----", "code", "----", "c"}, "")
+		So(r.ReadLinesUntil(isBlankLine), ShouldResemble, []string{"a", "b"})
+		So(r.LineNo(), ShouldEqual, 3)
+		r.SkipBlankLines()
+		r.Advance()
+		lines, found := r.ReadLinesUntilDelimiter("----")
+		So(lines, ShouldResemble, []string{"code"})
+		So(found, ShouldBeTrue)
+		So(r.LineInfo(), ShouldEqual, "<stdin>:7")
+		lines, found = r.ReadLinesUntilDelimiter("====")
+		So(lines, ShouldResemble, []string{"c"})
+		So(found, ShouldBeFalse)
+		So(r.ReadLinesUntil(nil), ShouldResemble, []string{})
+	})
+}
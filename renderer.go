@@ -1,12 +1,145 @@
 package asciidocgo
 
+import (
+	"bytes"
+	"html/template"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
 /* Methods for rendering Asciidoc Documents, Sections, and Blocks
-using <del>eRuby</del> Go templates */
-type Renderer struct{}
+using <del>eRuby</del> Go templates.
+The templates are looked up by the template name of the node to render
+('document', 'section', 'block_paragraph', ...), and get that node
+as their data: they come from the built-in (HTML5) template set,
+and/or from a template_dir.
+A node without template is converted by the built-in converter
+of the backend (HTML5 or DocBook 5). */
+type Renderer struct {
+	templates *template.Template
+	converter Converter
+	err       error
+}
+
+/* Functions available to the templates:
+raw marks an already rendered (or substituted) String as safe HTML,
+add sums two Integers (to compute a heading level) */
+var rendererFuncs = template.FuncMap{
+	"raw": func(s string) template.HTML { return template.HTML(s) },
+	"add": func(a, b int) int { return a + b },
+}
+
+/* The built-in converters, by backend name */
+var converters = map[string]Converter{
+	"html5":    &html5Converter{},
+	"docbook5": &docbook5Converter{},
+}
+
+/* Initialize the Renderer with the built-in HTML5 converter,
+overridden by the templates found in templateDir (if not empty).
+Each file of templateDir defines the template named after the file name,
+up to its first dot: 'block_paragraph.html' defines 'block_paragraph'.
+Returns an error if a template cannot be read or parsed */
+func NewRenderer(templateDir string) (*Renderer, error) {
+	return newRenderer(nil, templateDir, false)
+}
+
+/* Initialize the Renderer with the built-in templates (see
+builtinTemplates), overridden by the templates found in templateDir
+(if not empty), the built-in HTML5 converter rendering only the nodes
+which have no template (inline nodes, lists, tables, ...).
+Returns an error if a template cannot be read or parsed */
+func NewTemplateRenderer(templateDir string) (*Renderer, error) {
+	return newRenderer(nil, templateDir, true)
+}
+
+/* Initialize the Renderer, with the built-in templates if builtin is set,
+and the templates of templateDir read from fsys (nil for the file system
+of the system: see readFile) */
+func newRenderer(fsys fs.FS, templateDir string, builtin bool) (*Renderer, error) {
+	r := &Renderer{converter: converters["html5"]}
+	templates := template.New("").Funcs(rendererFuncs)
+	if builtin {
+		for name, text := range builtinTemplates {
+			template.Must(templates.New(name).Parse(text))
+		}
+		r.templates = templates
+	}
+	if templateDir == "" {
+		return r, nil
+	}
+	files, err := readDir(fsys, templateDir)
+	if err != nil {
+		return r, err
+	}
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		text, err := readFile(fsys, filepath.Join(templateDir, file.Name()))
+		if err != nil {
+			return r, err
+		}
+		name := strings.SplitN(file.Name(), ".", 2)[0]
+		if _, err := templates.New(name).Parse(string(text)); err != nil {
+			return r, err
+		}
+	}
+	r.templates = templates
+	return r, nil
+}
+
+/* Use the built-in converter of backend ('html5' or 'docbook5').
+An unknown backend has no converter: only the templates render nodes */
+func (r *Renderer) setBackend(backend string) {
+	r.converter = converters[backend]
+}
+
+/* Check whether a template is defined for the view name */
+func (r *Renderer) HasTemplate(view string) bool {
+	return r != nil && r.templates != nil && r.templates.Lookup(view) != nil
+}
 
 /* Render an Asciidoc object with a specified view template.
 view   - the String view template name.
 object - the Object to be used as an evaluation scope.
+If object is a block, the template gets the actual node
+(Document, Section or Block) as its data.
 locals - the optional Hash of locals to be passed to Tilt (default {})
-(also ignored, really) */
-func (r *Renderer) Render(view string, object interface{}, locals []interface{}) string { return "" }
+(also ignored, really)
+Returns "" if neither a template nor the converter handle view,
+or if the template fails (see Err()) */
+func (r *Renderer) Render(view string, object interface{}, locals []interface{}) string {
+	if r == nil {
+		return ""
+	}
+	if node, ok := object.(interface {
+		Node() interface{}
+	}); ok {
+		object = node.Node()
+	}
+	if !r.HasTemplate(view) {
+		if r.converter == nil {
+			return ""
+		}
+		res, _ := r.converter.Convert(object, view)
+		return res
+	}
+	var out bytes.Buffer
+	if err := r.templates.ExecuteTemplate(&out, view, object); err != nil {
+		if r.err == nil {
+			r.err = err
+		}
+		return ""
+	}
+	return out.String()
+}
+
+/* The first error met when executing a template (nil if none) */
+func (r *Renderer) Err() error {
+	if r == nil {
+		return nil
+	}
+	return r.err
+}
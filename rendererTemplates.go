@@ -0,0 +1,111 @@
+package asciidocgo
+
+/* The built-in (HTML5) templates, by template name, used by a document
+whose template_set option is 'builtin'.
+Each of them can be overridden by a file of the same name
+in the template_dir of that document. */
+var builtinTemplates = map[string]string{
+	"document": `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="{{.Attr "encoding" "UTF-8" false}}">
+<title>{{.Doctitle}}</title>
+</head>
+<body class="{{.DocType}}">
+{{- if .HasHeader}}{{if not (.HasAttr "notitle" nil false)}}
+<div id="header">
+<h1>{{raw .Header.Title}}</h1>
+</div>
+{{- end}}{{end}}
+<div id="content">
+{{raw .Content}}</div>
+</body>
+</html>
+`,
+	"embedded": `{{if .HasHeader}}{{if not (.HasAttr "notitle" nil false)}}<h1>{{raw .Header.Title}}</h1>
+{{end}}{{end}}{{raw .Content}}`,
+	"section": `{{if eq .Level 0}}<h1{{with .Id}} id="{{.}}"{{end}} class="sect0">{{raw .Title}}</h1>
+{{raw .Content}}
+{{- else if eq .Level 1}}<div class="sect1{{with .Role}} {{.}}{{end}}">
+<h2{{with .Id}} id="{{.}}"{{end}}>{{if .IsNumbered}}{{.SectNum}} {{end}}{{raw .Title}}</h2>
+<div class="sectionbody">
+{{raw .Content}}</div>
+</div>
+{{- else}}<div class="sect{{.Level}}{{with .Role}} {{.}}{{end}}">
+<h{{add .Level 1}}{{with .Id}} id="{{.}}"{{end}}>{{if .IsNumbered}}{{.SectNum}} {{end}}{{raw .Title}}</h{{add .Level 1}}>
+{{raw .Content}}</div>
+{{- end}}`,
+	"block_paragraph": `<div{{with .Id}} id="{{.}}"{{end}} class="paragraph{{with .Role}} {{.}}{{end}}">
+{{- if .HasTitle}}
+<div class="title">{{raw .Title}}</div>
+{{- end}}
+<p>{{raw .Content}}</p>
+</div>`,
+	"block_listing": `<div{{with .Id}} id="{{.}}"{{end}} class="listingblock{{with .Role}} {{.}}{{end}}">
+{{- if .HasTitle}}
+<div class="title">{{raw .CaptionedTitle}}</div>
+{{- end}}
+<div class="content">
+<pre>{{raw .Content}}</pre>
+</div>
+</div>`,
+	"block_literal": `<div{{with .Id}} id="{{.}}"{{end}} class="literalblock{{with .Role}} {{.}}{{end}}">
+{{- if .HasTitle}}
+<div class="title">{{raw .Title}}</div>
+{{- end}}
+<div class="content">
+<pre>{{raw .Content}}</pre>
+</div>
+</div>`,
+	"block_pass": `{{raw .Content}}`,
+	"block_example": `<div{{with .Id}} id="{{.}}"{{end}} class="exampleblock{{with .Role}} {{.}}{{end}}">
+{{- if .HasTitle}}
+<div class="title">{{raw .CaptionedTitle}}</div>
+{{- end}}
+<div class="content">
+{{raw .Content}}</div>
+</div>`,
+	"block_sidebar": `<div{{with .Id}} id="{{.}}"{{end}} class="sidebarblock{{with .Role}} {{.}}{{end}}">
+<div class="content">
+{{- if .HasTitle}}
+<div class="title">{{raw .Title}}</div>
+{{- end}}
+{{raw .Content}}</div>
+</div>`,
+	"block_quote": `<div{{with .Id}} id="{{.}}"{{end}} class="quoteblock{{with .Role}} {{.}}{{end}}">
+{{- if .HasTitle}}
+<div class="title">{{raw .Title}}</div>
+{{- end}}
+<blockquote>
+{{raw .Content}}</blockquote>
+{{- if or (.HasAttr "attribution" nil false) (.HasAttr "citetitle" nil false)}}
+<div class="attribution">
+{{- with .Attr "attribution" nil false}}
+&#8212; {{.}}{{end}}
+{{- with .Attr "citetitle" nil false}}<br>
+<cite>{{.}}</cite>{{end}}
+</div>
+{{- end}}
+</div>`,
+	"block_verse": `<div{{with .Id}} id="{{.}}"{{end}} class="verseblock{{with .Role}} {{.}}{{end}}">
+{{- if .HasTitle}}
+<div class="title">{{raw .Title}}</div>
+{{- end}}
+<pre class="content">{{raw .Content}}</pre>
+{{- if or (.HasAttr "attribution" nil false) (.HasAttr "citetitle" nil false)}}
+<div class="attribution">
+{{- with .Attr "attribution" nil false}}
+&#8212; {{.}}{{end}}
+{{- with .Attr "citetitle" nil false}}<br>
+<cite>{{.}}</cite>{{end}}
+</div>
+{{- end}}
+</div>`,
+	"block_open": `<div{{with .Id}} id="{{.}}"{{end}} class="openblock{{with .Role}} {{.}}{{end}}">
+{{- if .HasTitle}}
+<div class="title">{{raw .Title}}</div>
+{{- end}}
+<div class="content">
+{{raw .Content}}</div>
+</div>`,
+}
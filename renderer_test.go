@@ -1,23 +1,108 @@
 package asciidocgo
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/VonC/asciidocgo/consts/context"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
 func TestRenderer(t *testing.T) {
 
 	Convey("A Renderer can be initialized", t, func() {
-		// TODO r := newRenderer()
+		r, err := NewRenderer("")
 		Convey("By default, an Renderer can be created", func() {
 			So(&Renderer{}, ShouldNotBeNil)
-			// TODO So(r, ShouldNotBeNil)
+			So(r, ShouldNotBeNil)
+			So(err, ShouldBeNil)
+		})
+		Convey("By default, a Renderer has no template, only the built-in converter", func() {
+			So(r.HasTemplate("block_paragraph"), ShouldBeFalse)
+			So(r.converter, ShouldNotBeNil)
+			So((&Renderer{}).HasTemplate("section"), ShouldBeFalse)
+		})
+		Convey("A missing template dir is an error", func() {
+			_, err := NewRenderer("test/no-such-dir")
+			So(err, ShouldNotBeNil)
+		})
+	})
+	Convey("A Renderer can be initialized with the built-in templates", t, func() {
+		r, err := NewTemplateRenderer("")
+		So(err, ShouldBeNil)
+		So(r.HasTemplate("block_paragraph"), ShouldBeTrue)
+		So(r.HasTemplate("section"), ShouldBeTrue)
+		So(r.HasTemplate("block_ulist"), ShouldBeFalse)
+		So(r.converter, ShouldNotBeNil)
+		doc := NewDocument([]string{}, nil)
+		block := newBlock(doc.abstractBlock, context.Paragraph, []string{"a < b"})
+		block.setAttr("role", `r" onclick="evil()`, true)
+		So(r.Render("block_paragraph", block.abstractBlock, nil), ShouldEqual, "<div class=\"paragraph r&#34; onclick=&#34;evil()\">\n<p>a &lt; b</p>\n</div>")
+		Convey("A failing built-in template is reported by Err()", func() {
+			So(r.Render("section", "not a node", nil), ShouldEqual, "")
+			So(r.Err(), ShouldNotBeNil)
+		})
+		Convey("The built-in templates can be overridden from a template dir", func() {
+			dir, err := ioutil.TempDir("", "asciidocgo-templates")
+			So(err, ShouldBeNil)
+			defer os.RemoveAll(dir)
+			ioutil.WriteFile(filepath.Join(dir, "block_paragraph.html"), []byte(`<p class="custom">{{raw .Content}}</p>`), 0644)
+			r, err := NewTemplateRenderer(dir)
+			So(err, ShouldBeNil)
+			So(r.Render("block_paragraph", block, nil), ShouldEqual, `<p class="custom">a &lt; b</p>`)
+			So(r.HasTemplate("block_listing"), ShouldBeTrue)
 		})
 	})
 	Convey("A Renderer can render a template", t, func() {
 		Convey("Empty template means empty result", func() {
 			r := &Renderer{}
 			So(r.Render("", nil, nil), ShouldEqual, "")
+			var nilRenderer *Renderer
+			So(nilRenderer.Render("section", nil, nil), ShouldEqual, "")
+			So(nilRenderer.Err(), ShouldBeNil)
+		})
+		Convey("The built-in converter gets the node to render", func() {
+			r, _ := NewRenderer("")
+			doc := NewDocument([]string{}, nil)
+			block := newBlock(doc.abstractBlock, context.Paragraph, []string{"a < b"})
+			block.SetId("intro")
+			So(r.Render("block_paragraph", block.abstractBlock, nil), ShouldEqual, "<div id=\"intro\" class=\"paragraph\">\n<p>a &lt; b</p>\n</div>")
+			So(r.Err(), ShouldBeNil)
+		})
+		Convey("A view unknown to the converter renders nothing", func() {
+			r, _ := NewRenderer("")
+			So(r.Render("section", "not a node", nil), ShouldEqual, "")
+			So(r.Err(), ShouldBeNil)
+		})
+	})
+	Convey("A Renderer can load templates from a template dir", t, func() {
+		dir, err := ioutil.TempDir("", "asciidocgo-templates")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		ioutil.WriteFile(filepath.Join(dir, "block_paragraph.html.tmpl"), []byte(`<p class="custom">{{raw .Content}}</p>`), 0644)
+		ioutil.WriteFile(filepath.Join(dir, "block_custom.html"), []byte(`custom`), 0644)
+		r, err := NewRenderer(dir)
+		So(err, ShouldBeNil)
+		So(r.HasTemplate("block_custom"), ShouldBeTrue)
+		So(r.HasTemplate("block_listing"), ShouldBeFalse)
+		doc := NewDocument([]string{}, nil)
+		block := newBlock(doc.abstractBlock, context.Paragraph, []string{"text"})
+		So(r.Render("block_paragraph", block, nil), ShouldEqual, `<p class="custom">text</p>`)
+		listing := newBlock(doc.abstractBlock, context.Listing, []string{"code"})
+		So(r.Render("block_listing", listing, nil), ShouldContainSubstring, "<pre>code</pre>")
+		Convey("A failing template is reported by Err()", func() {
+			So(r.Render("block_custom", "not a node", nil), ShouldEqual, "custom")
+			ioutil.WriteFile(filepath.Join(dir, "block_failing.html"), []byte(`{{.Missing}}`), 0644)
+			r, _ := NewRenderer(dir)
+			So(r.Render("block_failing", block, nil), ShouldEqual, "")
+			So(r.Err(), ShouldNotBeNil)
+		})
+		Convey("An invalid template is an error", func() {
+			ioutil.WriteFile(filepath.Join(dir, "section.html"), []byte(`{{.Title`), 0644)
+			_, err := NewRenderer(dir)
+			So(err, ShouldNotBeNil)
 		})
 	})
 }
@@ -0,0 +1,240 @@
+package asciidocgo
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/VonC/asciidocgo/consts/compliance"
+	"github.com/VonC/asciidocgo/consts/context"
+)
+
+/* Methods for managing sections of AsciiDoc content in a document.
+The section responds as an Array of content blocks by delegating
+block-related methods to its @blocks property.
+
+Examples
+
+  section = Asciidoctor::Section.new
+  section.title = 'Section 1'
+  section.id = 'sect1'
+
+  section.size
+  => 0
+
+  section.id
+  => "sect1"
+
+  section << new_block
+  section.size
+  => 1 */
+type Section struct {
+	*abstractBlock
+	parentBlock *abstractBlock
+	index       int
+	number      int
+	sectname    string
+	special     bool
+	numbered    bool
+}
+
+/* Initialize an Asciidoctor::Section object.
+parent   - The parent Asciidoc Object.
+level    - the Integer level of this section (default: parent level + 1,
+or 1 if there is no parent)
+numbered - a Boolean indicating whether numbering is enabled for this
+Section (only honored for levels above 0) */
+func newSection(parent *abstractBlock, level int, numbered bool) *Section {
+	ab := newAbstractBlock(parent, context.Section)
+	ab.SetTemplateName("section")
+	if level < 0 {
+		if parent != nil {
+			level = parent.Level() + 1
+		} else {
+			level = 1
+		}
+	}
+	ab.SetLevel(level)
+	section := &Section{ab, parent, 0, 1, "", false, numbered && level > 0}
+	if parent != nil && parent.Context() == context.Section {
+		if parentSection, ok := parent.Node().(*Section); ok {
+			section.special = parentSection.IsSpecial()
+		}
+	}
+	ab.MainSectionAble(section)
+	ab.MainNode(section)
+	return section
+}
+
+/* Set the String section title */
+func (s *Section) SetTitle(title string) {
+	s.setTitle(title)
+}
+
+/* Get the String section name, which is the section title */
+func (s *Section) Name() string {
+	return s.Title()
+}
+
+/* Get/Set the 0-based index order of this section within the parent block */
+func (s *Section) Index() int {
+	return s.index
+}
+func (s *Section) SetIndex(index int) {
+	s.index = index
+}
+
+/* Get/Set the number of this section within the parent block
+(1-based, appendices being numbered from 1 for 'A') */
+func (s *Section) Number() int {
+	return s.number
+}
+func (s *Section) SetNumber(number int) {
+	s.number = number
+}
+
+/* Get/Set the section name of this section
+('sect1', 'part', or the style of a special section, like 'appendix') */
+func (s *Section) SectName() string {
+	return s.sectname
+}
+func (s *Section) SetSectName(sectname string) {
+	s.sectname = sectname
+}
+
+/* Get/Set the flag to indicate whether this is a special section
+(appendix, glossary, bibliography, preface, ...) or a child of one */
+func (s *Section) IsSpecial() bool {
+	return s.special
+}
+func (s *Section) SetSpecial(special bool) {
+	s.special = special
+}
+
+/* Get/Set the flag to indicate whether this section should be numbered */
+func (s *Section) IsNumbered() bool {
+	return s.numbered
+}
+func (s *Section) SetNumbered(numbered bool) {
+	s.numbered = numbered
+}
+
+/* Matches the tags, character references and non-word characters of a
+converted section title, which are not part of a generated id */
+var invalidSectionIdCharsRx, _ = regexp.Compile(`<[^>]+>|&(?:[a-zA-Z]{2,}|#\d{2,5}|#x[a-fA-F0-9]{2,4});|[^\p{L}\p{N}_]+?`)
+
+/* Generate a String id for this section.
+The generated id is prefixed with value of the 'idprefix' attribute, which
+is an underscore by default.
+Section id synthesis can be disabled by undefining the 'sectids' attribute.
+If the generated id is already in use in the document, a count is appended
+until a unique id is found.
+
+Examples
+
+  section = Section.new(parent)
+  section.title = "Foo"
+  section.generate_id
+  => "_foo"
+
+  another_section = Section.new(parent)
+  another_section.title = "Foo"
+  another_section.generate_id
+  => "_foo_2"
+
+Returns the String id, or "" if the sectids attribute is not set */
+func (s *Section) GenerateId() string {
+	doc := s.Document()
+	if doc == nil || !doc.HasAttr("sectids", nil, false) {
+		return ""
+	}
+	sep := stringAttr(doc, "idseparator", "_")
+	pre := stringAttr(doc, "idprefix", "_")
+	baseId := invalidSectionIdCharsRx.ReplaceAllString(strings.ToLower(s.Title()), sep)
+	if sep != "" {
+		for strings.Contains(baseId, sep+sep) {
+			baseId = strings.Replace(baseId, sep+sep, sep, -1)
+		}
+		baseId = strings.TrimSuffix(baseId, sep)
+		if pre == "" {
+			// ensure the id doesn't begin with the separator
+			baseId = strings.TrimLeft(baseId, sep)
+		}
+	}
+	baseId = pre + baseId
+	genId := baseId
+	if references := doc.References(); references != nil {
+		for cnt := compliance.UniqueIdStartIndex(); references.HasId(genId); cnt++ {
+			genId = baseId + sep + strconv.Itoa(cnt)
+		}
+	}
+	return genId
+}
+
+/* Get the section number for the current Section.
+The section number is a unique, dot separated String
+where each entry represents one level of nesting and
+the value of each entry is the 1-based outline number
+of the Section amongst its numbered sibling Sections.
+Appendices are numbered with letters.
+
+Examples
+
+  sect1 = Section.new(document)
+  sect1.level = 1
+  sect1_1 = Section.new(sect1)
+  sect1_1.level = 2
+  sect1_2 = Section.new(sect1)
+  sect1_2.level = 2
+  sect1 << sect1_1
+  sect1 << sect1_2
+  sect1_1_1 = Section.new(sect1_1)
+  sect1_1_1.level = 3
+  sect1_1 << sect1_1_1
+
+  sect1.sectnum
+  # => 1.
+  sect1_1.sectnum
+  # => 1.1.
+  sect1_2.sectnum
+  # => 1.2.
+  sect1_1_1.sectnum
+  # => 1.1.1.
+
+Returns the section number as a String, like "2.1.3." */
+func (s *Section) SectNum() string {
+	return s.sectNum(".", ".")
+}
+
+/* Get the section number, with delimiter between each level,
+and suffix after the last one */
+func (s *Section) sectNum(delimiter, suffix string) string {
+	number := strconv.Itoa(s.number)
+	if s.sectname == "appendix" {
+		number = appendixNumberString(s.number)
+	}
+	if s.Level() > 1 && s.parentBlock != nil && s.parentBlock.Context() == context.Section {
+		if parent, ok := s.parentBlock.Node().(*Section); ok {
+			return parent.sectNum(delimiter, delimiter) + number + suffix
+		}
+	}
+	return number + suffix
+}
+
+/* Get the letter of an appendix from its number ('A' for 1),
+or the number itself if there is no such letter */
+func appendixNumberString(number int) string {
+	if number > 0 && number <= 26 {
+		return string(rune('A' + number - 1))
+	}
+	return strconv.Itoa(number)
+}
+
+/* Get the String value of an attribute of the document
+(defaultValue if the attribute is not set) */
+func stringAttr(doc Documentable, name, defaultValue string) string {
+	if val, ok := doc.Attr(name, nil, false).(string); ok {
+		return val
+	}
+	return defaultValue
+}
@@ -0,0 +1,107 @@
+package asciidocgo
+
+import (
+	"testing"
+
+	"github.com/VonC/asciidocgo/consts/context"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSection(t *testing.T) {
+
+	Convey("A Section can be initialized", t, func() {
+		Convey("A Section without parent nor level has level 1", func() {
+			s := newSection(nil, -1, false)
+			So(s.Context(), ShouldEqual, context.Section)
+			So(s.Level(), ShouldEqual, 1)
+		})
+		Convey("A Section without level is one level deeper than its parent", func() {
+			parent := newSection(nil, 2, false)
+			So(newSection(parent.abstractBlock, -1, false).Level(), ShouldEqual, 3)
+		})
+		Convey("A Section can have a title", func() {
+			s := newSection(nil, 1, false)
+			s.SetTitle("a title")
+			So(s.Title(), ShouldEqual, "a title")
+		})
+		Convey("A Section is the node of its abstract block", func() {
+			s := newSection(nil, 1, false)
+			So(s.Node(), ShouldEqual, s)
+			So(newAbstractBlock(nil, context.Section).Node(), ShouldHaveSameTypeAs, s.abstractBlock)
+		})
+	})
+
+	Convey("A Section has a name, an index and a number", t, func() {
+		s := newSection(nil, 1, true)
+		s.SetTitle("Title")
+		So(s.Name(), ShouldEqual, "Title")
+		So(s.TemplateName(), ShouldEqual, "section")
+		So(s.Index(), ShouldEqual, 0)
+		So(s.Number(), ShouldEqual, 1)
+		So(s.IsNumbered(), ShouldBeTrue)
+		So(newSection(nil, 0, true).IsNumbered(), ShouldBeFalse)
+		s.SetSectName("appendix")
+		s.SetSpecial(true)
+		So(s.SectName(), ShouldEqual, "appendix")
+		So(s.IsSpecial(), ShouldBeTrue)
+		So(newSection(s.abstractBlock, 2, true).IsSpecial(), ShouldBeTrue)
+		s.SetNumbered(false)
+		So(s.IsNumbered(), ShouldBeFalse)
+	})
+
+	Convey("A Section has a section number", t, func() {
+		doc := NewDocument([]string{}, nil)
+		sect1 := newSection(doc.abstractBlock, 1, true)
+		doc.AppendBlock(newSection(doc.abstractBlock, 1, true).abstractBlock)
+		doc.AppendBlock(sect1.abstractBlock)
+		sect11 := newSection(sect1.abstractBlock, 2, true)
+		sect12 := newSection(sect1.abstractBlock, 2, true)
+		sect1.AppendBlock(sect11.abstractBlock)
+		sect1.AppendBlock(sect12.abstractBlock)
+		sect121 := newSection(sect12.abstractBlock, 3, true)
+		sect12.AppendBlock(sect121.abstractBlock)
+		So(sect1.Index(), ShouldEqual, 1)
+		So(sect1.SectNum(), ShouldEqual, "2.")
+		So(sect11.SectNum(), ShouldEqual, "2.1.")
+		So(sect12.SectNum(), ShouldEqual, "2.2.")
+		So(sect121.SectNum(), ShouldEqual, "2.2.1.")
+		So(sect121.sectNum(".", ""), ShouldEqual, "2.2.1")
+		appendix := newSection(doc.abstractBlock, 1, true)
+		appendix.SetSectName("appendix")
+		doc.AppendBlock(appendix.abstractBlock)
+		So(appendix.SectNum(), ShouldEqual, "A.")
+		So(appendix.Caption(), ShouldEqual, "Appendix A: ")
+		So(appendixNumberString(27), ShouldEqual, "27")
+	})
+
+	Convey("A Section can generate its id", t, func() {
+		doc := NewDocument([]string{}, nil)
+		s := newSection(doc.abstractBlock, 1, false)
+		s.SetTitle("Foo & Bar: the <b>Baz</b>!")
+		So(s.GenerateId(), ShouldEqual, "_foo_bar_the_b_baz_b")
+		Convey("The tags of the converted title are not part of the id", func() {
+			doc, _ := NewDocument([]string{"== The *bold* `code` word"}, nil).Parse()
+			So(doc.Sections()[0].Id(), ShouldEqual, "_the_bold_code_word")
+		})
+		Convey("The generated id is unique in the document", func() {
+			s.SetTitle("Foo")
+			doc.Register("ids", []string{"_foo"})
+			So(s.GenerateId(), ShouldEqual, "_foo_2")
+			doc.Register("ids", []string{"_foo_2"})
+			So(s.GenerateId(), ShouldEqual, "_foo_3")
+		})
+		Convey("The generated id follows idprefix and idseparator", func() {
+			s.SetTitle("  Section  Title ")
+			doc.setAttr("idprefix", "", true)
+			doc.setAttr("idseparator", "-", true)
+			So(s.GenerateId(), ShouldEqual, "section-title")
+			doc.setAttr("idprefix", "sec:", true)
+			So(s.GenerateId(), ShouldEqual, "sec:-section-title")
+		})
+		Convey("No id is generated without the sectids attribute", func() {
+			delete(doc.Attributes(), "sectids")
+			So(s.GenerateId(), ShouldEqual, "")
+			So(newSection(nil, 1, false).GenerateId(), ShouldEqual, "")
+		})
+	})
+}
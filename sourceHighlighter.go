@@ -0,0 +1,293 @@
+package asciidocgo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+/* A source highlighter converts the source code of a source block
+('[source,go]') to highlighted markup, when the source-highlighter
+attribute of the document names it.
+Highlight returns the markup of source, with its special characters
+escaped, and one line of markup for each line of source (a token
+spanning several lines is closed at the end of each line), so that
+the callouts, extracted before highlighting, can be restored at the
+end of their lines.
+language - the String language of the source ("go", "json", ...)
+source   - the String source code, without its callouts
+options  - the options of the block: "linenums" (true to number the
+lines) and "start" (the Integer number of the first line) */
+type SourceHighlighter interface {
+	Highlight(language string, source string, options map[string]interface{}) string
+}
+
+/* The source highlighters, by name of the source-highlighter attribute.
+The built-in "asciidocgo" highlighter needs no external tool. */
+var sourceHighlighters = map[string]SourceHighlighter{
+	"asciidocgo": &lexHighlighter{},
+}
+
+/* Register a source highlighter under the name used as value of the
+source-highlighter attribute (a nil highlighter unregisters it) */
+func RegisterSourceHighlighter(name string, highlighter SourceHighlighter) {
+	if highlighter == nil {
+		delete(sourceHighlighters, name)
+		return
+	}
+	sourceHighlighters[name] = highlighter
+}
+
+/* Get the source highlighter registered under name (nil if none) */
+func SourceHighlighterFor(name string) SourceHighlighter {
+	return sourceHighlighters[name]
+}
+
+/* The built-in source highlighter: a tokenizer driven by the rules of
+each supported language (Go, JSON, YAML, shell, Java and Python).
+Each token is wrapped in a span whose class is its kind: comment,
+keyword, type, constant, builtin, string, number, key, variable or
+annotation. The source of an unknown language is only escaped. */
+type lexHighlighter struct{}
+
+/* A tokenizing rule: a regexp anchored at the start of the remaining
+source, and the class of the tokens it matches.
+The class applies to the first group, if any (the rest of the match
+is plain text).
+Identifiers (class "ident") are classified by the keywords, types,
+constants and builtins of the language.
+A rule for wordStart tokens only applies at the start of the source,
+or after a whitespace. */
+type lexRule struct {
+	rx        *regexp.Regexp
+	class     string
+	wordStart bool
+}
+
+type lexLanguage struct {
+	rules     []*lexRule
+	keywords  map[string]bool
+	types     map[string]bool
+	constants map[string]bool
+	builtins  map[string]bool
+}
+
+func newLexRule(pattern, class string) *lexRule {
+	return &lexRule{regexp.MustCompile(`^(?:` + pattern + `)`), class, false}
+}
+
+func newWordStartLexRule(pattern, class string) *lexRule {
+	rule := newLexRule(pattern, class)
+	rule.wordStart = true
+	return rule
+}
+
+// A set of words, from a space-separated list
+func wordSet(words string) map[string]bool {
+	res := map[string]bool{}
+	for _, word := range strings.Fields(words) {
+		res[word] = true
+	}
+	return res
+}
+
+const (
+	lexDoubleQuoted = `"(?:[^"\\\n]|\\.)*"`
+	lexSingleQuoted = `'(?:[^'\\\n]|\\.)*'`
+	lexNumber       = `0[xX][0-9a-fA-F_]+|0[bB][01_]+|0[oO][0-7_]+|(?:[0-9][0-9_]*)?\.?[0-9][0-9_]*(?:[eE][+-]?[0-9]+)?`
+	lexIdentifier   = `[A-Za-z_][A-Za-z0-9_]*`
+)
+
+var lexGo = &lexLanguage{
+	rules: []*lexRule{
+		newLexRule(`//[^\n]*`, "comment"),
+		newLexRule(`(?s)/\*.*?\*/`, "comment"),
+		newLexRule(lexDoubleQuoted+"|`[^`]*`|"+lexSingleQuoted, "string"),
+		newLexRule(lexIdentifier, "ident"),
+		newLexRule(`(?:`+lexNumber+`)i?`, "number"),
+	},
+	keywords:  wordSet("break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var"),
+	types:     wordSet("bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64 rune string uint uint8 uint16 uint32 uint64 uintptr any"),
+	constants: wordSet("true false nil iota"),
+	builtins:  wordSet("append cap close complex copy delete imag len make new panic print println real recover"),
+}
+
+var lexJson = &lexLanguage{
+	rules: []*lexRule{
+		newLexRule(`(`+lexDoubleQuoted+`)[ \t]*:`, "key"),
+		newLexRule(lexDoubleQuoted, "string"),
+		newLexRule(lexIdentifier, "ident"),
+		newLexRule(`-?[0-9]+(?:\.[0-9]+)?(?:[eE][+-]?[0-9]+)?`, "number"),
+	},
+	constants: wordSet("true false null"),
+}
+
+var lexYaml = &lexLanguage{
+	rules: []*lexRule{
+		newWordStartLexRule(`#[^\n]*`, "comment"),
+		newLexRule(`(?:---|\.\.\.)[ \t]*(?:\n|$)`, "keyword"),
+		newLexRule(`([A-Za-z_][\w.\-]*|`+lexDoubleQuoted+`|`+lexSingleQuoted+`)[ \t]*:(?:[ \t]|\n|$)`, "key"),
+		newLexRule(lexDoubleQuoted+`|'(?:[^'\n]|'')*'`, "string"),
+		newLexRule(`[&*][\w\-]+`, "variable"),
+		newLexRule(`![\w!]*`, "type"),
+		newLexRule(`~`, "constant"),
+		newLexRule(lexIdentifier, "ident"),
+		newLexRule(`[-+]?(?:`+lexNumber+`)`, "number"),
+	},
+	constants: wordSet("true false null yes no on off True False Null Yes No On Off TRUE FALSE NULL YES NO ON OFF"),
+}
+
+var lexShell = &lexLanguage{
+	rules: []*lexRule{
+		newWordStartLexRule(`#[^\n]*`, "comment"),
+		newLexRule(`\$(?:\{[^}\n]*\}|[A-Za-z_][A-Za-z0-9_]*|[0-9#?@*$!-])`, "variable"),
+		newLexRule(`"(?:[^"\\]|\\.)*"|'[^']*'`, "string"),
+		newLexRule(lexIdentifier, "ident"),
+		newWordStartLexRule(`[0-9]+`, "number"),
+	},
+	keywords: wordSet("if then else elif fi for while until do done case esac function in select return exit break continue local export readonly declare"),
+	builtins: wordSet("alias bg cd command echo eval exec fg getopts hash jobs kill printf pwd read set shift source test trap type ulimit umask unalias unset wait"),
+}
+
+var lexJava = &lexLanguage{
+	rules: []*lexRule{
+		newLexRule(`//[^\n]*`, "comment"),
+		newLexRule(`(?s)/\*.*?\*/`, "comment"),
+		newLexRule(lexDoubleQuoted+"|"+lexSingleQuoted, "string"),
+		newLexRule(`@[A-Za-z_][\w.]*`, "annotation"),
+		newLexRule(lexIdentifier, "ident"),
+		newLexRule(`(?:`+lexNumber+`)[lLfFdD]?`, "number"),
+	},
+	keywords:  wordSet("abstract assert break case catch class continue default do else enum extends final finally for if implements import instanceof interface native new package private protected public return static strictfp super switch synchronized this throw throws transient try var volatile while yield record"),
+	types:     wordSet("boolean byte char double float int long short void"),
+	constants: wordSet("true false null"),
+}
+
+var lexPython = &lexLanguage{
+	rules: []*lexRule{
+		newLexRule(`#[^\n]*`, "comment"),
+		newLexRule(`(?s)(?:[rRbBuUfF]{1,2})?(?:""".*?"""|'''.*?''')`, "string"),
+		newLexRule(`(?:[rRbBuUfF]{1,2})?(?:`+lexDoubleQuoted+`|`+lexSingleQuoted+`)`, "string"),
+		newWordStartLexRule(`@[A-Za-z_][\w.]*`, "annotation"),
+		newLexRule(lexIdentifier, "ident"),
+		newLexRule(`(?:`+lexNumber+`)[jJ]?`, "number"),
+	},
+	keywords:  wordSet("and as assert async await break class continue def del elif else except finally for from global if import in is lambda nonlocal not or pass raise return try while with yield match case"),
+	types:     wordSet("bool bytes complex dict float frozenset int list object set str tuple"),
+	constants: wordSet("True False None"),
+	builtins:  wordSet("abs all any callable chr dir enumerate filter format getattr hasattr id input isinstance iter len map max min next open ord print range repr reversed round setattr sorted sum super type vars zip self"),
+}
+
+/* The languages of the built-in highlighter, by name and alias */
+var lexLanguages = map[string]*lexLanguage{
+	"go":      lexGo,
+	"golang":  lexGo,
+	"json":    lexJson,
+	"yaml":    lexYaml,
+	"yml":     lexYaml,
+	"shell":   lexShell,
+	"sh":      lexShell,
+	"bash":    lexShell,
+	"zsh":     lexShell,
+	"console": lexShell,
+	"java":    lexJava,
+	"python":  lexPython,
+	"py":      lexPython,
+	"python3": lexPython,
+}
+
+/* Highlight the source of one of the supported languages */
+func (lh *lexHighlighter) Highlight(language string, source string, options map[string]interface{}) string {
+	res := ""
+	if lang, ok := lexLanguages[strings.ToLower(language)]; ok {
+		res = lang.highlight(source)
+	} else {
+		res = subSpecialCharacters(source)
+	}
+	if linenums, _ := options["linenums"].(bool); linenums {
+		start, ok := options["start"].(int)
+		if !ok {
+			start = 1
+		}
+		lines := strings.Split(res, "\n")
+		width := len(fmt.Sprintf("%d", start+len(lines)-1))
+		for i, line := range lines {
+			lines[i] = fmt.Sprintf("<span class=\"linenum\">%*d</span> %s", width, start+i, line)
+		}
+		res = strings.Join(lines, "\n")
+	}
+	return res
+}
+
+/* Tokenize source with the rules of the language, and wrap each token
+(but plain text) in a span of its class */
+func (lang *lexLanguage) highlight(source string) string {
+	res := ""
+	plain := ""
+	for pos := 0; pos < len(source); {
+		token, class, rest := lang.nextToken(source, pos)
+		if class == "" {
+			plain = plain + token
+		} else {
+			res = res + subSpecialCharacters(plain) + wrapToken(token, class)
+			plain = rest
+		}
+		pos = pos + len(token) + len(rest)
+		if class == "" && token == "" {
+			plain = plain + source[pos:pos+1]
+			pos++
+		}
+	}
+	return res + subSpecialCharacters(plain)
+}
+
+/* Get the next token at pos in source, with its class ("" for plain
+text), and the rest of the match of its rule (plain text) */
+func (lang *lexLanguage) nextToken(source string, pos int) (string, string, string) {
+	text := source[pos:]
+	for _, rule := range lang.rules {
+		if rule.wordStart && pos > 0 && !strings.ContainsAny(source[pos-1:pos], " \t\n") {
+			continue
+		}
+		m := rule.rx.FindStringSubmatch(text)
+		if m == nil || m[0] == "" {
+			continue
+		}
+		token, rest := m[0], ""
+		if len(m) > 1 {
+			token, rest = m[1], m[0][len(m[1]):]
+		}
+		class := rule.class
+		if class == "ident" {
+			class = lang.classify(token)
+		}
+		return token, class, rest
+	}
+	return "", "", ""
+}
+
+// The class of an identifier ("" if it is not a known word)
+func (lang *lexLanguage) classify(word string) string {
+	switch {
+	case lang.keywords[word]:
+		return "keyword"
+	case lang.types[word]:
+		return "type"
+	case lang.constants[word]:
+		return "constant"
+	case lang.builtins[word]:
+		return "builtin"
+	}
+	return ""
+}
+
+/* Wrap a token in a span of its class, on each of its lines */
+func wrapToken(token string, class string) string {
+	lines := strings.Split(subSpecialCharacters(token), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = fmt.Sprintf("<span class=\"%s\">%s</span>", class, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,101 @@
+package asciidocgo
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testHighlighter struct {
+	language string
+	options  map[string]interface{}
+}
+
+func (th *testHighlighter) Highlight(language string, source string, options map[string]interface{}) string {
+	th.language = language
+	th.options = options
+	return "[" + source + "]"
+}
+
+func TestSourceHighlighter(t *testing.T) {
+
+	Convey("A source highlighter can be registered by name", t, func() {
+		So(SourceHighlighterFor("asciidocgo"), ShouldNotBeNil)
+		So(SourceHighlighterFor("test"), ShouldBeNil)
+		th := &testHighlighter{}
+		RegisterSourceHighlighter("test", th)
+		So(SourceHighlighterFor("test"), ShouldEqual, th)
+		RegisterSourceHighlighter("test", nil)
+		So(SourceHighlighterFor("test"), ShouldBeNil)
+	})
+
+	Convey("The built-in source highlighter tokenizes Go", t, func() {
+		lh := &lexHighlighter{}
+		res := lh.Highlight("go", "func f() []string {\n\treturn nil // none\n}", nil)
+		So(res, ShouldEqual, "<span class=\"keyword\">func</span> f() []<span class=\"type\">string</span> {\n"+
+			"\t<span class=\"keyword\">return</span> <span class=\"constant\">nil</span> <span class=\"comment\">// none</span>\n}")
+		res = lh.Highlight("golang", "s := `a<b\nc` + \"d\" + len(x) * 0x1F", nil)
+		So(res, ShouldEqual, "s := <span class=\"string\">`a&lt;b</span>\n<span class=\"string\">c`</span> + <span class=\"string\">\"d\"</span> + "+
+			"<span class=\"builtin\">len</span>(x) * <span class=\"number\">0x1F</span>")
+	})
+
+	Convey("The built-in source highlighter tokenizes JSON", t, func() {
+		res := (&lexHighlighter{}).Highlight("json", `{"a": "b", "n": -1.5, "ok": null}`, nil)
+		So(res, ShouldEqual, `{<span class="key">"a"</span>: <span class="string">"b"</span>, <span class="key">"n"</span>: `+
+			`<span class="number">-1.5</span>, <span class="key">"ok"</span>: <span class="constant">null</span>}`)
+	})
+
+	Convey("The built-in source highlighter tokenizes YAML", t, func() {
+		res := (&lexHighlighter{}).Highlight("yml", "---\nname: &a 'x' # c\nlist:\n  - *a\n  - yes", nil)
+		So(res, ShouldEqual, "<span class=\"keyword\">---</span>\n<span class=\"key\">name</span>: <span class=\"variable\">&amp;a</span> "+
+			"<span class=\"string\">'x'</span> <span class=\"comment\"># c</span>\n<span class=\"key\">list</span>:\n"+
+			"  - <span class=\"variable\">*a</span>\n  - <span class=\"constant\">yes</span>")
+	})
+
+	Convey("The built-in source highlighter tokenizes shell", t, func() {
+		res := (&lexHighlighter{}).Highlight("bash", "echo \"$HOME\" ${X}#1 # done", nil)
+		So(res, ShouldEqual, "<span class=\"builtin\">echo</span> <span class=\"string\">\"$HOME\"</span> "+
+			"<span class=\"variable\">${X}</span>#1 <span class=\"comment\"># done</span>")
+	})
+
+	Convey("The built-in source highlighter tokenizes Java", t, func() {
+		res := (&lexHighlighter{}).Highlight("java", "@Override\npublic int f() { return 1L; }", nil)
+		So(res, ShouldEqual, "<span class=\"annotation\">@Override</span>\n<span class=\"keyword\">public</span> "+
+			"<span class=\"type\">int</span> f() { <span class=\"keyword\">return</span> <span class=\"number\">1L</span>; }")
+	})
+
+	Convey("The built-in source highlighter tokenizes Python", t, func() {
+		res := (&lexHighlighter{}).Highlight("python", "def f(x):\n    \"\"\"doc\n    \"\"\"\n    return x or None", nil)
+		So(res, ShouldEqual, "<span class=\"keyword\">def</span> f(x):\n    <span class=\"string\">\"\"\"doc</span>\n"+
+			"<span class=\"string\">    \"\"\"</span>\n    <span class=\"keyword\">return</span> x <span class=\"keyword\">or</span> <span class=\"constant\">None</span>")
+	})
+
+	Convey("The built-in source highlighter only escapes an unknown language", t, func() {
+		So((&lexHighlighter{}).Highlight("cobol", "a < b", nil), ShouldEqual, "a &lt; b")
+	})
+
+	Convey("The built-in source highlighter can number the lines", t, func() {
+		res := (&lexHighlighter{}).Highlight("text", "a\nb", map[string]interface{}{"linenums": true, "start": 9})
+		So(res, ShouldEqual, "<span class=\"linenum\"> 9</span> a\n<span class=\"linenum\">10</span> b")
+	})
+
+	Convey("A source highlighter highlights source blocks without their callouts", t, func() {
+		th := &testHighlighter{}
+		RegisterSourceHighlighter("test", th)
+		defer RegisterSourceHighlighter("test", nil)
+		lines := []string{":source-highlighter: test", "", "[source,ruby,linenums]", "----", "a <1>", "b \\<2>", "c // <2> <3>", "----",
+			"<1> one", "<2> two", "<3> three"}
+		doc, _ := NewDocument(lines, nil).Parse()
+		res, _ := doc.Render()
+		So(res, ShouldContainSubstring, "<code class=\"language-ruby\" data-lang=\"ruby\">[a <b class=\"conum\">(1)</b>\n"+
+			"b <2>\nc  ]<b class=\"conum\">(2)</b> <b class=\"conum\">(3)</b></code>")
+		So(th.language, ShouldEqual, "ruby")
+		So(th.options["linenums"], ShouldBeTrue)
+
+		Convey("The source blocks of a docbook document are not highlighted", func() {
+			doc, _ := NewDocument(lines, map[string]string{"backend": "docbook5"}).Parse()
+			res, _ := doc.Render()
+			So(res, ShouldContainSubstring, "linenumbering=\"numbered\">a <co xml:id=\"CO1-1\"/>\nb &lt;2&gt;\n")
+		})
+	})
+}
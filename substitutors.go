@@ -13,6 +13,7 @@ import (
 	"github.com/VonC/asciidocgo/consts/context"
 	"github.com/VonC/asciidocgo/consts/regexps"
 	"github.com/VonC/asciidocgo/consts/regexps/quotes"
+	"github.com/VonC/asciidocgo/consts/severity"
 	"github.com/VonC/asciidocgo/debug"
 )
 
@@ -35,6 +36,7 @@ const (
 	subsMacros            _sub = "macros"
 	subsPostReplacements  _sub = "post_replacements"
 	subsCallout           _sub = "callouts"
+	subsHighlight         _sub = "highlight"
 )
 const (
 	subsNone         _sub = "none"
@@ -79,6 +81,7 @@ type subsEnumsValues struct {
 	macros            *subsEnum
 	postReplacements  *subsEnum
 	callouts          *subsEnum
+	highlight         *subsEnum
 }
 
 type compositeSubsEnums struct {
@@ -123,7 +126,8 @@ func newSubsEnumsValues() *subsEnumsValues {
 		&subsEnum{subsReplacements},
 		&subsEnum{subsMacros},
 		&subsEnum{subsPostReplacements},
-		&subsEnum{subsCallout}}
+		&subsEnum{subsCallout},
+		&subsEnum{subsHighlight}}
 }
 
 func newCompositeSubsEnums() *compositeSubsEnums {
@@ -192,9 +196,11 @@ func aToSEValues(key string) *subsEnum {
 	case string(subsMacros):
 		res = subValue.macros
 	case string(subsPostReplacements):
-		res = subValue.replacements
+		res = subValue.postReplacements
 	case string(subsCallout):
 		res = subValue.callouts
+	case string(subsHighlight):
+		res = subValue.highlight
 	}
 	return res
 }
@@ -383,26 +389,35 @@ type SubstDocumentable interface {
 	Attr(name string, defaultValue interface{}, inherit bool) interface{}
 	Basebackend(base interface{}) bool
 	SubAttributes(data string, opts *OptionsParseAttributes) string
-	Counter(name string, seed int) string
+	Counter(name string, seed string) string
 	HasAttr(name string, expect interface{}, inherit bool) bool
 	Extensions() Extensionables
 	Register(typeDoc string, value []string)
 	References() Referencable
-	NewFootnote(index int, id int, text string) Footnotable
+	NewFootnote(index int, id string, text string) Footnotable
 	RegisterFootnote(f Footnotable)
-	FindFootnote(id int) Footnotable
+	FindFootnote(id string) Footnotable
+	RegisterIndexTerm(terms []string, node AbstractNodable) string
+	Callouts() Calloutable
+	Logger() Logger
 }
 
 type Footnotable interface {
 	Index() int
-	Id() int
+	Id() string
 	Text() string
 	String() string
 }
 
+type Calloutable interface {
+	Register(ordinal string) string
+	ReadNextId() string
+}
+
 type Referencable interface {
 	HasId(id string) bool
 	Get(id string) string
+	HasInclude(path string) bool
 }
 
 type Convertable interface {
@@ -473,6 +488,14 @@ func (s *substitutors) Document() SubstDocumentable {
 	return s.document
 }
 
+/* Report a message about the substituted text to the logger of the document
+(the message is dropped without document) */
+func (s *substitutors) log(sev severity.Severity, text string) {
+	if s.Document() != nil {
+		logMessage(s.Document().Logger(), sev, text, nil)
+	}
+}
+
 /* Apply the specified substitutions to the lines of text
 
 source  - The String or String Array of text to process
@@ -537,16 +560,21 @@ func (s *substitutors) ApplySubs(source string, someSubs subArray, expand bool)
 			text = s.SubMacros(text)
 		case "highlight":
 			text = s.HighlightSource(text, (allSubs.include(subValue.callouts)), nil)
-			/*
-				case "callouts":
-				case "post_replacements":
-			*/
+		case "callouts":
+			// callouts are already restored by the source highlighter
+			if !allSubs.include(subValue.highlight) {
+				text = s.subCallouts(text)
+			}
+		case "post_replacements":
+			text = s.subPostReplacements(text)
 		}
 	}
 	if testsub == "test_ApplySubs_applyAllsubs" {
 		return text
 	}
-	// TODO complete (s *substitutors) ApplySubs after extractPassthroughs
+	if allSubs.include(subValue.macros) {
+		text = s.restorePassthroughs(text)
+	}
 	return text
 }
 
@@ -562,6 +590,88 @@ const (
 	subPASS_END = "\u0097"
 )
 
+/* Substitute the callout references in the text of a verbatim block
+(with their optional line comment prefix) by callout inline nodes,
+each one with the next callout id read from the document.
+An escaped callout reference ('\<1>') is kept, without its backslash.
+text - The String text to process
+returns the converted String text */
+func (s *substitutors) subCallouts(text string) string {
+	reres := regexps.NewCalloutConvertRxres(text)
+	if !reres.HasAnyMatch() {
+		return text
+	}
+	res := ""
+	suffix := ""
+	for reres.HasNext() {
+		res = res + reres.Prefix()
+		if reres.IsEscaped() {
+			// honor the escape
+			res = res + strings.Replace(reres.FullMatch(), "\\", "", 1)
+		} else {
+			optsInline := &OptionsInline{}
+			if s.Document() != nil && s.Document().Callouts() != nil {
+				optsInline.id = s.Document().Callouts().ReadNextId()
+			}
+			inline := s.inlineMaker.NewInline(s.abstractNodable, context.Callout, reres.CalloutNumber(), optsInline)
+			res = res + inline.Convert()
+		}
+		suffix = reres.Suffix()
+		reres.Next()
+	}
+	return res + suffix
+}
+
+// A node with options (the 'hardbreaks' option of a block)
+type Optionable interface {
+	HasOption(option string) bool
+}
+
+// A node with attributes (the 'language' attribute of a source block)
+type Attributable interface {
+	Attr(name string, defaultValue interface{}, inherit bool) interface{}
+	HasAttr(name string, expect interface{}, inherit bool) bool
+}
+
+/* Substitute the hard line breaks: a '+' at the end of a line,
+or the end of each line but the last one if the hardbreaks attribute
+is set on the document, or the hardbreaks option on the block.
+text - The String text to process
+returns the converted String text */
+func (s *substitutors) subPostReplacements(text string) string {
+	hardbreaks := s.Document() != nil && s.Document().HasAttr("hardbreaks", nil, false)
+	if node, ok := s.abstractNodable.(Optionable); ok && node.HasOption("hardbreaks") {
+		hardbreaks = true
+	}
+	if hardbreaks {
+		lines := strings.Split(text, "\n")
+		if len(lines) == 1 {
+			return text
+		}
+		for i, line := range lines[:len(lines)-1] {
+			line = strings.TrimSuffix(strings.TrimRight(line, " \t"), regexps.LINE_BREAK)
+			lines[i] = s.inlineMaker.NewInline(s.abstractNodable, context.Break, line, &OptionsInline{typeInline: "line"}).Convert()
+		}
+		return strings.Join(lines, "\n")
+	}
+	if !strings.Contains(text, "+") {
+		return text
+	}
+	reres := regexps.NewReres(text, regexps.LineBreakRx)
+	if !reres.HasAnyMatch() {
+		return text
+	}
+	res := ""
+	suffix := ""
+	for reres.HasNext() {
+		res = res + reres.Prefix()
+		res = res + s.inlineMaker.NewInline(s.abstractNodable, context.Break, reres.Group(1), &OptionsInline{typeInline: "line"}).Convert()
+		suffix = reres.Suffix()
+		reres.Next()
+	}
+	return res + suffix
+}
+
 /* Extract the passthrough text from the document for reinsertion after processing.
 text - The String from which to extract passthrough fragements
 returns - The text with the passthrough region substituted with placeholders */
@@ -745,6 +855,8 @@ func (s *substitutors) restorePassthroughs(text string) string {
 			optsInline.typeInline = typePT
 			inline := s.inlineMaker.NewInline(s.abstractNodable, context.Quoted, subbedText, optsInline)
 			res = res + inline.Convert()
+		} else {
+			res = res + subbedText
 		}
 		suffix = reres.Suffix()
 		reres.Next()
@@ -940,10 +1052,15 @@ func (s *substitutors) SubAttributes(data string, opts *OptionsParseAttributes)
 						}
 						reject_if_empty = true
 					case "counter", "counter2":
-						args := strings.Split(expr, ":")
-						seed, err := strconv.Atoi(args[1])
-						if err != nil {
-							panic(fmt.Sprintf("counter reference seed not int: %v", args))
+						args := strings.SplitN(expr, ":", 2)
+						seed := ""
+						if len(args) > 1 {
+							seed = args[1]
+						}
+						if err := checkCounterSeed(args[0], seed); err != nil {
+							s.log(severity.ERROR, err.Error())
+							lineres = lineres + reres.FullMatch()
+							break
 						}
 						val := ""
 						if s.Document() != nil {
@@ -957,7 +1074,7 @@ func (s *substitutors) SubAttributes(data string, opts *OptionsParseAttributes)
 						}
 					default:
 						// if we get here, our AttributeReference regex is too loose
-						log.Println(fmt.Sprintf("asciidocgo: WARNING: illegal attribute directive: %s", directive))
+						s.log(severity.WARN, fmt.Sprintf("illegal attribute directive: %s", directive))
 						lineres = lineres + reres.FullMatch()
 					}
 
@@ -1056,6 +1173,9 @@ func (s *substitutors) SubMacros(source string) string {
 						// Split into an array, and for each k, aggregate to result array c
 						//fmt.Printf("***** key='%v'\n", key)
 						reresKbd := regexps.NewKbdDelimiterRxres(key)
+						if !reresKbd.HasNext() {
+							keys = append(keys, strings.TrimSpace(key))
+						}
 						lastKey := false
 						akeySuffix := ""
 						for reresKbd.HasNext() || lastKey {
@@ -1227,18 +1347,19 @@ func (s *substitutors) SubMacros(source string) string {
 					reres.Next()
 					continue
 				}
-				target := reres.Group(1)
-				attributes := make(map[string]interface{})
+				// a short format macro has no target group in its regex
+				target, text := "", reres.Group(1)
 				if extension.IsShortFormat() == false {
-					// meaning 2 groups in the regex
-					if extension.IsContentModelAttributes() {
-						opts := &OptionsParseAttributes{subInput: true, unescapeInput: true}
-						attributes = s.parseAttributes(reres.Group(2), extension.PosAttrs(), opts)
-					} else {
-						attributes["text"] = unescapeBrackets(reres.Group(2))
-					}
+					target, text = reres.Group(1), reres.Group(2)
+				}
+				attributes := make(map[string]interface{})
+				if extension.IsContentModelAttributes() {
+					opts := &OptionsParseAttributes{subInput: true, unescapeInput: true}
+					attributes = s.parseAttributes(text, extension.PosAttrs(), opts)
+				} else {
+					attributes["text"] = unescapeBrackets(text)
 				}
-				res = res + extension.ProcessMethod(s, target, attributes)
+				res = res + extension.ProcessMethod(s.abstractNodable, target, attributes)
 
 				suffix = reres.Suffix()
 				reres.Next()
@@ -1346,12 +1467,12 @@ func (s *substitutors) SubMacros(source string) string {
 					// indexterm:[Tigers,Big cats]
 					terms = splitSimpleCsv(normalizeString(reres.IndextermTextOrTerms(), true))
 				}
-				if s.Document() != nil {
-					s.Document().Register("terms", terms)
-				}
 				attrs := make(map[string]interface{})
 				attrs["terms"] = terms
 				optsInline := &OptionsInline{attributes: attrs}
+				if s.Document() != nil {
+					optsInline.id = s.Document().RegisterIndexTerm(terms, s.abstractNodable)
+				}
 				inline := s.inlineMaker.NewInline(s.abstractNodable, context.IndexTerm, "", optsInline)
 				//fmt.Printf("\ninline '%v'\n", inline)
 				res = res + inline.Convert()
@@ -1363,11 +1484,11 @@ func (s *substitutors) SubMacros(source string) string {
 				} else {
 					text = normalizeString(reres.IndextermTextOrTerms(), true)
 				}
-				if s.Document() != nil {
-					s.Document().Register("indexterms", []string{text})
-				}
 				optsInline := &OptionsInline{}
 				optsInline.typeInline = "visible"
+				if s.Document() != nil {
+					optsInline.id = s.Document().RegisterIndexTerm([]string{text}, s.abstractNodable)
+				}
 				inline := s.inlineMaker.NewInline(s.abstractNodable, context.IndexTerm, text, optsInline)
 				res = res + inline.Convert()
 			}
@@ -1611,7 +1732,7 @@ func (s *substitutors) SubMacros(source string) string {
 
 	if found.macroish_short_form && strings.Contains(res, "footnote") {
 
-		// inline link macros, link:target[text]
+		// inline footnote macros, footnote:[text], footnoteref:[id,text] and footnoteref:[id]
 		reres := regexps.NewFootnoteInlineMacroRxres(res)
 		if reres.HasNext() {
 			res = ""
@@ -1633,58 +1754,27 @@ func (s *substitutors) SubMacros(source string) string {
 			targetf := ""
 			indexf := ""
 			if reres.FootnotePrefix() == "footnote" {
-				// REVIEW it's a dirty job, but somebody's gotta do it
-				// restore_passthroughs(sub_inline_xrefs(sub_inline_anchors(normalize_string m[2], true)))
-				normalizedString := normalizeString(reres.FootnoteText(), true)
-				subInlineAnchors := s.subInlineAnchors(normalizedString, nil)
-				subInlineXrefs := s.subInlineXrefs(subInlineAnchors, nil)
-				textf = s.restorePassthroughs(subInlineXrefs)
-				// fmt.Printf("\nnormalizedString='%v'\n", normalizedString)
-				// fmt.Printf("subInlineAnchors='%v'\n", subInlineAnchors)
-				// fmt.Printf("subInlineXrefs='%v'\n", subInlineXrefs)
-				// fmt.Printf("textf restorePassthroughs='%v'\n", textf)
-				if s.Document() != nil {
-					indexf = s.Document().Counter("footnote-number", 0)
-					iindexf, _ := strconv.Atoi(indexf)
-					iidf, _ := strconv.Atoi(idf)
-					footnote := s.Document().NewFootnote(iindexf, iidf, textf)
-					// Bug? idf is always empty, meaning iidf is always 0
-					//fmt.Printf("\nRegister footnote '%v' from index '%v', id '%v'\n", footnote.String(), indexf, idf)
-					s.Document().RegisterFootnote(footnote) // Document::Footnote.new(index, id, text)
-				}
+				textf = s.footnoteText(reres.FootnoteText())
+				indexf = s.registerFootnote(idf, textf)
 			} else {
-				r := strings.Split(reres.FootnoteText(), ",")
+				// id, text = m[2].split(',', 2)
+				r := strings.SplitN(reres.FootnoteText(), ",", 2)
 				idf = strings.TrimSpace(r[0])
 				if len(r) > 1 {
 					textf = r[1]
 				}
 				if textf != "" {
-					// REVIEW it's a dirty job, but somebody's gotta do it
-					// restore_passthroughs(sub_inline_xrefs(sub_inline_anchors(normalize_string text, true)))
-					normalizedString := normalizeString(textf, true)
-					subInlineAnchors := s.subInlineAnchors(normalizedString, nil)
-					subInlineXrefs := s.subInlineXrefs(subInlineAnchors, nil)
-					textf = s.restorePassthroughs(subInlineXrefs)
-					// fmt.Printf("\nnormalizedString='%v'\n", normalizedString)
-					// fmt.Printf("subInlineAnchors='%v'\n", subInlineAnchors)
-					// fmt.Printf("subInlineXrefs='%v'\n", subInlineXrefs)
-					// fmt.Printf("textf restorePassthroughs='%v'\n", textf)
-					if s.Document() != nil {
-						indexf = s.Document().Counter("footnote-number", 0)
-						iindexf, _ := strconv.Atoi(indexf)
-						iidf, _ := strconv.Atoi(idf)
-						footnote := s.Document().NewFootnote(iindexf, iidf, textf)
-						s.Document().RegisterFootnote(footnote) // Document::Footnote.new(index, id, text)
-					}
+					textf = s.footnoteText(textf)
+					indexf = s.registerFootnote(idf, textf)
 					typef = "ref"
 				} else {
 					textf = idf
 					if s.Document() != nil { // @document.references[:footnotes].find {|fn| fn.id == id })
-						iidf, _ := strconv.Atoi(idf)
-						footnote := s.Document().FindFootnote(iidf)
-						if footnote != nil {
+						if footnote := s.Document().FindFootnote(idf); footnote != nil {
 							indexf = strconv.Itoa(footnote.Index()) // footnote.index
 							textf = footnote.Text()                 // footnote.text
+						} else {
+							s.log(severity.WARN, fmt.Sprintf("invalid footnote reference: %v", idf))
 						}
 					}
 					targetf = idf
@@ -1712,6 +1802,26 @@ func (s *substitutors) SubMacros(source string) string {
 	return res
 }
 
+/* Substitute the text of a footnote: its anchors and cross references,
+and the passthroughs extracted from it
+restore_passthroughs(sub_inline_xrefs(sub_inline_anchors(normalize_string text, true))) */
+func (s *substitutors) footnoteText(text string) string {
+	return s.restorePassthroughs(s.subInlineXrefs(s.subInlineAnchors(normalizeString(text, true), nil), nil))
+}
+
+/* Register a footnote in the document, numbered by its 'footnote-number'
+counter.
+Returns the String number of the footnote ("" without document) */
+func (s *substitutors) registerFootnote(id, text string) string {
+	if s.Document() == nil {
+		return ""
+	}
+	index := s.Document().Counter("footnote-number", "")
+	number, _ := strconv.Atoi(index)
+	s.Document().RegisterFootnote(s.Document().NewFootnote(number, id, text)) // Document::Footnote.new(index, id, text)
+	return index
+}
+
 // Internal: Substitute normal and bibliographic anchors
 func (s *substitutors) subInlineAnchors(text string, found *found) string {
 	res := text
@@ -1871,57 +1981,36 @@ func (s *substitutors) subInlineXrefs(text string, found *found) string {
 				xrIds := strings.Split(xrId, "#")
 				xrPath = xrIds[0]
 				xrFragment = xrIds[1]
+			} else if s.isDocumentPath(xrId) {
+				// handles forms: doc.adoc (a path without fragment)
+				xrPath = xrId
+			} else {
+				xrFragment = xrId
 			}
 
-			xrefId := ""
-			xrefTarget := ""
-			// handles form: id
-			if xrPath == "" {
-				xrefId = xrFragment
-				xrefTarget = "#" + xrFragment
-			} else {
-				// handles forms: doc#, doc.adoc#, doc#id and doc.adoc#id
-				ext := filepath.Ext(xrPath)
-				if ext != "" {
-					xrPath = xrPath[0 : len(xrPath)-len(ext)]
-					/*
-						fmt.Printf("\nxrPath.ext='%v'.'%v'\n", xrPath, ext)
-						if s.Document() != nil {
-							fmt.Printf("Document().Attr(docname)='%v' => '%v'\n", s.Document().Attr("docname", compliance.AttributeUndefined(), false).(string), s.Document().Attr("docname", compliance.AttributeUndefined(), false).(string) == xrPath)
-							fmt.Printf("s.Document().References().Get(includes)='%v' xrPath'%v' => '%v'\n", s.Document().References().Get("includes"), xrPath, strings.Contains(s.Document().References().Get("includes"), xrPath))
-						}*/
+			xrefId := xrFragment
+			xrefTarget := "#" + xrFragment
+			// handles forms: doc#, doc.adoc#, doc#id and doc.adoc#id
+			if xrPath != "" {
+				xrPath = rootname(xrPath)
+				if s.isCurrentDocument(xrPath) {
 					// the referenced path is this document, or its contents has been included in this document
-					if s.Document() != nil &&
-						s.Document().Attr("docname", compliance.AttributeUndefined(), false).(string) == xrPath ||
-						strings.Contains(s.Document().References().Get("includes"), xrPath) {
-						xrefId = xrFragment
-						xrPath = ""
-						xrefTarget = "#" + xrFragment
-					} else {
-						//fmt.Printf("\nxrPath#xrFragment='%v'.'%v'\n", xrPath, xrFragment)
+					xrPath = ""
+				} else {
+					xrefId = xrPath
+					if xrFragment != "" {
 						xrefId = xrPath + "#" + xrFragment
-						if xrFragment == "" {
-							xrefId = xrPath
-						}
-						xrPathPrefix := ""
-						xrPathSuffix := ""
-						if s.Document() != nil {
-							xrPathPrefix = s.Document().Attr("relfileprefix", nil, false).(string)
-							xrPathSuffix = s.Document().Attr("outfilesuffix", nil, false).(string)
-							// fmt.Printf("s.Document().Attr(relfileprefix) xrPathPrefix='%v'\n", xrPathPrefix)
-							// fmt.Printf("s.Document().Attr(outfilesuffix) xrPathSuffix='%v'\n", xrPathSuffix)
-						}
-						if xrPathSuffix == "" {
-							xrPathSuffix = ".html"
-						}
-						xrPath = xrPathPrefix + xrPath + xrPathSuffix
+					}
+					xrPath = s.relativeFilePath(xrPath)
+					xrefTarget = xrPath
+					if xrFragment != "" {
 						xrefTarget = xrPath + "#" + xrFragment
-						if xrFragment == "" {
-							xrefTarget = xrPath
-						}
 					}
 				}
 			}
+			if xrPath == "" && xrefId != "" && s.Document() != nil && !s.Document().References().HasId(xrefId) {
+				s.log(severity.WARN, fmt.Sprintf("possible invalid reference: %v", xrefId))
+			}
 
 			suffix = reres.Suffix()
 			reres.Next()
@@ -1940,6 +2029,47 @@ func (s *substitutors) subInlineXrefs(text string, found *found) string {
 	return res
 }
 
+/* Check whether the root name of a cross reference path designates this
+document (its docname), or a file whose contents has been included in it */
+func (s *substitutors) isCurrentDocument(path string) bool {
+	if s.Document() == nil {
+		return false
+	}
+	if docname, ok := s.Document().Attr("docname", nil, false).(string); ok && docname == path {
+		return true
+	}
+	return s.Document().References().HasInclude(path)
+}
+
+/* Check whether the id of a cross reference without fragment is the path
+of a document: it ends with '.adoc', or with the docfilesuffix attribute */
+func (s *substitutors) isDocumentPath(id string) bool {
+	if strings.HasSuffix(id, ".adoc") {
+		return true
+	}
+	if s.Document() == nil {
+		return false
+	}
+	suffix, _ := s.Document().Attr("docfilesuffix", "", false).(string)
+	return suffix != "" && strings.HasSuffix(id, suffix)
+}
+
+/* The path of the output file of another document, from its root name:
+prefixed by the relfileprefix attribute, and suffixed by the relfilesuffix
+attribute, or else by the outfilesuffix attribute (default: ".html") */
+func (s *substitutors) relativeFilePath(path string) string {
+	prefix, suffix := "", ".html"
+	if s.Document() != nil {
+		prefix, _ = s.Document().Attr("relfileprefix", "", false).(string)
+		for _, name := range []string{"outfilesuffix", "relfilesuffix"} {
+			if value, ok := s.Document().Attr(name, "", false).(string); ok && value != "" {
+				suffix = value
+			}
+		}
+	}
+	return prefix + path + suffix
+}
+
 // REGEXP_ENCODE_URI_CHARS = /[^\w\-.!~*';:@=+$,()\[\]]/
 // BUG? doesn't work with the ^\w...
 var EncodeUriCharsRx, _ = regexp.Compile(`[\^\-!~*';:@=+$,()\[\]]`)
@@ -2094,6 +2224,20 @@ func (s *substitutors) ApplyNormalSubs(lines string) string {
 	return s.ApplySubs(lines, subArray{sub.normal}, false)
 }
 
+/* Apply substitutions for titles.
+title  - The String title to process
+returns A String with title substitutions performed */
+func (s *substitutors) ApplyTitleSubs(title string) string {
+	return s.ApplySubs(title, subs[sub.title], false)
+}
+
+/* Apply substitutions for header metadata and attribute assignments
+text - String containing the text process
+returns A String with header substitutions performed */
+func (s *substitutors) ApplyHeaderSubs(text string) string {
+	return s.ApplySubs(text, subs[sub.header], false)
+}
+
 func (s *substitutors) parseQuotedTextAttributes(str string) map[string]interface{} {
 	res := make(map[string]interface{})
 	if str == "" {
@@ -2383,5 +2527,77 @@ sub_callouts - a Boolean flag indicating whether callout marks should be substit
 returns the highlighted source code, if a source highlighter is defined
 on the document, otherwise the unprocessed text */
 func (s *substitutors) HighlightSource(source string, subCallouts bool, highlighter interface{}) string {
-	return ""
+	sourceHighlighter, ok := highlighter.(SourceHighlighter)
+	if !ok && s.Document() != nil {
+		name, _ := s.Document().Attr("source-highlighter", nil, false).(string)
+		sourceHighlighter = SourceHighlighterFor(name)
+	}
+	if sourceHighlighter == nil {
+		return source
+	}
+	calloutMarks := map[int][]string{}
+	if subCallouts {
+		lines := strings.Split(source, "\n")
+		for i, line := range lines {
+			lines[i] = extractCallouts(line, i+1, calloutMarks)
+		}
+		source = strings.Join(lines, "\n")
+	}
+	language := ""
+	options := map[string]interface{}{}
+	if node, ok := s.abstractNodable.(Attributable); ok {
+		language, _ = node.Attr("language", nil, false).(string)
+		if node.HasAttr("linenums", nil, false) {
+			options["linenums"] = true
+		}
+		if start, err := strconv.Atoi(fmt.Sprintf("%v", node.Attr("start", nil, false))); err == nil {
+			options["start"] = start
+		}
+	}
+	if node, ok := s.abstractNodable.(Optionable); ok && node.HasOption("linenums") {
+		options["linenums"] = true
+	}
+	res := sourceHighlighter.Highlight(language, source, options)
+	if len(calloutMarks) == 0 {
+		return res
+	}
+	lines := strings.Split(res, "\n")
+	for i, line := range lines {
+		conums, ok := calloutMarks[i+1]
+		if !ok {
+			continue
+		}
+		marks := []string{}
+		for _, conum := range conums {
+			optsInline := &OptionsInline{}
+			if s.Document().Callouts() != nil {
+				optsInline.id = s.Document().Callouts().ReadNextId()
+			}
+			marks = append(marks, s.inlineMaker.NewInline(s.abstractNodable, context.Callout, conum, optsInline).Convert())
+		}
+		lines[i] = line + strings.Join(marks, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+/* Remove the callouts of a line of source (keeping the escaped ones,
+without their backslash), and record their numbers by line number */
+func extractCallouts(line string, lineno int, calloutMarks map[int][]string) string {
+	reres := regexps.NewCalloutExtractRxres(line)
+	if !reres.HasAnyMatch() {
+		return line
+	}
+	res := ""
+	suffix := ""
+	for reres.HasNext() {
+		res = res + reres.Prefix()
+		if reres.IsEscaped() {
+			res = res + strings.Replace(reres.FullMatch(), "\\", "", 1)
+		} else {
+			calloutMarks[lineno] = append(calloutMarks[lineno], reres.CalloutNumber())
+		}
+		suffix = reres.Suffix()
+		reres.Next()
+	}
+	return res + suffix
 }
@@ -25,8 +25,9 @@ func TestSubstitutorSubs(t *testing.T) {
 		So(aToSEValues(string(subsAttributes)), ShouldEqual, subValue.attributes)
 		So(aToSEValues(string(subsReplacements)), ShouldEqual, subValue.replacements)
 		So(aToSEValues(string(subsMacros)), ShouldEqual, subValue.macros)
-		So(aToSEValues(string(subsPostReplacements)), ShouldEqual, subValue.replacements)
+		So(aToSEValues(string(subsPostReplacements)), ShouldEqual, subValue.postReplacements)
 		So(aToSEValues(string(subsCallout)), ShouldEqual, subValue.callouts)
+		So(aToSEValues(string(subsHighlight)), ShouldEqual, subValue.highlight)
 		So(aToSEValues("xxxtestxxx1"), ShouldEqual, nil)
 	})
 	Convey("A composite SE can be converted from string", t, func() {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/VonC/asciidocgo/consts/context"
 	"github.com/VonC/asciidocgo/consts/regexps"
+	"github.com/VonC/asciidocgo/consts/severity"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -19,6 +20,8 @@ type testSubstDocumentAble struct {
 	references      *testReferencable
 	footnotes       []Footnotable
 	counterFootnote int
+	callouts        *callouts
+	logger          *MemoryLogger
 }
 
 type testReferencable struct {
@@ -31,16 +34,18 @@ func (tr *testReferencable) HasId(id string) bool {
 	return false
 }
 func (tr *testReferencable) Get(id string) string {
-	if id == "includes" {
-		return "xxdoc8yy"
-	}
 	return ""
 }
+func (tr *testReferencable) HasInclude(path string) bool {
+	return path == "doc9"
+}
 
 func newTestSubstDocumentAble(s *substitutors) *testSubstDocumentAble {
 	tsd := &testSubstDocumentAble{s: s}
 	tsd.te = &testExtensionables{}
 	tsd.footnotes = []Footnotable{}
+	tsd.callouts = newCallouts()
+	tsd.logger = NewMemoryLogger()
 	return tsd
 }
 
@@ -70,7 +75,7 @@ func (tsd *testSubstDocumentAble) Attr(name string, defaultValue interface{}, in
 	if name == "relfileprefix" {
 		return "relfileprefixAttr"
 	}
-	if name == "outfilesuffix" {
+	if name == "outfilesuffix" || name == "relfilesuffix" {
 		return ""
 	}
 	if name == "docname" {
@@ -84,14 +89,14 @@ func (tsd *testSubstDocumentAble) Basebackend(base interface{}) bool {
 
 type testFootnotable struct {
 	index int
-	id    int
+	id    string
 	text  string
 }
 
 func (tf *testFootnotable) Index() int {
 	return tf.index
 }
-func (tf *testFootnotable) Id() int {
+func (tf *testFootnotable) Id() string {
 	return tf.id
 }
 func (tf *testFootnotable) Text() string {
@@ -102,13 +107,13 @@ func (tf *testFootnotable) String() string {
 	return fmt.Sprintf("footnote(%v,%v): '%v'", tf.Id(), tf.Index(), tf.Text())
 }
 
-func (tsd *testSubstDocumentAble) NewFootnote(index int, id int, text string) Footnotable {
+func (tsd *testSubstDocumentAble) NewFootnote(index int, id string, text string) Footnotable {
 	return &testFootnotable{index: index, id: id, text: text}
 }
 func (tsd *testSubstDocumentAble) RegisterFootnote(f Footnotable) {
 	tsd.footnotes = append(tsd.footnotes, f)
 }
-func (tsd *testSubstDocumentAble) FindFootnote(id int) Footnotable {
+func (tsd *testSubstDocumentAble) FindFootnote(id string) Footnotable {
 	var footnote Footnotable
 	for _, f := range tsd.footnotes {
 		if f.Id() == id {
@@ -119,6 +124,10 @@ func (tsd *testSubstDocumentAble) FindFootnote(id int) Footnotable {
 	return footnote
 }
 
+func (tsd *testSubstDocumentAble) Callouts() Calloutable {
+	return tsd.callouts
+}
+
 func (tsd *testSubstDocumentAble) SubAttributes(data string, opts *OptionsParseAttributes) string {
 	if tsd.s != nil {
 		return tsd.s.SubAttributes(data, opts)
@@ -141,16 +150,22 @@ func (tsd *testSubstDocumentAble) HasAttr(name string, expect interface{}, inher
 	return false
 }
 
-func (tsd *testSubstDocumentAble) Counter(name string, seed int) string {
+func (tsd *testSubstDocumentAble) Counter(name string, seed string) string {
 	if name == "footnote-number" {
 		tsd.counterFootnote = tsd.counterFootnote + 1
 		return strconv.Itoa(tsd.counterFootnote)
 	}
-	seed = seed + 1
-	return strconv.Itoa(seed)
+	iseed, _ := strconv.Atoi(seed)
+	return strconv.Itoa(iseed + 1)
+}
+func (tsd *testSubstDocumentAble) Logger() Logger {
+	return tsd.logger
 }
 func (tsd *testSubstDocumentAble) Register(typeDoc string, value []string) {
 }
+func (tsd *testSubstDocumentAble) RegisterIndexTerm(terms []string, node AbstractNodable) string {
+	return ""
+}
 
 type testGlobalParsable struct {
 }
@@ -583,15 +598,16 @@ the text %s5%s should be passed through as %s6%s text
 			s.document = testDocument
 			So(s.SubAttributes("a {counter:aaa:2} b", opts), ShouldEqual, "a 3 b")
 		})
-		Convey("Reference with non-integer counter directive panic", func() {
+		Convey("Reference with a counter directive seeded by neither a number nor a letter is kept and reported", func() {
+			s.document = testDocument
+			So(s.SubAttributes("a {counter:aaa:bbb} b", opts), ShouldEqual, "a {counter:aaa:bbb} b")
+			messages := testDocument.logger.Messages()
+			So(messages[len(messages)-1].Severity, ShouldEqual, severity.ERROR)
+			So(messages[len(messages)-1].Text, ShouldEqual, "counter reference seed is neither a number nor a letter: 'bbb' for counter 'aaa'")
+		})
+		Convey("Reference with a counter directive can omit the seed", func() {
 			s.document = testDocument
-			recovered := false
-			defer func() {
-				recover()
-				recovered = true
-				So(recovered, ShouldBeTrue)
-			}()
-			s.SubAttributes("a {counter:aaa:bbb} b", opts)
+			So(s.SubAttributes("a {counter:aaa} b", opts), ShouldEqual, "a 1 b")
 		})
 		Convey("Reference with unknown directive warns and returns the all reference", func() {
 			s.document = testDocument
@@ -687,7 +703,7 @@ the text %s5%s should be passed through as %s6%s text
 			So(s.SubMacros("test"), ShouldEqual, "test")
 		})
 		Convey("Substitute kbd macro with single key", func() {
-			So(s.SubMacros("kbd:[F3]"), ShouldEqual, "[]")
+			So(s.SubMacros("kbd:[F3]"), ShouldEqual, "[F3]")
 		})
 		Convey("Substitute kbd macro with escaped single key", func() {
 			So(s.SubMacros(`\kbd:[F3]`), ShouldEqual, "kbd:[F3]")
@@ -954,7 +970,7 @@ the text %s5%s should be passed through as %s6%s text
 			So(s.subInlineXrefs(`xref:id4[reftext4]`, nil), ShouldEqual, "ContextAn 'anchor': text 'reftext4' ===> type 'xref' target '#' attrs: 'map[path: fragment: refid:]'")
 		})
 		Convey("Substitute xref:id#xx[reftext]", func() {
-			So(s.subInlineXrefs(`xref:id5#xxx5[reftext5]`, nil), ShouldEqual, "ContextAn 'anchor': text 'reftext5' ===> type 'xref' target '' attrs: 'map[path:id5 fragment:xxx5 refid:]'")
+			So(s.subInlineXrefs(`xref:id5#xxx5[reftext5]`, nil), ShouldEqual, "ContextAn 'anchor': text 'reftext5' ===> type 'xref' target 'id5.html#xxx5' attrs: 'map[path:id5.html fragment:xxx5 refid:id5#xxx5]'")
 		})
 		Convey("Substitute xref:doc.adoc#xx[reftext]", func() {
 			testDocument := newTestSubstDocumentAble(s)
@@ -964,6 +980,10 @@ the text %s5%s should be passed through as %s6%s text
 
 			So(s.subInlineXrefs(`xref:doc7.adoc7#[reftext7]`, nil), ShouldEqual, "ContextAn 'anchor': text 'reftext7' ===> type 'xref' target 'relfileprefixAttrdoc7.html' attrs: 'map[path:relfileprefixAttrdoc7.html fragment: refid:doc7]'")
 			So(s.subInlineXrefs(`xref:doc8.adoc8#frag8[reftext8]`, nil), ShouldEqual, "ContextAn 'anchor': text 'reftext8' ===> type 'xref' target '#frag8' attrs: 'map[path: fragment:frag8 refid:frag8]'")
+			So(s.subInlineXrefs(`xref:doc9.adoc#frag9[reftext9]`, nil), ShouldEqual, "ContextAn 'anchor': text 'reftext9' ===> type 'xref' target '#frag9' attrs: 'map[path: fragment:frag9 refid:frag9]'")
+			messages := testDocument.logger.Messages()
+			So(len(messages), ShouldEqual, 2)
+			So(messages[1].Text, ShouldEqual, "possible invalid reference: frag9")
 		})
 	})
 
@@ -1001,7 +1021,7 @@ the text %s5%s should be passed through as %s6%s text
 			So(s.SubMacros("test footnote:[text2] ww\n ss"), ShouldEqual, "test ContextFt 'footnote': text 'text2' ===> type '' target '' id '' attrs: 'map[index:6]' ww\n ss")
 			So(len(testDocument.footnotes), ShouldEqual, 6)
 			footnote := testDocument.footnotes[len(testDocument.footnotes)-1]
-			So(footnote.String(), ShouldEqual, "footnote(0,6): 'text2'")
+			So(footnote.String(), ShouldEqual, "footnote(,6): 'text2'")
 		})
 		Convey("Substitute footnoteref:[id]", func() {
 			So(s.SubMacros("test footnoteref:[4] ww\n ss"), ShouldEqual, "test ContextFt 'footnote': text '4' ===> type 'xref' target '4' id '4' attrs: 'map[index:]' ww\n ss")
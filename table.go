@@ -0,0 +1,484 @@
+package asciidocgo
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/VonC/asciidocgo/consts/context"
+	"github.com/VonC/asciidocgo/consts/severity"
+)
+
+/* Methods for managing AsciiDoc tables.
+A table has columns (built from the cols attribute, or from the first row)
+and rows, split into a header row, body rows and a footer row.
+
+Examples
+
+  table = Asciidoctor::Table.new(parent, {'cols' => '1,2'})
+  table.columns.size
+  => 2 */
+type Table struct {
+	*abstractBlock
+	columns         []*TableColumn
+	rows            *TableRows
+	hasHeaderOption bool
+}
+
+/* The rows of a table: the header rows, the body rows and the footer rows.
+Each row is an Array of cells */
+type TableRows struct {
+	head [][]*TableCell
+	body [][]*TableCell
+	foot [][]*TableCell
+}
+
+/* The header rows (at most one) */
+func (tr *TableRows) Head() [][]*TableCell {
+	return tr.head
+}
+
+/* The body rows */
+func (tr *TableRows) Body() [][]*TableCell {
+	return tr.body
+}
+
+/* The footer rows (at most one) */
+func (tr *TableRows) Foot() [][]*TableCell {
+	return tr.foot
+}
+
+/* The precision of the computed column widths (4 decimals) */
+const tablePrecisionFactor = 10000
+
+/* Initialize an Asciidoctor::Table object.
+parent     - The parent Asciidoc Object.
+attributes - The block attributes of the table: the width attribute
+('50%') sets the tablepcwidth attribute (100 by default) */
+func newTable(parent *abstractBlock, attributes map[string]interface{}) *Table {
+	ab := newAbstractBlock(parent, context.Table)
+	_, hasHeaderOption := attributes["header-option"]
+	table := &Table{ab, []*TableColumn{}, &TableRows{}, hasHeaderOption}
+	ab.MainNode(table)
+	pcwidth := 100
+	if width, ok := attributes["width"].(string); ok {
+		if pcwidth, _ = strconv.Atoi(strings.TrimSuffix(width, "%")); pcwidth > 100 || pcwidth < 1 {
+			if pcwidth != 0 || (width != "0" && width != "0%") {
+				pcwidth = 100
+			}
+		}
+	}
+	table.setAttr("tablepcwidth", pcwidth, true)
+	return table
+}
+
+/* The columns of this table */
+func (t *Table) Columns() []*TableColumn {
+	return t.columns
+}
+
+/* The rows of this table */
+func (t *Table) Rows() *TableRows {
+	return t.rows
+}
+
+/* Check whether the row being parsed is the header row:
+the table has the header option, and no row has been parsed yet */
+func (t *Table) IsHeaderRow() bool {
+	return t.hasHeaderOption && len(t.rows.body) == 0
+}
+
+/* Create the columns of this table from the column specs parsed from
+the cols attribute, and assign their widths */
+func (t *Table) createColumns(colspecs []map[string]interface{}) {
+	widthBase := 0
+	for _, colspec := range colspecs {
+		widthBase = widthBase + colspec["width"].(int)
+		t.columns = append(t.columns, newTableColumn(t, len(t.columns), colspec))
+	}
+	if len(t.columns) > 0 {
+		t.setAttr("colcount", len(t.columns), true)
+		t.assignColumnWidths(widthBase)
+	}
+}
+
+/* Assign the width of each column, as a percentage of the table width:
+proportionally to the column widths if widthBase (their sum) is not 0,
+or evenly otherwise.
+The rounding balance goes to the last column, so that the widths
+add up to 100 */
+func (t *Table) assignColumnWidths(widthBase int) {
+	total, colwidth := 0, 0
+	for _, column := range t.columns {
+		if widthBase > 0 {
+			colwidth = column.Attr("width", 1, false).(int) * 100 * tablePrecisionFactor / widthBase
+		} else {
+			colwidth = 100 * tablePrecisionFactor / len(t.columns)
+		}
+		column.setAttr("colpcwidth", formatPcWidth(colwidth), true)
+		total = total + colwidth
+	}
+	if total != 100*tablePrecisionFactor {
+		t.columns[len(t.columns)-1].setAttr("colpcwidth", formatPcWidth(colwidth+100*tablePrecisionFactor-total), true)
+	}
+}
+
+// Format a width expressed in 1/10000 of a percent ("33.3333", "50")
+func formatPcWidth(width int) string {
+	return strconv.FormatFloat(float64(width)/tablePrecisionFactor, 'f', -1, 64)
+}
+
+/* Split the header and footer rows from the body rows:
+the first row is the header row if the table has the header option
+(the styles of its cells are dropped, AsciiDoc cells included), and the last row is the footer
+row if the table has the footer option.
+The rowcount attribute is set to the total number of rows */
+func (t *Table) partitionHeaderFooter(attributes map[string]interface{}) {
+	t.setAttr("rowcount", len(t.rows.body), true)
+	if len(t.rows.body) > 0 && t.hasHeaderOption {
+		head := t.rows.body[0]
+		t.rows.body = t.rows.body[1:]
+		for _, cell := range head {
+			// an implicit header row is only known once its cells are parsed
+			cell.style, cell.innerDocument = "", nil
+		}
+		t.rows.head = [][]*TableCell{head}
+	}
+	if _, footer := attributes["footer-option"]; len(t.rows.body) > 0 && footer {
+		t.rows.foot = [][]*TableCell{t.rows.body[len(t.rows.body)-1]}
+		t.rows.body = t.rows.body[:len(t.rows.body)-1]
+	}
+}
+
+/* Methods to manage the columns of an AsciiDoc table.
+A column has a colnumber (1-based), a width (relative to the other
+columns), a horizontal and a vertical alignment, and an optional style
+applied to its cells */
+type TableColumn struct {
+	*abstractNode
+	table *Table
+	style string
+}
+
+/* Initialize an Asciidoctor::Table::Column object.
+table      - The table of the column.
+index      - The Integer index (0-based) of the column.
+attributes - The attributes parsed from the column spec (may be nil) */
+func newTableColumn(table *Table, index int, attributes map[string]interface{}) *TableColumn {
+	column := &TableColumn{newAbstractNode(table.abstractNode, context.TableColumn), table, ""}
+	column.style, _ = attributes["style"].(string)
+	column.setAttr("colnumber", index+1, true)
+	column.setAttr("width", 1, true)
+	column.setAttr("halign", "left", true)
+	column.setAttr("valign", "top", true)
+	for name, value := range attributes {
+		column.setAttr(name, value, true)
+	}
+	return column
+}
+
+/* The table of this column */
+func (tc *TableColumn) Table() *Table {
+	return tc.table
+}
+
+/* The style of the cells of this column ("" for the default style) */
+func (tc *TableColumn) Style() string {
+	return tc.style
+}
+
+/* Methods for managing the cells of an AsciiDoc table.
+A cell inherits the attributes of its column (alignments and style),
+overridden by its own cell spec.
+The text of an AsciiDoc cell (style 'asciidoc') is parsed as a nested
+document. */
+type TableCell struct {
+	*abstractNode
+	column        *TableColumn
+	text          string
+	style         string
+	colspan       int
+	rowspan       int
+	innerDocument *Document
+}
+
+/* Initialize an Asciidoctor::Table::Cell object.
+column     - The column of the cell.
+text       - The String source text of the cell.
+attributes - The attributes parsed from the cell spec (may be nil):
+colspan, rowspan, halign, valign and style.
+cursor     - The position of the cell in the source (may be nil) */
+func newTableCell(column *TableColumn, text string, attributes map[string]interface{}, cursor *Cursor) *TableCell {
+	cell := &TableCell{newAbstractNode(column.abstractNode, context.TableCell), column, "", column.style, 0, 0, nil}
+	for name, value := range column.Attributes() {
+		cell.setAttr(name, value, true)
+	}
+	for name, value := range attributes {
+		switch name {
+		case "colspan":
+			cell.colspan = value.(int)
+		case "rowspan":
+			cell.rowspan = value.(int)
+		default:
+			if name == "style" {
+				cell.style = value.(string)
+			}
+			cell.setAttr(name, value, true)
+		}
+	}
+	if cell.style == "literal" {
+		cell.text = strings.TrimLeft(strings.TrimRight(text, " \t\n"), "\n")
+	} else {
+		cell.text = strings.TrimSpace(text)
+	}
+	// only allow AsciiDoc cells in non-header rows
+	if parent, ok := cell.Document().(*Document); ok && cell.style == "asciidoc" && !column.table.IsHeaderRow() {
+		cell.innerDocument = newInnerDocument(strings.Split(cell.text, "\n"), parent, cursor)
+		cell.innerDocument.Parse()
+	}
+	return cell
+}
+
+/* The column of this cell */
+func (tc *TableCell) Column() *TableColumn {
+	return tc.column
+}
+
+/* The style of this cell ("" for the default style) */
+func (tc *TableCell) Style() string {
+	return tc.style
+}
+
+/* The number of columns this cell spans (0 if it does not span) */
+func (tc *TableCell) Colspan() int {
+	return tc.colspan
+}
+
+/* The number of rows this cell spans (0 if it does not span) */
+func (tc *TableCell) Rowspan() int {
+	return tc.rowspan
+}
+
+/* The nested document parsed from an AsciiDoc cell (nil otherwise) */
+func (tc *TableCell) InnerDocument() *Document {
+	return tc.innerDocument
+}
+
+/* Get the text of this cell, with the normal substitutions applied */
+func (tc *TableCell) Text() string {
+	return tc.ApplySubs(tc.text, subs[sub.normal], false)
+}
+
+// Matches the blank lines separating the paragraphs of a cell
+var blankLineRx, _ = regexp.Compile(`\n[ \t]*\n+`)
+
+/* Get the converted content of this cell: the nested document of an
+AsciiDoc cell, or else one String for each paragraph of the text,
+quoted according to the style of the cell (strong, emphasis or
+monospaced) */
+func (tc *TableCell) Content() []string {
+	if tc.style == "asciidoc" && tc.innerDocument != nil {
+		content, _ := tc.innerDocument.Render()
+		return []string{strings.TrimSuffix(content, "\n")}
+	}
+	res := []string{}
+	for _, paragraph := range blankLineRx.Split(tc.Text(), -1) {
+		switch tc.style {
+		case "strong", "emphasis", "monospaced":
+			paragraph = newInline(tc.abstractNode, context.Quoted, paragraph, &OptionsInline{typeInline: tc.style}).Convert()
+		}
+		res = append(res, paragraph)
+	}
+	return res
+}
+
+/* The state of the parsing of the lines of a table: the cell being
+read, the specs of the cells to come and the row being filled */
+type tableParserContext struct {
+	table          *Table
+	format         string
+	delimiter      string
+	colcount       int
+	buffer         string
+	cellspecs      []map[string]interface{}
+	cellOpen       bool
+	activeRowspans []int
+	columnVisits   int
+	currentRow     []*TableCell
+	linenum        int
+	cursor         *Cursor
+}
+
+/* The supported data formats of a table, and their default delimiter */
+var tableFormats = map[string]string{"psv": "|", "csv": ",", "dsv": ":", "tsv": "\t"}
+
+/* Initialize the parser context of a table, from its format and
+separator attributes.
+An unknown format is reported, and replaced by 'psv' */
+func newTableParserContext(table *Table, attributes map[string]interface{}) *tableParserContext {
+	ctx := &tableParserContext{table: table, format: "psv", colcount: -1, activeRowspans: []int{0}, linenum: -1}
+	xsv := "psv"
+	if format, ok := attributes["format"].(string); ok {
+		if _, known := tableFormats[format]; known {
+			xsv = format
+		} else {
+			table.log(severity.WARN, fmt.Sprintf("illegal table format: %s", format))
+		}
+	}
+	ctx.format, ctx.delimiter = xsv, tableFormats[xsv]
+	if xsv == "tsv" {
+		ctx.format = "csv"
+	}
+	if separator, ok := attributes["separator"].(string); ok && separator != "" {
+		ctx.delimiter = separator
+		if separator == `\t` {
+			ctx.delimiter = "\t"
+		}
+	}
+	if len(table.columns) > 0 {
+		ctx.colcount = len(table.columns)
+	}
+	return ctx
+}
+
+/* Report a message located at the line being parsed */
+func (ctx *tableParserContext) log(s severity.Severity, text string) {
+	if ctx.table.Document() != nil {
+		logMessage(ctx.table.Document().Logger(), s, text, ctx.cursor)
+	}
+}
+
+/* Check whether the buffer (followed by appended) holds an opening
+double quote which is not closed yet, for the csv format */
+func (ctx *tableParserContext) bufferHasUnclosedQuotes(appended string) bool {
+	record := strings.TrimSpace(ctx.buffer + appended)
+	if !strings.HasPrefix(record, `"`) {
+		return false
+	}
+	trailingQuote := strings.HasSuffix(record, `"`)
+	if (trailingQuote && strings.HasSuffix(record, `""`)) || strings.HasPrefix(record, `""`) {
+		record = strings.Replace(record, `""`, "", -1)
+		return strings.HasPrefix(record, `"`) && !strings.HasSuffix(record, `"`)
+	}
+	return !trailingQuote
+}
+
+/* Queue the spec of the next cell (which may be empty) */
+func (ctx *tableParserContext) pushCellspec(cellspec map[string]interface{}) {
+	if cellspec == nil {
+		cellspec = map[string]interface{}{}
+	}
+	ctx.cellspecs = append(ctx.cellspecs, cellspec)
+}
+
+/* Queue the spec of the next cell, and close the cell being read,
+if any: a new line starts with a cell separator */
+func (ctx *tableParserContext) closeOpenCell(cellspec map[string]interface{}) {
+	ctx.pushCellspec(cellspec)
+	if ctx.cellOpen {
+		ctx.closeCell(true)
+	}
+	ctx.linenum++
+}
+
+/* Close the cell being read: build a cell (or several, for a duplicated
+cell) from the buffer and its spec, and add it to the current row.
+The row is closed once all its columns have been visited.
+eol - true if the cell is closed at the end of a line */
+func (ctx *tableParserContext) closeCell(eol bool) {
+	var cellText string
+	var cellspec map[string]interface{}
+	repeat := 1
+	if ctx.format == "psv" {
+		cellText = ctx.buffer
+		if len(ctx.cellspecs) > 0 {
+			cellspec, ctx.cellspecs = ctx.cellspecs[0], ctx.cellspecs[1:]
+			if repeatcol, ok := cellspec["repeatcol"].(int); ok {
+				repeat = repeatcol
+				delete(cellspec, "repeatcol")
+			}
+		} else {
+			ctx.log(severity.ERROR, "table missing leading separator, recovering automatically")
+			cellspec = map[string]interface{}{}
+		}
+	} else {
+		cellText = strings.TrimSpace(ctx.buffer)
+		if ctx.format == "csv" && strings.Contains(cellText, `"`) {
+			if len(cellText) > 1 && strings.HasPrefix(cellText, `"`) && strings.HasSuffix(cellText, `"`) {
+				cellText = strings.TrimSpace(cellText[1 : len(cellText)-1])
+			}
+			cellText = strings.Replace(cellText, `""`, `"`, -1)
+		}
+	}
+	ctx.buffer = ""
+	colspan := 1
+	if span, ok := cellspec["colspan"].(int); ok {
+		colspan = span
+	}
+	for i := 1; i <= repeat; i++ {
+		var column *TableColumn
+		if ctx.colcount == -1 {
+			column = newTableColumn(ctx.table, len(ctx.table.columns), nil)
+			ctx.table.columns = append(ctx.table.columns, column)
+			for extra := 1; extra < colspan; extra++ {
+				ctx.table.columns = append(ctx.table.columns, newTableColumn(ctx.table, len(ctx.table.columns), nil))
+			}
+		} else {
+			index := ctx.columnVisits + ctx.activeRowspans[0]
+			if index >= len(ctx.table.columns) {
+				ctx.log(severity.ERROR, "dropping cell because it exceeds specified number of columns")
+				ctx.cellOpen = false
+				return
+			}
+			column = ctx.table.columns[index]
+		}
+		spec := map[string]interface{}{}
+		for name, value := range cellspec {
+			spec[name] = value
+		}
+		cell := newTableCell(column, cellText, spec, ctx.cursor)
+		if cell.rowspan > 1 {
+			ctx.activateRowspan(cell.rowspan, colspan)
+		}
+		ctx.columnVisits = ctx.columnVisits + colspan
+		ctx.currentRow = append(ctx.currentRow, cell)
+		// don't close the row if we're on the first line and the column count has not been set explicitly
+		if ctx.isEndOfRow() && (ctx.colcount != -1 || ctx.linenum > 0 || (eol && i == repeat)) {
+			ctx.closeRow()
+		}
+	}
+	ctx.cellOpen = false
+}
+
+/* Add the current row to the body rows of the table.
+The first row sets the number of columns, if not set explicitly */
+func (ctx *tableParserContext) closeRow() {
+	ctx.table.rows.body = append(ctx.table.rows.body, ctx.currentRow)
+	if ctx.colcount == -1 {
+		ctx.colcount = ctx.columnVisits
+	}
+	ctx.columnVisits = 0
+	ctx.currentRow = nil
+	ctx.activeRowspans = ctx.activeRowspans[1:]
+	if len(ctx.activeRowspans) == 0 {
+		ctx.activeRowspans = []int{0}
+	}
+}
+
+/* Reserve the columns spanned by a cell in the next rows */
+func (ctx *tableParserContext) activateRowspan(rowspan, colspan int) {
+	for i := 1; i < rowspan; i++ {
+		if i < len(ctx.activeRowspans) {
+			ctx.activeRowspans[i] = ctx.activeRowspans[i] + colspan
+		} else {
+			ctx.activeRowspans = append(ctx.activeRowspans, colspan)
+		}
+	}
+}
+
+/* Check whether all the columns of the current row have been visited,
+including the ones spanned by the cells of the previous rows */
+func (ctx *tableParserContext) isEndOfRow() bool {
+	return ctx.colcount == -1 || ctx.columnVisits+ctx.activeRowspans[0] == ctx.colcount
+}
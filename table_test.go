@@ -0,0 +1,116 @@
+package asciidocgo
+
+import (
+	"testing"
+
+	"github.com/VonC/asciidocgo/consts/context"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTable(t *testing.T) {
+
+	Convey("A Table can be initialized", t, func() {
+		table := newTable(nil, map[string]interface{}{})
+		So(table.Context(), ShouldEqual, context.Table)
+		So(table.TemplateName(), ShouldEqual, "block_table")
+		So(table.Node(), ShouldEqual, table)
+		So(table.Attr("tablepcwidth", nil, false), ShouldEqual, 100)
+		So(len(table.Columns()), ShouldEqual, 0)
+		So(len(table.Rows().Body()), ShouldEqual, 0)
+		So(table.IsHeaderRow(), ShouldBeFalse)
+
+		Convey("Its width attribute sets its width as a percentage", func() {
+			So(newTable(nil, map[string]interface{}{"width": "50%"}).Attr("tablepcwidth", nil, false), ShouldEqual, 50)
+			So(newTable(nil, map[string]interface{}{"width": "150"}).Attr("tablepcwidth", nil, false), ShouldEqual, 100)
+		})
+		Convey("Its header option makes the first row a header row", func() {
+			So(newTable(nil, map[string]interface{}{"header-option": ""}).IsHeaderRow(), ShouldBeTrue)
+		})
+	})
+
+	Convey("A Table creates its columns and assigns their widths", t, func() {
+		table := newTable(nil, map[string]interface{}{})
+		table.createColumns([]map[string]interface{}{{"width": 1}, {"width": 2, "style": "monospaced"}, {"width": 3, "halign": "right"}})
+		So(table.Attr("colcount", nil, false), ShouldEqual, 3)
+		columns := table.Columns()
+		So(len(columns), ShouldEqual, 3)
+		So(columns[0].Attr("colnumber", nil, false), ShouldEqual, 1)
+		So(columns[0].Attr("colpcwidth", nil, false), ShouldEqual, "16.6666")
+		So(columns[1].Attr("colpcwidth", nil, false), ShouldEqual, "33.3333")
+		So(columns[2].Attr("colpcwidth", nil, false), ShouldEqual, "50.0001")
+		So(columns[1].Style(), ShouldEqual, "monospaced")
+		So(columns[1].Table(), ShouldEqual, table)
+		So(columns[2].Attr("halign", nil, false), ShouldEqual, "right")
+		So(columns[2].Attr("valign", nil, false), ShouldEqual, "top")
+
+		Convey("Columns without specs share the width evenly", func() {
+			table.assignColumnWidths(0)
+			So(columns[0].Attr("colpcwidth", nil, false), ShouldEqual, "33.3333")
+			So(columns[2].Attr("colpcwidth", nil, false), ShouldEqual, "33.3334")
+		})
+	})
+
+	Convey("A Table partitions its header and footer rows", t, func() {
+		table := newTable(nil, map[string]interface{}{"header-option": ""})
+		table.createColumns([]map[string]interface{}{{"width": 1, "style": "strong"}})
+		for _, text := range []string{"head", "body", "foot"} {
+			table.rows.body = append(table.rows.body, []*TableCell{newTableCell(table.columns[0], text, nil, nil)})
+		}
+		table.partitionHeaderFooter(map[string]interface{}{"footer-option": ""})
+		So(table.Attr("rowcount", nil, false), ShouldEqual, 3)
+		So(table.Rows().Head()[0][0].Text(), ShouldEqual, "head")
+		So(table.Rows().Head()[0][0].Style(), ShouldEqual, "")
+		So(table.Rows().Body()[0][0].Text(), ShouldEqual, "body")
+		So(table.Rows().Foot()[0][0].Style(), ShouldEqual, "strong")
+	})
+
+	Convey("A TableCell inherits from its column and its cell spec", t, func() {
+		table := newTable(NewDocument([]string{}, nil).abstractBlock, map[string]interface{}{})
+		table.createColumns([]map[string]interface{}{{"width": 1, "style": "emphasis", "halign": "center"}})
+		cell := newTableCell(table.columns[0], " a\n\nb ", map[string]interface{}{"colspan": 2, "valign": "bottom"}, nil)
+		So(cell.Context(), ShouldEqual, context.TableCell)
+		So(cell.Column(), ShouldEqual, table.columns[0])
+		So(cell.Text(), ShouldEqual, "a\n\nb")
+		So(cell.Style(), ShouldEqual, "emphasis")
+		So(cell.Colspan(), ShouldEqual, 2)
+		So(cell.Rowspan(), ShouldEqual, 0)
+		So(cell.Attr("halign", nil, false), ShouldEqual, "center")
+		So(cell.Attr("valign", nil, false), ShouldEqual, "bottom")
+		So(cell.InnerDocument(), ShouldBeNil)
+		So(cell.Content(), ShouldResemble, []string{"<em>a</em>", "<em>b</em>"})
+
+		Convey("A literal cell keeps its indentation", func() {
+			cell := newTableCell(table.columns[0], "\n  a\n  b \n", map[string]interface{}{"style": "literal"}, nil)
+			So(cell.Style(), ShouldEqual, "literal")
+			So(cell.Text(), ShouldEqual, "  a\n  b")
+		})
+	})
+
+	Convey("A tableParserContext reads the cells of a table", t, func() {
+		table := newTable(nil, map[string]interface{}{})
+		ctx := newTableParserContext(table, map[string]interface{}{"format": "csv", "separator": `\t`})
+		So(ctx.format, ShouldEqual, "csv")
+		So(ctx.delimiter, ShouldEqual, "\t")
+		So(newTableParserContext(table, map[string]interface{}{"format": "tsv"}).format, ShouldEqual, "csv")
+		So(newTableParserContext(table, map[string]interface{}{"format": "dsv"}).delimiter, ShouldEqual, ":")
+
+		Convey("Unclosed quotes keep a csv cell open", func() {
+			ctx.buffer = `"a`
+			So(ctx.bufferHasUnclosedQuotes(""), ShouldBeTrue)
+			So(ctx.bufferHasUnclosedQuotes(` b"`), ShouldBeFalse)
+			ctx.buffer = `"a ""q"" `
+			So(ctx.bufferHasUnclosedQuotes(""), ShouldBeTrue)
+		})
+
+		Convey("The first row sets the number of columns", func() {
+			for i, text := range []string{"a", `"b,c"`} {
+				ctx.buffer = text
+				ctx.closeCell(i == 1)
+			}
+			So(len(table.Columns()), ShouldEqual, 2)
+			So(ctx.colcount, ShouldEqual, 2)
+			So(len(table.Rows().Body()), ShouldEqual, 1)
+			So(table.Rows().Body()[0][1].Text(), ShouldEqual, "b,c")
+		})
+	})
+}
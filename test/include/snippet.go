@@ -0,0 +1,11 @@
+package main
+
+// tag::imports[]
+import "fmt"
+// end::imports[]
+
+func main() {
+	// tag::body[]
+	fmt.Println("hello")
+	// end::body[]
+}